@@ -0,0 +1,153 @@
+// Command agent manages the Hyperliquid agent (API) wallet a provider signs
+// with through `agent_key_file` in etc/exchange.yaml.
+//
+//	agent status  lists the account's agent approvals and when they lapse.
+//	agent rotate  generates a new agent key, approves it from the main wallet
+//	              and replaces the key file; running providers switch to the
+//	              new key within a second, without a restart.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	exchangepkg "nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/hyperliquid"
+)
+
+func main() {
+	flags := flag.NewFlagSet("agent", flag.ExitOnError)
+	configFile := flags.String("f", "etc/exchange.yaml", "the exchange config file")
+	providerName := flags.String("provider", "", "hyperliquid provider to manage (default: the config default)")
+	name := flags.String("name", "nof0", "agent name; rotations alternate between <name>-a and <name>-b")
+	validFor := flags.Duration("valid-for", 30*24*time.Hour, "how long a new approval lasts (max 180 days)")
+	mainKeyEnv := flags.String("main-key-env", "HYPERLIQUID_MAIN_PRIVATE_KEY", "environment variable holding the main wallet key")
+	mainSigner := flags.String("main-signer", "", "sign the approval through this signing daemon instead of a main wallet key")
+	mainSignerTokenEnv := flags.String("main-signer-token-env", "SIGNER_TOKEN", "environment variable holding the signing daemon token")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: agent [flags] status|rotate\n")
+		flags.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	_ = flags.Parse(os.Args[2:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := loadProvider(*configFile, *providerName)
+	if err != nil {
+		log.Fatalf("agent: %v", err)
+	}
+	switch command {
+	case "status":
+		err = status(ctx, cfg)
+	case "rotate":
+		var main *hyperliquid.Client
+		main, err = mainClient(ctx, cfg, os.Getenv(*mainKeyEnv), *mainSigner, os.Getenv(*mainSignerTokenEnv))
+		if err == nil {
+			err = rotate(ctx, main, cfg, *name, *validFor)
+		}
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("agent: %v", err)
+	}
+}
+
+func loadProvider(path, name string) (*exchangepkg.ProviderConfig, error) {
+	cfg, err := exchangepkg.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = cfg.Default
+	}
+	provider, ok := cfg.Providers[name]
+	if !ok {
+		return nil, fmt.Errorf("provider %q not found in %s", name, path)
+	}
+	if !strings.EqualFold(provider.Type, "hyperliquid") || provider.AgentKeyFile == "" {
+		return nil, fmt.Errorf("provider %q must be a hyperliquid provider with agent_key_file and main_address", name)
+	}
+	return provider, nil
+}
+
+// mainClient signs with the main wallet, either from a key or through a
+// signing daemon that allows approveAgent.
+func mainClient(ctx context.Context, cfg *exchangepkg.ProviderConfig, key, signerEndpoint, signerToken string) (*hyperliquid.Client, error) {
+	var opts []hyperliquid.ClientOption
+	switch {
+	case signerEndpoint != "":
+		signer, err := hyperliquid.NewRemoteSigner(ctx, signerEndpoint, hyperliquid.WithRemoteSignerToken(signerToken))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, hyperliquid.WithSigner(signer))
+	case key == "":
+		return nil, errors.New("the main wallet key is required to approve agents (see -main-key-env and -main-signer)")
+	}
+	client, err := hyperliquid.NewClient(key, cfg.Testnet, append(opts, hyperliquid.WithMainAddress(cfg.MainAddress))...)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func rotate(ctx context.Context, main *hyperliquid.Client, cfg *exchangepkg.ProviderConfig, name string, validFor time.Duration) error {
+	rotation, err := hyperliquid.RotateAgentKey(ctx, main, cfg.AgentKeyFile, name, validFor)
+	if err != nil {
+		return err
+	}
+	log.Printf("agent: approved %s as %q until %s and wrote it to %s",
+		rotation.Address, rotation.Name, rotation.ValidUntil.UTC().Format(time.RFC3339), cfg.AgentKeyFile)
+	if rotation.Previous != "" {
+		log.Printf("agent: previous agent %s stays approved until it expires or the next rotation replaces it", rotation.Previous)
+	}
+	return nil
+}
+
+func status(ctx context.Context, cfg *exchangepkg.ProviderConfig) error {
+	signer, err := hyperliquid.NewKeyFileSigner(cfg.AgentKeyFile)
+	if err != nil {
+		return err
+	}
+	client, err := hyperliquid.NewClient("", cfg.Testnet, hyperliquid.WithSigner(signer), hyperliquid.WithMainAddress(cfg.MainAddress))
+	if err != nil {
+		return err
+	}
+	approvals, err := client.GetAgentApprovals(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tADDRESS\tVALID UNTIL\tREMAINING\tIN USE")
+	for _, a := range approvals {
+		until, remaining := "never", "-"
+		if !a.ValidUntil.IsZero() {
+			until = a.ValidUntil.UTC().Format(time.RFC3339)
+			remaining = a.ValidUntil.Sub(now).Round(time.Minute).String()
+		}
+		inUse := ""
+		if strings.EqualFold(a.Address, signer.GetAddress()) {
+			inUse = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Name, a.Address, until, remaining, inUse)
+	}
+	return w.Flush()
+}
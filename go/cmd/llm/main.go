@@ -18,11 +18,13 @@ import (
 	"nof0-api/internal/ingest"
 	enginepersist "nof0-api/internal/persistence/engine"
 	marketpersist "nof0-api/internal/persistence/market"
+	simpersist "nof0-api/internal/persistence/sim"
 	"nof0-api/internal/svc"
 	"nof0-api/pkg/confkit"
 	exchangepkg "nof0-api/pkg/exchange"
+	_ "nof0-api/pkg/exchange/binance"
 	_ "nof0-api/pkg/exchange/hyperliquid"
-	_ "nof0-api/pkg/exchange/sim"
+	simexchange "nof0-api/pkg/exchange/sim"
 	executorpkg "nof0-api/pkg/executor"
 	llmpkg "nof0-api/pkg/llm"
 	managerpkg "nof0-api/pkg/manager"
@@ -254,6 +256,7 @@ func main() {
 		promptProfile = flag.String("executor-prompt-profile", "default", "executor prompt profile (default|fast)")
 		paperTrading  = flag.Bool("paper-trading", false, "route trades to the in-memory simulator instead of live exchanges")
 		paperExchange = flag.String("paper-exchange-provider", "paper_trading", "exchange provider id to use when --paper-trading is enabled")
+		shadowExport  = flag.String("shadow-export", "", "append shadow provider order comparisons to this file as JSON lines")
 	)
 	flag.Parse()
 	logx.MustSetup(logx.LogConf{})
@@ -408,6 +411,46 @@ func main() {
 			}
 		}
 	}
+	var persistedSims []*simexchange.Provider
+	for name, provider := range exchangeProviders {
+		cfg := exchangeCfg.Providers[name]
+		if cfg == nil || cfg.Sim == nil || cfg.Sim.State == nil || cfg.Sim.State.Backend != "redis" {
+			continue
+		}
+		provider = unwrapProvider(provider)
+		simProvider, ok := provider.(*simexchange.Provider)
+		if mirror, mirrored := provider.(simulatorOwner); mirrored {
+			simProvider, ok = mirror.Simulator(), true
+		}
+		if !ok {
+			continue
+		}
+		if svcCtx == nil || svcCtx.Cache == nil {
+			logx.Slowf("sim provider %s: redis state backend requested but cache is not configured; state will not persist", name)
+			continue
+		}
+		if err := simProvider.AttachStore(context.Background(), simpersist.NewRedisStore(svcCtx.Cache, name)); err != nil {
+			fatalf("sim provider %s: restore state: %v", name, err)
+		}
+		persistedSims = append(persistedSims, simProvider)
+	}
+	shadowReporters := make(map[string]shadowReporter)
+	for name, provider := range exchangeProviders {
+		if reporter, ok := unwrapProvider(provider).(shadowReporter); ok {
+			shadowReporters[name] = reporter
+		}
+	}
+	if strings.TrimSpace(*shadowExport) != "" && len(shadowReporters) > 0 {
+		exporter, err := newComparisonExporter(*shadowExport)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		defer exporter.Close()
+		for name, reporter := range shadowReporters {
+			reporter.SetComparisonListener(exporter.listener(name))
+		}
+		logx.Infof("exporting shadow order comparisons to %s", *shadowExport)
+	}
 	ingestor := ingest.NewMarketIngestor(filteredMarkets, allowedSymbols, 45*time.Second, 30*time.Minute, 150*time.Millisecond)
 	var conversationRecorder executorpkg.ConversationRecorder
 	if rec, ok := persistService.(executorpkg.ConversationRecorder); ok {
@@ -441,6 +484,9 @@ func main() {
 	if ingestor != nil {
 		go ingestor.Run(ctx)
 	}
+	if len(shadowReporters) > 0 {
+		go reportShadow(ctx, shadowReporters)
+	}
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -455,5 +501,9 @@ func main() {
 	if err := mgr.RunTradingLoop(ctx); err != nil && err != context.Canceled {
 		fatalf("manager loop exited with error: %v", err)
 	}
+	// Sim state is saved in the background; write out the last changes.
+	for _, simProvider := range persistedSims {
+		simProvider.Flush()
+	}
 	logx.Info("manager loop stopped")
 }
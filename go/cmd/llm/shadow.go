@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	exchangepkg "nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/shadow"
+	simexchange "nof0-api/pkg/exchange/sim"
+)
+
+// shadowReportInterval is how often shadow providers log their comparison
+// summary and position divergence.
+const shadowReportInterval = 15 * time.Minute
+
+// shadowReporter is implemented by shadow providers.
+type shadowReporter interface {
+	DryRun() bool
+	SetComparisonListener(fn func(shadow.OrderComparison))
+	Summary() shadow.Summary
+	Divergence(ctx context.Context) ([]shadow.PositionDivergence, error)
+}
+
+// simulatorOwner is implemented by decorators that mirror into a simulator.
+type simulatorOwner interface {
+	Simulator() *simexchange.Provider
+}
+
+// unwrapProvider strips the risk gateway so decorator interfaces are visible.
+func unwrapProvider(provider exchangepkg.Provider) exchangepkg.Provider {
+	if gateway, gated := provider.(*exchangepkg.RiskGateway); gated {
+		return gateway.Unwrap()
+	}
+	return provider
+}
+
+// comparisonExporter appends shadow comparisons to a file as JSON lines.
+type comparisonExporter struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func newComparisonExporter(path string) (*comparisonExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open shadow export %s: %w", path, err)
+	}
+	return &comparisonExporter{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// listener returns a comparison listener tagging records with provider.
+func (e *comparisonExporter) listener(provider string) func(shadow.OrderComparison) {
+	return func(c shadow.OrderComparison) {
+		record := struct {
+			Provider string `json:"provider"`
+			shadow.OrderComparison
+		}{Provider: provider, OrderComparison: c}
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if err := e.enc.Encode(record); err != nil {
+			logx.Errorf("shadow provider %s: export comparison: %v", provider, err)
+		}
+	}
+}
+
+func (e *comparisonExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.file.Close()
+}
+
+// reportShadow logs each shadow provider's comparison summary and, unless it
+// runs dry, its position divergence until ctx is done.
+func reportShadow(ctx context.Context, reporters map[string]shadowReporter) {
+	ticker := time.NewTicker(shadowReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for name, reporter := range reporters {
+			s := reporter.Summary()
+			logx.Infof("shadow provider %s: orders=%d compared=%d mean_slippage_bps=%.2f max_abs_slippage_bps=%.2f status_mismatches=%d",
+				name, s.Orders, s.Compared, s.MeanSlippageBps, s.MaxAbsSlippageBps, s.StatusMismatches)
+			if reporter.DryRun() {
+				continue
+			}
+			divergence, err := reporter.Divergence(ctx)
+			if err != nil {
+				logx.Errorf("shadow provider %s: divergence: %v", name, err)
+				continue
+			}
+			for _, d := range divergence {
+				if d.SizeDiff == 0 {
+					continue
+				}
+				logx.Slowf("shadow provider %s: %s position diverged live_szi=%.6f sim_szi=%.6f live_entry=%.6f sim_entry=%.6f",
+					name, d.Coin, d.LiveSzi, d.SimSzi, d.LiveEntryPx, d.SimEntryPx)
+			}
+		}
+	}
+}
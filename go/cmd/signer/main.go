@@ -0,0 +1,147 @@
+// Command signer is the reference signing daemon for Hyperliquid. It holds
+// the private key, so trading processes configured with a `signer:` block in
+// etc/exchange.yaml never do, and it signs only the actions its policy allows.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"nof0-api/pkg/exchange/hyperliquid"
+)
+
+// Config is the daemon configuration (see etc/signer.yaml).
+type Config struct {
+	// Listen is unix:///path/to.sock or http://host:port.
+	Listen     string       `yaml:"listen"`
+	PrivateKey string       `yaml:"private_key"`
+	Testnet    bool         `yaml:"testnet"`
+	Token      string       `yaml:"token"`
+	Policy     PolicyConfig `yaml:"policy"`
+}
+
+// PolicyConfig mirrors hyperliquid.SignerPolicy with coin names instead of
+// asset ids.
+type PolicyConfig struct {
+	AllowedActions      []string `yaml:"allowed_actions"`
+	AllowedAssets       []string `yaml:"allowed_assets"`
+	AllowedVaults       []string `yaml:"allowed_vaults"`
+	MaxOrderNotionalUSD float64  `yaml:"max_order_notional_usd"`
+	PriceBandPct        float64  `yaml:"price_band_pct"`
+	MaxTransferUSD      float64  `yaml:"max_transfer_usd"`
+}
+
+func main() {
+	configFile := flag.String("f", "etc/signer.yaml", "the signer config file")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("signer: %v", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	key, err := hyperliquid.NewPrivateKeySigner(cfg.PrivateKey)
+	if err != nil {
+		log.Fatalf("signer: %v", err)
+	}
+	client, err := hyperliquid.NewClient(cfg.PrivateKey, cfg.Testnet)
+	if err != nil {
+		log.Fatalf("signer: %v", err)
+	}
+	policy, err := buildPolicy(ctx, client, cfg)
+	if err != nil {
+		log.Fatalf("signer: %v", err)
+	}
+	// Orders are priced against marks the daemon fetches itself.
+	marks := hyperliquid.NewClientMarks(client, 0)
+	listener, err := hyperliquid.ListenSigner(cfg.Listen)
+	if err != nil {
+		log.Fatalf("signer: %v", err)
+	}
+	server := &http.Server{
+		Handler:           hyperliquid.NewSignerServer(key, policy, hyperliquid.WithSignerServerToken(cfg.Token), hyperliquid.WithSignerServerMarks(marks)),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("signer: signing for %s on %s (testnet=%t)", key.GetAddress(), cfg.Listen, cfg.Testnet)
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("signer: %v", err)
+	}
+}
+
+func loadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(raw))), &cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if strings.TrimSpace(cfg.Listen) == "" {
+		return nil, errors.New("config: listen is required")
+	}
+	if strings.TrimSpace(cfg.PrivateKey) == "" {
+		return nil, errors.New("config: private_key is required")
+	}
+	if strings.HasPrefix(cfg.Listen, "http") && strings.TrimSpace(cfg.Token) == "" {
+		return nil, errors.New("config: token is required when listening on tcp")
+	}
+	if cfg.Policy.PriceBandPct < 0 || cfg.Policy.PriceBandPct >= 100 {
+		return nil, errors.New("config: policy.price_band_pct must be in [0, 100)")
+	}
+	return &cfg, nil
+}
+
+// buildPolicy resolves coin names in the policy to asset ids; numeric
+// entries are taken as ids.
+func buildPolicy(ctx context.Context, client *hyperliquid.Client, cfg *Config) (hyperliquid.SignerPolicy, error) {
+	policy := hyperliquid.SignerPolicy{
+		Mainnet:          !cfg.Testnet,
+		MaxOrderNotional: cfg.Policy.MaxOrderNotionalUSD,
+		PriceBandPct:     cfg.Policy.PriceBandPct,
+		MaxTransferUSD:   cfg.Policy.MaxTransferUSD,
+		VaultAddresses:   cfg.Policy.AllowedVaults,
+	}
+	for _, action := range cfg.Policy.AllowedActions {
+		if action = strings.TrimSpace(action); action != "" {
+			policy.Actions = append(policy.Actions, hyperliquid.ActionType(action))
+		}
+	}
+	if len(cfg.Policy.AllowedAssets) == 0 {
+		return policy, nil
+	}
+	for _, asset := range cfg.Policy.AllowedAssets {
+		asset = strings.TrimSpace(asset)
+		if id, err := strconv.Atoi(asset); err == nil {
+			policy.Assets = append(policy.Assets, id)
+			continue
+		}
+		id, err := client.GetAssetIndex(ctx, strings.ToUpper(asset))
+		if err != nil {
+			return policy, fmt.Errorf("resolve asset %s: %w", asset, err)
+		}
+		policy.Assets = append(policy.Assets, id)
+	}
+	return policy, nil
+}
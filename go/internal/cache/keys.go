@@ -264,6 +264,12 @@ func SimulatorTTL(ttl TTLSet) time.Duration {
 	return ttl.Duration(TTLMedium)
 }
 
+// SimulatorStateTTL returns the TTL for persisted simulator accounts, long
+// enough for a paper competition to survive deploys and downtime.
+func SimulatorStateTTL() time.Duration {
+	return 30 * 24 * time.Hour
+}
+
 // FormatCacheKey is exported for dynamic key construction when patterns
 // are not covered by helpers (e.g. segmented analytics keys).
 func FormatCacheKey(parts ...string) string {
@@ -28,6 +28,8 @@ import (
 
 var (
 	_ managerpkg.PersistenceService    = (*Service)(nil)
+	_ managerpkg.FundingCursorStore    = (*Service)(nil)
+	_ managerpkg.TWAPStore             = (*Service)(nil)
 	_ executorpkg.ConversationRecorder = (*Service)(nil)
 )
 
@@ -158,6 +160,7 @@ func (s *Service) RecordAccountSnapshot(ctx context.Context, snapshot managerpkg
 	metaPayload := map[string]any{
 		"available_balance_usd": snapshot.AvailableBalanceUSD,
 		"unrealized_pnl_usd":    snapshot.UnrealizedPnLUSD,
+		"funding_usd":           snapshot.FundingUSD,
 	}
 	metaBytes, _ := json.Marshal(metaPayload)
 	row := &model.AccountEquitySnapshots{
@@ -201,8 +204,14 @@ func (s *Service) RecordAnalytics(ctx context.Context, snapshot managerpkg.Analy
 		"win_rate":           snapshot.WinRate,
 		"total_trades":       snapshot.TotalTrades,
 		"max_drawdown_pct":   snapshot.MaxDrawdownPct,
+		"total_funding_usd":  snapshot.TotalFundingUSD,
 		"updated_at_rfc3339": snapshot.UpdatedAt.UTC().Format(time.RFC3339),
 	}
+	if breakdown, err := s.feePnlBreakdown(ctx, snapshot.TraderID); err != nil {
+		logx.WithContext(ctx).Errorf("persistence: fee/pnl breakdown model=%s err=%v", snapshot.TraderID, err)
+	} else {
+		payload["fee_pnl_moves_breakdown_table"] = breakdown
+	}
 	payloadBytes, _ := json.Marshal(payload)
 	metaBytes, _ := json.Marshal(map[string]any{
 		"refresh_at": time.Now().UTC().Format(time.RFC3339),
@@ -233,6 +242,236 @@ func (s *Service) RecordAnalytics(ctx context.Context, snapshot managerpkg.Analy
 	return nil
 }
 
+// RecordFundingPayments stores funding cash flows and attributes each one to
+// the trader's position in that symbol when it was open at payment time.
+// Payments already stored are skipped, so overlapping syncs are harmless.
+func (s *Service) RecordFundingPayments(ctx context.Context, record managerpkg.FundingRecord) error {
+	if s == nil || s.sqlConn == nil || strings.TrimSpace(record.TraderID) == "" || len(record.Payments) == 0 {
+		return nil
+	}
+	exchangeName := record.Exchange
+	if strings.TrimSpace(exchangeName) == "" {
+		exchangeName = "unknown"
+	}
+	insert := `
+INSERT INTO public.funding_payments (
+    model_id, exchange_provider, symbol, position_id, ts_ms, usdc, szi, funding_rate, hash, created_at
+) VALUES (
+    $1, $2, $3,
+    (SELECT id FROM public.positions WHERE id = $4 AND status = 'open' AND entry_time_ms <= $5),
+    $5, $6, $7, $8, $9, NOW()
+)
+ON CONFLICT (model_id, symbol, ts_ms) DO NOTHING
+RETURNING position_id`
+	accrue := `
+UPDATE public.positions
+SET funding_dollars = COALESCE(funding_dollars, 0) + $2,
+    updated_at = NOW()
+WHERE id = $1`
+	return s.sqlConn.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		for _, p := range record.Payments {
+			symbol := strings.ToUpper(strings.TrimSpace(p.Coin))
+			usdc, err := strconv.ParseFloat(strings.TrimSpace(p.USDC), 64)
+			if symbol == "" || err != nil {
+				continue
+			}
+			var inserted struct {
+				PositionID sql.NullString `db:"position_id"`
+			}
+			err = session.QueryRowCtx(ctx, &inserted, insert,
+				record.TraderID,
+				exchangeName,
+				symbol,
+				positionID(record.TraderID, symbol),
+				p.Timestamp,
+				usdc,
+				nullableDecimal(p.Szi),
+				nullableDecimal(p.FundingRate),
+				nullableString(p.Hash),
+			)
+			if errors.Is(err, sqlx.ErrNotFound) {
+				continue // already recorded
+			}
+			if err != nil {
+				return err
+			}
+			if inserted.PositionID.Valid {
+				if _, err := session.ExecCtx(ctx, accrue, inserted.PositionID.String, usdc); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// FundingCursor returns the time just after the latest funding payment stored
+// for traderID, so the manager's funding sync resumes there after a restart.
+func (s *Service) FundingCursor(ctx context.Context, traderID string) (time.Time, error) {
+	if s == nil || s.sqlConn == nil || strings.TrimSpace(traderID) == "" {
+		return time.Time{}, nil
+	}
+	var latest int64
+	query := `SELECT COALESCE(MAX(ts_ms), 0) FROM public.funding_payments WHERE model_id = $1`
+	if err := s.sqlConn.QueryRowCtx(ctx, &latest, query, traderID); err != nil {
+		return time.Time{}, err
+	}
+	if latest <= 0 {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(latest + 1), nil
+}
+
+// RecordOrderUpdate upserts the latest state of a manager-submitted order
+// keyed by cloid. Updates older than the stored row are ignored.
+func (s *Service) RecordOrderUpdate(ctx context.Context, record managerpkg.OrderRecord) error {
+	order := record.Order
+	if s == nil || s.sqlConn == nil || strings.TrimSpace(record.TraderID) == "" || strings.TrimSpace(order.Cloid) == "" {
+		return nil
+	}
+	side := "sell"
+	if order.IsBuy {
+		side = "buy"
+	}
+	var oid, twapID, leverage interface{}
+	if order.Oid != 0 {
+		oid = order.Oid
+	}
+	if order.TwapID != 0 {
+		twapID = order.TwapID
+	}
+	if record.Leverage > 0 {
+		leverage = record.Leverage
+	}
+	upsert := `
+INSERT INTO public.orders (
+    cloid, oid, model_id, account, symbol, side, size, limit_px, filled_sz, avg_px,
+    status, error, submitted_at_ms, updated_at_ms, twap_id, leverage, stop_loss, take_profit,
+    created_at, updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NOW(), NOW()
+)
+ON CONFLICT (cloid) DO UPDATE SET
+    oid = COALESCE(EXCLUDED.oid, orders.oid),
+    twap_id = COALESCE(EXCLUDED.twap_id, orders.twap_id),
+    leverage = COALESCE(EXCLUDED.leverage, orders.leverage),
+    stop_loss = COALESCE(EXCLUDED.stop_loss, orders.stop_loss),
+    take_profit = COALESCE(EXCLUDED.take_profit, orders.take_profit),
+    filled_sz = EXCLUDED.filled_sz,
+    avg_px = EXCLUDED.avg_px,
+    status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    size = EXCLUDED.size,
+    updated_at_ms = EXCLUDED.updated_at_ms,
+    updated_at = NOW()
+WHERE orders.updated_at_ms <= EXCLUDED.updated_at_ms`
+	_, err := s.sqlConn.ExecCtx(ctx, upsert,
+		strings.ToLower(order.Cloid),
+		oid,
+		record.TraderID,
+		order.Account,
+		strings.ToUpper(strings.TrimSpace(order.Coin)),
+		side,
+		toNullFloat(order.Size, order.Size > 0),
+		nullableDecimal(order.LimitPx),
+		order.FilledSz,
+		toNullFloat(order.AvgPx, order.AvgPx > 0),
+		string(order.State),
+		nullableString(order.Error),
+		order.SubmittedAt.UnixMilli(),
+		order.UpdatedAt.UnixMilli(),
+		twapID,
+		leverage,
+		toNullFloat(record.StopLoss, record.StopLoss > 0),
+		toNullFloat(record.TakeProfit, record.TakeProfit > 0),
+	)
+	return err
+}
+
+// ActiveTWAPOrders returns traderID's orders still worked by a venue TWAP, so
+// the manager re-attaches them after a restart.
+func (s *Service) ActiveTWAPOrders(ctx context.Context, traderID string) ([]managerpkg.OrderRecord, error) {
+	if s == nil || s.sqlConn == nil || strings.TrimSpace(traderID) == "" {
+		return nil, nil
+	}
+	var rows []struct {
+		Cloid         string          `db:"cloid"`
+		TwapID        int64           `db:"twap_id"`
+		Account       string          `db:"account"`
+		Symbol        string          `db:"symbol"`
+		Side          string          `db:"side"`
+		Size          sql.NullFloat64 `db:"size"`
+		FilledSz      float64         `db:"filled_sz"`
+		AvgPx         sql.NullFloat64 `db:"avg_px"`
+		Status        string          `db:"status"`
+		SubmittedAtMs int64           `db:"submitted_at_ms"`
+		Leverage      sql.NullInt64   `db:"leverage"`
+		StopLoss      sql.NullFloat64 `db:"stop_loss"`
+		TakeProfit    sql.NullFloat64 `db:"take_profit"`
+	}
+	query := `
+SELECT cloid, twap_id, account, symbol, side, size, filled_sz, avg_px, status, submitted_at_ms,
+    leverage, stop_loss, take_profit
+FROM public.orders
+WHERE model_id = $1 AND twap_id IS NOT NULL AND status NOT IN ('filled', 'cancelled', 'rejected')
+ORDER BY submitted_at_ms`
+	if err := s.sqlConn.QueryRowsCtx(ctx, &rows, query, traderID); err != nil {
+		return nil, err
+	}
+	records := make([]managerpkg.OrderRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, managerpkg.OrderRecord{
+			TraderID: traderID,
+			Order: exchange.TrackedOrder{
+				Cloid:       row.Cloid,
+				TwapID:      row.TwapID,
+				Account:     row.Account,
+				Owner:       traderID,
+				Coin:        row.Symbol,
+				IsBuy:       row.Side == "buy",
+				Size:        row.Size.Float64,
+				FilledSz:    row.FilledSz,
+				AvgPx:       row.AvgPx.Float64,
+				State:       exchange.OrderState(row.Status),
+				SubmittedAt: time.UnixMilli(row.SubmittedAtMs),
+			},
+			Leverage:   int(row.Leverage.Int64),
+			StopLoss:   row.StopLoss.Float64,
+			TakeProfit: row.TakeProfit.Float64,
+		})
+	}
+	return records, nil
+}
+
+// feePnlBreakdown aggregates closed trades and funding into the fee/PnL
+// breakdown table served by /analytics.
+func (s *Service) feePnlBreakdown(ctx context.Context, modelID string) (map[string]any, error) {
+	var totals struct {
+		Gross   float64 `db:"gross"`
+		Fees    float64 `db:"fees"`
+		Funding float64 `db:"funding"`
+	}
+	query := `
+SELECT
+    COALESCE((SELECT SUM(realized_gross_pnl) FROM public.trades WHERE model_id = $1), 0) AS gross,
+    COALESCE((SELECT SUM(total_commission_dollars) FROM public.trades WHERE model_id = $1), 0) AS fees,
+    COALESCE((SELECT SUM(usdc) FROM public.funding_payments WHERE model_id = $1), 0) AS funding`
+	if err := s.sqlConn.QueryRowCtx(ctx, &totals, query, modelID); err != nil {
+		return nil, err
+	}
+	breakdown := map[string]any{
+		"overall_pnl_without_fees":          totals.Gross,
+		"total_fees_paid":                   totals.Fees,
+		"overall_pnl_with_fees":             totals.Gross - totals.Fees,
+		"total_funding":                     totals.Funding,
+		"overall_pnl_with_fees_and_funding": totals.Gross - totals.Fees + totals.Funding,
+	}
+	if totals.Gross != 0 {
+		breakdown["total_fees_as_pct_of_pnl"] = totals.Fees / math.Abs(totals.Gross) * 100
+	}
+	return breakdown, nil
+}
+
 // HydrateCaches reloads cache state for provided trader IDs. Currently best-effort no-op
 // until dedicated cache warmup jobs are implemented.
 func (s *Service) HydrateCaches(ctx context.Context, traderIDs []string) error {
@@ -629,15 +868,24 @@ func (s *Service) handleOpenPosition(ctx context.Context, modelID, symbol string
 	if entryTime.IsZero() {
 		entryTime = time.Now()
 	}
+	// Venue fills, when attached, carry the actual entry price, size and fee.
+	var entryOid sql.NullInt64
+	var commission sql.NullFloat64
+	if fill, ok := exchange.SummarizeFills(event.Fills); ok {
+		price = fill.AvgPx
+		qty = fill.Size
+		entryOid = sql.NullInt64{Int64: fill.Oid, Valid: fill.Oid != 0}
+		commission = sql.NullFloat64{Float64: fill.Fee, Valid: true}
+	}
 	statement := `
 INSERT INTO public.positions (
     id, model_id, exchange_provider, symbol, side, status,
     entry_time_ms, entry_price, leverage, quantity, confidence, risk_usd,
-    wait_for_fill, created_at, updated_at
+    entry_oid, commission, wait_for_fill, created_at, updated_at
 ) VALUES (
     $1, $2, $3, $4, $5, 'open',
     $6, $7, $8, $9, $10, $11,
-    FALSE, NOW(), NOW()
+    $12, $13, FALSE, NOW(), NOW()
 )
 ON CONFLICT (id) DO UPDATE SET
     side = EXCLUDED.side,
@@ -648,6 +896,9 @@ ON CONFLICT (id) DO UPDATE SET
     quantity = EXCLUDED.quantity,
     confidence = EXCLUDED.confidence,
     risk_usd = EXCLUDED.risk_usd,
+    entry_oid = EXCLUDED.entry_oid,
+    commission = EXCLUDED.commission,
+    funding_dollars = CASE WHEN positions.status = 'closed' THEN 0 ELSE positions.funding_dollars END,
     updated_at = NOW();
 `
 	_, err := s.sqlConn.ExecCtx(
@@ -664,6 +915,8 @@ ON CONFLICT (id) DO UPDATE SET
 		qty,
 		float64(event.Decision.Confidence),
 		event.Decision.RiskUSD,
+		nullIntValue(entryOid),
+		nullFloatValue(commission),
 	)
 	if err != nil {
 		return err
@@ -682,7 +935,9 @@ ON CONFLICT (id) DO UPDATE SET
 	return nil
 }
 
-// handleClosePosition transitions the row to closed status and attempts PnL computation.
+// handleClosePosition transitions the row to closed status and records the
+// trade. PnL and fees come from the venue fills when attached and are
+// otherwise estimated from the entry price.
 func (s *Service) handleClosePosition(ctx context.Context, modelID, symbol string, event managerpkg.PositionEvent) error {
 	var existing *model.Positions
 	if s.positionsModel != nil {
@@ -708,15 +963,32 @@ func (s *Service) handleClosePosition(ctx context.Context, modelID, symbol strin
 	if qtyForPnl <= 0 && existing != nil {
 		qtyForPnl = existing.Quantity
 	}
-	var pnl sql.NullFloat64
-	if existing != nil && closePrice > 0 && existing.EntryPrice > 0 && qtyForPnl > 0 {
+	exit := tradeExit{Price: closePrice, Qty: qty}
+	if fill, ok := exchange.SummarizeFills(event.Fills); ok {
+		exit = tradeExit{
+			Price:    fill.AvgPx,
+			Qty:      fill.Size,
+			GrossPnl: sql.NullFloat64{Float64: fill.ClosedPnl, Valid: true},
+			Fee:      sql.NullFloat64{Float64: fill.Fee, Valid: true},
+			Oid:      sql.NullInt64{Int64: fill.Oid, Valid: fill.Oid != 0},
+			Tid:      sql.NullInt64{Int64: fill.Tid, Valid: fill.Tid != 0},
+			Crossed:  sql.NullBool{Bool: fill.Crossed, Valid: true},
+		}
+		closePrice = fill.AvgPx
+	} else if existing != nil && closePrice > 0 && existing.EntryPrice > 0 && qtyForPnl > 0 {
 		sign := 1.0
 		if strings.EqualFold(existing.Side, "short") {
 			sign = -1.0
 		}
 		value := sign * (closePrice - existing.EntryPrice) * qtyForPnl
-		pnl = sql.NullFloat64{Float64: value, Valid: true}
+		exit.GrossPnl = sql.NullFloat64{Float64: value, Valid: true}
 	}
+	pnl := exit.GrossPnl
+	funding, err := s.positionFunding(ctx, positionID(modelID, symbol))
+	if err != nil {
+		return err
+	}
+	exit.Funding = funding
 	statement := `
 UPDATE public.positions
 SET status = 'closed',
@@ -728,7 +1000,7 @@ WHERE id = $1;
 	if _, err := s.sqlConn.ExecCtx(ctx, statement, positionID(modelID, symbol), closePrice, nullFloatValue(pnl)); err != nil {
 		return err
 	}
-	summary, err := s.insertTrade(ctx, existing, modelID, symbol, closePrice, qty, pnl, closeTime, event)
+	summary, err := s.insertTrade(ctx, existing, modelID, symbol, exit, closeTime, event)
 	if err != nil {
 		return err
 	}
@@ -739,14 +1011,39 @@ WHERE id = $1;
 	return nil
 }
 
-func (s *Service) insertTrade(ctx context.Context, pos *model.Positions, modelID, symbol string, closePrice, qty float64, pnl sql.NullFloat64, closeTime time.Time, event managerpkg.PositionEvent) (*tradeCacheEntry, error) {
+// tradeExit describes how a position was closed. Fee, Oid, Tid and Crossed
+// are only known when the close was reconciled against venue fills.
+type tradeExit struct {
+	Price    float64
+	Qty      float64
+	GrossPnl sql.NullFloat64 // venue closedPnl, or estimated from the entry price
+	Fee      sql.NullFloat64
+	Oid      sql.NullInt64
+	Tid      sql.NullInt64
+	Crossed  sql.NullBool
+	Funding  sql.NullFloat64 // funding accrued while the position was open
+}
+
+func (s *Service) insertTrade(ctx context.Context, pos *model.Positions, modelID, symbol string, exit tradeExit, closeTime time.Time, event managerpkg.PositionEvent) (*tradeCacheEntry, error) {
 	if s == nil || s.tradesModel == nil || pos == nil {
 		return nil, nil
 	}
-	tradeQty := qty
+	closePrice := exit.Price
+	tradeQty := exit.Qty
 	if tradeQty <= 0 {
 		tradeQty = pos.Quantity
 	}
+	totalCommission := pos.Commission
+	if exit.Fee.Valid {
+		totalCommission = sql.NullFloat64{Float64: pos.Commission.Float64 + exit.Fee.Float64, Valid: true}
+	}
+	netPnl := exit.GrossPnl
+	if netPnl.Valid && totalCommission.Valid {
+		netPnl.Float64 -= totalCommission.Float64
+	}
+	if netPnl.Valid && exit.Funding.Valid {
+		netPnl.Float64 += exit.Funding.Float64
+	}
 	entryHuman := sql.NullString{}
 	if pos.EntryTimeMs > 0 {
 		entryHuman = sql.NullString{String: time.UnixMilli(pos.EntryTimeMs).UTC().Format(time.RFC3339), Valid: true}
@@ -775,12 +1072,15 @@ func (s *Service) insertTrade(ctx context.Context, pos *model.Positions, modelID
 		ExitTsMs:               sql.NullInt64{Int64: closeTime.UTC().UnixMilli(), Valid: true},
 		ExitHumanTime:          exitHuman,
 		ExitSz:                 toNullFloat(tradeQty, tradeQty > 0),
-		ExitCommissionDollars:  sql.NullFloat64{},
-		ExitClosedPnl:          pnl,
+		ExitTid:                exit.Tid,
+		ExitOid:                exit.Oid,
+		ExitCrossed:            exit.Crossed,
+		ExitCommissionDollars:  exit.Fee,
+		ExitClosedPnl:          exit.GrossPnl,
 		ExitPlan:               pos.ExitPlan,
-		RealizedGrossPnl:       pnl,
-		RealizedNetPnl:         pnl,
-		TotalCommissionDollars: pos.Commission,
+		RealizedGrossPnl:       exit.GrossPnl,
+		RealizedNetPnl:         netPnl,
+		TotalCommissionDollars: totalCommission,
 	}
 	_, err := s.tradesModel.Insert(ctx, trade)
 	if isUniqueViolation(err) {
@@ -789,6 +1089,11 @@ func (s *Service) insertTrade(ctx context.Context, pos *model.Positions, modelID
 	if err != nil {
 		return nil, err
 	}
+	if exit.Funding.Valid {
+		if _, err := s.sqlConn.ExecCtx(ctx, `UPDATE public.trades SET funding_dollars = $2 WHERE id = $1`, trade.Id, exit.Funding.Float64); err != nil {
+			return nil, err
+		}
+	}
 	summary := &tradeCacheEntry{
 		ModelID:      modelID,
 		Symbol:       symbol,
@@ -796,7 +1101,7 @@ func (s *Service) insertTrade(ctx context.Context, pos *model.Positions, modelID
 		Quantity:     tradeQty,
 		EntryPrice:   pos.EntryPrice,
 		ExitPrice:    closePrice,
-		RealizedPnL:  pnl.Float64,
+		RealizedPnL:  netPnl.Float64,
 		Confidence:   float64(event.Decision.Confidence),
 		ClosedAtMs:   closeTime.UTC().UnixMilli(),
 		Exchange:     traderExchange(event),
@@ -807,6 +1112,19 @@ func (s *Service) insertTrade(ctx context.Context, pos *model.Positions, modelID
 	return summary, nil
 }
 
+// positionFunding returns the funding accrued on the position row, or an
+// invalid value when the row does not exist.
+func (s *Service) positionFunding(ctx context.Context, id string) (sql.NullFloat64, error) {
+	var row struct {
+		Funding sql.NullFloat64 `db:"funding_dollars"`
+	}
+	err := s.sqlConn.QueryRowCtx(ctx, &row, `SELECT funding_dollars FROM public.positions WHERE id = $1`, id)
+	if errors.Is(err, sqlx.ErrNotFound) {
+		return sql.NullFloat64{}, nil
+	}
+	return row.Funding, err
+}
+
 func normalizedModelID(event managerpkg.PositionEvent) string {
 	if strings.TrimSpace(event.TraderID) != "" {
 		return event.TraderID
@@ -890,6 +1208,30 @@ func nullFloatValue(value sql.NullFloat64) interface{} {
 	return nil
 }
 
+func nullIntValue(value sql.NullInt64) interface{} {
+	if value.Valid {
+		return value.Int64
+	}
+	return nil
+}
+
+// nullableDecimal parses a venue decimal string, returning nil when it is
+// empty or malformed so the column is stored as NULL.
+func nullableDecimal(value string) interface{} {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func nullableString(value string) interface{} {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return value
+}
+
 func toNullFloat(value float64, valid bool) sql.NullFloat64 {
 	if !valid {
 		return sql.NullFloat64{}
@@ -0,0 +1,60 @@
+package simpersist
+
+import (
+	"context"
+	"fmt"
+
+	gocache "github.com/zeromicro/go-zero/core/stores/cache"
+
+	cachekeys "nof0-api/internal/cache"
+	"nof0-api/pkg/exchange/sim"
+)
+
+// RedisStore persists a simulator account in the cache, keyed by the
+// exchange provider name. The full state lives under SimBalancesKey so a
+// restore is always consistent; open orders are mirrored under SimOrdersKey
+// for inspection.
+type RedisStore struct {
+	cache gocache.Cache
+	id    string
+}
+
+// NewRedisStore returns a sim.Store for the provider named id.
+func NewRedisStore(cache gocache.Cache, id string) *RedisStore {
+	return &RedisStore{cache: cache, id: id}
+}
+
+var _ sim.SubStore = (*RedisStore)(nil)
+
+// Sub returns the store for sub-account id of the same provider.
+func (s *RedisStore) Sub(id string) sim.Store {
+	return NewRedisStore(s.cache, s.id+":"+id)
+}
+
+// Load reads the saved state; it returns nil when nothing was saved.
+func (s *RedisStore) Load(ctx context.Context) (*sim.State, error) {
+	var state sim.State
+	if err := s.cache.GetCtx(ctx, cachekeys.SimBalancesKey(s.id), &state); err != nil {
+		if s.cache.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("simpersist: load state %s: %w", s.id, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state and refreshes the open-order mirror.
+func (s *RedisStore) Save(ctx context.Context, state sim.State) error {
+	ttl := cachekeys.SimulatorStateTTL()
+	if err := s.cache.SetWithExpireCtx(ctx, cachekeys.SimBalancesKey(s.id), state, ttl); err != nil {
+		return fmt.Errorf("simpersist: save state %s: %w", s.id, err)
+	}
+	orders := state.Orders
+	if orders == nil {
+		orders = []sim.OpenOrder{}
+	}
+	if err := s.cache.SetWithExpireCtx(ctx, cachekeys.SimOrdersKey(s.id), orders, ttl); err != nil {
+		return fmt.Errorf("simpersist: save orders %s: %w", s.id, err)
+	}
+	return nil
+}
@@ -17,7 +17,9 @@ import (
 	"nof0-api/internal/model"
 	"nof0-api/pkg/confkit"
 	exchangepkg "nof0-api/pkg/exchange"
+	_ "nof0-api/pkg/exchange/binance"
 	_ "nof0-api/pkg/exchange/hyperliquid"
+	_ "nof0-api/pkg/exchange/shadow"
 	_ "nof0-api/pkg/exchange/sim"
 	executorpkg "nof0-api/pkg/executor"
 	llmpkg "nof0-api/pkg/llm"
@@ -0,0 +1,8 @@
+-- Rollback funding payment ingestion
+
+ALTER TABLE trades DROP COLUMN IF EXISTS funding_dollars;
+ALTER TABLE positions DROP COLUMN IF EXISTS funding_dollars;
+
+DROP INDEX IF EXISTS idx_funding_payments_position;
+DROP INDEX IF EXISTS idx_funding_payments_model_ts_desc;
+DROP TABLE IF EXISTS funding_payments;
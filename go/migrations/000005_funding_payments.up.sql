@@ -0,0 +1,31 @@
+-- Perpetual funding cash flows per trader, attributed to the open position
+-- they applied to.
+
+CREATE TABLE IF NOT EXISTS funding_payments (
+    id BIGSERIAL PRIMARY KEY,
+    model_id TEXT NOT NULL,
+    exchange_provider TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    position_id TEXT,
+    ts_ms BIGINT NOT NULL,
+    usdc DOUBLE PRECISION NOT NULL,
+    szi DOUBLE PRECISION,
+    funding_rate DOUBLE PRECISION,
+    hash TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (model_id, symbol, ts_ms)
+);
+
+CREATE INDEX IF NOT EXISTS idx_funding_payments_model_ts_desc
+    ON funding_payments(model_id, ts_ms DESC);
+
+CREATE INDEX IF NOT EXISTS idx_funding_payments_position
+    ON funding_payments(position_id);
+
+-- Funding accrued while a position is open; reset when it is re-opened.
+ALTER TABLE positions
+ADD COLUMN IF NOT EXISTS funding_dollars DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Funding accrued over the trade's lifetime, included in realized_net_pnl.
+ALTER TABLE trades
+ADD COLUMN IF NOT EXISTS funding_dollars DOUBLE PRECISION;
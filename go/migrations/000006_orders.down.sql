@@ -0,0 +1,5 @@
+-- Rollback order lifecycle tracking
+
+DROP INDEX IF EXISTS idx_orders_oid;
+DROP INDEX IF EXISTS idx_orders_model_submitted_desc;
+DROP TABLE IF EXISTS orders;
@@ -0,0 +1,28 @@
+-- Lifecycle of orders submitted by the manager, keyed by client order id.
+-- Market orders and closes carry a manager-generated cloid that never
+-- reached the venue; their venue oid links them to fills.
+
+CREATE TABLE IF NOT EXISTS orders (
+    cloid TEXT PRIMARY KEY,
+    oid BIGINT,
+    model_id TEXT NOT NULL,
+    account TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    side TEXT NOT NULL,
+    size DOUBLE PRECISION,
+    limit_px DOUBLE PRECISION,
+    filled_sz DOUBLE PRECISION NOT NULL DEFAULT 0,
+    avg_px DOUBLE PRECISION,
+    status TEXT NOT NULL,
+    error TEXT,
+    submitted_at_ms BIGINT NOT NULL,
+    updated_at_ms BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_model_submitted_desc
+    ON orders(model_id, submitted_at_ms DESC);
+
+CREATE INDEX IF NOT EXISTS idx_orders_oid
+    ON orders(oid);
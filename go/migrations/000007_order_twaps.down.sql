@@ -0,0 +1,6 @@
+-- Rollback TWAP order tracking
+
+ALTER TABLE orders DROP COLUMN IF EXISTS take_profit;
+ALTER TABLE orders DROP COLUMN IF EXISTS stop_loss;
+ALTER TABLE orders DROP COLUMN IF EXISTS leverage;
+ALTER TABLE orders DROP COLUMN IF EXISTS twap_id;
@@ -0,0 +1,7 @@
+-- Venue TWAPs working manager orders, with the protection placed once they
+-- stop, so TWAPs still running across a restart are re-attached.
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS twap_id BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS leverage INT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS stop_loss DOUBLE PRECISION;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS take_profit DOUBLE PRECISION;
@@ -5,6 +5,7 @@ import (
 	"math"
 	"testing"
 
+	"nof0-api/pkg/exchange"
 	simex "nof0-api/pkg/exchange/sim"
 
 	"github.com/stretchr/testify/assert"
@@ -32,3 +33,25 @@ func TestBacktest_ThresholdWithSim(t *testing.T) {
 	assert.False(t, res.MaxDDPct < 0 || math.IsNaN(res.MaxDDPct), "max drawdown should be non-negative and not NaN")
 	assert.False(t, math.IsNaN(res.Sharpe), "sharpe ratio should not be NaN")
 }
+
+func TestPortfolio_ExactDecimalPnL(t *testing.T) {
+	d := exchange.MustParseDecimal
+	pf := &portfolio{cash: d("1000"), feeBps: 4.5}
+	_, fee, done := pf.apply(true, d("100.1"), d("0.1"))
+	assert.False(t, done)
+	assert.Equal(t, "0.0045045", fee.String())
+	pf.apply(true, d("100.2"), d("0.2"))
+
+	// Selling through zero closes the long and opens the remainder short.
+	realized, _, done := pf.apply(false, d("100.3"), d("0.4"))
+	assert.True(t, done)
+	assert.Equal(t, "0.04", realized.String(), "float64 gives 0.039999999999993464")
+	assert.Equal(t, "-0.1", pf.pos.String())
+	assert.Equal(t, "100.3", pf.avgCost.String())
+	assert.Equal(t, "1000.0084235", pf.cash.String())
+
+	realized, _, _ = pf.apply(true, d("100.1"), d("0.1"))
+	assert.Equal(t, "0.02", realized.String())
+	assert.True(t, pf.pos.IsZero())
+	assert.True(t, pf.avgCost.IsZero())
+}
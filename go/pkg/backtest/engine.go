@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"strconv"
 
 	"nof0-api/pkg/exchange"
 	"nof0-api/pkg/market"
@@ -63,7 +62,7 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 	if eq0 <= 0 {
 		eq0 = 100000
 	}
-	pf := &portfolio{cash: eq0, feeBps: e.FeeBps, slippageBps: e.SlippageBps}
+	pf := &portfolio{cash: exchange.DecimalFromFloat(eq0), feeBps: e.FeeBps, slippageBps: e.SlippageBps}
 	lastEquity := eq0
 	for {
 		snap, ok, err := e.Feeder.Next(ctx, e.Symbol)
@@ -81,12 +80,12 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 		px := snap.Price.Last
 		for _, ord := range orders {
 			// Derive numeric size & execution price
-			sz, _ := strconv.ParseFloat(ord.Sz, 64)
-			execPx := applySlippage(px, e.SlippageBps, ord.IsBuy)
+			sz, _ := exchange.ParseDecimal(ord.Sz)
+			execPx := exchange.DecimalFromFloat(applySlippage(px, e.SlippageBps, ord.IsBuy))
 			realized, fee, tradeCompleted := pf.apply(ord.IsBuy, execPx, sz)
 			if tradeCompleted {
 				res.Trades++
-				if realized > 0 {
+				if realized.Sign() > 0 {
 					res.Wins++
 				}
 			}
@@ -98,19 +97,19 @@ func (e *Engine) Run(ctx context.Context) (*Result, error) {
 			res.Details = append(res.Details, TradeDetail{
 				Step:     res.Steps,
 				Side:     sideStr(ord.IsBuy),
-				Price:    execPx,
-				Qty:      sz,
-				Fee:      fee,
-				Realized: realized,
-				Position: pf.pos,
+				Price:    execPx.Float64(),
+				Qty:      sz.Float64(),
+				Fee:      fee.Float64(),
+				Realized: realized.Float64(),
+				Position: pf.pos.Float64(),
 			})
 		}
 		equity := pf.equity(px)
 		res.EquityCurve = append(res.EquityCurve, equity)
 		lastEquity = equity
 	}
-	res.RealizedPNL = pf.realized
-	res.UnrealPNL = pf.unrealized
+	res.RealizedPNL = pf.realized.Float64()
+	res.UnrealPNL = pf.unrealized.Float64()
 	res.TotalPNL = res.RealizedPNL + res.UnrealPNL
 	if res.Trades > 0 {
 		res.WinRate = float64(res.Wins) / float64(res.Trades)
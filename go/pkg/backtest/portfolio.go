@@ -1,120 +1,90 @@
 package backtest
 
 import (
-	"math"
+	"nof0-api/pkg/exchange"
 )
 
-// portfolio tracks PnL with simple fee/slippage.
+const (
+	// cashPlaces is the precision realised PnL settles to.
+	cashPlaces = 8
+	// avgCostPlaces bounds the digits of a volume-weighted average cost.
+	avgCostPlaces = 12
+)
+
+var bpsUnit = exchange.NewDecimal(1, -4)
+
+// portfolio tracks PnL with simple fee/slippage. Sizes, costs, fees and cash
+// are exact decimals so long runs do not accumulate float drift.
 type portfolio struct {
-	cash        float64
-	pos         float64 // signed size in base units
-	avgCost     float64 // average price of current position
-	realized    float64
-	unrealized  float64
+	cash        exchange.Decimal
+	pos         exchange.Decimal // signed size in base units
+	avgCost     exchange.Decimal // average price of current position
+	realized    exchange.Decimal
+	unrealized  exchange.Decimal
 	feeBps      float64
 	slippageBps float64
 }
 
 // apply processes an order at given execution price and quantity.
 // Returns (realized PnL for closed portion, fee charged, tradeCompleted-when-close-occurs).
-func (p *portfolio) apply(isBuy bool, execPx float64, qty float64) (realized float64, fee float64, tradeCompleted bool) {
-	if qty <= 0 || execPx <= 0 {
-		return 0, 0, false
+func (p *portfolio) apply(isBuy bool, execPx, qty exchange.Decimal) (realized, fee exchange.Decimal, tradeCompleted bool) {
+	if qty.Sign() <= 0 || execPx.Sign() <= 0 {
+		return realized, fee, false
 	}
-	side := 1.0
+	delta := qty
 	if !isBuy {
-		side = -1.0
+		delta = qty.Neg()
 	}
 	// trading fee in quote currency
 	fee = p.fee(execPx, qty)
-	// existing position sign
-	posSign := 0.0
-	if p.pos > 0 {
-		posSign = 1
-	} else if p.pos < 0 {
-		posSign = -1
-	}
+	p.cash = p.cash.Sub(fee)
 
 	// If position increases in same direction
-	if posSign == 0 || posSign == side {
-		newPos := p.pos + side*qty
+	if p.pos.Sign()*delta.Sign() >= 0 {
+		newPos := p.pos.Add(delta)
 		// weighted avg cost update
-		if p.pos == 0 {
+		if p.pos.IsZero() {
 			p.avgCost = execPx
 		} else {
-			totalQty := math.Abs(p.pos) + qty
-			if totalQty > 0 {
-				p.avgCost = (p.avgCost*math.Abs(p.pos) + execPx*qty) / totalQty
-			}
+			p.avgCost = p.avgCost.Mul(p.pos).Add(execPx.Mul(delta)).Div(newPos, avgCostPlaces)
 		}
 		p.pos = newPos
-		p.cash -= fee
-		return 0, fee, false
+		return realized, fee, false
 	}
 
 	// Opposite direction: close part or all
-	closeQty := math.Min(math.Abs(p.pos), qty)
+	closeQty := p.pos.Abs()
+	if qty.Cmp(closeQty) < 0 {
+		closeQty = qty
+	}
 	// realized PnL depends on previous sign
-	if p.pos > 0 { // closing long by selling
-		realized = (execPx - p.avgCost) * closeQty
-	} else { // closing short by buying
-		realized = (p.avgCost - execPx) * closeQty
+	realized = execPx.Sub(p.avgCost).Mul(closeQty)
+	if p.pos.Sign() < 0 { // closing short by buying
+		realized = realized.Neg()
 	}
-	p.cash += realized
-	p.realized += realized
-	p.cash -= fee
-	tradeCompleted = true
+	realized = realized.Round(cashPlaces, exchange.RoundHalfUp)
+	p.cash = p.cash.Add(realized)
+	p.realized = p.realized.Add(realized)
 
-	remaining := qty - closeQty
-	if remaining == 0 {
-		// position may go to zero or reduce
-		if closeQty == math.Abs(p.pos) {
-			p.pos = 0
-			p.avgCost = 0
-		} else {
-			// reduced but same sign remains
-			if p.pos > 0 {
-				p.pos -= closeQty
-			} else {
-				p.pos += closeQty
-			}
-		}
-		return realized, fee, tradeCompleted
-	}
-	// Crossed through zero: open new position with remaining in the new side
-	if closeQty == math.Abs(p.pos) {
-		p.pos = 0
-		p.avgCost = 0
-	} else {
-		if p.pos > 0 {
-			p.pos -= closeQty
-		} else {
-			p.pos += closeQty
-		}
-	}
-	// Open new position with remaining amount
-	if side > 0 { // buy
-		p.pos += remaining
-	} else {
-		p.pos -= remaining
+	p.pos = p.pos.Add(delta)
+	switch {
+	case p.pos.IsZero():
+		p.avgCost = exchange.Decimal{}
+	case p.pos.Sign() == delta.Sign():
+		// Crossed through zero: the remainder opens at the execution price
+		p.avgCost = execPx
 	}
-	p.avgCost = execPx
-	return realized, fee, tradeCompleted
+	return realized, fee, true
 }
 
 func (p *portfolio) equity(lastPx float64) float64 {
-	p.unrealized = 0
-	if p.pos > 0 {
-		p.unrealized = (lastPx - p.avgCost) * p.pos
-	} else if p.pos < 0 {
-		p.unrealized = (p.avgCost - lastPx) * math.Abs(p.pos)
-	}
-	return p.cash + p.unrealized
+	p.unrealized = p.pos.Mul(exchange.DecimalFromFloat(lastPx).Sub(p.avgCost))
+	return p.cash.Add(p.unrealized).Float64()
 }
 
-func (p *portfolio) fee(px, qty float64) float64 {
+func (p *portfolio) fee(px, qty exchange.Decimal) exchange.Decimal {
 	if p.feeBps == 0 {
-		return 0
+		return exchange.Decimal{}
 	}
-	return px * qty * (p.feeBps / 10000.0)
+	return px.Mul(qty).Mul(exchange.DecimalFromFloat(p.feeBps)).Mul(bpsUnit)
 }
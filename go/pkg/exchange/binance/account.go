@@ -0,0 +1,178 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+)
+
+// GetAccountState fetches the futures account summary plus open positions.
+func (c *Client) GetAccountState(ctx context.Context) (*exchange.AccountState, error) {
+	var acct accountResponse
+	if err := c.doSigned(ctx, http.MethodGet, "/fapi/v2/account", nil, &acct); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(acct.TotalMarginBalance) == "" {
+		return nil, fmt.Errorf("binance: account response missing fields")
+	}
+	positions, err := c.GetPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notional := 0.0
+	for _, p := range positions {
+		notional += parseFloat(p.PositionValue)
+	}
+	summary := exchange.MarginSummary{
+		AccountValue:    acct.TotalMarginBalance,
+		TotalMarginUsed: acct.TotalInitialMargin,
+		TotalNtlPos:     formatDecimal(notional),
+		TotalRawUSD:     acct.TotalWalletBalance,
+	}
+	return &exchange.AccountState{
+		MarginSummary:      summary,
+		CrossMarginSummary: exchange.CrossMarginSummary(summary),
+		AssetPositions:     positions,
+	}, nil
+}
+
+// GetAccountValue returns the margin balance (wallet balance plus unrealised PnL).
+func (c *Client) GetAccountValue(ctx context.Context) (float64, error) {
+	var acct accountResponse
+	if err := c.doSigned(ctx, http.MethodGet, "/fapi/v2/account", nil, &acct); err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseFloat(acct.TotalMarginBalance, 64)
+	if err != nil {
+		return 0, fmt.Errorf("binance: parse account value: %w", err)
+	}
+	return value, nil
+}
+
+// GetPositions returns non-zero positions in one-way (BOTH) position mode.
+func (c *Client) GetPositions(ctx context.Context) ([]exchange.Position, error) {
+	var raw []positionRiskResponse
+	if err := c.doSigned(ctx, http.MethodGet, "/fapi/v2/positionRisk", nil, &raw); err != nil {
+		return nil, err
+	}
+	positions := make([]exchange.Position, 0, len(raw))
+	for _, p := range raw {
+		qty := parseFloat(p.PositionAmt)
+		if qty == 0 {
+			continue
+		}
+		positions = append(positions, c.convertPosition(p))
+	}
+	return positions, nil
+}
+
+func (c *Client) convertPosition(p positionRiskResponse) exchange.Position {
+	coin := p.Symbol
+	if info, ok := c.cachedSymbol(canonicalAssetKey(p.Symbol)); ok && info.BaseAsset != "" {
+		coin = info.BaseAsset
+	} else if trimmed := strings.TrimSuffix(canonicalAssetKey(p.Symbol), c.quoteAsset); trimmed != "" {
+		coin = trimmed
+	}
+	notional := math.Abs(parseFloat(p.Notional))
+	if notional == 0 {
+		notional = math.Abs(parseFloat(p.PositionAmt) * parseFloat(p.MarkPrice))
+	}
+	lev, _ := strconv.Atoi(strings.TrimSpace(p.Leverage))
+	unreal := parseFloat(p.UnRealizedProfit)
+	roe := "0"
+	if lev > 0 && notional > 0 {
+		roe = formatDecimal(unreal / (notional / float64(lev)))
+	}
+	marginType := "cross"
+	if strings.EqualFold(p.MarginType, "isolated") {
+		marginType = "isolated"
+	}
+	pos := exchange.Position{
+		Coin:           coin,
+		PositionValue:  formatDecimal(notional),
+		Szi:            p.PositionAmt,
+		UnrealizedPnl:  p.UnRealizedProfit,
+		ReturnOnEquity: roe,
+		Leverage:       exchange.Leverage{Type: marginType, Value: lev},
+	}
+	if entry := strings.TrimSpace(p.EntryPrice); entry != "" && !isZeroString(entry) {
+		pos.EntryPx = &entry
+	}
+	if liq := strings.TrimSpace(p.LiquidationPrice); liq != "" && !isZeroString(liq) {
+		pos.LiquidationPx = &liq
+	}
+	return pos
+}
+
+// ClosePosition flattens the position for coin with a reduce-only market order.
+func (c *Client) ClosePosition(ctx context.Context, coin string) (*exchange.OrderResponse, error) {
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := c.GetPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var target *exchange.Position
+	for i := range positions {
+		if strings.EqualFold(positions[i].Coin, info.BaseAsset) || strings.EqualFold(positions[i].Coin, info.Symbol) {
+			target = &positions[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, nil
+	}
+	qty := parseFloat(target.Szi)
+	if qty == 0 {
+		return nil, nil
+	}
+	params := url.Values{}
+	params.Set("symbol", info.Symbol)
+	params.Set("side", sideFor(qty < 0))
+	params.Set("type", orderTypeMarket)
+	params.Set("quantity", strings.TrimPrefix(strings.TrimSpace(target.Szi), "-"))
+	params.Set("reduceOnly", "true")
+	params.Set("newOrderRespType", "RESULT")
+	var out orderResponse
+	if err := c.doSigned(ctx, http.MethodPost, "/fapi/v1/order", params, &out); err != nil {
+		return nil, err
+	}
+	return toOrderResponse(out), nil
+}
+
+// UpdateLeverage sets margin type and leverage for the symbol behind asset.
+func (c *Client) UpdateLeverage(ctx context.Context, asset int, isCross bool, leverage int) error {
+	if leverage <= 0 {
+		return fmt.Errorf("binance: leverage must be positive")
+	}
+	info, err := c.symbolForAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	marginType := "ISOLATED"
+	if isCross {
+		marginType = "CROSSED"
+	}
+	params := url.Values{}
+	params.Set("symbol", info.Symbol)
+	params.Set("marginType", marginType)
+	if err := c.doSigned(ctx, http.MethodPost, "/fapi/v1/marginType", params, nil); err != nil {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != errCodeNoNeedToChangeMarginType {
+			return err
+		}
+	}
+	params = url.Values{}
+	params.Set("symbol", info.Symbol)
+	params.Set("leverage", strconv.Itoa(leverage))
+	return c.doSigned(ctx, http.MethodPost, "/fapi/v1/leverage", params, nil)
+}
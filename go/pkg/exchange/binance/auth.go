@@ -0,0 +1,40 @@
+package binance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+)
+
+// signParams returns the encoded query string for params with timestamp,
+// recvWindow and the trailing HMAC-SHA256 signature appended. The caller's
+// params are not mutated so retries can re-sign with a fresh timestamp.
+func (c *Client) signParams(params url.Values) string {
+	signed := url.Values{}
+	for k, v := range params {
+		signed[k] = append([]string(nil), v...)
+	}
+	signed.Set("timestamp", strconv.FormatInt(c.now().UnixMilli(), 10))
+	if c.recvWindow > 0 {
+		signed.Set("recvWindow", strconv.FormatInt(c.recvWindow.Milliseconds(), 10))
+	}
+	payload := signed.Encode()
+	return payload + "&signature=" + sign(c.apiSecret, payload)
+}
+
+// sign computes the hex-encoded HMAC-SHA256 of payload using secret, as
+// required by Binance SIGNED endpoints.
+func sign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func encodeParams(params url.Values) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return params.Encode()
+}
@@ -0,0 +1,261 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mainnetBaseURL = "https://fapi.binance.com"
+	testnetBaseURL = "https://testnet.binancefuture.com"
+
+	defaultHTTPTimeout = 30 * time.Second
+	defaultRecvWindow  = 5 * time.Second
+	defaultQuoteAsset  = "USDT"
+	defaultSlippage    = 0.01
+
+	apiKeyHeader = "X-MBX-APIKEY"
+)
+
+// Client coordinates signed requests against the Binance USDT-M futures REST API.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	apiSecret  []byte
+	httpClient *http.Client
+	logger     *log.Logger
+	clock      func() time.Time
+	recvWindow time.Duration
+	quoteAsset string
+	isTestnet  bool
+
+	// Trade defaults
+	defaultSlippage float64
+
+	// Offset applied to the local clock so signed timestamps track server time.
+	timeMu     sync.RWMutex
+	timeOffset time.Duration
+
+	// Symbol directory cache
+	symbolMu      sync.RWMutex
+	symbols       map[string]SymbolInfo // exchange symbol (BTCUSDT) -> info
+	baseToSymbol  map[string]string     // base asset (BTC) -> exchange symbol
+	indexToSymbol map[int]string
+	// assignedIndex keeps every asset index ever handed out, so a symbol
+	// keeps its index across refreshes and delisted indexes are not reused.
+	assignedIndex map[string]int
+	symbolTTL     time.Duration
+	symbolLastRef time.Time
+}
+
+// ClientOption customises the Binance client.
+type ClientOption func(*Client)
+
+// WithHTTPClient overrides the default HTTP client.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithLogger attaches a custom logger (defaults to log.Default()).
+func WithLogger(logger *log.Logger) ClientOption {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithBaseURL overrides the REST endpoint (primarily for tests and proxies).
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		if trimmed := strings.TrimRight(strings.TrimSpace(baseURL), "/"); trimmed != "" {
+			c.baseURL = trimmed
+		}
+	}
+}
+
+// WithClock overrides the time source (primarily for testing).
+func WithClock(clock func() time.Time) ClientOption {
+	return func(c *Client) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
+
+// WithRecvWindow sets the recvWindow attached to signed requests.
+func WithRecvWindow(window time.Duration) ClientOption {
+	return func(c *Client) {
+		if window > 0 {
+			c.recvWindow = window
+		}
+	}
+}
+
+// WithQuoteAsset selects the settlement asset used to map coins to symbols
+// (defaults to USDT, so "BTC" resolves to "BTCUSDT").
+func WithQuoteAsset(asset string) ClientOption {
+	return func(c *Client) {
+		if trimmed := strings.ToUpper(strings.TrimSpace(asset)); trimmed != "" {
+			c.quoteAsset = trimmed
+		}
+	}
+}
+
+// WithDefaultSlippage configures a default slippage fraction used by helpers
+// when caller does not specify one (e.g. 0.01 = 1%).
+func WithDefaultSlippage(slippage float64) ClientOption {
+	return func(c *Client) {
+		if slippage > 0 {
+			c.defaultSlippage = slippage
+		}
+	}
+}
+
+// WithSymbolCacheTTL sets a time-to-live for the exchangeInfo cache.
+// When positive, the client refreshes symbol metadata after TTL elapses.
+func WithSymbolCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		if ttl > 0 {
+			c.symbolTTL = ttl
+		}
+	}
+}
+
+// NewClient constructs a Binance USDT-M futures client using API key credentials.
+func NewClient(apiKey, apiSecret string, isTestnet bool, opts ...ClientOption) (*Client, error) {
+	apiKey = strings.TrimSpace(apiKey)
+	apiSecret = strings.TrimSpace(apiSecret)
+	if apiKey == "" {
+		return nil, fmt.Errorf("binance: api key is required")
+	}
+	if apiSecret == "" {
+		return nil, fmt.Errorf("binance: api secret is required")
+	}
+
+	client := &Client{
+		baseURL:         mainnetBaseURL,
+		apiKey:          apiKey,
+		apiSecret:       []byte(apiSecret),
+		httpClient:      &http.Client{Timeout: defaultHTTPTimeout},
+		logger:          log.Default(),
+		clock:           time.Now,
+		recvWindow:      defaultRecvWindow,
+		quoteAsset:      defaultQuoteAsset,
+		isTestnet:       isTestnet,
+		defaultSlippage: defaultSlippage,
+		symbols:         make(map[string]SymbolInfo),
+		baseToSymbol:    make(map[string]string),
+		indexToSymbol:   make(map[int]string),
+		assignedIndex:   make(map[string]int),
+	}
+	if isTestnet {
+		client.baseURL = testnetBaseURL
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client, nil
+}
+
+// doPublic issues an unsigned GET request against a market data endpoint.
+func (c *Client) doPublic(ctx context.Context, path string, params url.Values, result interface{}) error {
+	return c.do(ctx, http.MethodGet, path, encodeParams(params), result)
+}
+
+// doSigned attaches timestamp, recvWindow and HMAC signature before issuing the
+// request. A timestamp rejection triggers one server-time resync and retry.
+func (c *Client) doSigned(ctx context.Context, method, path string, params url.Values, result interface{}) error {
+	err := c.do(ctx, method, path, c.signParams(params), result)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == errCodeTimestampOutsideWindow {
+		if syncErr := c.SyncServerTime(ctx); syncErr != nil {
+			return err
+		}
+		return c.do(ctx, method, path, c.signParams(params), result)
+	}
+	return err
+}
+
+func (c *Client) do(ctx context.Context, method, path, query string, result interface{}) error {
+	endpoint := c.baseURL + path
+	if query != "" {
+		endpoint += "?" + query
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("binance: build request: %w", err)
+	}
+	req.Header.Set(apiKeyHeader, c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("binance: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("binance: read response: %w", err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if jsonErr := json.Unmarshal(body, apiErr); jsonErr != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+		c.logf("binance: %s %s error status=%d body=%s", method, path, resp.StatusCode, string(body))
+		return apiErr
+	}
+	if result != nil {
+		if err := json.Unmarshal(body, result); err != nil {
+			return fmt.Errorf("binance: decode %s response: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// SyncServerTime measures the offset between the local clock and Binance
+// server time so subsequent signed requests stay inside recvWindow.
+func (c *Client) SyncServerTime(ctx context.Context) error {
+	var out serverTimeResponse
+	if err := c.doPublic(ctx, "/fapi/v1/time", nil, &out); err != nil {
+		return err
+	}
+	if out.ServerTime <= 0 {
+		return fmt.Errorf("binance: server time missing")
+	}
+	offset := time.UnixMilli(out.ServerTime).Sub(c.clock())
+	c.timeMu.Lock()
+	c.timeOffset = offset
+	c.timeMu.Unlock()
+	return nil
+}
+
+func (c *Client) now() time.Time {
+	c.timeMu.RLock()
+	offset := c.timeOffset
+	c.timeMu.RUnlock()
+	return c.clock().Add(offset)
+}
+
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, args...)
+	}
+}
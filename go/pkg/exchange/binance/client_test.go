@@ -0,0 +1,463 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+const (
+	testAPIKey    = "test-key"
+	testAPISecret = "test-secret"
+)
+
+// fakeFutures is a minimal stand-in for the USDT-M futures REST API that
+// verifies API key headers and HMAC signatures on signed endpoints.
+type fakeFutures struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	requests []*http.Request
+	orders   []map[string]string
+
+	orderStatus  string
+	positionRisk []positionRiskResponse
+	listing      []symbolEntry // exchangeInfo symbols; nil serves the default set
+	marginErr    bool
+}
+
+func newFakeFutures(t *testing.T) (*fakeFutures, *Client) {
+	t.Helper()
+	f := &fakeFutures{t: t, orderStatus: orderStatusFilled}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(testAPIKey, testAPISecret, true,
+		WithBaseURL(srv.URL),
+		WithClock(func() time.Time { return time.UnixMilli(1_700_000_000_000) }),
+	)
+	require.NoError(t, err)
+	return f, client
+}
+
+func (f *fakeFutures) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+	f.mu.Unlock()
+
+	signed := r.URL.Path != "/fapi/v1/exchangeInfo" && r.URL.Path != "/fapi/v1/premiumIndex" && r.URL.Path != "/fapi/v1/time"
+	if signed {
+		require.Equal(f.t, testAPIKey, r.Header.Get(apiKeyHeader))
+		raw := r.URL.RawQuery
+		idx := strings.LastIndex(raw, "&signature=")
+		require.GreaterOrEqual(f.t, idx, 0, "missing signature")
+		require.Equal(f.t, sign([]byte(testAPISecret), raw[:idx]), raw[idx+len("&signature="):])
+		require.NotEmpty(f.t, r.URL.Query().Get("timestamp"))
+	}
+
+	q := r.URL.Query()
+	switch r.URL.Path {
+	case "/fapi/v1/exchangeInfo":
+		f.mu.Lock()
+		listing := f.listing
+		f.mu.Unlock()
+		if listing != nil {
+			writeJSON(w, exchangeInfoResponse{Symbols: listing})
+			return
+		}
+		writeJSON(w, exchangeInfoResponse{Symbols: []symbolEntry{
+			testSymbol("BTCUSDT", "BTC", "0.10", "0.001"),
+			{Symbol: "BTCUSDT_250328", BaseAsset: "BTC", QuoteAsset: "USDT", ContractType: "CURRENT_QUARTER", Status: "TRADING"},
+			testSymbol("ETHUSDT", "ETH", "0.01", "0.001"),
+		}})
+	case "/fapi/v1/premiumIndex":
+		writeJSON(w, premiumIndexResponse{Symbol: q.Get("symbol"), MarkPrice: "100.00"})
+	case "/fapi/v1/order":
+		order := map[string]string{"method": r.Method}
+		for k := range q {
+			order[k] = q.Get(k)
+		}
+		f.mu.Lock()
+		f.orders = append(f.orders, order)
+		f.mu.Unlock()
+		writeJSON(w, orderResponse{
+			OrderID:     42,
+			Symbol:      q.Get("symbol"),
+			Status:      f.orderStatus,
+			OrigQty:     q.Get("quantity"),
+			ExecutedQty: q.Get("quantity"),
+			AvgPrice:    "100.5",
+		})
+	case "/fapi/v1/openOrders":
+		writeJSON(w, []orderResponse{{
+			OrderID: 7, Symbol: "ETHUSDT", Side: sideSell, Price: "2000", OrigQty: "1.5", ExecutedQty: "0.5",
+			ClientOrderID: "abc", Time: 10, UpdateTime: 11,
+		}})
+	case "/fapi/v2/positionRisk":
+		writeJSON(w, f.positionRisk)
+	case "/fapi/v2/account":
+		writeJSON(w, accountResponse{TotalWalletBalance: "1000", TotalMarginBalance: "1050.5", TotalInitialMargin: "100"})
+	case "/fapi/v1/marginType":
+		if f.marginErr {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":-4046,"msg":"No need to change margin type."}`))
+			return
+		}
+		writeJSON(w, map[string]interface{}{"code": 200, "msg": "success"})
+	case "/fapi/v1/leverage":
+		writeJSON(w, map[string]interface{}{"leverage": q.Get("leverage"), "symbol": q.Get("symbol")})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":-1000,"msg":"unknown path"}`))
+	}
+}
+
+func (f *fakeFutures) lastOrder() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.NotEmpty(f.t, f.orders)
+	return f.orders[len(f.orders)-1]
+}
+
+func testSymbol(symbol, base, tick, step string) symbolEntry {
+	return symbolEntry{
+		Symbol:       symbol,
+		BaseAsset:    base,
+		QuoteAsset:   "USDT",
+		ContractType: "PERPETUAL",
+		Status:       "TRADING",
+		Filters: []symbolFilter{
+			{FilterType: "PRICE_FILTER", TickSize: tick},
+			{FilterType: "LOT_SIZE", StepSize: step, MinQty: step},
+		},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestNewClientRequiresCredentials(t *testing.T) {
+	_, err := NewClient("", "secret", false)
+	require.Error(t, err)
+	_, err = NewClient("key", " ", false)
+	require.Error(t, err)
+
+	client, err := NewClient("key", "secret", false)
+	require.NoError(t, err)
+	require.Equal(t, mainnetBaseURL, client.baseURL)
+
+	client, err = NewClient("key", "secret", true)
+	require.NoError(t, err)
+	require.Equal(t, testnetBaseURL, client.baseURL)
+}
+
+func TestSignParamsMatchesDocumentedExample(t *testing.T) {
+	// Example taken from the Binance API documentation for SIGNED endpoints.
+	secret := []byte("NhqPtmdSJYdKjVHjA7PZj4Mge3R5YNiP1e3UZjInClVN65XAbvqqM6A7H5fATj0j")
+	payload := "symbol=LTCBTC&side=BUY&type=LIMIT&timeInForce=GTC&quantity=1&price=0.1&recvWindow=5000&timestamp=1499827319559"
+	require.Equal(t, "c8db56825ae71d6d79447849e617115f4a920fa2acdcab2b053c4b2838bd6b71", sign(secret, payload))
+}
+
+func TestSymbolDirectoryFiltersPerpetuals(t *testing.T) {
+	_, client := newFakeFutures(t)
+	ctx := context.Background()
+
+	btc, err := client.GetAssetIndex(ctx, "btc")
+	require.NoError(t, err)
+	require.Equal(t, 0, btc)
+
+	eth, err := client.GetAssetIndex(ctx, "ETHUSDT")
+	require.NoError(t, err)
+	require.Equal(t, 1, eth)
+
+	_, err = client.GetAssetIndex(ctx, "DOGE")
+	require.Error(t, err)
+}
+
+func TestSymbolDirectoryKeepsIndexesAcrossRefresh(t *testing.T) {
+	fake, client := newFakeFutures(t)
+	ctx := context.Background()
+
+	btc, err := client.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+	eth, err := client.GetAssetIndex(ctx, "ETH")
+	require.NoError(t, err)
+
+	fake.mu.Lock()
+	fake.listing = []symbolEntry{
+		testSymbol("SOLUSDT", "SOL", "0.01", "1"),
+		testSymbol("ETHUSDT", "ETH", "0.01", "0.001"),
+		testSymbol("BTCUSDT", "BTC", "0.10", "0.001"),
+	}
+	fake.mu.Unlock()
+	require.NoError(t, client.refreshSymbols(ctx))
+
+	got, err := client.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+	require.Equal(t, btc, got, "a reordered listing keeps existing indexes")
+	got, err = client.GetAssetIndex(ctx, "ETH")
+	require.NoError(t, err)
+	require.Equal(t, eth, got)
+	sol, err := client.GetAssetIndex(ctx, "SOL")
+	require.NoError(t, err)
+	require.Equal(t, 2, sol, "new symbols are appended")
+
+	fake.mu.Lock()
+	fake.listing = []symbolEntry{testSymbol("BTCUSDT", "BTC", "0.10", "0.001"), testSymbol("DOGEUSDT", "DOGE", "0.0001", "1")}
+	fake.mu.Unlock()
+	require.NoError(t, client.refreshSymbols(ctx))
+	doge, err := client.GetAssetIndex(ctx, "DOGE")
+	require.NoError(t, err)
+	require.Equal(t, 3, doge, "indexes of delisted symbols are not reused")
+	info, err := client.GetSymbolInfo(ctx, "BTC")
+	require.NoError(t, err)
+	require.Equal(t, btc, info.Index)
+}
+
+func TestFormatPriceAndSize(t *testing.T) {
+	_, client := newFakeFutures(t)
+	ctx := context.Background()
+
+	px, err := client.FormatPrice(ctx, "BTC", 100.16)
+	require.NoError(t, err)
+	require.Equal(t, "100.2", px)
+
+	sz, err := client.FormatSize(ctx, "BTC", 0.12345)
+	require.NoError(t, err)
+	require.Equal(t, "0.123", sz)
+	step, err := client.SizeStep(ctx, "BTC")
+	require.NoError(t, err)
+	require.Equal(t, "0.001", step.String())
+
+	_, err = client.FormatSize(ctx, "BTC", 0.0004)
+	require.Error(t, err)
+
+	// Sizes already on the lot grid survive the floor even where float
+	// division lands just below it (166320.455 / 0.001 = 166320454.99999997).
+	sz, err = client.FormatSize(ctx, "BTC", 166320.455)
+	require.NoError(t, err)
+	require.Equal(t, "166320.455", sz)
+}
+
+func TestPlaceOrderLimit(t *testing.T) {
+	f, client := newFakeFutures(t)
+	resp, err := client.PlaceOrder(context.Background(), exchange.Order{
+		Asset:     1,
+		IsBuy:     true,
+		LimitPx:   "2000.5",
+		Sz:        "0.5",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Alo"}},
+		Cloid:     "nof0-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp.Status)
+	require.Len(t, resp.Response.Data.Statuses, 1)
+	require.NotNil(t, resp.Response.Data.Statuses[0].Filled)
+	require.Equal(t, "0.5", resp.Response.Data.Statuses[0].Filled.TotalSz)
+
+	order := f.lastOrder()
+	require.Equal(t, http.MethodPost, order["method"])
+	require.Equal(t, "ETHUSDT", order["symbol"])
+	require.Equal(t, sideBuy, order["side"])
+	require.Equal(t, orderTypeLimit, order["type"])
+	require.Equal(t, tifGTX, order["timeInForce"])
+	require.Equal(t, "2000.5", order["price"])
+	require.Equal(t, "nof0-1", order["newClientOrderId"])
+}
+
+func TestIOCMarketUsesMarkPriceWithSlippage(t *testing.T) {
+	f, client := newFakeFutures(t)
+	_, err := client.IOCMarket(context.Background(), "BTC", false, 0.01, 0.05, true)
+	require.NoError(t, err)
+
+	order := f.lastOrder()
+	require.Equal(t, sideSell, order["side"])
+	require.Equal(t, tifIOC, order["timeInForce"])
+	require.Equal(t, "95", order["price"])
+	require.Equal(t, "true", order["reduceOnly"])
+}
+
+func TestToOrderResponseStatuses(t *testing.T) {
+	filled := toOrderResponse(orderResponse{OrderID: 1, Status: orderStatusFilled, ExecutedQty: "1", AvgPrice: "10"})
+	st := filled.Response.Data.Statuses[0]
+	require.NotNil(t, st.Filled)
+	require.Nil(t, st.Resting)
+
+	partial := toOrderResponse(orderResponse{OrderID: 2, Status: orderStatusPartiallyFilled, ExecutedQty: "0.5"}).Response.Data.Statuses[0]
+	require.NotNil(t, partial.Filled)
+	require.NotNil(t, partial.Resting)
+
+	resting := toOrderResponse(orderResponse{OrderID: 3, Status: orderStatusNew}).Response.Data.Statuses[0]
+	require.NotNil(t, resting.Resting)
+	require.Equal(t, int64(3), resting.Resting.Oid)
+
+	expired := toOrderResponse(orderResponse{OrderID: 4, Status: orderStatusExpired, ExecutedQty: "0"}).Response.Data.Statuses[0]
+	require.NotEmpty(t, expired.Error)
+
+	expiredPartial := toOrderResponse(orderResponse{OrderID: 5, Status: orderStatusExpired, ExecutedQty: "0.2"}).Response.Data.Statuses[0]
+	require.Empty(t, expiredPartial.Error)
+	require.NotNil(t, expiredPartial.Filled)
+}
+
+func TestIOCMarketRejectsUnfilledOrder(t *testing.T) {
+	f, client := newFakeFutures(t)
+	f.orderStatus = orderStatusRejected
+	_, err := client.IOCMarket(context.Background(), "BTC", true, 0.01, 0.01, false)
+	require.Error(t, err)
+}
+
+func TestBuildOrderParamsValidation(t *testing.T) {
+	_, err := buildOrderParams("BTCUSDT", exchange.Order{Sz: "0", LimitPx: "1", OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}}})
+	require.Error(t, err)
+
+	_, err = buildOrderParams("BTCUSDT", exchange.Order{Sz: "1", LimitPx: "1"})
+	require.Error(t, err)
+
+	_, err = buildOrderParams("BTCUSDT", exchange.Order{Sz: "1", LimitPx: "1", Cloid: "0x" + strings.Repeat("a", 40), OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}}})
+	require.Error(t, err)
+
+	params, err := buildOrderParams("BTCUSDT", exchange.Order{
+		Sz: "1", LimitPx: "99", TriggerPx: "100",
+		OrderType: exchange.OrderType{Trigger: &exchange.TriggerOrderType{IsMarket: false, Tpsl: "tp"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, orderTypeTakeProfit, params.Get("type"))
+	require.Equal(t, "99", params.Get("price"))
+	require.Equal(t, "100", params.Get("stopPrice"))
+	require.Equal(t, tifGTC, params.Get("timeInForce"))
+
+	_, err = mapTIF("Fok")
+	require.Error(t, err)
+}
+
+func TestSetStopLossPlacesReduceOnlyTrigger(t *testing.T) {
+	f, client := newFakeFutures(t)
+	provider := &Provider{client: client}
+	require.NoError(t, provider.SetStopLoss(context.Background(), "BTC", "LONG", 0.01, 90.04))
+
+	order := f.lastOrder()
+	require.Equal(t, orderTypeStopMarket, order["type"])
+	require.Equal(t, sideSell, order["side"])
+	require.Equal(t, "90", order["stopPrice"])
+	require.Equal(t, workingTypeMarkPrice, order["workingType"])
+	require.Equal(t, "true", order["reduceOnly"])
+	require.Empty(t, order["price"])
+
+	require.NoError(t, provider.SetTakeProfit(context.Background(), "BTC", "SHORT", 0.01, 80))
+	order = f.lastOrder()
+	require.Equal(t, orderTypeTakeProfitMarket, order["type"])
+	require.Equal(t, sideBuy, order["side"])
+}
+
+func TestGetOpenOrders(t *testing.T) {
+	_, client := newFakeFutures(t)
+	_, err := client.GetAssetIndex(context.Background(), "ETH")
+	require.NoError(t, err)
+
+	orders, err := client.GetOpenOrders(context.Background())
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	require.Equal(t, "ETH", orders[0].Order.Coin)
+	require.Equal(t, "A", orders[0].Order.Side)
+	require.Equal(t, "1", orders[0].Order.Sz)
+	require.Equal(t, "1.5", orders[0].Order.OrigSz)
+	require.Equal(t, int64(7), orders[0].Order.Oid)
+	require.Equal(t, "abc", orders[0].Order.Cloid)
+}
+
+func TestGetPositionsAndAccountState(t *testing.T) {
+	f, client := newFakeFutures(t)
+	f.positionRisk = []positionRiskResponse{
+		{Symbol: "BTCUSDT", PositionAmt: "0.5", EntryPrice: "90", MarkPrice: "100", UnRealizedProfit: "5", Leverage: "10", MarginType: "cross", Notional: "50", LiquidationPrice: "0"},
+		{Symbol: "ETHUSDT", PositionAmt: "0", Leverage: "20"},
+	}
+	ctx := context.Background()
+	_, err := client.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+
+	positions, err := client.GetPositions(ctx)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	pos := positions[0]
+	require.Equal(t, "BTC", pos.Coin)
+	require.Equal(t, "0.5", pos.Szi)
+	require.Equal(t, "50", pos.PositionValue)
+	require.Equal(t, "1", pos.ReturnOnEquity)
+	require.Equal(t, exchange.Leverage{Type: "cross", Value: 10}, pos.Leverage)
+	require.NotNil(t, pos.EntryPx)
+	require.Equal(t, "90", *pos.EntryPx)
+	require.Nil(t, pos.LiquidationPx)
+
+	state, err := client.GetAccountState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "1050.5", state.MarginSummary.AccountValue)
+	require.Equal(t, "100", state.MarginSummary.TotalMarginUsed)
+	require.Equal(t, "50", state.MarginSummary.TotalNtlPos)
+	require.Equal(t, "1000", state.MarginSummary.TotalRawUSD)
+	require.Len(t, state.AssetPositions, 1)
+
+	value, err := client.GetAccountValue(ctx)
+	require.NoError(t, err)
+	require.InDelta(t, 1050.5, value, 1e-9)
+}
+
+func TestClosePositionSendsReduceOnlyMarket(t *testing.T) {
+	f, client := newFakeFutures(t)
+	f.positionRisk = []positionRiskResponse{
+		{Symbol: "BTCUSDT", PositionAmt: "-0.25", EntryPrice: "100", MarkPrice: "100", Leverage: "5", Notional: "-25"},
+	}
+	resp, err := client.ClosePosition(context.Background(), "BTC")
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	order := f.lastOrder()
+	require.Equal(t, orderTypeMarket, order["type"])
+	require.Equal(t, sideBuy, order["side"])
+	require.Equal(t, "0.25", order["quantity"])
+	require.Equal(t, "true", order["reduceOnly"])
+
+	resp, err = client.ClosePosition(context.Background(), "ETH")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+func TestUpdateLeverageIgnoresUnchangedMarginType(t *testing.T) {
+	f, client := newFakeFutures(t)
+	f.marginErr = true
+	require.NoError(t, client.UpdateLeverage(context.Background(), 0, true, 15))
+
+	var paths []string
+	f.mu.Lock()
+	for _, r := range f.requests {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/fapi/v1/marginType" {
+			require.Equal(t, "CROSSED", r.URL.Query().Get("marginType"))
+		}
+		if r.URL.Path == "/fapi/v1/leverage" {
+			require.Equal(t, "15", r.URL.Query().Get("leverage"))
+		}
+	}
+	f.mu.Unlock()
+	require.Contains(t, paths, "/fapi/v1/marginType")
+	require.Contains(t, paths, "/fapi/v1/leverage")
+}
+
+func TestAPIErrorIsSurfaced(t *testing.T) {
+	_, client := newFakeFutures(t)
+	err := client.doSigned(context.Background(), http.MethodGet, "/fapi/v1/unknown", nil, nil)
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	require.Equal(t, -1000, apiErr.Code)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
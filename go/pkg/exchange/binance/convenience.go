@@ -0,0 +1,120 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+)
+
+// GetMarkPrice returns the latest mark price for coin from /fapi/v1/premiumIndex.
+func (c *Client) GetMarkPrice(ctx context.Context, coin string) (float64, error) {
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return 0, err
+	}
+	params := url.Values{}
+	params.Set("symbol", info.Symbol)
+	var out premiumIndexResponse
+	if err := c.doPublic(ctx, "/fapi/v1/premiumIndex", params, &out); err != nil {
+		return 0, err
+	}
+	px := parseFloat(out.MarkPrice)
+	if !(px > 0) {
+		return 0, fmt.Errorf("binance: invalid mark price %q for %s", out.MarkPrice, info.Symbol)
+	}
+	return px, nil
+}
+
+// IOCMarket places an IOC limit order offset from the mark price by slippage,
+// bounding execution price the same way the Hyperliquid helper does.
+// - slippage is a fraction, e.g. 0.01 = 1%.
+func (c *Client) IOCMarket(ctx context.Context, coin string, isBuy bool, qty float64, slippage float64, reduceOnly bool) (*exchange.OrderResponse, error) {
+	if slippage <= 0 {
+		slippage = c.defaultSlippage
+	}
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	mark, err := c.GetMarkPrice(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	px := mark * (1 - slippage)
+	if isBuy {
+		px = mark * (1 + slippage)
+	}
+	price, err := c.FormatPrice(ctx, coin, px)
+	if err != nil {
+		return nil, err
+	}
+	size, err := c.FormatSize(ctx, coin, qty)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.PlaceOrder(ctx, exchange.Order{
+		Asset:      info.Index,
+		IsBuy:      isBuy,
+		LimitPx:    price,
+		Sz:         size,
+		ReduceOnly: reduceOnly,
+		OrderType:  exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Response.Data.Statuses) > 0 && resp.Response.Data.Statuses[0].Error != "" {
+		return resp, fmt.Errorf("binance: order rejected: %s", resp.Response.Data.Statuses[0].Error)
+	}
+	return resp, nil
+}
+
+// PlaceTriggerReduceOnly creates a reduce-only mark-price trigger order.
+// tpsl selects TAKE_PROFIT_MARKET ("tp") or STOP_MARKET ("sl").
+func (c *Client) PlaceTriggerReduceOnly(ctx context.Context, coin string, isBuy bool, qty float64, triggerPx float64, tpsl string) error {
+	if !(triggerPx > 0) {
+		return fmt.Errorf("binance: trigger price must be positive")
+	}
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return err
+	}
+	size, err := c.FormatSize(ctx, coin, qty)
+	if err != nil {
+		return err
+	}
+	stop, err := c.FormatPrice(ctx, coin, triggerPx)
+	if err != nil {
+		return err
+	}
+	_, err = c.PlaceOrder(ctx, exchange.Order{
+		Asset:      info.Index,
+		IsBuy:      isBuy,
+		Sz:         size,
+		ReduceOnly: true,
+		TriggerPx:  stop,
+		OrderType:  exchange.OrderType{Trigger: &exchange.TriggerOrderType{IsMarket: true, Tpsl: strings.ToLower(tpsl)}},
+	})
+	return err
+}
+
+// GetOrder queries a single order by exchange id, mainly to poll resting orders.
+func (c *Client) GetOrder(ctx context.Context, coin string, oid int64) (*exchange.OrderResponse, error) {
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("symbol", info.Symbol)
+	params.Set("orderId", strconv.FormatInt(oid, 10))
+	var out orderResponse
+	if err := c.doSigned(ctx, http.MethodGet, "/fapi/v1/order", params, &out); err != nil {
+		return nil, err
+	}
+	return toOrderResponse(out), nil
+}
@@ -0,0 +1,24 @@
+package binance
+
+import "fmt"
+
+const (
+	// errCodeTimestampOutsideWindow is returned when the signed timestamp
+	// drifts outside recvWindow relative to server time.
+	errCodeTimestampOutsideWindow = -1021
+	// errCodeNoNeedToChangeMarginType is returned when the requested margin
+	// type is already active for the symbol.
+	errCodeNoNeedToChangeMarginType = -4046
+)
+
+// APIError describes an error payload returned by the Binance REST API.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"code"`
+	Message    string `json:"msg"`
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("binance: api error code=%d status=%d: %s", e.Code, e.StatusCode, e.Message)
+}
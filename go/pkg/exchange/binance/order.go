@@ -0,0 +1,249 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+)
+
+var (
+	errInvalidPrice = errors.New("binance: price must be positive")
+	errInvalidSize  = errors.New("binance: size must be positive")
+
+	// Binance restricts newClientOrderId to this character set and length.
+	clientOrderIDPattern = regexp.MustCompile(`^[.A-Z:/a-z0-9_-]{1,36}$`)
+)
+
+// PlaceOrder submits a single order to /fapi/v1/order and normalises the
+// response into the Hyperliquid-shaped exchange.OrderResponse.
+func (c *Client) PlaceOrder(ctx context.Context, order exchange.Order) (*exchange.OrderResponse, error) {
+	info, err := c.symbolForAsset(ctx, order.Asset)
+	if err != nil {
+		return nil, err
+	}
+	params, err := buildOrderParams(info.Symbol, order)
+	if err != nil {
+		return nil, err
+	}
+	var out orderResponse
+	if err := c.doSigned(ctx, http.MethodPost, "/fapi/v1/order", params, &out); err != nil {
+		return nil, err
+	}
+	return toOrderResponse(out), nil
+}
+
+// CancelOrder cancels a single order by exchange order id.
+func (c *Client) CancelOrder(ctx context.Context, asset int, oid int64) error {
+	info, err := c.symbolForAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("symbol", info.Symbol)
+	params.Set("orderId", strconv.FormatInt(oid, 10))
+	return c.doSigned(ctx, http.MethodDelete, "/fapi/v1/order", params, nil)
+}
+
+// CancelByCloid cancels a single order identified by client order id.
+func (c *Client) CancelByCloid(ctx context.Context, asset int, cloid string) error {
+	cloid = strings.TrimSpace(cloid)
+	if cloid == "" {
+		return fmt.Errorf("binance: cloid is required")
+	}
+	info, err := c.symbolForAsset(ctx, asset)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("symbol", info.Symbol)
+	params.Set("origClientOrderId", cloid)
+	return c.doSigned(ctx, http.MethodDelete, "/fapi/v1/order", params, nil)
+}
+
+// CancelAllBySymbol cancels all resting orders for the given coin.
+func (c *Client) CancelAllBySymbol(ctx context.Context, coin string) error {
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("symbol", info.Symbol)
+	return c.doSigned(ctx, http.MethodDelete, "/fapi/v1/allOpenOrders", params, nil)
+}
+
+// GetOpenOrders returns currently resting orders across all symbols.
+func (c *Client) GetOpenOrders(ctx context.Context) ([]exchange.OrderStatus, error) {
+	var orders []orderResponse
+	if err := c.doSigned(ctx, http.MethodGet, "/fapi/v1/openOrders", nil, &orders); err != nil {
+		return nil, err
+	}
+	results := make([]exchange.OrderStatus, 0, len(orders))
+	for _, o := range orders {
+		coin := o.Symbol
+		if info, ok := c.cachedSymbol(canonicalAssetKey(o.Symbol)); ok && info.BaseAsset != "" {
+			coin = info.BaseAsset
+		}
+		side := "B"
+		if strings.EqualFold(o.Side, sideSell) {
+			side = "A"
+		}
+		limitPx := o.Price
+		if isZeroString(limitPx) && !isZeroString(o.StopPrice) {
+			limitPx = o.StopPrice
+		}
+		remaining := parseFloat(o.OrigQty) - parseFloat(o.ExecutedQty)
+		results = append(results, exchange.OrderStatus{
+			Order: exchange.OrderInfo{
+				Coin:      coin,
+				Side:      side,
+				LimitPx:   limitPx,
+				Sz:        formatDecimal(remaining),
+				Oid:       o.OrderID,
+				Timestamp: o.Time,
+				OrigSz:    o.OrigQty,
+				Cloid:     o.ClientOrderID,
+			},
+			Status:          "open",
+			StatusTimestamp: o.UpdateTime,
+		})
+	}
+	return results, nil
+}
+
+// buildOrderParams maps a venue-agnostic order onto /fapi/v1/order parameters.
+func buildOrderParams(symbol string, order exchange.Order) (url.Values, error) {
+	if !isPositiveString(order.Sz) {
+		return nil, errInvalidSize
+	}
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", sideFor(order.IsBuy))
+	params.Set("quantity", strings.TrimSpace(order.Sz))
+	params.Set("newOrderRespType", "RESULT")
+	if order.ReduceOnly {
+		params.Set("reduceOnly", "true")
+	}
+	if cloid := strings.TrimSpace(order.Cloid); cloid != "" {
+		if !clientOrderIDPattern.MatchString(cloid) {
+			return nil, fmt.Errorf("binance: cloid %q does not satisfy client order id constraints", cloid)
+		}
+		params.Set("newClientOrderId", cloid)
+	}
+
+	if order.OrderType.Trigger != nil || (strings.TrimSpace(order.TriggerPx) != "" && order.OrderType.Limit == nil) {
+		if !isPositiveString(order.TriggerPx) {
+			return nil, fmt.Errorf("binance: trigger price must be positive")
+		}
+		trigger := order.OrderType.Trigger
+		isMarket := trigger == nil || trigger.IsMarket
+		isTakeProfit := trigger != nil && strings.EqualFold(trigger.Tpsl, "tp")
+		switch {
+		case isTakeProfit && isMarket:
+			params.Set("type", orderTypeTakeProfitMarket)
+		case isTakeProfit:
+			params.Set("type", orderTypeTakeProfit)
+		case isMarket:
+			params.Set("type", orderTypeStopMarket)
+		default:
+			params.Set("type", orderTypeStop)
+		}
+		if !isMarket {
+			if !isPositiveString(order.LimitPx) {
+				return nil, errInvalidPrice
+			}
+			params.Set("price", strings.TrimSpace(order.LimitPx))
+			params.Set("timeInForce", tifGTC)
+		}
+		params.Set("stopPrice", strings.TrimSpace(order.TriggerPx))
+		params.Set("workingType", workingTypeMarkPrice)
+		return params, nil
+	}
+
+	if order.OrderType.Limit == nil {
+		return nil, fmt.Errorf("binance: order type not specified (limit or trigger)")
+	}
+	if !isPositiveString(order.LimitPx) {
+		return nil, errInvalidPrice
+	}
+	tif, err := mapTIF(order.OrderType.Limit.TIF)
+	if err != nil {
+		return nil, err
+	}
+	params.Set("type", orderTypeLimit)
+	params.Set("price", strings.TrimSpace(order.LimitPx))
+	params.Set("timeInForce", tif)
+	return params, nil
+}
+
+// mapTIF translates Hyperliquid-style TIF names (Gtc/Ioc/Alo) to Binance.
+func mapTIF(tif string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(tif)) {
+	case "", "gtc":
+		return tifGTC, nil
+	case "ioc":
+		return tifIOC, nil
+	case "alo", "gtx":
+		return tifGTX, nil
+	default:
+		return "", fmt.Errorf("binance: unsupported time in force %q", tif)
+	}
+}
+
+func sideFor(isBuy bool) string {
+	if isBuy {
+		return sideBuy
+	}
+	return sideSell
+}
+
+// toOrderResponse converts a Binance order result into the shared response
+// shape: executed quantity is reported as filled, open quantity as resting and
+// IOC/GTX orders that expired untouched as an error status.
+func toOrderResponse(o orderResponse) *exchange.OrderResponse {
+	var st exchange.OrderStatusResponse
+	executed := parseFloat(o.ExecutedQty)
+	switch strings.ToUpper(o.Status) {
+	case orderStatusFilled, orderStatusPartiallyFilled:
+		st.Filled = &exchange.FilledOrder{TotalSz: o.ExecutedQty, AvgPx: o.AvgPrice, Oid: o.OrderID}
+		if strings.ToUpper(o.Status) == orderStatusPartiallyFilled {
+			st.Resting = &exchange.RestingOrder{Oid: o.OrderID}
+		}
+	case orderStatusNew:
+		st.Resting = &exchange.RestingOrder{Oid: o.OrderID}
+	case orderStatusExpired, orderStatusCanceled:
+		if executed > 0 {
+			st.Filled = &exchange.FilledOrder{TotalSz: o.ExecutedQty, AvgPx: o.AvgPrice, Oid: o.OrderID}
+		} else {
+			st.Error = fmt.Sprintf("order %d %s without fill", o.OrderID, strings.ToLower(o.Status))
+		}
+	case orderStatusRejected:
+		st.Error = fmt.Sprintf("order %d rejected", o.OrderID)
+	default:
+		st.Resting = &exchange.RestingOrder{Oid: o.OrderID}
+	}
+	return &exchange.OrderResponse{
+		Status: "ok",
+		Response: exchange.OrderResponseData{
+			Type: "order",
+			Data: exchange.OrderResponseDataDetail{
+				Statuses: []exchange.OrderStatusResponse{st},
+			},
+		},
+	}
+}
+
+func isPositiveString(s string) bool {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil && v > 0 && isFinite(v)
+}
+
+func isZeroString(s string) bool {
+	return parseFloat(s) == 0
+}
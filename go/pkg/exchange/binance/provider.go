@@ -0,0 +1,151 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/vcr"
+)
+
+// Provider wraps Client to satisfy the exchange.Provider interface.
+type Provider struct {
+	client *Client
+}
+
+// Compile-time checks for the optional extensions the manager relies on.
+var (
+	_ exchange.Provider          = (*Provider)(nil)
+	_ exchange.MarketOrderer     = (*Provider)(nil)
+	_ exchange.ProtectiveOrderer = (*Provider)(nil)
+	_ exchange.Formatter         = (*Provider)(nil)
+	_ exchange.SymbolCanceller   = (*Provider)(nil)
+)
+
+// NewProvider constructs a Binance USDT-M futures exchange provider.
+func NewProvider(apiKey, apiSecret string, isTestnet bool, opts ...ClientOption) (*Provider, error) {
+	client, err := NewClient(apiKey, apiSecret, isTestnet, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{client: client}, nil
+}
+
+func init() {
+	exchange.RegisterProvider("binance", func(name string, cfg *exchange.ProviderConfig) (exchange.Provider, error) {
+		opts := []ClientOption{}
+		if cfg.Timeout > 0 {
+			opts = append(opts, WithHTTPClient(&http.Client{Timeout: cfg.Timeout}))
+		}
+		if c := cfg.Cassette; c != nil {
+			recorder, err := vcr.New(c.Path, vcr.Mode(c.Mode))
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, WithHTTPClient(recorder.HTTPClient(cfg.Timeout)))
+		}
+		return NewProvider(cfg.APIKey, cfg.APISecret, cfg.Testnet, opts...)
+	})
+}
+
+// Client exposes the underlying REST client for venue-specific calls.
+func (p *Provider) Client() *Client {
+	return p.client
+}
+
+// PlaceOrder delegates to the underlying client.
+func (p *Provider) PlaceOrder(ctx context.Context, order exchange.Order) (*exchange.OrderResponse, error) {
+	return p.client.PlaceOrder(ctx, order)
+}
+
+// CancelOrder cancels a single order.
+func (p *Provider) CancelOrder(ctx context.Context, asset int, oid int64) error {
+	return p.client.CancelOrder(ctx, asset, oid)
+}
+
+// GetOpenOrders returns currently resting orders.
+func (p *Provider) GetOpenOrders(ctx context.Context) ([]exchange.OrderStatus, error) {
+	return p.client.GetOpenOrders(ctx)
+}
+
+// GetPositions fetches all open positions.
+func (p *Provider) GetPositions(ctx context.Context) ([]exchange.Position, error) {
+	return p.client.GetPositions(ctx)
+}
+
+// ClosePosition attempts to close an open position.
+func (p *Provider) ClosePosition(ctx context.Context, coin string) (*exchange.OrderResponse, error) {
+	return p.client.ClosePosition(ctx, coin)
+}
+
+// UpdateLeverage updates margin type and leverage configuration.
+func (p *Provider) UpdateLeverage(ctx context.Context, asset int, isCross bool, leverage int) error {
+	return p.client.UpdateLeverage(ctx, asset, isCross, leverage)
+}
+
+// GetAccountState returns current account state.
+func (p *Provider) GetAccountState(ctx context.Context) (*exchange.AccountState, error) {
+	return p.client.GetAccountState(ctx)
+}
+
+// GetAccountValue returns parsed account value.
+func (p *Provider) GetAccountValue(ctx context.Context) (float64, error) {
+	return p.client.GetAccountValue(ctx)
+}
+
+// GetAssetIndex resolves asset index for a symbol.
+func (p *Provider) GetAssetIndex(ctx context.Context, coin string) (int, error) {
+	return p.client.GetAssetIndex(ctx, coin)
+}
+
+// Convenience wrappers (not part of the generic exchange.Provider interface)
+
+// IOCMarket places an IOC order using a small price slippage as market.
+func (p *Provider) IOCMarket(ctx context.Context, coin string, isBuy bool, qty float64, slippage float64, reduceOnly bool) (*exchange.OrderResponse, error) {
+	return p.client.IOCMarket(ctx, coin, isBuy, qty, slippage, reduceOnly)
+}
+
+// SetStopLoss places a reduce-only STOP_MARKET order.
+// positionSide: "LONG" or "SHORT".
+func (p *Provider) SetStopLoss(ctx context.Context, coin string, positionSide string, qty float64, stopPrice float64) error {
+	isBuy := strings.EqualFold(positionSide, "SHORT") // buy to cover short
+	return p.client.PlaceTriggerReduceOnly(ctx, coin, isBuy, qty, stopPrice, "sl")
+}
+
+// SetTakeProfit places a reduce-only TAKE_PROFIT_MARKET order.
+// positionSide: "LONG" or "SHORT".
+func (p *Provider) SetTakeProfit(ctx context.Context, coin string, positionSide string, qty float64, takeProfit float64) error {
+	isBuy := strings.EqualFold(positionSide, "SHORT")
+	return p.client.PlaceTriggerReduceOnly(ctx, coin, isBuy, qty, takeProfit, "tp")
+}
+
+// CancelAllBySymbol cancels all resting orders for the given symbol.
+func (p *Provider) CancelAllBySymbol(ctx context.Context, coin string) error {
+	return p.client.CancelAllBySymbol(ctx, coin)
+}
+
+// CancelByCloid cancels an order using its client order identifier.
+func (p *Provider) CancelByCloid(ctx context.Context, asset int, cloid string) error {
+	return p.client.CancelByCloid(ctx, asset, cloid)
+}
+
+// FormatSize rounds a quantity down to the symbol's lot step.
+func (p *Provider) FormatSize(ctx context.Context, coin string, qty float64) (string, error) {
+	return p.client.FormatSize(ctx, coin, qty)
+}
+
+// SizeStep returns the symbol's lot step.
+func (p *Provider) SizeStep(ctx context.Context, coin string) (exchange.Decimal, error) {
+	return p.client.SizeStep(ctx, coin)
+}
+
+// FormatPrice rounds a price to the symbol's tick size.
+func (p *Provider) FormatPrice(ctx context.Context, coin string, price float64) (string, error) {
+	return p.client.FormatPrice(ctx, coin, price)
+}
+
+// Capabilities reports the optional exchange extensions this provider supports.
+func (p *Provider) Capabilities() exchange.Capabilities {
+	return exchange.DetectCapabilities(p)
+}
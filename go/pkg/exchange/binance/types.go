@@ -0,0 +1,130 @@
+package binance
+
+// Order type and time-in-force identifiers accepted by /fapi/v1/order.
+const (
+	orderTypeLimit            = "LIMIT"
+	orderTypeMarket           = "MARKET"
+	orderTypeStop             = "STOP"
+	orderTypeStopMarket       = "STOP_MARKET"
+	orderTypeTakeProfit       = "TAKE_PROFIT"
+	orderTypeTakeProfitMarket = "TAKE_PROFIT_MARKET"
+
+	tifGTC = "GTC"
+	tifIOC = "IOC"
+	tifGTX = "GTX" // post-only
+
+	sideBuy  = "BUY"
+	sideSell = "SELL"
+
+	orderStatusNew             = "NEW"
+	orderStatusPartiallyFilled = "PARTIALLY_FILLED"
+	orderStatusFilled          = "FILLED"
+	orderStatusCanceled        = "CANCELED"
+	orderStatusExpired         = "EXPIRED"
+	orderStatusRejected        = "REJECTED"
+
+	workingTypeMarkPrice = "MARK_PRICE"
+)
+
+// SymbolInfo aggregates the trading rules needed to build valid orders.
+type SymbolInfo struct {
+	Symbol            string
+	BaseAsset         string
+	QuoteAsset        string
+	Index             int
+	PricePrecision    int
+	QuantityPrecision int
+	TickSize          string
+	StepSize          string
+	MinQty            string
+	MinNotional       string
+}
+
+type serverTimeResponse struct {
+	ServerTime int64 `json:"serverTime"`
+}
+
+type exchangeInfoResponse struct {
+	ServerTime int64         `json:"serverTime"`
+	Symbols    []symbolEntry `json:"symbols"`
+}
+
+type symbolEntry struct {
+	Symbol            string         `json:"symbol"`
+	Pair              string         `json:"pair"`
+	ContractType      string         `json:"contractType"`
+	Status            string         `json:"status"`
+	BaseAsset         string         `json:"baseAsset"`
+	QuoteAsset        string         `json:"quoteAsset"`
+	MarginAsset       string         `json:"marginAsset"`
+	PricePrecision    int            `json:"pricePrecision"`
+	QuantityPrecision int            `json:"quantityPrecision"`
+	Filters           []symbolFilter `json:"filters"`
+}
+
+type symbolFilter struct {
+	FilterType string `json:"filterType"`
+	TickSize   string `json:"tickSize,omitempty"`
+	MinPrice   string `json:"minPrice,omitempty"`
+	MaxPrice   string `json:"maxPrice,omitempty"`
+	StepSize   string `json:"stepSize,omitempty"`
+	MinQty     string `json:"minQty,omitempty"`
+	MaxQty     string `json:"maxQty,omitempty"`
+	Notional   string `json:"notional,omitempty"`
+}
+
+type premiumIndexResponse struct {
+	Symbol          string `json:"symbol"`
+	MarkPrice       string `json:"markPrice"`
+	IndexPrice      string `json:"indexPrice"`
+	LastFundingRate string `json:"lastFundingRate"`
+	NextFundingTime int64  `json:"nextFundingTime"`
+	Time            int64  `json:"time"`
+}
+
+type orderResponse struct {
+	OrderID       int64  `json:"orderId"`
+	Symbol        string `json:"symbol"`
+	Status        string `json:"status"`
+	ClientOrderID string `json:"clientOrderId"`
+	Price         string `json:"price"`
+	AvgPrice      string `json:"avgPrice"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	CumQuote      string `json:"cumQuote"`
+	TimeInForce   string `json:"timeInForce"`
+	Type          string `json:"type"`
+	ReduceOnly    bool   `json:"reduceOnly"`
+	Side          string `json:"side"`
+	StopPrice     string `json:"stopPrice"`
+	Time          int64  `json:"time"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+type positionRiskResponse struct {
+	Symbol           string `json:"symbol"`
+	PositionAmt      string `json:"positionAmt"`
+	EntryPrice       string `json:"entryPrice"`
+	MarkPrice        string `json:"markPrice"`
+	UnRealizedProfit string `json:"unRealizedProfit"`
+	LiquidationPrice string `json:"liquidationPrice"`
+	Leverage         string `json:"leverage"`
+	MarginType       string `json:"marginType"`
+	IsolatedMargin   string `json:"isolatedMargin"`
+	Notional         string `json:"notional"`
+	PositionSide     string `json:"positionSide"`
+	UpdateTime       int64  `json:"updateTime"`
+}
+
+type accountResponse struct {
+	TotalWalletBalance          string `json:"totalWalletBalance"`
+	TotalUnrealizedProfit       string `json:"totalUnrealizedProfit"`
+	TotalMarginBalance          string `json:"totalMarginBalance"`
+	TotalInitialMargin          string `json:"totalInitialMargin"`
+	TotalPositionInitialMargin  string `json:"totalPositionInitialMargin"`
+	TotalOpenOrderInitialMargin string `json:"totalOpenOrderInitialMargin"`
+	TotalMaintMargin            string `json:"totalMaintMargin"`
+	TotalCrossWalletBalance     string `json:"totalCrossWalletBalance"`
+	AvailableBalance            string `json:"availableBalance"`
+	MaxWithdrawAmount           string `json:"maxWithdrawAmount"`
+}
@@ -0,0 +1,262 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+)
+
+// GetAssetIndex resolves a stable asset index for the given coin. Binance has
+// no numeric asset ids, so the index is the symbol's position within the
+// filtered exchangeInfo listing and is only meaningful for this client.
+func (c *Client) GetAssetIndex(ctx context.Context, coin string) (int, error) {
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return 0, err
+	}
+	return info.Index, nil
+}
+
+// GetSymbolInfo returns trading rules for a coin ("BTC") or exchange symbol ("BTCUSDT").
+func (c *Client) GetSymbolInfo(ctx context.Context, coin string) (*SymbolInfo, error) {
+	key := canonicalAssetKey(coin)
+	if key == "" {
+		return nil, fmt.Errorf("binance: empty coin symbol")
+	}
+	_ = c.maybeRefreshSymbols(ctx)
+	if info, ok := c.cachedSymbol(key); ok {
+		return &info, nil
+	}
+	if err := c.refreshSymbols(ctx); err != nil {
+		return nil, err
+	}
+	if info, ok := c.cachedSymbol(key); ok {
+		return &info, nil
+	}
+	return nil, fmt.Errorf("binance: symbol for %s not found", coin)
+}
+
+func (c *Client) symbolForAsset(ctx context.Context, asset int) (*SymbolInfo, error) {
+	if asset < 0 {
+		return nil, fmt.Errorf("binance: asset index must be non-negative")
+	}
+	_ = c.maybeRefreshSymbols(ctx)
+	c.symbolMu.RLock()
+	sym, ok := c.indexToSymbol[asset]
+	c.symbolMu.RUnlock()
+	if !ok {
+		if err := c.refreshSymbols(ctx); err != nil {
+			return nil, err
+		}
+		c.symbolMu.RLock()
+		sym, ok = c.indexToSymbol[asset]
+		c.symbolMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("binance: unknown asset index %d", asset)
+		}
+	}
+	return c.GetSymbolInfo(ctx, sym)
+}
+
+func (c *Client) cachedSymbol(key string) (SymbolInfo, bool) {
+	c.symbolMu.RLock()
+	defer c.symbolMu.RUnlock()
+	if info, ok := c.symbols[key]; ok {
+		return info, true
+	}
+	if sym, ok := c.baseToSymbol[key]; ok {
+		info, ok := c.symbols[sym]
+		return info, ok
+	}
+	return SymbolInfo{}, false
+}
+
+func (c *Client) refreshSymbols(ctx context.Context) error {
+	var resp exchangeInfoResponse
+	if err := c.doPublic(ctx, "/fapi/v1/exchangeInfo", nil, &resp); err != nil {
+		return err
+	}
+
+	symbols := make(map[string]SymbolInfo, len(resp.Symbols))
+	bases := make(map[string]string, len(resp.Symbols))
+	indexes := make(map[int]string, len(resp.Symbols))
+	// Indexes are positional in the first exchangeInfo seen; later refreshes
+	// keep existing assignments and append new symbols, so an index a caller
+	// cached keeps pointing at the same symbol when the listing is reordered.
+	c.symbolMu.Lock()
+	defer c.symbolMu.Unlock()
+	for _, entry := range resp.Symbols {
+		if !strings.EqualFold(entry.ContractType, "PERPETUAL") || !strings.EqualFold(entry.QuoteAsset, c.quoteAsset) {
+			continue
+		}
+		if entry.Status != "" && !strings.EqualFold(entry.Status, "TRADING") {
+			continue
+		}
+		key := canonicalAssetKey(entry.Symbol)
+		if key == "" {
+			continue
+		}
+		info := SymbolInfo{
+			Symbol:            key,
+			BaseAsset:         canonicalAssetKey(entry.BaseAsset),
+			QuoteAsset:        canonicalAssetKey(entry.QuoteAsset),
+			Index:             c.assetIndexLocked(key),
+			PricePrecision:    entry.PricePrecision,
+			QuantityPrecision: entry.QuantityPrecision,
+		}
+		for _, f := range entry.Filters {
+			switch f.FilterType {
+			case "PRICE_FILTER":
+				info.TickSize = f.TickSize
+			case "LOT_SIZE":
+				info.StepSize = f.StepSize
+				info.MinQty = f.MinQty
+			case "MIN_NOTIONAL":
+				info.MinNotional = f.Notional
+			}
+		}
+		symbols[key] = info
+		indexes[info.Index] = key
+		if info.BaseAsset != "" {
+			bases[info.BaseAsset] = key
+		}
+	}
+	if len(symbols) == 0 {
+		return fmt.Errorf("binance: exchangeInfo contained no %s perpetual symbols", c.quoteAsset)
+	}
+
+	c.symbols = symbols
+	c.baseToSymbol = bases
+	c.indexToSymbol = indexes
+	c.symbolLastRef = c.clock()
+	return nil
+}
+
+// assetIndexLocked returns the index assigned to symbol, assigning the next
+// free one on first sight. Callers hold symbolMu.
+func (c *Client) assetIndexLocked(symbol string) int {
+	if idx, ok := c.assignedIndex[symbol]; ok {
+		return idx
+	}
+	if c.assignedIndex == nil {
+		c.assignedIndex = make(map[string]int)
+	}
+	idx := len(c.assignedIndex)
+	c.assignedIndex[symbol] = idx
+	return idx
+}
+
+// maybeRefreshSymbols refreshes the symbol directory if the TTL has expired.
+// Errors are swallowed; the normal lookup path performs a hard refresh.
+func (c *Client) maybeRefreshSymbols(ctx context.Context) error {
+	if c.symbolTTL <= 0 {
+		return nil
+	}
+	c.symbolMu.RLock()
+	last := c.symbolLastRef
+	c.symbolMu.RUnlock()
+	if last.IsZero() || c.clock().Sub(last) > c.symbolTTL {
+		return c.refreshSymbols(ctx)
+	}
+	return nil
+}
+
+func canonicalAssetKey(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// FormatPrice rounds a price to the symbol's tick size.
+func (c *Client) FormatPrice(ctx context.Context, coin string, price float64) (string, error) {
+	if price <= 0 || !isFinite(price) {
+		return "", fmt.Errorf("binance: invalid price")
+	}
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return "", err
+	}
+	return roundToStep(price, info.TickSize, info.PricePrecision, false), nil
+}
+
+// FormatSize rounds a quantity down to the symbol's lot step so the order
+// never exceeds the caller's intended notional.
+func (c *Client) FormatSize(ctx context.Context, coin string, qty float64) (string, error) {
+	if qty < 0 {
+		qty = -qty
+	}
+	if qty == 0 || !isFinite(qty) {
+		return "", fmt.Errorf("binance: invalid size")
+	}
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return "", err
+	}
+	size := roundToStep(qty, info.StepSize, info.QuantityPrecision, true)
+	if min, err := exchange.ParseDecimal(info.MinQty); err == nil && min.Sign() > 0 && exchange.MustParseDecimal(size).Cmp(min) < 0 {
+		return "", fmt.Errorf("binance: size %s below minimum %s for %s", size, info.MinQty, info.Symbol)
+	}
+	return size, nil
+}
+
+// roundToStep snaps value to a multiple of step, half up or, with floor,
+// towards zero. Snapping is exact, so values already on the grid stay put.
+// When step is missing it falls back to the advertised decimal precision.
+// SizeStep returns the symbol's lot step, or 10^-quantityPrecision when the
+// exchange info carries no LOT_SIZE filter.
+func (c *Client) SizeStep(ctx context.Context, coin string) (exchange.Decimal, error) {
+	info, err := c.GetSymbolInfo(ctx, coin)
+	if err != nil {
+		return exchange.Decimal{}, err
+	}
+	if step, err := exchange.ParseDecimal(info.StepSize); err == nil && step.Sign() > 0 {
+		return step, nil
+	}
+	return exchange.NewDecimal(1, -int32(max(info.QuantityPrecision, 0))), nil
+}
+
+func roundToStep(value float64, step string, precision int, floor bool) string {
+	v := exchange.DecimalFromFloat(value)
+	mode := exchange.RoundHalfUp
+	if floor {
+		mode = exchange.RoundDown
+	}
+	if s, err := exchange.ParseDecimal(step); err == nil && s.Sign() > 0 {
+		return v.RoundStep(s, mode).String()
+	}
+	if precision < 0 {
+		precision = 0
+	}
+	return v.Round(int32(precision), mode).String()
+}
+
+func trimTrailingZeros(value string) string {
+	if !strings.Contains(value, ".") {
+		return value
+	}
+	value = strings.TrimRight(value, "0")
+	value = strings.TrimRight(value, ".")
+	if value == "" || value == "-" {
+		return "0"
+	}
+	return value
+}
+
+func formatDecimal(v float64) string {
+	if !isFinite(v) || math.Abs(v) < 1e-12 {
+		return "0"
+	}
+	return trimTrailingZeros(strconv.FormatFloat(v, 'f', 8, 64))
+}
+
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func isFinite(f float64) bool { return !math.IsNaN(f) && !math.IsInf(f, 0) }
@@ -0,0 +1,254 @@
+package exchange
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Optional provider extensions. Providers implement whichever of these the
+// venue supports; callers discover them with a type assertion or through
+// CapabilitiesOf instead of declaring anonymous interfaces inline.
+
+// MarketOrderer submits marketable IOC orders bounded by a slippage fraction
+// around the current mark price (e.g. 0.01 = 1%).
+type MarketOrderer interface {
+	IOCMarket(ctx context.Context, coin string, isBuy bool, qty float64, slippage float64, reduceOnly bool) (*OrderResponse, error)
+}
+
+// ProtectiveOrderer places reduce-only stop-loss and take-profit orders for an
+// open position. positionSide is "LONG" or "SHORT".
+type ProtectiveOrderer interface {
+	SetStopLoss(ctx context.Context, coin string, positionSide string, qty float64, stopPrice float64) error
+	SetTakeProfit(ctx context.Context, coin string, positionSide string, qty float64, takeProfit float64) error
+}
+
+// Formatter rounds prices and sizes to the venue's tick and lot constraints.
+// SizeStep returns the coin's lot step, so a size computed as a Decimal can
+// be rounded with Decimal.RoundStep and sent as its exact string.
+type Formatter interface {
+	FormatPrice(ctx context.Context, coin string, price float64) (string, error)
+	FormatSize(ctx context.Context, coin string, qty float64) (string, error)
+	SizeStep(ctx context.Context, coin string) (Decimal, error)
+}
+
+// SymbolCanceller cancels every resting order for a single coin.
+type SymbolCanceller interface {
+	CancelAllBySymbol(ctx context.Context, coin string) error
+}
+
+// MarkPriceSetter accepts externally sourced mark prices (simulators and
+// replay providers that have no market feed of their own).
+type MarkPriceSetter interface {
+	SetMarkPrice(ctx context.Context, coin string, price float64) error
+}
+
+// FundingRateSetter accepts externally sourced perpetual funding rates, the
+// per-interval fraction longs pay shorts (negative when shorts pay).
+type FundingRateSetter interface {
+	SetFundingRate(ctx context.Context, coin string, rate float64) error
+}
+
+// FillHistory returns the account's executed fills at or after since, oldest
+// first, with the venue-reported fee and closed PnL for each fill.
+type FillHistory interface {
+	GetFills(ctx context.Context, since time.Time) ([]Fill, error)
+}
+
+// FundingHistory returns perpetual funding payments applied to the account at
+// or after since, oldest first.
+type FundingHistory interface {
+	GetFundingPayments(ctx context.Context, since time.Time) ([]FundingPayment, error)
+}
+
+// TWAPOrderer executes orders as venue-side TWAPs. PlaceTWAP returns the
+// status of the newly started TWAP; GetTWAPStatus polls it until it is no
+// longer active.
+type TWAPOrderer interface {
+	PlaceTWAP(ctx context.Context, order TWAPOrder) (*TWAPStatus, error)
+	CancelTWAP(ctx context.Context, coin string, twapID int64) error
+	GetTWAPStatus(ctx context.Context, twapID int64) (*TWAPStatus, error)
+}
+
+// CancelScheduler arms a venue-side dead-man's switch: every resting order is
+// cancelled at the given time unless the schedule is pushed back first. A zero
+// time clears the schedule.
+type CancelScheduler interface {
+	ScheduleCancel(ctx context.Context, at time.Time) error
+}
+
+// IsolatedMarginUpdater moves margin into (positive amountUSD) or out of
+// (negative) the isolated-margin position on asset. isBuy selects the long
+// side, matching the position's direction.
+type IsolatedMarginUpdater interface {
+	UpdateIsolatedMargin(ctx context.Context, asset int, isBuy bool, amountUSD float64) error
+}
+
+// SubAccountProvider opens isolated accounts under one provider, keyed by an
+// owner ID such as a trader ID. Calling it again with the same id returns the
+// existing account; initialEquity only applies when the account is created.
+type SubAccountProvider interface {
+	SubAccount(ctx context.Context, id string, initialEquity float64) (Provider, error)
+}
+
+// FundTransferer moves USD collateral between the master account and one of
+// its sub-accounts opened through SubAccountProvider. A positive amountUSD
+// funds the sub-account; a negative amount returns funds to the master. The
+// sending account must hold the amount free of margin.
+type FundTransferer interface {
+	TransferToSubAccount(ctx context.Context, id string, amountUSD float64) error
+}
+
+// CredentialExpirer is implemented by providers whose signing credential
+// lapses, such as a Hyperliquid agent wallet approved with an expiry. It is
+// operational metadata rather than a trading capability, so it is not
+// reported by Capabilities.
+type CredentialExpirer interface {
+	// CredentialExpiry returns when the venue stops accepting the signing
+	// credential; the zero time means it does not expire.
+	CredentialExpiry(ctx context.Context) (time.Time, error)
+}
+
+// RateLimitSyncer is implemented by providers that budget requests against an
+// allowance the venue reports, such as Hyperliquid's per-address request cap,
+// which grows with traded volume. Like CredentialExpirer it is not reported
+// by Capabilities.
+type RateLimitSyncer interface {
+	// SyncRateLimit aligns the local request budget with the venue's count.
+	SyncRateLimit(ctx context.Context) error
+}
+
+// Capability names reported by Capabilities.List.
+const (
+	CapabilityMarketOrders     = "market_orders"
+	CapabilityProtectiveOrders = "protective_orders"
+	CapabilityFormatting       = "formatting"
+	CapabilityCancelAll        = "cancel_all_by_symbol"
+	CapabilityMarkPrice        = "mark_price"
+	CapabilityFundingRate      = "funding_rate"
+	CapabilityEventStream      = "event_stream"
+	CapabilityFillHistory      = "fill_history"
+	CapabilityFundingHistory   = "funding_history"
+	CapabilitySubAccounts      = "sub_accounts"
+	CapabilityTWAPOrders       = "twap_orders"
+	CapabilityScheduleCancel   = "schedule_cancel"
+	CapabilityIsolatedMargin   = "isolated_margin"
+	CapabilityFundTransfers    = "fund_transfers"
+)
+
+// Capabilities summarises which optional extensions a provider supports.
+type Capabilities struct {
+	MarketOrders     bool `json:"market_orders"`
+	ProtectiveOrders bool `json:"protective_orders"`
+	Formatting       bool `json:"formatting"`
+	CancelAll        bool `json:"cancel_all_by_symbol"`
+	MarkPrice        bool `json:"mark_price"`
+	FundingRate      bool `json:"funding_rate"`
+	EventStream      bool `json:"event_stream"`
+	FillHistory      bool `json:"fill_history"`
+	FundingHistory   bool `json:"funding_history"`
+	SubAccounts      bool `json:"sub_accounts"`
+	TWAPOrders       bool `json:"twap_orders"`
+	ScheduleCancel   bool `json:"schedule_cancel"`
+	IsolatedMargin   bool `json:"isolated_margin"`
+	FundTransfers    bool `json:"fund_transfers"`
+}
+
+// CapabilityReporter is implemented by providers that report their own
+// capabilities, e.g. decorators forwarding to a wrapped provider.
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// DetectCapabilities inspects the concrete provider type for optional
+// extensions. It ignores CapabilityReporter so providers can build their own
+// report on top of it.
+func DetectCapabilities(p Provider) Capabilities {
+	var caps Capabilities
+	if p == nil {
+		return caps
+	}
+	_, caps.MarketOrders = p.(MarketOrderer)
+	_, caps.ProtectiveOrders = p.(ProtectiveOrderer)
+	_, caps.Formatting = p.(Formatter)
+	_, caps.CancelAll = p.(SymbolCanceller)
+	_, caps.MarkPrice = p.(MarkPriceSetter)
+	_, caps.FundingRate = p.(FundingRateSetter)
+	_, caps.EventStream = p.(EventStream)
+	_, caps.FillHistory = p.(FillHistory)
+	_, caps.FundingHistory = p.(FundingHistory)
+	_, caps.SubAccounts = p.(SubAccountProvider)
+	_, caps.TWAPOrders = p.(TWAPOrderer)
+	_, caps.ScheduleCancel = p.(CancelScheduler)
+	_, caps.IsolatedMargin = p.(IsolatedMarginUpdater)
+	_, caps.FundTransfers = p.(FundTransferer)
+	return caps
+}
+
+// CapabilitiesOf returns the provider's self-reported capabilities when
+// available and falls back to DetectCapabilities otherwise.
+func CapabilitiesOf(p Provider) Capabilities {
+	if reporter, ok := p.(CapabilityReporter); ok {
+		return reporter.Capabilities()
+	}
+	return DetectCapabilities(p)
+}
+
+// List returns the names of supported capabilities in sorted order.
+func (c Capabilities) List() []string {
+	names := make([]string, 0, 14)
+	if c.MarketOrders {
+		names = append(names, CapabilityMarketOrders)
+	}
+	if c.ProtectiveOrders {
+		names = append(names, CapabilityProtectiveOrders)
+	}
+	if c.Formatting {
+		names = append(names, CapabilityFormatting)
+	}
+	if c.CancelAll {
+		names = append(names, CapabilityCancelAll)
+	}
+	if c.MarkPrice {
+		names = append(names, CapabilityMarkPrice)
+	}
+	if c.FundingRate {
+		names = append(names, CapabilityFundingRate)
+	}
+	if c.EventStream {
+		names = append(names, CapabilityEventStream)
+	}
+	if c.FillHistory {
+		names = append(names, CapabilityFillHistory)
+	}
+	if c.FundingHistory {
+		names = append(names, CapabilityFundingHistory)
+	}
+	if c.SubAccounts {
+		names = append(names, CapabilitySubAccounts)
+	}
+	if c.TWAPOrders {
+		names = append(names, CapabilityTWAPOrders)
+	}
+	if c.ScheduleCancel {
+		names = append(names, CapabilityScheduleCancel)
+	}
+	if c.IsolatedMargin {
+		names = append(names, CapabilityIsolatedMargin)
+	}
+	if c.FundTransfers {
+		names = append(names, CapabilityFundTransfers)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String renders the supported capabilities as a comma-separated list.
+func (c Capabilities) String() string {
+	names := c.List()
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,41 @@
+package exchange_test
+
+import (
+	"testing"
+
+	exchange "nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bareProvider implements only the required Provider methods.
+type bareProvider struct{ exchange.Provider }
+
+type reportingProvider struct {
+	exchange.Provider
+	caps exchange.Capabilities
+}
+
+func (r reportingProvider) Capabilities() exchange.Capabilities { return r.caps }
+
+func TestDetectCapabilities(t *testing.T) {
+	caps := exchange.DetectCapabilities(sim.New())
+	assert.True(t, caps.MarketOrders, "sim supports IOC market orders")
+	assert.True(t, caps.Formatting, "sim supports formatting")
+	assert.True(t, caps.CancelAll, "sim supports cancel all")
+	assert.True(t, caps.SubAccounts, "sim supports sub-accounts")
+
+	bare := exchange.CapabilitiesOf(bareProvider{})
+	assert.Equal(t, exchange.Capabilities{}, bare, "bare provider has no extensions")
+	assert.Equal(t, "none", bare.String())
+	assert.Empty(t, exchange.DetectCapabilities(nil).List())
+}
+
+func TestCapabilitiesOfPrefersReporter(t *testing.T) {
+	reported := exchange.Capabilities{MarketOrders: true, ProtectiveOrders: true}
+	caps := exchange.CapabilitiesOf(reportingProvider{caps: reported})
+	assert.Equal(t, reported, caps)
+	assert.Equal(t, []string{exchange.CapabilityMarketOrders, exchange.CapabilityProtectiveOrders}, caps.List())
+	assert.Equal(t, "market_orders,protective_orders", caps.String())
+}
@@ -29,16 +29,112 @@ type ProviderConfig struct {
 	VaultAddress string `yaml:"vault_address"`
 	MainAddress  string `yaml:"main_address"` // Main account address (for API wallet scenarios)
 	Testnet      bool   `yaml:"testnet"`
+	// Signer delegates signing to a signing daemon instead of private_key
+	// (hyperliquid; see hyperliquid.RemoteSigner and cmd/signer).
+	Signer *SignerConfig `yaml:"signer"`
+	// AgentKeyFile signs with the agent (API) wallet key stored in this file
+	// instead of private_key (hyperliquid; requires main_address). The
+	// provider picks up a replaced key without restarting, so cmd/agent can
+	// rotate it.
+	AgentKeyFile string `yaml:"agent_key_file"`
+	// NonceFile persists the signer's nonce high-water mark (hyperliquid);
+	// processes on one host that share a key should share the file.
+	NonceFile string `yaml:"nonce_file"`
+	// SubAccounts lets traders trade their own venue sub-account
+	// (hyperliquid): it maps trader ids to sub-account names or addresses.
+	// Traders without an entry use the sub-account named after their id, so
+	// an empty map ({}) enables lookup by id alone.
+	SubAccounts map[string]string `yaml:"sub_accounts"`
 
 	TimeoutRaw string        `yaml:"timeout"`
 	Timeout    time.Duration `yaml:"-"`
+	// Cassette records the provider's HTTP traffic to, or replays it from,
+	// a go-vcr cassette (hyperliquid and binance; see pkg/exchange/vcr).
+	Cassette *CassetteConfig `yaml:"cassette"`
+
+	// Wraps names the provider a decorator type (see RegisterDecorator)
+	// forwards to; other provider types reject it.
+	Wraps string `yaml:"wraps"`
+
+	// Sim tunes the in-memory simulator; used by the "sim" and "shadow"
+	// provider types.
+	Sim *SimConfig `yaml:"sim"`
+	// Shadow configures the "shadow" decorator.
+	Shadow *ShadowConfig `yaml:"shadow"`
+	// Risk wraps the built provider, of any type, in a RiskGateway
+	// enforcing these order-level limits.
+	Risk *RiskLimits `yaml:"risk"`
+}
+
+// CassetteConfig selects a cassette file and whether to record live traffic
+// into it ("record") or serve it back without network access ("replay").
+type CassetteConfig struct {
+	Path string `yaml:"path"`
+	Mode string `yaml:"mode"`
+}
+
+// SignerConfig points a provider at a remote signing daemon. Endpoint is
+// "unix:///path/to.sock" or an http(s) URL; Token, when set, is sent as a
+// bearer token.
+type SignerConfig struct {
+	Endpoint string `yaml:"endpoint"`
+	Token    string `yaml:"token"`
+}
+
+// ShadowConfig holds settings for the "shadow" decorator, which forwards to
+// the wrapped provider and mirrors every order into a simulator configured by
+// the provider's sim block.
+type ShadowConfig struct {
+	// DryRun sends orders to the simulator only; the wrapped provider, if
+	// any, is never called.
+	DryRun bool `yaml:"dry_run"`
+	// MaxRecords bounds the per-order comparisons kept in memory
+	// (default 1000).
+	MaxRecords int `yaml:"max_records"`
+}
+
+// SimConfig holds paper-trading settings for the "sim" provider type.
+type SimConfig struct {
+	InitialEquity float64 `yaml:"initial_equity"`
+	// MakerFeeBps and TakerFeeBps are charged on fill notional, in basis points.
+	MakerFeeBps float64 `yaml:"maker_fee_bps"`
+	TakerFeeBps float64 `yaml:"taker_fee_bps"`
+	// FundingInterval is how often funding accrues on open positions at the
+	// latest funding rate (Hyperliquid settles hourly).
+	FundingIntervalRaw string        `yaml:"funding_interval"`
+	FundingInterval    time.Duration `yaml:"-"`
+	// MarginTiers maps a symbol (or "default" for all others) to its
+	// leverage tiers; maintenance margin is half the initial margin at each
+	// tier's max leverage.
+	MarginTiers map[string][]SimMarginTier `yaml:"margin_tiers"`
+	// State persists the simulator account across restarts.
+	State *SimStateConfig `yaml:"state"`
+}
+
+// SimStateConfig selects where sim state is saved. The "file" backend is
+// handled by the provider itself; "redis" is attached by the application,
+// which owns the cache connection.
+type SimStateConfig struct {
+	Backend string `yaml:"backend"` // "file" or "redis"
+	Path    string `yaml:"path"`    // file backend only
+}
+
+// SimMarginTier caps leverage for sim positions of at least MinNotional USD.
+type SimMarginTier struct {
+	MinNotional float64 `yaml:"min_notional"`
+	MaxLeverage int     `yaml:"max_leverage"`
 }
 
 // ProviderBuilder constructs a Provider from configuration.
 type ProviderBuilder func(name string, cfg *ProviderConfig) (Provider, error)
 
+// DecoratorBuilder constructs a Provider that wraps inner, the provider named
+// by the config's Wraps; inner is nil when Wraps is empty.
+type DecoratorBuilder func(name string, cfg *ProviderConfig, inner Provider) (Provider, error)
+
 var (
 	providerRegistry   = make(map[string]ProviderBuilder)
+	decoratorRegistry  = make(map[string]DecoratorBuilder)
 	providerRegistryMu sync.RWMutex
 )
 
@@ -49,13 +145,35 @@ func RegisterProvider(typeName string, builder ProviderBuilder) {
 	providerRegistry[strings.ToLower(strings.TrimSpace(typeName))] = builder
 }
 
+// RegisterDecorator associates a builder with a provider type that wraps
+// another configured provider.
+func RegisterDecorator(typeName string, builder DecoratorBuilder) {
+	providerRegistryMu.Lock()
+	defer providerRegistryMu.Unlock()
+	decoratorRegistry[strings.ToLower(strings.TrimSpace(typeName))] = builder
+}
+
+// lookupProviderBuilder resolves a provider type; decorator types build with
+// a nil inner provider.
 func lookupProviderBuilder(typeName string) (ProviderBuilder, bool) {
+	if decorator, ok := lookupDecoratorBuilder(typeName); ok {
+		return func(name string, cfg *ProviderConfig) (Provider, error) {
+			return decorator(name, cfg, nil)
+		}, true
+	}
 	providerRegistryMu.RLock()
 	defer providerRegistryMu.RUnlock()
 	builder, ok := providerRegistry[strings.ToLower(strings.TrimSpace(typeName))]
 	return builder, ok
 }
 
+func lookupDecoratorBuilder(typeName string) (DecoratorBuilder, bool) {
+	providerRegistryMu.RLock()
+	defer providerRegistryMu.RUnlock()
+	builder, ok := decoratorRegistry[strings.ToLower(strings.TrimSpace(typeName))]
+	return builder, ok
+}
+
 // GetProvider constructs a single provider instance for the given type using
 // the provided configuration. This is a convenience for tests and callers that
 // want to instantiate a provider without building a full config map.
@@ -73,7 +191,11 @@ func GetProvider(typeName string, cfg *ProviderConfig) (Provider, error) {
 	if !ok {
 		return nil, fmt.Errorf("exchange provider: unsupported type %q", cfgCopy.Type)
 	}
-	return builder("inline", &cfgCopy)
+	provider, err := builder("inline", &cfgCopy)
+	if err != nil || cfgCopy.Risk == nil || !cfgCopy.Risk.Enabled() {
+		return provider, err
+	}
+	return NewRiskGateway(provider, *cfgCopy.Risk), nil
 }
 
 // LoadConfig reads configuration from disk.
@@ -143,10 +265,41 @@ func (p *ProviderConfig) expandEnv() {
 	p.Passphrase = strings.TrimSpace(os.ExpandEnv(p.Passphrase))
 	p.VaultAddress = strings.TrimSpace(os.ExpandEnv(p.VaultAddress))
 	p.MainAddress = strings.TrimSpace(os.ExpandEnv(p.MainAddress))
+	p.NonceFile = strings.TrimSpace(os.ExpandEnv(p.NonceFile))
+	p.AgentKeyFile = strings.TrimSpace(os.ExpandEnv(p.AgentKeyFile))
+	if s := p.Signer; s != nil {
+		s.Endpoint = strings.TrimSpace(os.ExpandEnv(s.Endpoint))
+		s.Token = strings.TrimSpace(os.ExpandEnv(s.Token))
+	}
+	for id, target := range p.SubAccounts {
+		p.SubAccounts[id] = strings.TrimSpace(os.ExpandEnv(target))
+	}
 	p.TimeoutRaw = strings.TrimSpace(os.ExpandEnv(p.TimeoutRaw))
+	p.Wraps = strings.TrimSpace(os.ExpandEnv(p.Wraps))
+	if c := p.Cassette; c != nil {
+		c.Path = strings.TrimSpace(os.ExpandEnv(c.Path))
+		c.Mode = strings.ToLower(strings.TrimSpace(os.ExpandEnv(c.Mode)))
+	}
+	if p.Risk != nil {
+		for i, coin := range p.Risk.AllowedSymbols {
+			p.Risk.AllowedSymbols[i] = strings.ToUpper(strings.TrimSpace(os.ExpandEnv(coin)))
+		}
+	}
+	if p.Sim != nil {
+		p.Sim.FundingIntervalRaw = strings.TrimSpace(os.ExpandEnv(p.Sim.FundingIntervalRaw))
+		if st := p.Sim.State; st != nil {
+			st.Backend = strings.ToLower(strings.TrimSpace(os.ExpandEnv(st.Backend)))
+			st.Path = strings.TrimSpace(os.ExpandEnv(st.Path))
+		}
+	}
 }
 
 func (p *ProviderConfig) parseDurations(name string) error {
+	if p.Sim != nil {
+		if err := p.Sim.parseDurations(name); err != nil {
+			return err
+		}
+	}
 	if p.TimeoutRaw == "" {
 		p.Timeout = 0
 		return nil
@@ -162,6 +315,22 @@ func (p *ProviderConfig) parseDurations(name string) error {
 	return nil
 }
 
+func (s *SimConfig) parseDurations(name string) error {
+	if s.FundingIntervalRaw == "" {
+		s.FundingInterval = 0
+		return nil
+	}
+	d, err := time.ParseDuration(s.FundingIntervalRaw)
+	if err != nil {
+		return fmt.Errorf("exchange provider %s: invalid sim.funding_interval %q: %w", name, s.FundingIntervalRaw, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("exchange provider %s: sim.funding_interval must be positive, got %s", name, d)
+	}
+	s.FundingInterval = d
+	return nil
+}
+
 // Validate ensures all providers have sane configuration.
 func (c *Config) Validate() error {
 	if len(c.Providers) == 0 {
@@ -180,6 +349,25 @@ func (c *Config) Validate() error {
 		if err := provider.validate(name); err != nil {
 			return err
 		}
+		if provider.Wraps == "" {
+			continue
+		}
+		if _, ok := c.Providers[provider.Wraps]; !ok {
+			return fmt.Errorf("exchange config: provider %s wraps undefined provider %q", name, provider.Wraps)
+		}
+		// Follow the chain to catch decorators wrapping each other.
+		seen := map[string]bool{name: true}
+		for next := provider.Wraps; next != ""; {
+			if seen[next] {
+				return fmt.Errorf("exchange config: provider %s has a wraps cycle through %q", name, next)
+			}
+			seen[next] = true
+			wrapped := c.Providers[next]
+			if wrapped == nil {
+				break
+			}
+			next = wrapped.Wraps
+		}
 	}
 	return nil
 }
@@ -195,26 +383,142 @@ func (p *ProviderConfig) validate(name string) error {
 	if _, ok := lookupProviderBuilder(p.Type); !ok {
 		return fmt.Errorf("exchange config: provider %s has unsupported type %q", name, p.Type)
 	}
+	if _, ok := lookupDecoratorBuilder(p.Type); !ok && p.Wraps != "" {
+		return fmt.Errorf("exchange config: provider %s of type %q cannot wrap another provider", name, p.Type)
+	}
+	if p.Risk != nil {
+		if err := p.Risk.validate(name); err != nil {
+			return err
+		}
+	}
+	if c := p.Cassette; c != nil {
+		if c.Path == "" {
+			return fmt.Errorf("exchange config: provider %s cassette.path is required", name)
+		}
+		if c.Mode != "record" && c.Mode != "replay" {
+			return fmt.Errorf("exchange config: provider %s has unsupported cassette.mode %q (want record or replay)", name, c.Mode)
+		}
+	}
 
-	if strings.ToLower(p.Type) == "hyperliquid" && p.PrivateKey == "" {
-		return fmt.Errorf("exchange config: provider %s requires private_key", name)
+	switch strings.ToLower(p.Type) {
+	case "hyperliquid":
+		if p.AgentKeyFile != "" {
+			if p.PrivateKey != "" || p.Signer != nil {
+				return fmt.Errorf("exchange config: provider %s sets agent_key_file with private_key or signer; use one", name)
+			}
+			if p.MainAddress == "" {
+				return fmt.Errorf("exchange config: provider %s agent_key_file requires main_address", name)
+			}
+			break
+		}
+		if s := p.Signer; s != nil {
+			if p.PrivateKey != "" {
+				return fmt.Errorf("exchange config: provider %s sets both private_key and signer; the key belongs to the signing daemon", name)
+			}
+			if !strings.HasPrefix(s.Endpoint, "unix://") && !strings.HasPrefix(s.Endpoint, "http://") && !strings.HasPrefix(s.Endpoint, "https://") {
+				return fmt.Errorf("exchange config: provider %s signer.endpoint %q must be a unix://, http:// or https:// URL", name, s.Endpoint)
+			}
+			break
+		}
+		if p.PrivateKey == "" {
+			return fmt.Errorf("exchange config: provider %s requires private_key, signer or agent_key_file", name)
+		}
+	case "binance":
+		if p.APIKey == "" || p.APISecret == "" {
+			return fmt.Errorf("exchange config: provider %s requires api_key and api_secret", name)
+		}
+	case "shadow":
+		if p.Wraps == "" && (p.Shadow == nil || !p.Shadow.DryRun) {
+			return fmt.Errorf("exchange config: provider %s requires wraps unless shadow.dry_run is set", name)
+		}
+		if p.Shadow != nil && p.Shadow.MaxRecords < 0 {
+			return fmt.Errorf("exchange config: provider %s shadow.max_records must be non-negative", name)
+		}
+		return p.validateSim(name)
+	case "sim":
+		return p.validateSim(name)
+	}
+	return nil
+}
+
+func (p *ProviderConfig) validateSim(name string) error {
+	if s := p.Sim; s != nil {
+		if s.InitialEquity < 0 {
+			return fmt.Errorf("exchange config: provider %s sim.initial_equity must be non-negative", name)
+		}
+		if s.MakerFeeBps < 0 || s.TakerFeeBps < 0 {
+			return fmt.Errorf("exchange config: provider %s sim fee bps must be non-negative", name)
+		}
+		if st := s.State; st != nil {
+			switch st.Backend {
+			case "file":
+				if st.Path == "" {
+					return fmt.Errorf("exchange config: provider %s sim.state.path is required for the file backend", name)
+				}
+			case "redis":
+			default:
+				return fmt.Errorf("exchange config: provider %s has unsupported sim.state.backend %q", name, st.Backend)
+			}
+		}
+		for coin, tiers := range s.MarginTiers {
+			for _, tier := range tiers {
+				if tier.MaxLeverage <= 0 || tier.MinNotional < 0 {
+					return fmt.Errorf("exchange config: provider %s sim.margin_tiers.%s needs max_leverage > 0 and min_notional >= 0", name, coin)
+				}
+			}
+		}
 	}
 	return nil
 }
 
-// BuildProviders instantiates exchange providers according to the configuration.
+// BuildProviders instantiates exchange providers according to the
+// configuration. Decorators are built after the provider they wrap and
+// receive that instance. Providers with a risk block are returned behind a
+// RiskGateway, which decorators wrapping them also go through.
 func (c *Config) BuildProviders() (map[string]Provider, error) {
 	result := make(map[string]Provider, len(c.Providers))
-	for name, providerCfg := range c.Providers {
-		builder, ok := lookupProviderBuilder(providerCfg.Type)
-		if !ok {
-			return nil, fmt.Errorf("exchange provider %s: unsupported type %q", name, providerCfg.Type)
+	var build func(name string, depth int) (Provider, error)
+	build = func(name string, depth int) (Provider, error) {
+		if provider, ok := result[name]; ok {
+			return provider, nil
+		}
+		providerCfg, ok := c.Providers[name]
+		if !ok || providerCfg == nil {
+			return nil, fmt.Errorf("exchange provider %s: not defined", name)
+		}
+		if depth > len(c.Providers) {
+			return nil, fmt.Errorf("exchange provider %s: wraps cycle", name)
+		}
+		var provider Provider
+		var err error
+		if decorator, ok := lookupDecoratorBuilder(providerCfg.Type); ok {
+			var inner Provider
+			if providerCfg.Wraps != "" {
+				if inner, err = build(providerCfg.Wraps, depth+1); err != nil {
+					return nil, err
+				}
+			}
+			provider, err = decorator(name, providerCfg, inner)
+		} else {
+			builder, ok := lookupProviderBuilder(providerCfg.Type)
+			if !ok {
+				return nil, fmt.Errorf("exchange provider %s: unsupported type %q", name, providerCfg.Type)
+			}
+			provider, err = builder(name, providerCfg)
 		}
-		provider, err := builder(name, providerCfg)
 		if err != nil {
 			return nil, fmt.Errorf("exchange provider %s: %w", name, err)
 		}
+		if providerCfg.Risk != nil && providerCfg.Risk.Enabled() {
+			provider = NewRiskGateway(provider, *providerCfg.Risk)
+		}
 		result[name] = provider
+		return provider, nil
+	}
+	for name := range c.Providers {
+		if _, err := build(name, 0); err != nil {
+			return nil, err
+		}
 	}
 	return result, nil
 }
@@ -1,12 +1,19 @@
 package exchange_test
 
 import (
+	"context"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	exchange "nof0-api/pkg/exchange"
-	_ "nof0-api/pkg/exchange/hyperliquid"
+	_ "nof0-api/pkg/exchange/binance"
+	"nof0-api/pkg/exchange/hyperliquid"
+	"nof0-api/pkg/exchange/shadow"
+	_ "nof0-api/pkg/exchange/sim"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -60,3 +67,328 @@ providers:
 	assert.Error(t, err, "LoadConfig should error for missing private_key")
 	assert.Contains(t, err.Error(), "private_key", "error should mention private_key")
 }
+
+func TestLoadConfigRequiresBinanceCredentials(t *testing.T) {
+	dir := t.TempDir()
+	configYAML := `
+providers:
+  binance_testnet:
+    type: binance
+    api_key: key-only
+    testnet: true
+`
+	path := filepath.Join(dir, "exchange.yaml")
+	err := os.WriteFile(path, []byte(configYAML), 0o600)
+	assert.NoError(t, err, "write config should succeed")
+
+	_, err = exchange.LoadConfig(path)
+	assert.Error(t, err, "LoadConfig should error for missing api_secret")
+	assert.Contains(t, err.Error(), "api_secret", "error should mention api_secret")
+}
+
+func TestLoadConfigSimSettings(t *testing.T) {
+	configYAML := `
+providers:
+  paper_trading:
+    type: sim
+    sim:
+      initial_equity: 5000
+      maker_fee_bps: 1.5
+      taker_fee_bps: 4.5
+      funding_interval: 8h
+      margin_tiers:
+        default:
+          - max_leverage: 20
+        BTC:
+          - max_leverage: 40
+          - min_notional: 1000000
+            max_leverage: 20
+`
+	cfg, err := exchange.LoadConfigFromReader(strings.NewReader(configYAML))
+	assert.NoError(t, err, "LoadConfigFromReader should not error")
+	sim := cfg.Providers["paper_trading"].Sim
+	if assert.NotNil(t, sim, "sim block should be parsed") {
+		assert.Equal(t, 5000.0, sim.InitialEquity)
+		assert.Equal(t, 4.5, sim.TakerFeeBps)
+		assert.Equal(t, 8*time.Hour, sim.FundingInterval)
+		assert.Len(t, sim.MarginTiers["BTC"], 2)
+	}
+
+	providers, err := cfg.BuildProviders()
+	assert.NoError(t, err, "BuildProviders should not error")
+	value, err := providers["paper_trading"].GetAccountValue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 5000.0, value, "initial equity should be applied")
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  paper_trading:
+    type: sim
+    sim:
+      taker_fee_bps: -1
+`))
+	assert.Error(t, err, "negative fees should be rejected")
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  paper_trading:
+    type: sim
+    sim:
+      margin_tiers:
+        ETH:
+          - min_notional: 0
+`))
+	assert.Error(t, err, "margin tiers need a max leverage")
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  paper_trading:
+    type: sim
+    sim:
+      state:
+        backend: postgres
+`))
+	assert.Error(t, err, "unknown state backends should be rejected")
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  paper_trading:
+    type: sim
+    sim:
+      state:
+        backend: file
+`))
+	assert.Error(t, err, "the file backend needs a path")
+}
+
+func TestLoadConfigSimFileState(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SIM_STATE_DIR", dir)
+	configYAML := `
+providers:
+  paper_trading:
+    type: sim
+    sim:
+      initial_equity: 5000
+      state:
+        backend: File
+        path: ${SIM_STATE_DIR}/paper.json
+`
+	cfg, err := exchange.LoadConfigFromReader(strings.NewReader(configYAML))
+	assert.NoError(t, err, "LoadConfigFromReader should not error")
+	state := cfg.Providers["paper_trading"].Sim.State
+	assert.Equal(t, "file", state.Backend)
+	assert.Equal(t, filepath.Join(dir, "paper.json"), state.Path)
+
+	providers, err := cfg.BuildProviders()
+	assert.NoError(t, err, "BuildProviders should not error")
+	orderer, ok := providers["paper_trading"].(exchange.MarketOrderer)
+	if assert.True(t, ok, "sim provider should place market orders") {
+		_, err = orderer.IOCMarket(context.Background(), "BTC", true, 1, 0.01, false)
+		assert.NoError(t, err)
+	}
+	// State is saved in the background.
+	providers["paper_trading"].(interface{ Flush() }).Flush()
+
+	// A second build picks up the saved position.
+	providers, err = cfg.BuildProviders()
+	assert.NoError(t, err)
+	positions, err := providers["paper_trading"].GetPositions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1, "position should be restored from the state file")
+	// Let any restore-time save finish before the temp dir is removed.
+	providers["paper_trading"].(interface{ Flush() }).Flush()
+}
+
+func TestLoadConfigShadowDecorator(t *testing.T) {
+	cfg, err := exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  paper:
+    type: sim
+  mirrored:
+    type: shadow
+    wraps: paper
+    sim:
+      initial_equity: 5000
+    shadow:
+      max_records: 10
+  dry:
+    type: shadow
+    shadow:
+      dry_run: true
+`))
+	assert.NoError(t, err)
+	providers, err := cfg.BuildProviders()
+	assert.NoError(t, err)
+	mirrored, ok := providers["mirrored"].(*shadow.Provider)
+	if assert.True(t, ok, "shadow type builds a shadow provider") {
+		assert.Same(t, providers["paper"], mirrored.Primary(), "decorators receive the provider they wrap")
+		value, err := mirrored.Simulator().GetAccountValue(context.Background())
+		assert.NoError(t, err)
+		assert.InDelta(t, 5000, value, 1e-9)
+	}
+	dry, ok := providers["dry"].(*shadow.Provider)
+	if assert.True(t, ok) {
+		assert.True(t, dry.DryRun())
+	}
+}
+
+func TestLoadConfigRejectsInvalidWraps(t *testing.T) {
+	cases := map[string]string{
+		"wraps undefined provider": `
+providers:
+  mirrored:
+    type: shadow
+    wraps: missing
+`,
+		"cannot wrap another provider": `
+providers:
+  paper:
+    type: sim
+  other:
+    type: sim
+    wraps: paper
+`,
+		"wraps cycle": `
+providers:
+  a:
+    type: shadow
+    wraps: b
+  b:
+    type: shadow
+    wraps: a
+`,
+		"requires wraps unless shadow.dry_run": `
+providers:
+  mirrored:
+    type: shadow
+`,
+	}
+	for want, yaml := range cases {
+		_, err := exchange.LoadConfigFromReader(strings.NewReader(yaml))
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func TestLoadConfigCassette(t *testing.T) {
+	t.Setenv("HL_KEY", testPrivateKey)
+	dir := t.TempDir()
+	cfg, err := exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  recorded:
+    type: hyperliquid
+    private_key: ${HL_KEY}
+    testnet: true
+    cassette:
+      path: ` + filepath.Join(dir, "hl.yaml") + `
+      mode: " Record "
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "record", cfg.Providers["recorded"].Cassette.Mode)
+	providers, err := cfg.BuildProviders()
+	assert.NoError(t, err)
+	assert.NotNil(t, providers["recorded"])
+
+	cfg.Providers["recorded"].Cassette.Mode = "replay"
+	_, err = cfg.BuildProviders()
+	assert.ErrorContains(t, err, "not found", "replay needs a recorded cassette")
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  recorded:
+    type: sim
+    cassette:
+      path: hl.yaml
+      mode: rewind
+`))
+	assert.ErrorContains(t, err, "cassette.mode")
+}
+
+func TestLoadConfigSigner(t *testing.T) {
+	key, err := hyperliquid.NewPrivateKeySigner(testPrivateKey)
+	assert.NoError(t, err)
+	daemon := httptest.NewServer(hyperliquid.NewSignerServer(key, hyperliquid.SignerPolicy{}, hyperliquid.WithSignerServerToken("s3cret")))
+	t.Cleanup(daemon.Close)
+	t.Setenv("SIGNER_URL", daemon.URL)
+	t.Setenv("SIGNER_TOKEN", "s3cret")
+
+	cfg, err := exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  remote:
+    type: hyperliquid
+    testnet: true
+    signer:
+      endpoint: ${SIGNER_URL}
+      token: ${SIGNER_TOKEN}
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, daemon.URL, cfg.Providers["remote"].Signer.Endpoint)
+	assert.Equal(t, "s3cret", cfg.Providers["remote"].Signer.Token)
+	providers, err := cfg.BuildProviders()
+	assert.NoError(t, err)
+	assert.NotNil(t, providers["remote"])
+
+	cfg.Providers["remote"].Signer.Token = "wrong"
+	_, err = cfg.BuildProviders()
+	assert.ErrorIs(t, err, hyperliquid.ErrSignRejected)
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  remote:
+    type: hyperliquid
+    private_key: ` + testPrivateKey + `
+    signer:
+      endpoint: unix:///run/signer.sock
+`))
+	assert.ErrorContains(t, err, "both private_key and signer")
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  remote:
+    type: hyperliquid
+    signer:
+      endpoint: tcp://127.0.0.1:7000
+`))
+	assert.ErrorContains(t, err, "signer.endpoint")
+}
+
+func TestLoadConfigAgentKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.key")
+	key, _, err := hyperliquid.GenerateAgentKey()
+	assert.NoError(t, err)
+	assert.NoError(t, hyperliquid.WriteAgentKeyFile(path, key))
+	t.Setenv("AGENT_KEY_FILE", path)
+
+	cfg, err := exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  agent:
+    type: hyperliquid
+    testnet: true
+    main_address: 0x2222222222222222222222222222222222222222
+    agent_key_file: ${AGENT_KEY_FILE}
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, path, cfg.Providers["agent"].AgentKeyFile)
+	providers, err := cfg.BuildProviders()
+	assert.NoError(t, err)
+	_, ok := providers["agent"].(exchange.CredentialExpirer)
+	assert.True(t, ok)
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  agent:
+    type: hyperliquid
+    agent_key_file: ` + path + `
+`))
+	assert.ErrorContains(t, err, "main_address")
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  agent:
+    type: hyperliquid
+    private_key: ` + testPrivateKey + `
+    main_address: 0x2222222222222222222222222222222222222222
+    agent_key_file: ` + path + `
+`))
+	assert.ErrorContains(t, err, "use one")
+}
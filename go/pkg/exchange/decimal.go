@@ -0,0 +1,360 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact base-10 number, coef × 10^exp. Venues quote prices and
+// sizes as decimal strings; carrying them through float64 turns "0.1" into
+// 0.1000000000000000055… and values that sat exactly on a tick or lot
+// boundary can round off it. The zero value is 0, and every operation
+// returns a new Decimal, so values are safe to copy and share.
+type Decimal struct {
+	coef *big.Int // nil means zero
+	exp  int32
+}
+
+// RoundingMode selects how Round, RoundStep and RoundSigFigs resolve values
+// that do not fall on the target grid.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest grid value, ties away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest grid value, ties to the even one.
+	RoundHalfEven
+	// RoundDown truncates towards zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+// maxDecimalExp bounds the exponent ParseDecimal accepts, so a hostile
+// "1e999999999" cannot make String or alignment allocate gigabytes.
+const maxDecimalExp = 64
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// NewDecimal returns coef × 10^exp.
+func NewDecimal(coef int64, exp int32) Decimal {
+	return Decimal{coef: big.NewInt(coef), exp: exp}
+}
+
+// DecimalFromInt returns v as a Decimal.
+func DecimalFromInt(v int64) Decimal { return NewDecimal(v, 0) }
+
+// DecimalFromFloat returns the shortest decimal that parses back to v, so
+// 0.1 becomes exactly 0.1. NaN, infinities and magnitudes outside
+// ParseDecimal's range yield zero.
+func DecimalFromFloat(v float64) Decimal {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return Decimal{}
+	}
+	d, err := ParseDecimal(strconv.FormatFloat(v, 'g', -1, 64))
+	if err != nil {
+		return Decimal{}
+	}
+	return d
+}
+
+// ParseDecimal parses a plain ("-12.50") or exponent ("1.25e-3") decimal
+// string. Surrounding whitespace is ignored. Values needing an exponent
+// beyond ±64 (e.g. "1e65", or more than 64 fractional digits) are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	body := strings.TrimSpace(s)
+	exp := int64(0)
+	if i := strings.IndexAny(body, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(body[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("exchange: invalid decimal %q", s)
+		}
+		exp, body = e, body[:i]
+	}
+	neg := false
+	if body != "" && (body[0] == '-' || body[0] == '+') {
+		neg, body = body[0] == '-', body[1:]
+	}
+	intPart, fracPart := body, ""
+	if i := strings.IndexByte(body, '.'); i >= 0 {
+		intPart, fracPart = body[:i], body[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("exchange: invalid decimal %q", s)
+	}
+	exp -= int64(len(fracPart))
+	if exp < -maxDecimalExp || exp > maxDecimalExp {
+		return Decimal{}, fmt.Errorf("exchange: decimal %q out of range", s)
+	}
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+	return Decimal{coef: coef, exp: int32(exp)}, nil
+}
+
+// MustParseDecimal is ParseDecimal for constants; it panics on bad input.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) c() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// Sign returns -1, 0 or +1.
+func (d Decimal) Sign() int {
+	if d.coef == nil {
+		return 0
+	}
+	return d.coef.Sign()
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool { return d.Sign() == 0 }
+
+// Cmp compares d and o, returning -1, 0 or +1.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and o are numerically equal ("1.50" equals "1.5").
+func (d Decimal) Equal(o Decimal) bool { return d.Cmp(o) == 0 }
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.c()), exp: d.exp}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.c()), exp: d.exp}
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, exp := align(d, o)
+	return Decimal{coef: a.Add(a, b), exp: exp}
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, exp := align(d, o)
+	return Decimal{coef: a.Sub(a, b), exp: exp}
+}
+
+// Mul returns d × o. It panics if the product's exponent overflows int32,
+// which only values built with extreme NewDecimal exponents can reach.
+func (d Decimal) Mul(o Decimal) Decimal {
+	exp := int64(d.exp) + int64(o.exp)
+	if exp < math.MinInt32 || exp > math.MaxInt32 {
+		panic("exchange: decimal exponent overflow")
+	}
+	return Decimal{coef: new(big.Int).Mul(d.c(), o.c()), exp: int32(exp)}
+}
+
+// Div returns d ÷ o rounded half up to places fractional digits. It panics
+// if o is zero.
+func (d Decimal) Div(o Decimal, places int32) Decimal {
+	if o.IsZero() {
+		panic("exchange: decimal division by zero")
+	}
+	// d/o = (dc/oc) × 10^(d.exp-o.exp); scale so the integer quotient has
+	// places fractional digits.
+	shift := int64(places) + int64(d.exp) - int64(o.exp)
+	num, den := new(big.Int).Set(d.c()), new(big.Int).Set(o.c())
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	if den.Sign() < 0 {
+		num.Neg(num)
+		den.Neg(den)
+	}
+	return Decimal{coef: roundQuo(num, den, RoundHalfUp), exp: -places}
+}
+
+// Round returns d rounded to places fractional digits; negative places round
+// to tens, hundreds and so on.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if d.exp >= -places {
+		return d
+	}
+	unit := pow10(int64(-places) - int64(d.exp))
+	q := roundQuo(d.c(), unit, mode)
+	return Decimal{coef: q, exp: -places}
+}
+
+// RoundStep returns the multiple of step nearest to d under mode, e.g. a
+// price snapped to a tick size or a quantity to a lot size. It panics if
+// step is not positive.
+func (d Decimal) RoundStep(step Decimal, mode RoundingMode) Decimal {
+	if step.Sign() <= 0 {
+		panic("exchange: decimal step must be positive")
+	}
+	a, b, _ := align(d, step)
+	q := roundQuo(a, b, mode)
+	return Decimal{coef: q.Mul(q, step.c()), exp: step.exp}
+}
+
+// RoundSigFigs returns d rounded to at most n significant digits.
+func (d Decimal) RoundSigFigs(n int, mode RoundingMode) Decimal {
+	if d.IsZero() || n <= 0 {
+		return Decimal{}
+	}
+	digits := int64(len(new(big.Int).Abs(d.coef).String()))
+	if digits <= int64(n) {
+		return d
+	}
+	// Rounding 9.99 up to 10.0 adds a digit, but only a trailing zero.
+	return d.Round(int32(-(int64(d.exp) + digits - int64(n))), mode)
+}
+
+// Places returns the number of fractional digits in d's shortest plain form.
+func (d Decimal) Places() int32 {
+	n := d.normalized()
+	if n.exp >= 0 {
+		return 0
+	}
+	return -n.exp
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d in plain notation without trailing fractional zeros,
+// e.g. "110820", "0.00123" or "-5.5": the form venues accept on the wire.
+func (d Decimal) String() string {
+	n := d.normalized()
+	if n.coef == nil || n.coef.Sign() == 0 {
+		return "0"
+	}
+	digits := new(big.Int).Abs(n.coef).String()
+	sign := ""
+	if n.coef.Sign() < 0 {
+		sign = "-"
+	}
+	if n.exp >= 0 {
+		return sign + digits + strings.Repeat("0", int(n.exp))
+	}
+	places := int(-n.exp)
+	if len(digits) <= places {
+		return sign + "0." + strings.Repeat("0", places-len(digits)) + digits
+	}
+	return sign + digits[:len(digits)-places] + "." + digits[len(digits)-places:]
+}
+
+// MarshalJSON encodes d as a JSON string, the form venues use for prices
+// and sizes.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON accepts a JSON string, a bare number or null (zero).
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+	raw := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseDecimal(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// normalized strips trailing zeros from the coefficient.
+func (d Decimal) normalized() Decimal {
+	if d.coef == nil || d.coef.Sign() == 0 {
+		return Decimal{}
+	}
+	coef := new(big.Int).Set(d.coef)
+	exp := d.exp
+	r := new(big.Int)
+	for {
+		q, m := new(big.Int).QuoRem(coef, bigTen, r)
+		if m.Sign() != 0 {
+			break
+		}
+		coef = q
+		exp++
+	}
+	return Decimal{coef: coef, exp: exp}
+}
+
+// align returns copies of the coefficients of a and b scaled to their
+// common (smaller) exponent.
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	ac, bc := new(big.Int).Set(a.c()), new(big.Int).Set(b.c())
+	switch {
+	case a.exp > b.exp:
+		ac.Mul(ac, pow10(int64(a.exp)-int64(b.exp)))
+		return ac, bc, b.exp
+	case b.exp > a.exp:
+		bc.Mul(bc, pow10(int64(b.exp)-int64(a.exp)))
+	}
+	return ac, bc, a.exp
+}
+
+// roundQuo returns num/den rounded to an integer under mode; den > 0.
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	away := false
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundHalfUp, RoundHalfEven:
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		switch twice.Cmp(den) {
+		case 1:
+			away = true
+		case 0:
+			away = mode == RoundHalfUp || q.Bit(0) == 1
+		}
+	}
+	if away {
+		if num.Sign() < 0 {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return q
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(n), nil)
+}
@@ -0,0 +1,180 @@
+package exchange_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	exchange "nof0-api/pkg/exchange"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randDecimal is a quick.Generator producing decimals with up to 12
+// significant digits and 0-8 fractional digits, the range venues quote in.
+type randDecimal struct{ exchange.Decimal }
+
+func (randDecimal) Generate(r *rand.Rand, size int) reflect.Value {
+	coef := r.Int63n(1_000_000_000_000) - 500_000_000_000
+	return reflect.ValueOf(randDecimal{exchange.NewDecimal(coef, -int32(r.Intn(9)))})
+}
+
+// randStep is a positive tick or lot size such as 0.01, 0.5 or 25.
+type randStep struct{ exchange.Decimal }
+
+func (randStep) Generate(r *rand.Rand, size int) reflect.Value {
+	mantissa := []int64{1, 2, 5, 25}[r.Intn(4)]
+	return reflect.ValueOf(randStep{exchange.NewDecimal(mantissa, -int32(r.Intn(9))+2)})
+}
+
+func rat(t *testing.T, d exchange.Decimal) *big.Rat {
+	t.Helper()
+	r, ok := new(big.Rat).SetString(d.String())
+	require.True(t, ok, d.String())
+	return r
+}
+
+var quickConfig = &quick.Config{MaxCount: 2000}
+
+func TestDecimalArithmeticMatchesRationals(t *testing.T) {
+	check := func(a, b randDecimal) bool {
+		ra, rb := rat(t, a.Decimal), rat(t, b.Decimal)
+		return rat(t, a.Add(b.Decimal)).Cmp(new(big.Rat).Add(ra, rb)) == 0 &&
+			rat(t, a.Sub(b.Decimal)).Cmp(new(big.Rat).Sub(ra, rb)) == 0 &&
+			rat(t, a.Mul(b.Decimal)).Cmp(new(big.Rat).Mul(ra, rb)) == 0 &&
+			a.Cmp(b.Decimal) == ra.Cmp(rb)
+	}
+	require.NoError(t, quick.Check(check, quickConfig))
+}
+
+func TestDecimalStringRoundTrips(t *testing.T) {
+	check := func(a randDecimal) bool {
+		s := a.String()
+		parsed, err := exchange.ParseDecimal(s)
+		if err != nil || !parsed.Equal(a.Decimal) || parsed.String() != s {
+			return false
+		}
+		// Plain notation only, and no trailing fractional zeros.
+		if strings.ContainsAny(s, "eE") || (strings.Contains(s, ".") && strings.HasSuffix(s, "0")) {
+			return false
+		}
+		data, err := json.Marshal(a.Decimal)
+		if err != nil {
+			return false
+		}
+		var decoded exchange.Decimal
+		return json.Unmarshal(data, &decoded) == nil && decoded.Equal(a.Decimal)
+	}
+	require.NoError(t, quick.Check(check, quickConfig))
+
+	// Keep magnitudes within ParseDecimal's exponent range (about 1e±45
+	// with 17 significant digits).
+	floats := func(f float64, e int8) bool {
+		frac, _ := math.Frexp(f)
+		f = math.Ldexp(frac, int(e)%150)
+		return exchange.DecimalFromFloat(f).Float64() == f
+	}
+	require.NoError(t, quick.Check(floats, quickConfig))
+	assert.True(t, exchange.DecimalFromFloat(math.MaxFloat64).IsZero(), "out of range")
+}
+
+func TestDecimalRoundStepProperties(t *testing.T) {
+	half := big.NewRat(1, 2)
+	check := func(a randDecimal, step randStep) bool {
+		ra, rs := rat(t, a.Decimal), rat(t, step.Decimal)
+		for _, mode := range []exchange.RoundingMode{exchange.RoundHalfUp, exchange.RoundHalfEven, exchange.RoundDown, exchange.RoundUp} {
+			got := a.RoundStep(step.Decimal, mode)
+			rg := rat(t, got)
+			// The result lies on the grid ...
+			if !new(big.Rat).Quo(rg, rs).IsInt() {
+				return false
+			}
+			// ... less than one step away ...
+			diff := new(big.Rat).Abs(new(big.Rat).Sub(rg, ra))
+			if diff.Cmp(rs) >= 0 {
+				return false
+			}
+			// ... on the side the mode asks for.
+			switch mode {
+			case exchange.RoundDown:
+				if new(big.Rat).Abs(rg).Cmp(new(big.Rat).Abs(ra)) > 0 {
+					return false
+				}
+			case exchange.RoundUp:
+				if new(big.Rat).Abs(rg).Cmp(new(big.Rat).Abs(ra)) < 0 {
+					return false
+				}
+			default:
+				if diff.Cmp(new(big.Rat).Mul(rs, half)) > 0 {
+					return false
+				}
+			}
+			// Values already on the grid never move.
+			if !got.RoundStep(step.Decimal, mode).Equal(got) {
+				return false
+			}
+		}
+		return true
+	}
+	require.NoError(t, quick.Check(check, quickConfig))
+}
+
+func TestDecimalRoundAndSigFigsProperties(t *testing.T) {
+	check := func(a randDecimal, places uint8, sig uint8) bool {
+		p := int32(places % 8)
+		rounded := a.Round(p, exchange.RoundHalfEven)
+		if rounded.Places() > p {
+			return false
+		}
+		ulp := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(p)), nil))
+		diff := new(big.Rat).Abs(new(big.Rat).Sub(rat(t, rounded), rat(t, a.Decimal)))
+		if diff.Cmp(new(big.Rat).Mul(ulp, big.NewRat(1, 2))) > 0 {
+			return false
+		}
+
+		n := int(sig%8) + 1
+		fig := a.RoundSigFigs(n, exchange.RoundHalfUp)
+		digits := strings.TrimLeft(strings.NewReplacer("-", "", ".", "").Replace(fig.String()), "0")
+		digits = strings.TrimRight(digits, "0")
+		return len(digits) <= n
+	}
+	require.NoError(t, quick.Check(check, quickConfig))
+}
+
+func TestDecimalRoundingExamples(t *testing.T) {
+	d := exchange.MustParseDecimal
+	// Float division leaves 0.3/0.1 at 2.9999999999999996 and floors it to
+	// 2 lots; the decimal quotient is exact.
+	assert.Equal(t, "0.3", d("0.3").RoundStep(d("0.1"), exchange.RoundDown).String())
+	assert.Equal(t, "1.015", d("1.0149999").RoundStep(d("0.005"), exchange.RoundHalfUp).String())
+	assert.Equal(t, "110820", d("110823.7").RoundSigFigs(5, exchange.RoundHalfUp).String())
+	assert.Equal(t, "10", d("9.996").RoundSigFigs(3, exchange.RoundHalfUp).String())
+	assert.Equal(t, "0.000123", d("1.23e-4").String())
+	assert.Equal(t, "-2.5", d("-2.45").Round(1, exchange.RoundHalfUp).String())
+	assert.Equal(t, "-2.4", d("-2.45").Round(1, exchange.RoundHalfEven).String())
+	assert.Equal(t, "0.333333", d("1").Div(d("3"), 6).String())
+	assert.Equal(t, "-0.666667", d("2").Div(d("-3"), 6).String())
+	assert.Equal(t, "0.3", exchange.DecimalFromFloat(0.1).Add(exchange.DecimalFromFloat(0.2)).String())
+	assert.True(t, exchange.DecimalFromFloat(math.NaN()).IsZero())
+
+	for _, bad := range []string{"", "-", ".", "1.2.3", "abc", "1e", "0x10", "NaN", "1e65", "1e-65", "1e999999999", "0." + strings.Repeat("0", 64) + "1"} {
+		_, err := exchange.ParseDecimal(bad)
+		assert.Error(t, err, bad)
+	}
+	for _, edge := range []string{"1e64", "1e-64", "10e63", "0.5e65"} {
+		_, err := exchange.ParseDecimal(edge)
+		assert.NoError(t, err, edge)
+	}
+	huge := exchange.NewDecimal(1, math.MaxInt32)
+	assert.Panics(t, func() { huge.Mul(huge) })
+	assert.Equal(t, "1"+strings.Repeat("0", 64), d("1e32").Mul(d("1e32")).String())
+	var fromNumber exchange.Decimal
+	require.NoError(t, json.Unmarshal([]byte(`12.50`), &fromNumber))
+	assert.Equal(t, "12.5", fromNumber.String())
+}
@@ -0,0 +1,35 @@
+package exchange
+
+import (
+	"context"
+	"time"
+)
+
+// EventType classifies asynchronous account events pushed by a provider.
+type EventType string
+
+const (
+	// EventFill reports an execution against one of the account's orders.
+	EventFill EventType = "fill"
+	// EventOrderUpdate reports an order lifecycle transition (open, filled,
+	// canceled, triggered, rejected, ...).
+	EventOrderUpdate EventType = "order_update"
+)
+
+// Event is a single account event delivered by an EventStream.
+type Event struct {
+	Type  EventType    `json:"type"`
+	Fill  *Fill        `json:"fill,omitempty"`
+	Order *OrderStatus `json:"order,omitempty"`
+	// Recovered marks events replayed from REST history after a stream gap
+	// rather than received live.
+	Recovered  bool      `json:"recovered,omitempty"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// EventStream is implemented by providers that push fills and order updates
+// asynchronously. Events starts the subscription and returns a channel that
+// is closed once ctx is cancelled; implementations reconnect transparently.
+type EventStream interface {
+	Events(ctx context.Context) (<-chan Event, error)
+}
@@ -0,0 +1,98 @@
+package exchange
+
+// FillSummary aggregates one or more fills of the same order or position
+// change into volume-weighted totals.
+type FillSummary struct {
+	Size      float64 // total executed size
+	AvgPx     float64 // size-weighted average price
+	Fee       float64 // total fees, in FeeToken units
+	ClosedPnl float64 // venue-reported realised PnL before fees
+	Crossed   bool    // any fill took liquidity
+	Oid       int64   // order id of the last fill
+	Tid       int64   // trade id of the last fill
+	Timestamp int64   // time of the last fill (ms)
+}
+
+// SummarizeFills aggregates fills. Totals are summed exactly in decimal and
+// converted once, so many small fills do not accumulate float error. Fills
+// with an unparsable price or size are skipped; ok is false when nothing
+// could be aggregated.
+func SummarizeFills(fills []Fill) (summary FillSummary, ok bool) {
+	var notional, size, fee, closedPnl Decimal
+	for _, f := range fills {
+		px := parseFillDecimal(f.Px)
+		if px.Sign() <= 0 {
+			px = parseFillDecimal(f.AvgPx)
+		}
+		sz := parseFillDecimal(f.Sz)
+		if px.Sign() <= 0 || sz.Sign() <= 0 {
+			continue
+		}
+		ok = true
+		notional = notional.Add(px.Mul(sz))
+		size = size.Add(sz)
+		fee = fee.Add(parseFillDecimal(f.Fee))
+		closedPnl = closedPnl.Add(parseFillDecimal(f.ClosedPnl))
+		summary.Crossed = summary.Crossed || f.Crossed
+		if f.Timestamp >= summary.Timestamp {
+			summary.Timestamp = f.Timestamp
+			summary.Oid = f.Oid
+			summary.Tid = f.Tid
+		}
+	}
+	summary.Size = size.Float64()
+	summary.Fee = fee.Float64()
+	summary.ClosedPnl = closedPnl.Float64()
+	if size.Sign() > 0 {
+		summary.AvgPx = notional.Div(size, fillAvgPxPlaces).Float64()
+	}
+	return summary, ok
+}
+
+// fillAvgPxPlaces bounds the fractional digits of an averaged fill price,
+// well beyond any venue tick.
+const fillAvgPxPlaces = 12
+
+// FillsForOrders returns the fills belonging to any of the given order ids,
+// preserving order.
+func FillsForOrders(fills []Fill, oids ...int64) []Fill {
+	if len(oids) == 0 {
+		return nil
+	}
+	want := make(map[int64]struct{}, len(oids))
+	for _, oid := range oids {
+		want[oid] = struct{}{}
+	}
+	var out []Fill
+	for _, f := range fills {
+		if _, ok := want[f.Oid]; ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// OrderIDs returns the ids of filled or resting orders in resp.
+func (o *OrderResponse) OrderIDs() []int64 {
+	if o == nil {
+		return nil
+	}
+	var oids []int64
+	for _, st := range o.Response.Data.Statuses {
+		switch {
+		case st.Filled != nil && st.Filled.Oid != 0:
+			oids = append(oids, st.Filled.Oid)
+		case st.Resting != nil && st.Resting.Oid != 0:
+			oids = append(oids, st.Resting.Oid)
+		}
+	}
+	return oids
+}
+
+func parseFillDecimal(s string) Decimal {
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return Decimal{}
+	}
+	return v
+}
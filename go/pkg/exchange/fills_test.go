@@ -0,0 +1,47 @@
+package exchange_test
+
+import (
+	"testing"
+
+	exchange "nof0-api/pkg/exchange"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeFills(t *testing.T) {
+	fills := []exchange.Fill{
+		{Px: "100", Sz: "1", Fee: "0.05", ClosedPnl: "2", Oid: 7, Tid: 1, Timestamp: 10},
+		{Px: "110", Sz: "3", Fee: "0.15", ClosedPnl: "6", Crossed: true, Oid: 7, Tid: 2, Timestamp: 20},
+		{Px: "bad", Sz: "1"},
+	}
+	summary, ok := exchange.SummarizeFills(fills)
+	require.True(t, ok)
+	assert.InDelta(t, 4, summary.Size, 1e-9)
+	assert.InDelta(t, 107.5, summary.AvgPx, 1e-9)
+	assert.InDelta(t, 0.2, summary.Fee, 1e-9)
+	assert.InDelta(t, 8, summary.ClosedPnl, 1e-9)
+	assert.True(t, summary.Crossed)
+	assert.Equal(t, int64(2), summary.Tid)
+	assert.Equal(t, int64(20), summary.Timestamp)
+
+	_, ok = exchange.SummarizeFills(nil)
+	assert.False(t, ok)
+}
+
+func TestFillsForOrders(t *testing.T) {
+	resp := &exchange.OrderResponse{Response: exchange.OrderResponseData{Data: exchange.OrderResponseDataDetail{
+		Statuses: []exchange.OrderStatusResponse{
+			{Filled: &exchange.FilledOrder{Oid: 7}},
+			{Resting: &exchange.RestingOrder{Oid: 9}},
+			{Error: "rejected"},
+		},
+	}}}
+	oids := resp.OrderIDs()
+	assert.Equal(t, []int64{7, 9}, oids)
+
+	fills := []exchange.Fill{{Oid: 6}, {Oid: 7, Tid: 1}, {Oid: 9, Tid: 2}}
+	assert.Equal(t, []exchange.Fill{{Oid: 7, Tid: 1}, {Oid: 9, Tid: 2}}, exchange.FillsForOrders(fills, oids...))
+	assert.Nil(t, exchange.FillsForOrders(fills))
+	assert.Nil(t, (*exchange.OrderResponse)(nil).OrderIDs())
+}
@@ -3,14 +3,33 @@ package hyperliquid
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"nof0-api/pkg/exchange"
 )
 
-// GetAccountState fetches the clearinghouse state for the signer address.
+// GetAccountState fetches the clearinghouse state for the signer address
+// together with its spot balances. A failing spot lookup is logged and the
+// perp state is returned without balances.
 func (c *Client) GetAccountState(ctx context.Context) (*exchange.AccountState, error) {
+	state, err := c.getClearinghouseState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := c.GetSpotBalances(ctx)
+	if err != nil {
+		c.logf("hyperliquid: spot balances unavailable: %v", err)
+		return state, nil
+	}
+	state.SpotBalances = balances
+	return state, nil
+}
+
+// getClearinghouseState fetches the perp clearinghouse state only.
+func (c *Client) getClearinghouseState(ctx context.Context) (*exchange.AccountState, error) {
 	infoAddr := c.getInfoAddress()
 	if infoAddr == "" {
 		return nil, fmt.Errorf("hyperliquid: client address unavailable")
@@ -31,7 +50,7 @@ func (c *Client) GetAccountState(ctx context.Context) (*exchange.AccountState, e
 
 // GetAccountValue returns the account value parsed as float64.
 func (c *Client) GetAccountValue(ctx context.Context) (float64, error) {
-	state, err := c.GetAccountState(ctx)
+	state, err := c.getClearinghouseState(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -41,3 +60,151 @@ func (c *Client) GetAccountValue(ctx context.Context) (float64, error) {
 	}
 	return value, nil
 }
+
+// maxFillsPerRequest is the page size cap Hyperliquid applies to
+// userFills and userFillsByTime.
+const maxFillsPerRequest = 2000
+
+// GetUserFills returns the most recent fills (up to 2000) for the info address.
+func (c *Client) GetUserFills(ctx context.Context) ([]UserFill, error) {
+	infoAddr := c.getInfoAddress()
+	if infoAddr == "" {
+		return nil, fmt.Errorf("hyperliquid: client address unavailable")
+	}
+	var fills []UserFill
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "userFills", User: infoAddr}, &fills); err != nil {
+		return nil, err
+	}
+	return fills, nil
+}
+
+// GetUserFillsByTime returns fills for the info address between startMs and
+// endMs (inclusive, milliseconds). endMs <= 0 means "until now". Hyperliquid
+// caps each response at 2000 fills.
+func (c *Client) GetUserFillsByTime(ctx context.Context, startMs, endMs int64) ([]UserFill, error) {
+	infoAddr := c.getInfoAddress()
+	if infoAddr == "" {
+		return nil, fmt.Errorf("hyperliquid: client address unavailable")
+	}
+	req := InfoRequest{Type: "userFillsByTime", User: infoAddr, StartTime: startMs}
+	if endMs > 0 {
+		req.EndTime = endMs
+	}
+	var fills []UserFill
+	if err := c.doInfoRequest(ctx, req, &fills); err != nil {
+		return nil, err
+	}
+	return fills, nil
+}
+
+// GetFills returns fills at or after since, oldest first, paging through
+// userFillsByTime until a short page is returned. A zero since falls back to
+// the most recent userFills page.
+func (c *Client) GetFills(ctx context.Context, since time.Time) ([]exchange.Fill, error) {
+	var raw []UserFill
+	if since.IsZero() {
+		fills, err := c.GetUserFills(ctx)
+		if err != nil {
+			return nil, err
+		}
+		raw = fills
+	} else {
+		start := since.UnixMilli()
+		seen := make(map[int64]struct{})
+		for {
+			page, err := c.GetUserFillsByTime(ctx, start, 0)
+			if err != nil {
+				return nil, err
+			}
+			last := start
+			for _, f := range page {
+				if _, ok := seen[f.Tid]; ok {
+					continue
+				}
+				seen[f.Tid] = struct{}{}
+				raw = append(raw, f)
+				if f.Time > last {
+					last = f.Time
+				}
+			}
+			// Fills sharing the boundary millisecond are re-fetched and
+			// de-duplicated by tid; stop once no progress is possible.
+			if len(page) < maxFillsPerRequest || last == start {
+				break
+			}
+			start = last
+		}
+	}
+	sort.SliceStable(raw, func(i, j int) bool { return raw[i].Time < raw[j].Time })
+	out := make([]exchange.Fill, 0, len(raw))
+	for _, f := range raw {
+		out = append(out, f.ToExchangeFill())
+	}
+	return out, nil
+}
+
+// maxFundingPerRequest is the page size cap Hyperliquid applies to userFunding.
+const maxFundingPerRequest = 500
+
+// GetUserFunding returns funding payments for the info address between
+// startMs and endMs (milliseconds). endMs <= 0 means "until now".
+func (c *Client) GetUserFunding(ctx context.Context, startMs, endMs int64) ([]UserFunding, error) {
+	infoAddr := c.getInfoAddress()
+	if infoAddr == "" {
+		return nil, fmt.Errorf("hyperliquid: client address unavailable")
+	}
+	req := InfoRequest{Type: "userFunding", User: infoAddr, StartTime: startMs}
+	if endMs > 0 {
+		req.EndTime = endMs
+	}
+	var entries []UserFunding
+	if err := c.doInfoRequest(ctx, req, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetFundingPayments returns funding payments at or after since, oldest
+// first, paging through userFunding until a short page is returned.
+func (c *Client) GetFundingPayments(ctx context.Context, since time.Time) ([]exchange.FundingPayment, error) {
+	start := since.UnixMilli()
+	if since.IsZero() || start < 0 {
+		start = 0
+	}
+	type fundingKey struct {
+		time int64
+		coin string
+	}
+	seen := make(map[fundingKey]struct{})
+	var raw []UserFunding
+	for {
+		page, err := c.GetUserFunding(ctx, start, 0)
+		if err != nil {
+			return nil, err
+		}
+		last := start
+		for _, f := range page {
+			key := fundingKey{time: f.Time, coin: f.Delta.Coin}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			raw = append(raw, f)
+			if f.Time > last {
+				last = f.Time
+			}
+		}
+		// Hourly payments for several coins share a timestamp; re-fetch the
+		// boundary and de-duplicate by (time, coin).
+		if len(page) < maxFundingPerRequest || last == start {
+			break
+		}
+		start = last
+	}
+	sort.SliceStable(raw, func(i, j int) bool { return raw[i].Time < raw[j].Time })
+	out := make([]exchange.FundingPayment, 0, len(raw))
+	for _, f := range raw {
+		out = append(out, f.ToExchangeFunding())
+	}
+	return out, nil
+}
@@ -2,11 +2,14 @@ package hyperliquid
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetAccountState(t *testing.T) {
@@ -173,3 +176,74 @@ func TestDoInfoRequest(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestGetFillsPagesByTime(t *testing.T) {
+	var starts []int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req InfoRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "userFillsByTime", req.Type)
+		starts = append(starts, req.StartTime)
+		var fills []UserFill
+		if len(starts) == 1 {
+			// A full page forces a second request from the last timestamp.
+			for i := 0; i < maxFillsPerRequest; i++ {
+				fills = append(fills, UserFill{Coin: "BTC", Px: "100", Sz: "0.1", Time: req.StartTime + int64(i), Tid: int64(i + 1), Fee: "0.02", ClosedPnl: "0.0"})
+			}
+		} else {
+			last := req.StartTime
+			fills = []UserFill{
+				{Coin: "BTC", Time: last, Tid: int64(maxFillsPerRequest)}, // boundary duplicate
+				{Coin: "BTC", Px: "110", Sz: "0.1", Time: last + 5, Tid: 9999, Dir: "Close Long", Fee: "0.03", ClosedPnl: "1.0"},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(fills)
+	}))
+	defer server.Close()
+
+	client, err := NewClient("0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a741b52d7c5d5095e2f", false)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+
+	since := time.UnixMilli(1_000)
+	fills, err := client.GetFills(context.Background(), since)
+	require.NoError(t, err)
+	require.Len(t, fills, maxFillsPerRequest+1)
+	assert.Equal(t, []int64{1_000, 1_000 + maxFillsPerRequest - 1}, starts)
+	closing := fills[len(fills)-1]
+	assert.Equal(t, int64(9999), closing.Tid)
+	assert.True(t, closing.IsClose())
+	assert.Equal(t, "0.03", closing.Fee)
+	assert.Equal(t, "1.0", closing.ClosedPnl)
+}
+
+func TestGetFundingPayments(t *testing.T) {
+	var requests []InfoRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req InfoRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+		w.Write([]byte(`[
+			{"time": 1700003600000, "hash": "0x2", "delta": {"type": "funding", "coin": "BTC", "usdc": "-0.42", "szi": "0.5", "fundingRate": "0.0000125"}},
+			{"time": 1700000000000, "hash": "0x1", "delta": {"type": "funding", "coin": "ETH", "usdc": "0.10", "szi": "-2.0", "fundingRate": "0.00001"}}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a741b52d7c5d5095e2f", false)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+
+	payments, err := client.GetFundingPayments(context.Background(), time.UnixMilli(1_699_999_000_000))
+	require.NoError(t, err)
+	require.Len(t, requests, 1, "short page stops pagination")
+	assert.Equal(t, "userFunding", requests[0].Type)
+	assert.Equal(t, int64(1_699_999_000_000), requests[0].StartTime)
+	require.Len(t, payments, 2)
+	assert.Equal(t, "ETH", payments[0].Coin, "payments are returned oldest first")
+	assert.Equal(t, "0.10", payments[0].USDC)
+	assert.Equal(t, "-2.0", payments[0].Szi)
+	assert.Equal(t, "BTC", payments[1].Coin)
+	assert.Equal(t, "-0.42", payments[1].USDC)
+	assert.Equal(t, int64(1700003600000), payments[1].Timestamp)
+}
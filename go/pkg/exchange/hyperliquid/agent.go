@@ -0,0 +1,210 @@
+package hyperliquid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	mathhex "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// MaxAgentValidity is the longest approval Hyperliquid grants an agent wallet.
+const MaxAgentValidity = 180 * 24 * time.Hour
+
+const (
+	// userSignedChainID is the EIP-712 chain id of user-signed actions; the
+	// venue checks it against signatureChainId.
+	userSignedChainID = "0x66eee"
+	// agentValidUntilTag marks the expiry the venue reads from an agent name.
+	agentValidUntilTag = " valid_until "
+)
+
+// AgentApproval is an agent (API) wallet approved to trade for an account.
+type AgentApproval struct {
+	Name    string
+	Address string
+	// ValidUntil is when the approval lapses; zero if it does not.
+	ValidUntil time.Time
+}
+
+func hyperliquidChain(isMainnet bool) string {
+	if isMainnet {
+		return "Mainnet"
+	}
+	return "Testnet"
+}
+
+// buildApproveAgentAction approves agentAddress under name until validUntil
+// (zero for no expiry). The nonce is filled in when the action is signed.
+func buildApproveAgentAction(agentAddress, name string, validUntil time.Time, isMainnet bool) (approveAgentAction, error) {
+	if !common.IsHexAddress(agentAddress) {
+		return approveAgentAction{}, fmt.Errorf("hyperliquid: invalid agent address %q", agentAddress)
+	}
+	name = strings.TrimSpace(name)
+	if strings.Contains(name, strings.TrimSpace(agentValidUntilTag)) {
+		return approveAgentAction{}, fmt.Errorf("hyperliquid: agent name %q must not contain %q", name, strings.TrimSpace(agentValidUntilTag))
+	}
+	if !validUntil.IsZero() {
+		name += agentValidUntilTag + strconv.FormatInt(validUntil.UnixMilli(), 10)
+	}
+	return approveAgentAction{
+		Type:             ActionTypeApproveAgent,
+		SignatureChainID: userSignedChainID,
+		HyperliquidChain: hyperliquidChain(isMainnet),
+		AgentAddress:     strings.ToLower(common.HexToAddress(agentAddress).Hex()),
+		AgentName:        name,
+	}, nil
+}
+
+// approveAgentDigest hashes a as the HyperliquidTransaction:ApproveAgent
+// typed message the venue recovers the signer from.
+func approveAgentDigest(a approveAgentAction) ([]byte, error) {
+	chainID, ok := new(big.Int).SetString(strings.TrimPrefix(a.SignatureChainID, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("hyperliquid: invalid signatureChainId %q", a.SignatureChainID)
+	}
+	const primaryType = "HyperliquidTransaction:ApproveAgent"
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			primaryType: {
+				{Name: "hyperliquidChain", Type: "string"},
+				{Name: "agentAddress", Type: "address"},
+				{Name: "agentName", Type: "string"},
+				{Name: "nonce", Type: "uint64"},
+			},
+		},
+		PrimaryType: primaryType,
+		Domain: apitypes.TypedDataDomain{
+			Name:              "HyperliquidSignTransaction",
+			Version:           "1",
+			ChainId:           (*mathhex.HexOrDecimal256)(chainID),
+			VerifyingContract: verifyingContractHex,
+		},
+		Message: map[string]interface{}{
+			"hyperliquidChain": a.HyperliquidChain,
+			"agentAddress":     a.AgentAddress,
+			"agentName":        a.AgentName,
+			"nonce":            big.NewInt(a.Nonce),
+		},
+	}
+	return typedDataHash(typedData)
+}
+
+// actionDigest returns the digest to sign for action. User-signed actions
+// (approveAgent) are hashed over their own fields; everything else is an L1
+// action hashed by buildEIP712Message.
+func actionDigest(action interface{}, nonce int64, vaultAddress string, isMainnet bool) ([]byte, error) {
+	a, ok := action.(approveAgentAction)
+	if !ok {
+		return buildEIP712Message(action, nonce, vaultAddress, isMainnet)
+	}
+	if vaultAddress != "" {
+		return nil, errors.New("hyperliquid: approveAgent cannot be signed on behalf of a vault")
+	}
+	if a.Nonce != nonce {
+		return nil, fmt.Errorf("hyperliquid: approveAgent nonce %d does not match request nonce %d", a.Nonce, nonce)
+	}
+	if a.HyperliquidChain != hyperliquidChain(isMainnet) {
+		return nil, fmt.Errorf("hyperliquid: approveAgent is for %s, request is for %s", a.HyperliquidChain, hyperliquidChain(isMainnet))
+	}
+	return approveAgentDigest(a)
+}
+
+// ApproveAgent authorises agentAddress to trade for this account until
+// validUntil (zero for no expiry, at most MaxAgentValidity ahead). It must be
+// signed by the main wallet. Approving a new address under a name that is
+// already in use replaces the agent holding that name.
+func (c *Client) ApproveAgent(ctx context.Context, agentAddress, name string, validUntil time.Time) error {
+	if main := c.mainAddress; main != "" && !strings.EqualFold(main, c.signer.GetAddress()) {
+		return fmt.Errorf("hyperliquid: approveAgent must be signed by the main wallet %s, not agent %s", main, c.signer.GetAddress())
+	}
+	if !validUntil.IsZero() {
+		now := c.clock()
+		if !validUntil.After(now) {
+			return fmt.Errorf("hyperliquid: agent expiry %s is in the past", validUntil.UTC().Format(time.RFC3339))
+		}
+		if validUntil.Sub(now) > MaxAgentValidity {
+			return fmt.Errorf("hyperliquid: agent expiry %s is more than %s ahead", validUntil.UTC().Format(time.RFC3339), MaxAgentValidity)
+		}
+	}
+	action, err := buildApproveAgentAction(agentAddress, name, validUntil, !c.isTestnet)
+	if err != nil {
+		return err
+	}
+	var envelope exchangeEnvelope
+	if err := c.postAction(ctx, action, "", &envelope); err != nil {
+		return err
+	}
+	if err := envelope.err(); err != nil {
+		return fmt.Errorf("hyperliquid: approve agent: %w", err)
+	}
+	return nil
+}
+
+// GetAgentApprovals lists the agent wallets approved for the account.
+func (c *Client) GetAgentApprovals(ctx context.Context) ([]AgentApproval, error) {
+	var raw []struct {
+		Name       string `json:"name"`
+		Address    string `json:"address"`
+		ValidUntil int64  `json:"validUntil"`
+	}
+	user := common.HexToAddress(c.getInfoAddress()).Hex()
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "extraAgents", User: user}, &raw); err != nil {
+		return nil, err
+	}
+	out := make([]AgentApproval, 0, len(raw))
+	for _, r := range raw {
+		approval := AgentApproval{Name: r.Name, Address: strings.ToLower(r.Address)}
+		if r.ValidUntil > 0 {
+			approval.ValidUntil = time.UnixMilli(r.ValidUntil)
+		}
+		out = append(out, approval)
+	}
+	return out, nil
+}
+
+// AgentExpiry returns when the approval of the signing agent wallet lapses.
+// A signer that is the account's main wallet never expires.
+func (c *Client) AgentExpiry(ctx context.Context) (time.Time, error) {
+	agent := c.signer.GetAddress()
+	if strings.EqualFold(agent, c.getInfoAddress()) {
+		return time.Time{}, nil
+	}
+	approvals, err := c.GetAgentApprovals(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, a := range approvals {
+		if strings.EqualFold(a.Address, agent) {
+			return a.ValidUntil, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("hyperliquid: agent %s is not approved for %s", agent, c.getInfoAddress())
+}
+
+// agentSource is satisfied by clients that can report agent approvals.
+type agentSource interface {
+	AgentExpiry(ctx context.Context) (time.Time, error)
+}
+
+// CredentialExpiry reports when the provider's agent wallet approval lapses
+// (zero when signing with the main wallet).
+func (p *Provider) CredentialExpiry(ctx context.Context) (time.Time, error) {
+	src, ok := p.client.(agentSource)
+	if !ok {
+		return time.Time{}, nil
+	}
+	return src.AgentExpiry(ctx)
+}
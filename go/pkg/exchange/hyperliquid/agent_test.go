@@ -0,0 +1,260 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const agentTestMainKey = "0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a741b52d7c5d5095e2f"
+
+// fakeAgentVenue serves extraAgents and records approveAgent requests,
+// adding each approved agent to the list it serves.
+type fakeAgentVenue struct {
+	mu        sync.Mutex
+	approvals []map[string]interface{}
+	requests  []ExchangeRequest
+}
+
+func (v *fakeAgentVenue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Type   string          `json:"type"`
+		Action json.RawMessage `json:"action"`
+	}
+	raw := json.NewDecoder(r.Body)
+	var msg json.RawMessage
+	if err := raw.Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_ = json.Unmarshal(msg, &body)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if body.Type == "extraAgents" {
+		_ = json.NewEncoder(w).Encode(v.approvals)
+		return
+	}
+	var req ExchangeRequest
+	var action approveAgentAction
+	_ = json.Unmarshal(msg, &req)
+	_ = json.Unmarshal(body.Action, &action)
+	req.Action = action
+	v.requests = append(v.requests, req)
+	name, until, _ := strings.Cut(action.AgentName, agentValidUntilTag)
+	var validUntil int64
+	_, _ = fmt.Sscan(until, &validUntil)
+	for i, a := range v.approvals {
+		if a["name"] == name {
+			v.approvals = append(v.approvals[:i], v.approvals[i+1:]...)
+			break
+		}
+	}
+	v.approvals = append(v.approvals, map[string]interface{}{"name": name, "address": action.AgentAddress, "validUntil": validUntil})
+	_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"default"}}`))
+}
+
+func newAgentTestClient(t *testing.T, venue http.Handler, opts ...ClientOption) *Client {
+	t.Helper()
+	server := httptest.NewServer(venue)
+	t.Cleanup(server.Close)
+	client, err := NewClient(agentTestMainKey, true, append([]ClientOption{WithoutRateLimit()}, opts...)...)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+	client.exchangeURL = server.URL
+	return client
+}
+
+// recoverApprover returns the address that signed an approveAgent request.
+func recoverApprover(t *testing.T, req ExchangeRequest) string {
+	t.Helper()
+	digest, err := approveAgentDigest(req.Action.(approveAgentAction))
+	require.NoError(t, err)
+	sig := make([]byte, 65)
+	copy(sig[:32], common.FromHex(req.Signature.R))
+	copy(sig[32:64], common.FromHex(req.Signature.S))
+	sig[64] = byte(req.Signature.V - 27)
+	pub, err := crypto.SigToPub(digest, sig)
+	require.NoError(t, err)
+	return strings.ToLower(crypto.PubkeyToAddress(*pub).Hex())
+}
+
+func TestBuildApproveAgentAction(t *testing.T) {
+	until := time.UnixMilli(1_760_000_000_000)
+	action, err := buildApproveAgentAction("0xABCDEF0000000000000000000000000000000001", " nof0-a ", until, false)
+	require.NoError(t, err)
+	assert.Equal(t, "0xabcdef0000000000000000000000000000000001", action.AgentAddress)
+	assert.Equal(t, "nof0-a valid_until 1760000000000", action.AgentName)
+	assert.Equal(t, "Testnet", action.HyperliquidChain)
+	assert.Equal(t, "0x66eee", action.SignatureChainID)
+
+	unnamed, err := buildApproveAgentAction("0xabcdef0000000000000000000000000000000001", "", time.Time{}, true)
+	require.NoError(t, err)
+	raw, err := json.Marshal(unnamed)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "agentName", "an empty name is signed as \"\" and left out of the request")
+	assert.Contains(t, string(raw), `"hyperliquidChain":"Mainnet"`)
+
+	_, err = buildApproveAgentAction("not-an-address", "x", time.Time{}, true)
+	assert.Error(t, err)
+	_, err = buildApproveAgentAction("0xabcdef0000000000000000000000000000000001", "x valid_until 1", time.Time{}, true)
+	assert.Error(t, err)
+
+	// The digest is bound to the request nonce, network and account.
+	unnamed.Nonce = 7
+	_, err = actionDigest(unnamed, 8, "", true)
+	assert.ErrorContains(t, err, "nonce")
+	_, err = actionDigest(unnamed, 7, "", false)
+	assert.ErrorContains(t, err, "Mainnet")
+	_, err = actionDigest(unnamed, 7, "0x3333333333333333333333333333333333333333", true)
+	assert.ErrorContains(t, err, "vault")
+}
+
+func TestApproveAgentSignedByMainWallet(t *testing.T) {
+	venue := &fakeAgentVenue{}
+	client := newAgentTestClient(t, venue)
+	agent := "0x1111111111111111111111111111111111111111"
+	until := client.clock().Add(24 * time.Hour)
+
+	require.NoError(t, client.ApproveAgent(context.Background(), agent, "bot", until))
+	require.Len(t, venue.requests, 1)
+	req := venue.requests[0]
+	action := req.Action.(approveAgentAction)
+	assert.Equal(t, req.Nonce, action.Nonce, "the action carries the request nonce")
+	assert.Empty(t, req.VaultAddress)
+	assert.Equal(t, client.address, recoverApprover(t, req))
+
+	assert.ErrorContains(t, client.ApproveAgent(context.Background(), agent, "bot", client.clock().Add(-time.Minute)), "past")
+	assert.ErrorContains(t, client.ApproveAgent(context.Background(), agent, "bot", client.clock().Add(MaxAgentValidity+time.Hour)), "ahead")
+
+	// An agent cannot approve other agents.
+	agentClient := newAgentTestClient(t, venue, WithMainAddress("0x2222222222222222222222222222222222222222"))
+	assert.ErrorContains(t, agentClient.ApproveAgent(context.Background(), agent, "bot", until), "main wallet")
+}
+
+func TestAgentExpiry(t *testing.T) {
+	venue := &fakeAgentVenue{}
+	main := "0x2222222222222222222222222222222222222222"
+	client := newAgentTestClient(t, venue, WithMainAddress(main))
+	provider := &Provider{client: client}
+	ctx := context.Background()
+
+	_, err := provider.CredentialExpiry(ctx)
+	assert.ErrorContains(t, err, "not approved")
+
+	venue.approvals = []map[string]interface{}{
+		{"name": "other", "address": "0x1111111111111111111111111111111111111111", "validUntil": 1},
+		{"name": "nof0-a", "address": client.address, "validUntil": 1_760_000_000_000},
+	}
+	expiry, err := provider.CredentialExpiry(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.UnixMilli(1_760_000_000_000), expiry)
+
+	mainWallet := &Provider{client: newAgentTestClient(t, venue)}
+	expiry, err = mainWallet.CredentialExpiry(ctx)
+	require.NoError(t, err)
+	assert.True(t, expiry.IsZero(), "main wallet keys do not expire")
+}
+
+func TestKeyFileSignerPicksUpRotatedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.key")
+	first, firstAddr, err := GenerateAgentKey()
+	require.NoError(t, err)
+	require.NoError(t, WriteAgentKeyFile(path, first))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	signer, err := NewKeyFileSigner(path)
+	require.NoError(t, err)
+	now := time.Now()
+	signer.now = func() time.Time { return now }
+	assert.Equal(t, firstAddr, signer.GetAddress())
+
+	second, secondAddr, err := GenerateAgentKey()
+	require.NoError(t, err)
+	require.NoError(t, WriteAgentKeyFile(path, second))
+	require.NoError(t, os.Chtimes(path, now.Add(time.Minute), now.Add(time.Minute)))
+	assert.Equal(t, firstAddr, signer.GetAddress(), "the file is checked at most once a second")
+
+	now = now.Add(keyFileCheckInterval)
+	assert.Equal(t, secondAddr, signer.GetAddress())
+	sig, err := signer.Sign(crypto.Keccak256([]byte("payload")))
+	require.NoError(t, err)
+	assert.NotEmpty(t, sig.R)
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	require.NoError(t, os.Chtimes(path, now.Add(2*time.Minute), now.Add(2*time.Minute)))
+	now = now.Add(keyFileCheckInterval)
+	assert.Equal(t, secondAddr, signer.GetAddress(), "an unreadable key keeps the previous one")
+}
+
+func TestRotateAgentKeyAlternatesNames(t *testing.T) {
+	venue := &fakeAgentVenue{}
+	main := newAgentTestClient(t, venue)
+	path := filepath.Join(t.TempDir(), "agent.key")
+	ctx := context.Background()
+
+	first, err := RotateAgentKey(ctx, main, path, "nof0", 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "nof0-a", first.Name)
+	assert.Empty(t, first.Previous)
+	signer, err := NewKeyFileSigner(path)
+	require.NoError(t, err)
+	assert.Equal(t, first.Address, signer.GetAddress())
+
+	second, err := RotateAgentKey(ctx, main, path, "nof0", 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "nof0-b", second.Name, "the outgoing agent keeps its approval")
+	assert.Equal(t, first.Address, second.Previous)
+
+	third, err := RotateAgentKey(ctx, main, path, "nof0", 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "nof0-a", third.Name)
+
+	require.Len(t, venue.requests, 3)
+	for _, req := range venue.requests {
+		assert.Equal(t, main.address, recoverApprover(t, req))
+	}
+	approvals, err := main.GetAgentApprovals(ctx)
+	require.NoError(t, err)
+	require.Len(t, approvals, 2)
+	assert.Equal(t, third.ValidUntil, approvals[1].ValidUntil)
+
+	_, err = RotateAgentKey(ctx, main, path, " ", time.Hour)
+	assert.Error(t, err)
+}
+
+func TestSignerServerApproveAgentMustBeAllowed(t *testing.T) {
+	ctx := context.Background()
+	action, err := buildApproveAgentAction("0x1111111111111111111111111111111111111111", "bot", time.Time{}, true)
+	require.NoError(t, err)
+	action.Nonce = 99
+
+	_, open := newTestSignerServer(t, SignerPolicy{Mainnet: true})
+	remote, err := NewRemoteSigner(ctx, open.URL)
+	require.NoError(t, err)
+	_, err = remote.SignAction(ctx, action, 99, "", true)
+	assert.ErrorIs(t, err, ErrSignRejected, "an empty allow-list does not cover approveAgent")
+
+	key, allowed := newTestSignerServer(t, SignerPolicy{Mainnet: true, Actions: []ActionType{ActionTypeApproveAgent}})
+	remote, err = NewRemoteSigner(ctx, allowed.URL)
+	require.NoError(t, err)
+	got, err := remote.SignAction(ctx, action, 99, "", true)
+	require.NoError(t, err)
+	want, err := signAction(action, key, 99, "", "", true)
+	require.NoError(t, err)
+	assert.Equal(t, want.Signature, *got)
+}
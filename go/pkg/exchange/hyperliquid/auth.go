@@ -91,7 +91,7 @@ func signAction(action interface{}, signer Signer, nonce int64, mainAddress stri
 		nonce = time.Now().UnixMilli()
 	}
 
-	digest, err := buildEIP712Message(action, nonce, vaultAddress, isMainnet)
+	digest, err := actionDigest(action, nonce, vaultAddress, isMainnet)
 	if err != nil {
 		return nil, err
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/ratelimit"
 )
 
 const (
@@ -22,6 +23,8 @@ const (
 	mainnetExchangeURL = "https://api.hyperliquid.xyz/exchange"
 	testnetInfoURL     = "https://api.hyperliquid-testnet.xyz/info"
 	testnetExchangeURL = "https://api.hyperliquid-testnet.xyz/exchange"
+	mainnetWSURL       = "wss://api.hyperliquid.xyz/ws"
+	testnetWSURL       = "wss://api.hyperliquid-testnet.xyz/ws"
 
 	defaultHTTPTimeout  = 30 * time.Second
 	defaultRetryBackoff = 200 * time.Millisecond
@@ -32,6 +35,7 @@ const (
 type Client struct {
 	infoURL     string
 	exchangeURL string
+	wsURL       string
 	httpClient  *http.Client
 	signer      Signer
 	address     string // API wallet address (derived from signer)
@@ -40,10 +44,17 @@ type Client struct {
 	logger      *log.Logger
 	clock       func() time.Time
 	vault       string
+	nonces      *NonceManager
+	nonceStore  NonceStore
+
+	// Request budgeting (see ratelimit.go)
+	rateLimiter  *ratelimit.Limiter
+	rateLimitOff bool
 
 	assetMu    sync.RWMutex
 	assetIndex map[string]int
 	assetInfo  map[string]AssetInfo
+	spotInfo   map[string]AssetInfo // loaded on first spot lookup (see spot.go)
 
 	// Trade defaults / formatting
 	defaultSlippage float64
@@ -52,6 +63,7 @@ type Client struct {
 	// Asset directory cache
 	assetTTL     time.Duration
 	assetLastRef time.Time
+	spotLastRef  time.Time
 }
 
 // ClientOption customises the Hyperliquid client.
@@ -97,6 +109,16 @@ func WithMainAddress(addr string) ClientOption {
 	}
 }
 
+// WithWebSocketURL overrides the websocket endpoint used by event streams
+// (primarily for tests and proxies).
+func WithWebSocketURL(wsURL string) ClientOption {
+	return func(c *Client) {
+		if trimmed := strings.TrimSpace(wsURL); trimmed != "" {
+			c.wsURL = trimmed
+		}
+	}
+}
+
 // WithClock overrides the time source (primarily for testing).
 func WithClock(clock func() time.Time) ClientOption {
 	return func(c *Client) {
@@ -106,6 +128,25 @@ func WithClock(clock func() time.Time) ClientOption {
 	}
 }
 
+// WithSigner signs with signer instead of a private key; NewClient then
+// ignores its privateKeyHex argument. Use it with a RemoteSigner to keep the
+// key out of the process.
+func WithSigner(signer Signer) ClientOption {
+	return func(c *Client) {
+		if signer != nil {
+			c.signer = signer
+		}
+	}
+}
+
+// WithNonceStore persists the signer's nonce high-water mark so nonces keep
+// increasing across restarts and processes that share the signer.
+func WithNonceStore(store NonceStore) ClientOption {
+	return func(c *Client) {
+		c.nonceStore = store
+	}
+}
+
 // WithDefaultSlippage configures a default slippage fraction used by helpers
 // when caller does not specify one (e.g. 0.01 = 1%).
 func WithDefaultSlippage(slippage float64) ClientOption {
@@ -146,26 +187,16 @@ func (c *Client) getInfoAddress() string {
 	return c.address
 }
 
-// NewClient constructs a Hyperliquid trading client using the provided private key.
+// NewClient constructs a Hyperliquid trading client using the provided private
+// key, or the signer set by WithSigner.
 func NewClient(privateKeyHex string, isTestnet bool, opts ...ClientOption) (*Client, error) {
-	if privateKeyHex == "" {
-		return nil, fmt.Errorf("hyperliquid: private key is required")
-	}
-
-	signer, err := NewPrivateKeySigner(privateKeyHex)
-	if err != nil {
-		return nil, fmt.Errorf("hyperliquid: create signer: %w", err)
-	}
-
-	address := signer.GetAddress()
 	client := &Client{
 		infoURL:     mainnetInfoURL,
 		exchangeURL: mainnetExchangeURL,
+		wsURL:       mainnetWSURL,
 		httpClient: &http.Client{
 			Timeout: defaultHTTPTimeout,
 		},
-		signer:       signer,
-		address:      address,
 		isTestnet:    isTestnet,
 		logger:       log.Default(),
 		clock:        time.Now,
@@ -176,10 +207,23 @@ func NewClient(privateKeyHex string, isTestnet bool, opts ...ClientOption) (*Cli
 	if isTestnet {
 		client.infoURL = testnetInfoURL
 		client.exchangeURL = testnetExchangeURL
+		client.wsURL = testnetWSURL
 	}
 	for _, opt := range opts {
 		opt(client)
 	}
+	if client.signer == nil {
+		if privateKeyHex == "" {
+			return nil, fmt.Errorf("hyperliquid: private key is required")
+		}
+		signer, err := NewPrivateKeySigner(privateKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("hyperliquid: create signer: %w", err)
+		}
+		client.signer = signer
+	}
+	address := client.signer.GetAddress()
+	client.address = address
 	if client.httpClient == nil {
 		client.httpClient = &http.Client{Timeout: defaultHTTPTimeout}
 	}
@@ -189,6 +233,10 @@ func NewClient(privateKeyHex string, isTestnet bool, opts ...ClientOption) (*Cli
 	if client.clock == nil {
 		client.clock = time.Now
 	}
+	client.nonces = nonceManagerFor(address)
+	if client.nonceStore != nil {
+		client.nonces.SetStore(client.nonceStore)
+	}
 	return client, nil
 }
 
@@ -322,6 +370,9 @@ func (c *Client) doInfoRequest(ctx context.Context, req InfoRequest, result inte
 	backoff := defaultRetryBackoff
 	var lastErr error
 	for attempt := 0; attempt < maxRetryAttempts; attempt++ {
+		if err := c.waitInfo(ctx, req.Type); err != nil {
+			return err
+		}
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.infoURL, bytes.NewReader(payload))
 		if err != nil {
 			return fmt.Errorf("hyperliquid: build info request: %w", err)
@@ -340,6 +391,7 @@ func (c *Client) doInfoRequest(ctx context.Context, req InfoRequest, result inte
 			if readErr != nil {
 				lastErr = fmt.Errorf("hyperliquid: read info response: %w", readErr)
 			} else if resp.StatusCode < http.StatusOK || resp.StatusCode >= 300 {
+				c.noteStatus(c.infoURL, resp.StatusCode)
 				lastErr = fmt.Errorf("hyperliquid: info http status %d: %s", resp.StatusCode, string(body))
 			} else if result != nil {
 				if err := json.Unmarshal(body, result); err != nil {
@@ -398,7 +450,17 @@ func (c *Client) GetVaultDetails(ctx context.Context, vaultAddress string, user
 
 // doExchangeRequest signs and submits an exchange action.
 func (c *Client) doExchangeRequest(ctx context.Context, action interface{}, result interface{}) error {
-	exchangeReq, err := c.signAction(action)
+	return c.postAction(ctx, action, c.vault, result)
+}
+
+// postAction signs action on behalf of vault ("" for the signer's own
+// account) and posts it to the exchange endpoint.
+func (c *Client) postAction(ctx context.Context, action interface{}, vault string, result interface{}) error {
+	// Wait for budget before signing so the nonce reflects the send time.
+	if err := c.waitAction(ctx, action); err != nil {
+		return err
+	}
+	exchangeReq, err := c.signAction(ctx, action, vault)
 	if err != nil {
 		return err
 	}
@@ -428,6 +490,7 @@ func (c *Client) doExchangeRequest(ctx context.Context, action interface{}, resu
 	}
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= 300 {
 		c.logf("hyperliquid: exchange error status=%d body=%s", resp.StatusCode, string(body))
+		c.noteStatus(c.exchangeURL, resp.StatusCode)
 		return fmt.Errorf("hyperliquid: exchange http status %d: %s", resp.StatusCode, string(body))
 	}
 	if result != nil {
@@ -438,14 +501,34 @@ func (c *Client) doExchangeRequest(ctx context.Context, action interface{}, resu
 	return nil
 }
 
-// signAction builds the EIP-712 payload and signs it.
-func (c *Client) signAction(action interface{}) (*ExchangeRequest, error) {
+// signAction builds the EIP-712 payload and signs it with the next nonce for
+// the client's signer.
+func (c *Client) signAction(ctx context.Context, action interface{}, vault string) (*ExchangeRequest, error) {
 	now := c.clock
 	if now == nil {
 		now = time.Now
 	}
-	nonce := now().UnixMilli()
-	exchangeReq, err := signAction(action, c.signer, nonce, c.mainAddress, c.vault, !c.isTestnet)
+	nonces := c.nonces
+	if nonces == nil {
+		nonces = nonceManagerFor(c.address)
+	}
+	nonce, err := nonces.Next(ctx, now())
+	if err != nil {
+		return nil, err
+	}
+	if a, ok := action.(approveAgentAction); ok {
+		// User-signed actions carry the request nonce in the signed fields.
+		a.Nonce = nonce
+		action = a
+	}
+	if remote, ok := c.signer.(ActionSigner); ok {
+		sig, err := remote.SignAction(ctx, action, nonce, vault, !c.isTestnet)
+		if err != nil {
+			return nil, err
+		}
+		return &ExchangeRequest{Action: action, Nonce: nonce, Signature: *sig, VaultAddress: vault}, nil
+	}
+	exchangeReq, err := signAction(action, c.signer, nonce, c.mainAddress, vault, !c.isTestnet)
 	if err != nil {
 		return nil, err
 	}
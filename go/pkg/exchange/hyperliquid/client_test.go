@@ -413,6 +413,9 @@ func TestFormatSizeAndIOCMarket(t *testing.T) {
 	sz, err := c.FormatSize(context.Background(), "BTC", 0.12349)
 	require.NoError(t, err)
 	require.Equal(t, "0.123", sz)
+	step, err := c.SizeStep(context.Background(), "BTC")
+	require.NoError(t, err)
+	require.Equal(t, "0.001", step.String())
 
 	// Verify RoundPriceToSigFigs
 	p := RoundPriceToSigFigs(50000*1.01, 5)
@@ -3,41 +3,33 @@ package hyperliquid
 import (
 	"context"
 	"fmt"
-	"math"
-	"strconv"
 
 	"nof0-api/pkg/exchange"
 )
 
-// FormatSize rounds a float quantity to the coin's szDecimals and returns a
-// normalized decimal string (no scientific notation).
+// FormatSize rounds a float quantity half up to the coin's szDecimals and
+// returns a normalized decimal string (no scientific notation).
 func (c *Client) FormatSize(ctx context.Context, coin string, qty float64) (string, error) {
 	info, err := c.GetAssetInfo(ctx, coin)
 	if err != nil {
 		return "", err
 	}
-	if qty < 0 {
-		qty = -qty
-	}
-	// round half up to szDecimals places
-	pow := math.Pow(10, float64(info.SzDecimals))
-	v := math.Round(qty*pow) / pow
-	s := strconv.FormatFloat(v, 'f', info.SzDecimals, 64)
-	// trim trailing zeros
-	for len(s) > 0 && s[len(s)-1] == '0' {
-		s = s[:len(s)-1]
-	}
-	if len(s) > 0 && s[len(s)-1] == '.' {
-		s = s[:len(s)-1]
-	}
-	if s == "" { // in case qty was 0 exactly
-		s = "0"
+	size := exchange.DecimalFromFloat(qty).Abs()
+	return size.Round(int32(info.SzDecimals), exchange.RoundHalfUp).String(), nil
+}
+
+// SizeStep returns the coin's lot step, 10^-szDecimals.
+func (c *Client) SizeStep(ctx context.Context, coin string) (exchange.Decimal, error) {
+	info, err := c.GetAssetInfo(ctx, coin)
+	if err != nil {
+		return exchange.Decimal{}, err
 	}
-	return s, nil
+	return exchange.NewDecimal(1, -int32(info.SzDecimals)), nil
 }
 
 // IOCMarket places an IOC limit order using a small slippage on the mid/mark
-// price to simulate market execution.
+// price to simulate market execution. Spot pairs ("HYPE/USDC" or "@107")
+// trade the spot book and cannot be reduce-only.
 // - slippage is a fraction, e.g. 0.01 = 1%.
 func (c *Client) IOCMarket(ctx context.Context, coin string, isBuy bool, qty float64, slippage float64, reduceOnly bool) (*exchange.OrderResponse, error) {
 	if slippage <= 0 {
@@ -55,25 +47,29 @@ func (c *Client) IOCMarket(ctx context.Context, coin string, isBuy bool, qty flo
 	if err != nil {
 		return nil, err
 	}
+	if info.IsSpot && reduceOnly {
+		return nil, fmt.Errorf("hyperliquid: reduce-only orders are not supported for spot pair %s", coin)
+	}
 	// pick a base price: prefer MidPx then MarkPx then OraclePx
 	base := firstNonEmpty(info.MidPx, info.MarkPx, info.OraclePx)
 	if base == "" {
 		return nil, fmt.Errorf("hyperliquid: missing reference price for %s", coin)
 	}
-	px, err := strconv.ParseFloat(base, 64)
-	if err != nil || !(px > 0) {
+	px, err := exchange.ParseDecimal(base)
+	if err != nil || px.Sign() <= 0 {
 		return nil, fmt.Errorf("hyperliquid: invalid reference price %q for %s", base, coin)
 	}
+	one := exchange.DecimalFromInt(1)
 	if isBuy {
-		px = px * (1 + slippage)
+		px = px.Mul(one.Add(exchange.DecimalFromFloat(slippage)))
 	} else {
-		px = px * (1 - slippage)
+		px = px.Mul(one.Sub(exchange.DecimalFromFloat(slippage)))
 	}
 	sigs := c.priceSigFigs
 	if sigs <= 0 {
 		sigs = 5
 	}
-	price := RoundPriceToSigFigs(px, sigs)
+	price := roundPrice(px, sigs, *info).String()
 	size, err := c.FormatSize(ctx, coin, qty)
 	if err != nil {
 		return nil, err
@@ -120,7 +116,11 @@ func (c *Client) PlaceTriggerReduceOnly(ctx context.Context, coin string, isBuy
 	if err != nil {
 		return err
 	}
-	tp := RoundPriceToSigFigs(triggerPx, 5)
+	info, err := c.GetAssetInfo(ctx, coin)
+	if err != nil {
+		return err
+	}
+	tp := roundPrice(exchange.DecimalFromFloat(triggerPx), 5, *info).String()
 	// For trigger orders with isMarket, use an aggressive limit price as safety
 	limitPx := aggressiveLimitPrice(isBuy)
 	ord := exchange.Order{
@@ -0,0 +1,200 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// keyFileCheckInterval bounds how often KeyFileSigner stats its file.
+const keyFileCheckInterval = time.Second
+
+// KeyFileSigner signs with the private key stored in a file and switches to a
+// new key as soon as the file is replaced, so an agent wallet can be rotated
+// (see RotateAgentKey and cmd/agent) without restarting the process. Each
+// signature uses either the old or the new key in full. A file that fails to
+// parse is logged and the previous key is kept.
+type KeyFileSigner struct {
+	path   string
+	logger *log.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	current *PrivateKeySigner
+	modTime time.Time
+	size    int64
+	checked time.Time
+}
+
+// NewKeyFileSigner loads the hex-encoded private key at path.
+func NewKeyFileSigner(path string) (*KeyFileSigner, error) {
+	s := &KeyFileSigner{path: path, logger: log.Default(), now: time.Now}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: agent key file: %w", err)
+	}
+	key, err := readKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s.current, s.modTime, s.size, s.checked = key, info.ModTime(), info.Size(), s.now()
+	return s, nil
+}
+
+// Sign signs message with the current key.
+func (s *KeyFileSigner) Sign(message []byte) (*Signature, error) {
+	return s.key().Sign(message)
+}
+
+// GetAddress returns the address of the current key.
+func (s *KeyFileSigner) GetAddress() string {
+	return s.key().GetAddress()
+}
+
+// key returns the current key, reloading the file if it changed.
+func (s *KeyFileSigner) key() *PrivateKeySigner {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.checked) < keyFileCheckInterval {
+		return s.current
+	}
+	s.checked = now
+	info, err := os.Stat(s.path)
+	if err != nil {
+		s.logger.Printf("hyperliquid: agent key file %s: %v; keeping %s", s.path, err, s.current.GetAddress())
+		return s.current
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.current
+	}
+	key, err := readKeyFile(s.path)
+	if err != nil {
+		s.logger.Printf("%v; keeping %s", err, s.current.GetAddress())
+		return s.current
+	}
+	if key.GetAddress() != s.current.GetAddress() {
+		s.logger.Printf("hyperliquid: agent key rotated from %s to %s", s.current.GetAddress(), key.GetAddress())
+	}
+	s.current, s.modTime, s.size = key, info.ModTime(), info.Size()
+	return s.current
+}
+
+func readKeyFile(path string) (*PrivateKeySigner, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: agent key file: %w", err)
+	}
+	key, err := NewPrivateKeySigner(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: agent key file %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// GenerateAgentKey creates a fresh agent wallet key, returned hex-encoded
+// with its address.
+func GenerateAgentKey() (privateKeyHex, address string, err error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return "", "", fmt.Errorf("hyperliquid: generate agent key: %w", err)
+	}
+	privateKeyHex = "0x" + hex.EncodeToString(crypto.FromECDSA(key))
+	return privateKeyHex, strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex()), nil
+}
+
+// WriteAgentKeyFile atomically replaces path with privateKeyHex, readable
+// by the owner only.
+func WriteAgentKeyFile(path, privateKeyHex string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("hyperliquid: write agent key file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("hyperliquid: write agent key file: %w", err)
+	}
+	if _, err := tmp.WriteString(privateKeyHex + "\n"); err != nil {
+		tmp.Close()
+		return fmt.Errorf("hyperliquid: write agent key file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("hyperliquid: write agent key file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("hyperliquid: write agent key file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("hyperliquid: write agent key file: %w", err)
+	}
+	return nil
+}
+
+// AgentRotation describes a completed RotateAgentKey.
+type AgentRotation struct {
+	Name       string
+	Address    string
+	ValidUntil time.Time
+	// Previous is the agent the key file held before, "" if none. It stays
+	// approved until it expires or the next rotation reuses its name.
+	Previous string
+}
+
+// agentSlotSuffixes are the two names rotations alternate between, so the
+// outgoing agent stays approved while running processes switch keys.
+var agentSlotSuffixes = [2]string{"-a", "-b"}
+
+// RotateAgentKey generates a new agent key, approves it from main (which must
+// sign with the main wallet) for validFor, then atomically writes it to
+// keyFile, where KeyFileSigners pick it up. Rotations alternate between the
+// names baseName-a and baseName-b: approving under the name the current agent
+// does not hold keeps that agent valid until every process has switched.
+func RotateAgentKey(ctx context.Context, main *Client, keyFile, baseName string, validFor time.Duration) (*AgentRotation, error) {
+	baseName = strings.TrimSpace(baseName)
+	if baseName == "" {
+		return nil, errors.New("hyperliquid: agent name is required")
+	}
+	rotation := &AgentRotation{Name: baseName + agentSlotSuffixes[0]}
+	if previous, err := readKeyFile(keyFile); err == nil {
+		rotation.Previous = previous.GetAddress()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if rotation.Previous != "" {
+		approvals, err := main.GetAgentApprovals(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range approvals {
+			if strings.EqualFold(a.Address, rotation.Previous) && strings.HasPrefix(a.Name, baseName+agentSlotSuffixes[0]) {
+				rotation.Name = baseName + agentSlotSuffixes[1]
+			}
+		}
+	}
+
+	keyHex, address, err := GenerateAgentKey()
+	if err != nil {
+		return nil, err
+	}
+	rotation.Address = address
+	rotation.ValidUntil = main.clock().Add(validFor).Truncate(time.Millisecond)
+	if err := main.ApproveAgent(ctx, address, rotation.Name, rotation.ValidUntil); err != nil {
+		return nil, err
+	}
+	if err := WriteAgentKeyFile(keyFile, keyHex); err != nil {
+		return nil, fmt.Errorf("%w (agent %s is approved; its key was not saved)", err, address)
+	}
+	return rotation, nil
+}
@@ -0,0 +1,199 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hyperliquid tracks the highest nonces per signer and rejects reused ones,
+// so every signed action needs a unique nonce. Nonces are millisecond
+// timestamps by convention; the venue accepts them within (now - 2 days,
+// now + 1 day).
+
+// NonceStore persists the highest nonce issued for a signer so nonces keep
+// increasing across restarts and across processes sharing the signer.
+type NonceStore interface {
+	// Reserve atomically records and returns a nonce for signer that is at
+	// least floor and greater than every nonce reserved before.
+	Reserve(ctx context.Context, signer string, floor int64) (int64, error)
+}
+
+// NonceManager hands out strictly increasing nonces for one signer. It is
+// safe for concurrent use; clients created with the same signer share one
+// manager (see nonceManagerFor).
+type NonceManager struct {
+	signer string
+
+	mu    sync.Mutex
+	last  int64
+	store NonceStore
+}
+
+// NewNonceManager returns a manager for signer, optionally backed by store.
+func NewNonceManager(signer string, store NonceStore) *NonceManager {
+	return &NonceManager{signer: normalizeSigner(signer), store: store}
+}
+
+// SetStore attaches a persistent store. Later nonces are reserved through it.
+func (m *NonceManager) SetStore(store NonceStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+}
+
+// Next returns a nonce that is at least now in milliseconds and greater than
+// any nonce issued before, by this manager or through its store.
+func (m *NonceManager) Next(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	nonce := now.UnixMilli()
+	if nonce <= m.last {
+		nonce = m.last + 1
+	}
+	if m.store != nil {
+		reserved, err := m.store.Reserve(ctx, m.signer, nonce)
+		if err != nil {
+			return 0, fmt.Errorf("hyperliquid: reserve nonce: %w", err)
+		}
+		if reserved < nonce {
+			return 0, fmt.Errorf("hyperliquid: nonce store returned %d below floor %d", reserved, nonce)
+		}
+		nonce = reserved
+	}
+	m.last = nonce
+	return nonce, nil
+}
+
+var (
+	nonceManagersMu sync.Mutex
+	nonceManagers   = make(map[string]*NonceManager)
+)
+
+// nonceManagerFor returns the process-wide manager for signer so clients that
+// share a key never issue the same nonce.
+func nonceManagerFor(signer string) *NonceManager {
+	key := normalizeSigner(signer)
+	nonceManagersMu.Lock()
+	defer nonceManagersMu.Unlock()
+	if m, ok := nonceManagers[key]; ok {
+		return m
+	}
+	m := NewNonceManager(key, nil)
+	nonceManagers[key] = m
+	return m
+}
+
+func normalizeSigner(signer string) string {
+	return strings.ToLower(strings.TrimSpace(signer))
+}
+
+// FileNonceStore keeps per-signer high-water marks in a JSON file. Reserve
+// holds an exclusive lock on a sibling ".lock" file (on platforms with file
+// locking), so processes on the same host sharing the file never issue the
+// same nonce; the data file is replaced atomically.
+type FileNonceStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNonceStore returns a store backed by path.
+func NewFileNonceStore(path string) *FileNonceStore {
+	return &FileNonceStore{path: path}
+}
+
+var _ NonceStore = (*FileNonceStore)(nil)
+
+// Reserve implements NonceStore.
+func (s *FileNonceStore) Reserve(ctx context.Context, signer string, floor int64) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return 0, fmt.Errorf("hyperliquid: nonce store dir: %w", err)
+	}
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return 0, fmt.Errorf("hyperliquid: lock nonce store: %w", err)
+	}
+	defer unlock()
+
+	marks, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	signer = normalizeSigner(signer)
+	nonce := floor
+	if last := marks[signer]; nonce <= last {
+		nonce = last + 1
+	}
+	marks[signer] = nonce
+	if err := s.write(marks); err != nil {
+		return 0, err
+	}
+	return nonce, nil
+}
+
+// HighWaterMark returns the highest nonce reserved for signer, or 0.
+func (s *FileNonceStore) HighWaterMark(signer string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	marks, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	return marks[normalizeSigner(signer)], nil
+}
+
+func (s *FileNonceStore) read() (map[string]int64, error) {
+	marks := make(map[string]int64)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return marks, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: read nonce store: %w", err)
+	}
+	if len(data) == 0 {
+		return marks, nil
+	}
+	if err := json.Unmarshal(data, &marks); err != nil {
+		return nil, fmt.Errorf("hyperliquid: decode nonce store %s: %w", s.path, err)
+	}
+	return marks, nil
+}
+
+func (s *FileNonceStore) write(marks map[string]int64) error {
+	data, err := json.Marshal(marks)
+	if err != nil {
+		return fmt.Errorf("hyperliquid: encode nonce store: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("hyperliquid: write nonce store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("hyperliquid: write nonce store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("hyperliquid: sync nonce store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("hyperliquid: write nonce store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("hyperliquid: replace nonce store: %w", err)
+	}
+	return nil
+}
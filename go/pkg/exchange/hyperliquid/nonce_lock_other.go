@@ -0,0 +1,9 @@
+//go:build !unix
+
+package hyperliquid
+
+// lockFile is a no-op where advisory file locks are unavailable; the store
+// then only coordinates goroutines within one process.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
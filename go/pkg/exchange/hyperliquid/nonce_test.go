@@ -0,0 +1,185 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+const nonceTestKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func TestNonceManagerStrictlyIncreasing(t *testing.T) {
+	m := NewNonceManager("0xABC", nil)
+	ctx := context.Background()
+	now := time.UnixMilli(1_700_000_000_000)
+
+	first, err := m.Next(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.UnixMilli(), first)
+	second, err := m.Next(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, first+1, second, "same millisecond bumps the nonce")
+	third, err := m.Next(ctx, now.Add(-time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, second+1, third, "a clock step backwards never reuses a nonce")
+
+	const workers, perWorker = 16, 50
+	seen := make(map[int64]struct{}, workers*perWorker)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				n, err := m.Next(ctx, now)
+				assert.NoError(t, err)
+				mu.Lock()
+				seen[n] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, workers*perWorker)
+}
+
+func TestFileNonceStorePersistsHighWaterMark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	ctx := context.Background()
+	now := time.UnixMilli(1_700_000_000_000)
+
+	before := NewNonceManager("0xabc", NewFileNonceStore(path))
+	var last int64
+	for i := 0; i < 3; i++ {
+		n, err := before.Next(ctx, now)
+		require.NoError(t, err)
+		last = n
+	}
+	mark, err := NewFileNonceStore(path).HighWaterMark("0xABC")
+	require.NoError(t, err)
+	assert.Equal(t, last, mark)
+
+	// A restarted process whose clock is behind continues above the mark.
+	after := NewNonceManager("0xabc", NewFileNonceStore(path))
+	n, err := after.Next(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, last+1, n)
+
+	// Other signers in the same file are independent.
+	other, err := NewNonceManager("0xdef", NewFileNonceStore(path)).Next(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Minute).UnixMilli(), other)
+}
+
+func TestFileNonceStoreSharedAcrossManagers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	ctx := context.Background()
+	now := time.UnixMilli(1_700_000_000_000)
+
+	// Separate managers and stores stand in for separate processes.
+	managers := []*NonceManager{
+		NewNonceManager("0xabc", NewFileNonceStore(path)),
+		NewNonceManager("0xabc", NewFileNonceStore(path)),
+		NewNonceManager("0xabc", NewFileNonceStore(path)),
+	}
+	seen := make(map[int64]struct{})
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, m := range managers {
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(m *NonceManager) {
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					n, err := m.Next(ctx, now)
+					assert.NoError(t, err)
+					mu.Lock()
+					seen[n] = struct{}{}
+					mu.Unlock()
+				}
+			}(m)
+		}
+	}
+	wg.Wait()
+	assert.Len(t, seen, 3*4*10)
+}
+
+func TestClientConcurrentActionsUseUniqueNonces(t *testing.T) {
+	var mu sync.Mutex
+	nonces := make(map[int64]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Action map[string]any `json:"action"`
+			Nonce  int64          `json:"nonce"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		nonces[req.Nonce]++
+		mu.Unlock()
+		if req.Action["type"] == "order" {
+			_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"order","data":{"statuses":[{"resting":{"oid":1}}]}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"cancel","data":{"statuses":["success"]}}}`))
+	}))
+	defer server.Close()
+
+	frozen := func() time.Time { return time.UnixMilli(1_700_000_000_000) }
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	clients := make([]*Client, 2)
+	for i := range clients {
+		// Two clients sharing a key, e.g. two traders on one API wallet.
+		c, err := NewClient(nonceTestKey, true, WithClock(frozen), WithNonceStore(NewFileNonceStore(path)))
+		require.NoError(t, err)
+		c.exchangeURL = server.URL
+		clients[i] = c
+	}
+	require.Same(t, clients[0].nonces, clients[1].nonces, "clients with one signer share a nonce manager")
+
+	ctx := context.Background()
+	order := exchange.Order{
+		Asset:     0,
+		IsBuy:     true,
+		LimitPx:   "100",
+		Sz:        "1",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}},
+	}
+	const calls = 20
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		for i := 0; i < calls; i++ {
+			wg.Add(2)
+			go func(c *Client) {
+				defer wg.Done()
+				_, err := c.PlaceOrder(ctx, order)
+				assert.NoError(t, err)
+			}(c)
+			go func(c *Client, oid int64) {
+				defer wg.Done()
+				assert.NoError(t, c.CancelOrder(ctx, 0, oid))
+			}(c, int64(i+1))
+		}
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, nonces, 2*2*calls, "every signed action carries a distinct nonce")
+	for nonce, n := range nonces {
+		assert.Equal(t, 1, n, "nonce %d reused", nonce)
+	}
+	mark, err := NewFileNonceStore(path).HighWaterMark(clients[0].address)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, mark, frozen().UnixMilli()+2*2*calls-1)
+}
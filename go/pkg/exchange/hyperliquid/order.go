@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/big"
 	"strings"
 
 	"nof0-api/pkg/exchange"
@@ -210,11 +209,8 @@ func validateOrder(order exchange.Order) error {
 }
 
 func isPositiveDecimal(value string) bool {
-	v := new(big.Rat)
-	if _, ok := v.SetString(strings.TrimSpace(value)); !ok {
-		return false
-	}
-	return v.Sign() > 0
+	v, err := exchange.ParseDecimal(value)
+	return err == nil && v.Sign() > 0
 }
 
 func isZeroDecimal(value string) bool {
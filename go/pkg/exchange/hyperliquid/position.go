@@ -3,15 +3,15 @@ package hyperliquid
 import (
 	"context"
 	"fmt"
-	"math/big"
+	"math"
 	"strings"
 
 	"nof0-api/pkg/exchange"
 )
 
-// GetPositions returns live positions by delegating to account state.
+// GetPositions returns live perp positions from the clearinghouse state.
 func (c *Client) GetPositions(ctx context.Context) ([]exchange.Position, error) {
-	state, err := c.GetAccountState(ctx)
+	state, err := c.getClearinghouseState(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +74,41 @@ func (c *Client) UpdateLeverage(ctx context.Context, asset int, isCross bool, le
 	return c.doExchangeRequest(ctx, action, nil)
 }
 
+// UpdateIsolatedMargin adds amountUSD of margin to the isolated position on
+// asset, or removes it when amountUSD is negative. isBuy must match the
+// position's side. The venue rejects removals that would push the position
+// past its initial margin requirement.
+func (c *Client) UpdateIsolatedMargin(ctx context.Context, asset int, isBuy bool, amountUSD float64) error {
+	action, err := buildUpdateIsolatedMarginAction(asset, isBuy, amountUSD)
+	if err != nil {
+		return err
+	}
+	var envelope exchangeEnvelope
+	if err := c.doExchangeRequest(ctx, action, &envelope); err != nil {
+		return err
+	}
+	if err := envelope.err(); err != nil {
+		return fmt.Errorf("hyperliquid: update isolated margin: %w", err)
+	}
+	return nil
+}
+
+func buildUpdateIsolatedMarginAction(asset int, isBuy bool, amountUSD float64) (updateIsolatedMarginAction, error) {
+	if math.IsNaN(amountUSD) || math.IsInf(amountUSD, 0) {
+		return updateIsolatedMarginAction{}, fmt.Errorf("hyperliquid: invalid isolated margin amount %v", amountUSD)
+	}
+	ntli := int64(math.Round(amountUSD * 1e6))
+	if ntli == 0 {
+		return updateIsolatedMarginAction{}, fmt.Errorf("hyperliquid: isolated margin amount must be non-zero")
+	}
+	return updateIsolatedMarginAction{
+		Type:  ActionTypeUpdateIsolatedMargin,
+		Asset: asset,
+		IsBuy: isBuy,
+		Ntli:  ntli,
+	}, nil
+}
+
 func buildCloseOrder(assetIdx int, markPx string, pos exchange.Position) (exchange.Order, bool, error) {
 	rawSize := strings.TrimSpace(pos.Szi)
 	if rawSize == "" || isZeroDecimal(rawSize) {
@@ -104,30 +139,21 @@ func buildCloseOrder(assetIdx int, markPx string, pos exchange.Position) (exchan
 const closePriceSlippage = 0.005
 
 var (
-	closeMultiplierBuy  = big.NewRat(1005, 1000)
-	closeMultiplierSell = big.NewRat(995, 1000)
+	closeMultiplierBuy  = exchange.MustParseDecimal("1.005")
+	closeMultiplierSell = exchange.MustParseDecimal("0.995")
 )
 
 func computeCloseLimit(mark string, isBuy bool) string {
-	trimmed := strings.TrimSpace(mark)
-	if trimmed != "" && isPositiveDecimal(trimmed) {
-		price := new(big.Rat)
-		if _, ok := price.SetString(trimmed); ok && price.Sign() > 0 {
-			multiplier := closeMultiplierSell
-			if isBuy {
-				multiplier = closeMultiplierBuy
-			}
-			result := new(big.Rat).Mul(price, multiplier)
-			if result.Sign() > 0 {
-				// Round to 5 significant figures for submission consistency
-				f, _ := new(big.Rat).Set(result).Float64()
-				if f > 0 {
-					return RoundPriceToSigFigs(f, 5)
-				}
-			}
-		}
+	price, err := exchange.ParseDecimal(mark)
+	if err != nil || price.Sign() <= 0 {
+		return aggressiveLimitPrice(isBuy)
+	}
+	multiplier := closeMultiplierSell
+	if isBuy {
+		multiplier = closeMultiplierBuy
 	}
-	return aggressiveLimitPrice(isBuy)
+	// Round to 5 significant figures for submission consistency
+	return price.Mul(multiplier).RoundSigFigs(5, exchange.RoundHalfUp).String()
 }
 
 func trimSign(value string) string {
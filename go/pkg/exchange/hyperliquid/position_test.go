@@ -2,11 +2,13 @@ package hyperliquid
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetPositions(t *testing.T) {
@@ -53,6 +55,41 @@ func TestGetPositions(t *testing.T) {
 		assert.Equal(t, "0.1", positions[0].Szi)
 	})
 
+	t.Run("venue_wrapped_positions", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+                    "assetPositions": [
+                        {
+                            "type": "oneWay",
+                            "position": {
+                                "coin": "ETH",
+                                "entryPx": "3000.0",
+                                "leverage": {"type": "cross", "value": 5},
+                                "positionValue": "600.0",
+                                "szi": "-0.2",
+                                "unrealizedPnl": "-4.0"
+                            }
+                        }
+                    ],
+                    "marginSummary": {"accountValue": "1000.0", "totalMarginUsed": "120.0", "totalNtlPos": "600.0", "totalRawUsd": "1600.0"},
+                    "crossMarginSummary": {"accountValue": "1000.0", "totalMarginUsed": "120.0", "totalNtlPos": "600.0", "totalRawUsd": "1600.0"}
+            }`))
+		}))
+		defer server.Close()
+
+		client, err := NewClient("0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a741b52d7c5d5095e2f", false)
+		require.NoError(t, err)
+		client.infoURL = server.URL
+
+		positions, err := client.GetPositions(context.Background())
+		require.NoError(t, err)
+		require.Len(t, positions, 1)
+		assert.Equal(t, "ETH", positions[0].Coin)
+		assert.Equal(t, "-0.2", positions[0].Szi)
+		assert.Equal(t, 5, positions[0].Leverage.Value)
+	})
+
 	t.Run("empty_positions", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -136,6 +173,39 @@ func TestUpdateLeverage(t *testing.T) {
 	})
 }
 
+func TestUpdateIsolatedMargin(t *testing.T) {
+	var actions []map[string]any
+	reply := `{"status":"ok","response":{"type":"default"}}`
+	client := newTwapTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Action map[string]any `json:"action"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		actions = append(actions, req.Action)
+		_, _ = w.Write([]byte(reply))
+	})
+	ctx := context.Background()
+
+	require.NoError(t, client.UpdateIsolatedMargin(ctx, 5, true, 12.5))
+	require.Len(t, actions, 1)
+	assert.Equal(t, "updateIsolatedMargin", actions[0]["type"])
+	assert.EqualValues(t, 5, actions[0]["asset"])
+	assert.Equal(t, true, actions[0]["isBuy"])
+	assert.EqualValues(t, 12_500_000, actions[0]["ntli"], "amount is sent in micro-USDC")
+
+	reply = `{"status":"err","response":"Insufficient margin to remove."}`
+	assert.ErrorContains(t, client.UpdateIsolatedMargin(ctx, 5, false, -3), "Insufficient margin")
+	assert.EqualValues(t, -3_000_000, actions[1]["ntli"])
+
+	assert.Error(t, client.UpdateIsolatedMargin(ctx, 5, true, 0))
+	assert.Len(t, actions, 2, "a zero amount is rejected locally")
+
+	action, err := buildUpdateIsolatedMarginAction(5, true, 1)
+	require.NoError(t, err)
+	_, err = buildEIP712Message(action, 1, "", true)
+	assert.NoError(t, err)
+}
+
 func TestDecimalsForString(t *testing.T) {
 	tests := []struct {
 		name     string
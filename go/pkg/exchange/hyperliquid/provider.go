@@ -2,10 +2,14 @@ package hyperliquid
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/vcr"
 )
 
 // Provider wraps Client to satisfy the exchange.Provider interface.
@@ -20,6 +24,8 @@ type clientAPI interface {
 	GetAccountState(ctx context.Context) (*exchange.AccountState, error)
 	GetAccountValue(ctx context.Context) (float64, error)
 	GetAssetIndex(ctx context.Context, coin string) (int, error)
+	GetFills(ctx context.Context, since time.Time) ([]exchange.Fill, error)
+	GetFundingPayments(ctx context.Context, since time.Time) ([]exchange.FundingPayment, error)
 
 	// Convenience methods used by the provider
 	IOCMarket(ctx context.Context, coin string, isBuy bool, qty float64, slippage float64, reduceOnly bool) (*exchange.OrderResponse, error)
@@ -27,16 +33,46 @@ type clientAPI interface {
 	CancelAllOrders(ctx context.Context, asset int) error
 	FormatSize(ctx context.Context, coin string, qty float64) (string, error)
 	FormatPrice(ctx context.Context, coin string, price float64) (string, error)
+	SizeStep(ctx context.Context, coin string) (exchange.Decimal, error)
 	CancelByCloid(ctx context.Context, asset int, cloid string) error
 	CancelOrdersByCloid(ctx context.Context, cancels []CancelByCloid) error
 	ModifyOrder(ctx context.Context, req ModifyOrderRequest) (*exchange.OrderResponse, error)
 	ModifyOrders(ctx context.Context, requests []ModifyOrderRequest) (*exchange.OrderResponse, error)
+	PlaceTWAP(ctx context.Context, order exchange.TWAPOrder) (*exchange.TWAPStatus, error)
+	CancelTWAP(ctx context.Context, coin string, twapID int64) error
+	GetTWAPStatus(ctx context.Context, twapID int64) (*exchange.TWAPStatus, error)
+	ScheduleCancel(ctx context.Context, at time.Time) error
+	UpdateIsolatedMargin(ctx context.Context, asset int, isBuy bool, amountUSD float64) error
 }
 
 type Provider struct {
 	client clientAPI
+
+	// Sub-accounts (see subaccount.go); nil names disable them.
+	subMu           sync.Mutex
+	subAccountNames map[string]string
+	subAccounts     map[string]*subAccountEntry
 }
 
+// Compile-time checks for the optional extensions the manager relies on.
+var (
+	_ exchange.Provider              = (*Provider)(nil)
+	_ exchange.MarketOrderer         = (*Provider)(nil)
+	_ exchange.ProtectiveOrderer     = (*Provider)(nil)
+	_ exchange.Formatter             = (*Provider)(nil)
+	_ exchange.SymbolCanceller       = (*Provider)(nil)
+	_ exchange.EventStream           = (*Provider)(nil)
+	_ exchange.FillHistory           = (*Provider)(nil)
+	_ exchange.FundingHistory        = (*Provider)(nil)
+	_ exchange.TWAPOrderer           = (*Provider)(nil)
+	_ exchange.CancelScheduler       = (*Provider)(nil)
+	_ exchange.IsolatedMarginUpdater = (*Provider)(nil)
+	_ exchange.SubAccountProvider    = (*Provider)(nil)
+	_ exchange.FundTransferer        = (*Provider)(nil)
+	_ exchange.CredentialExpirer     = (*Provider)(nil)
+	_ exchange.RateLimitSyncer       = (*Provider)(nil)
+)
+
 // NewProvider constructs a Hyperliquid exchange provider.
 func NewProvider(privateKeyHex string, isTestnet bool, opts ...ClientOption) (*Provider, error) {
 	client, err := NewClient(privateKeyHex, isTestnet, opts...)
@@ -52,13 +88,43 @@ func init() {
 		if cfg.Timeout > 0 {
 			opts = append(opts, WithHTTPClient(&http.Client{Timeout: cfg.Timeout}))
 		}
+		if c := cfg.Cassette; c != nil {
+			recorder, err := vcr.New(c.Path, vcr.Mode(c.Mode))
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, WithHTTPClient(recorder.HTTPClient(cfg.Timeout)))
+		}
 		if cfg.VaultAddress != "" {
 			opts = append(opts, WithVaultAddress(cfg.VaultAddress))
 		}
 		if cfg.MainAddress != "" {
 			opts = append(opts, WithMainAddress(cfg.MainAddress))
 		}
-		return NewProvider(cfg.PrivateKey, cfg.Testnet, opts...)
+		if cfg.NonceFile != "" {
+			opts = append(opts, WithNonceStore(NewFileNonceStore(cfg.NonceFile)))
+		}
+		if s := cfg.Signer; s != nil {
+			signer, err := NewRemoteSigner(context.Background(), s.Endpoint,
+				WithRemoteSignerToken(s.Token), WithRemoteSignerTimeout(cfg.Timeout))
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, WithSigner(signer))
+		}
+		if cfg.AgentKeyFile != "" {
+			signer, err := NewKeyFileSigner(cfg.AgentKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, WithSigner(signer))
+		}
+		provider, err := NewProvider(cfg.PrivateKey, cfg.Testnet, opts...)
+		if err != nil {
+			return nil, err
+		}
+		provider.EnableSubAccounts(cfg.SubAccounts)
+		return provider, nil
 	})
 }
 
@@ -107,6 +173,17 @@ func (p *Provider) GetAssetIndex(ctx context.Context, coin string) (int, error)
 	return p.client.GetAssetIndex(ctx, coin)
 }
 
+// GetFills returns executed fills at or after since with venue fees and
+// closed PnL.
+func (p *Provider) GetFills(ctx context.Context, since time.Time) ([]exchange.Fill, error) {
+	return p.client.GetFills(ctx, since)
+}
+
+// GetFundingPayments returns perpetual funding payments at or after since.
+func (p *Provider) GetFundingPayments(ctx context.Context, since time.Time) ([]exchange.FundingPayment, error) {
+	return p.client.GetFundingPayments(ctx, since)
+}
+
 // Convenience wrappers (not part of the generic exchange.Provider interface)
 
 // IOCMarket places an IOC order using a small price slippage as market.
@@ -157,13 +234,73 @@ func (p *Provider) ModifyOrders(ctx context.Context, requests []ModifyOrderReque
 	return p.client.ModifyOrders(ctx, requests)
 }
 
+// PlaceTWAP starts a native TWAP execution.
+func (p *Provider) PlaceTWAP(ctx context.Context, order exchange.TWAPOrder) (*exchange.TWAPStatus, error) {
+	return p.client.PlaceTWAP(ctx, order)
+}
+
+// CancelTWAP stops a running TWAP.
+func (p *Provider) CancelTWAP(ctx context.Context, coin string, twapID int64) error {
+	return p.client.CancelTWAP(ctx, coin, twapID)
+}
+
+// GetTWAPStatus polls the progress of a TWAP.
+func (p *Provider) GetTWAPStatus(ctx context.Context, twapID int64) (*exchange.TWAPStatus, error) {
+	return p.client.GetTWAPStatus(ctx, twapID)
+}
+
+// ScheduleCancel arms, pushes back or (with a zero time) clears the
+// dead-man's switch.
+func (p *Provider) ScheduleCancel(ctx context.Context, at time.Time) error {
+	return p.client.ScheduleCancel(ctx, at)
+}
+
+// UpdateIsolatedMargin tops up (positive amountUSD) or reduces (negative)
+// the margin of an isolated position.
+func (p *Provider) UpdateIsolatedMargin(ctx context.Context, asset int, isBuy bool, amountUSD float64) error {
+	return p.client.UpdateIsolatedMargin(ctx, asset, isBuy, amountUSD)
+}
+
 // FormatSize rounds a float quantity to szDecimals and returns a string.
 func (p *Provider) FormatSize(ctx context.Context, coin string, qty float64) (string, error) {
 	return p.client.FormatSize(ctx, coin, qty)
 }
 
+// SizeStep returns the coin's lot step, 10^-szDecimals.
+func (p *Provider) SizeStep(ctx context.Context, coin string) (exchange.Decimal, error) {
+	return p.client.SizeStep(ctx, coin)
+}
+
 // FormatPrice rounds and formats a price using the client's configured
 // significant figures, after verifying the asset exists.
 func (p *Provider) FormatPrice(ctx context.Context, coin string, price float64) (string, error) {
 	return p.client.FormatPrice(ctx, coin, price)
 }
+
+// eventStreamSource is satisfied by clients that can open a user event stream.
+type eventStreamSource interface {
+	NewUserEventStream(opts ...StreamOption) *UserEventStream
+}
+
+// Events subscribes to fills and order updates over the websocket API. The
+// returned channel is closed when ctx is cancelled.
+func (p *Provider) Events(ctx context.Context) (<-chan exchange.Event, error) {
+	src, ok := p.client.(eventStreamSource)
+	if !ok {
+		return nil, fmt.Errorf("hyperliquid: client does not support event streams")
+	}
+	return src.NewUserEventStream().Events(ctx)
+}
+
+// Capabilities reports the optional exchange extensions this provider
+// supports. Sub-accounts and transfers are only reported once enabled.
+func (p *Provider) Capabilities() exchange.Capabilities {
+	caps := exchange.DetectCapabilities(p)
+	p.subMu.Lock()
+	enabled := p.subAccountNames != nil
+	p.subMu.Unlock()
+	_, supported := p.client.(subAccountSource)
+	caps.SubAccounts = enabled && supported
+	caps.FundTransfers = enabled && supported
+	return caps
+}
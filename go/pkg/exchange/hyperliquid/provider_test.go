@@ -2,7 +2,9 @@ package hyperliquid
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"nof0-api/pkg/exchange"
 
@@ -64,6 +66,22 @@ func (m *MockClient) GetAssetIndex(ctx context.Context, coin string) (int, error
 	return args.Get(0).(int), args.Error(1)
 }
 
+func (m *MockClient) GetFills(ctx context.Context, since time.Time) ([]exchange.Fill, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]exchange.Fill), args.Error(1)
+}
+
+func (m *MockClient) GetFundingPayments(ctx context.Context, since time.Time) ([]exchange.FundingPayment, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]exchange.FundingPayment), args.Error(1)
+}
+
 func (m *MockClient) IOCMarket(ctx context.Context, coin string, isBuy bool, qty float64, slippage float64, reduceOnly bool) (*exchange.OrderResponse, error) {
 	args := m.Called(ctx, coin, isBuy, qty, slippage, reduceOnly)
 	return args.Get(0).(*exchange.OrderResponse), args.Error(1)
@@ -104,11 +122,47 @@ func (m *MockClient) FormatSize(ctx context.Context, coin string, qty float64) (
 	return args.Get(0).(string), args.Error(1)
 }
 
+func (m *MockClient) SizeStep(ctx context.Context, coin string) (exchange.Decimal, error) {
+	args := m.Called(ctx, coin)
+	return args.Get(0).(exchange.Decimal), args.Error(1)
+}
+
 func (m *MockClient) FormatPrice(ctx context.Context, coin string, price float64) (string, error) {
 	args := m.Called(ctx, coin, price)
 	return args.Get(0).(string), args.Error(1)
 }
 
+func (m *MockClient) PlaceTWAP(ctx context.Context, order exchange.TWAPOrder) (*exchange.TWAPStatus, error) {
+	args := m.Called(ctx, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*exchange.TWAPStatus), args.Error(1)
+}
+
+func (m *MockClient) CancelTWAP(ctx context.Context, coin string, twapID int64) error {
+	args := m.Called(ctx, coin, twapID)
+	return args.Error(0)
+}
+
+func (m *MockClient) GetTWAPStatus(ctx context.Context, twapID int64) (*exchange.TWAPStatus, error) {
+	args := m.Called(ctx, twapID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*exchange.TWAPStatus), args.Error(1)
+}
+
+func (m *MockClient) ScheduleCancel(ctx context.Context, at time.Time) error {
+	args := m.Called(ctx, at)
+	return args.Error(0)
+}
+
+func (m *MockClient) UpdateIsolatedMargin(ctx context.Context, asset int, isBuy bool, amountUSD float64) error {
+	args := m.Called(ctx, asset, isBuy, amountUSD)
+	return args.Error(0)
+}
+
 func TestNewProvider(t *testing.T) {
 	// Test successful provider creation
 	t.Run("successful_creation", func(t *testing.T) {
@@ -412,6 +466,73 @@ func TestProviderGetAssetIndex(t *testing.T) {
 	})
 }
 
+func TestProviderGetFills(t *testing.T) {
+	mockClient := &MockClient{}
+	provider := &Provider{client: mockClient}
+	ctx := context.Background()
+	since := time.UnixMilli(1_700_000_000_000)
+
+	fills := []exchange.Fill{{Coin: "ETH", Px: "2000", Sz: "1", Fee: "0.5", ClosedPnl: "12.5", Dir: "Close Short"}}
+	mockClient.On("GetFills", ctx, since).Return(fills, nil)
+
+	got, err := provider.GetFills(ctx, since)
+	assert.NoError(t, err)
+	assert.Equal(t, fills, got)
+	assert.True(t, exchange.CapabilitiesOf(provider).FillHistory)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProviderTWAP(t *testing.T) {
+	mockClient := &MockClient{}
+	provider := &Provider{client: mockClient}
+	ctx := context.Background()
+
+	order := exchange.TWAPOrder{Coin: "ETH", IsBuy: true, Sz: "2", Duration: 30 * time.Minute}
+	running := &exchange.TWAPStatus{TwapID: 9, Coin: "ETH", Status: exchange.TWAPStatusActive}
+	mockClient.On("PlaceTWAP", ctx, order).Return(running, nil)
+	mockClient.On("GetTWAPStatus", ctx, int64(9)).Return(running, nil)
+	mockClient.On("CancelTWAP", ctx, "ETH", int64(9)).Return(nil)
+
+	got, err := provider.PlaceTWAP(ctx, order)
+	assert.NoError(t, err)
+	assert.Equal(t, running, got)
+	got, err = provider.GetTWAPStatus(ctx, 9)
+	assert.NoError(t, err)
+	assert.True(t, got.Active())
+	assert.NoError(t, provider.CancelTWAP(ctx, "ETH", 9))
+	assert.True(t, exchange.CapabilitiesOf(provider).TWAPOrders)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProviderScheduleCancel(t *testing.T) {
+	mockClient := &MockClient{}
+	provider := &Provider{client: mockClient}
+	ctx := context.Background()
+	at := time.UnixMilli(1_700_000_060_000)
+
+	mockClient.On("ScheduleCancel", ctx, at).Return(nil)
+	mockClient.On("ScheduleCancel", ctx, time.Time{}).Return(errors.New("rejected"))
+
+	assert.NoError(t, provider.ScheduleCancel(ctx, at))
+	assert.Error(t, provider.ScheduleCancel(ctx, time.Time{}))
+	assert.True(t, exchange.CapabilitiesOf(provider).ScheduleCancel)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProviderUpdateIsolatedMargin(t *testing.T) {
+	mockClient := &MockClient{}
+	provider := &Provider{client: mockClient}
+	ctx := context.Background()
+
+	mockClient.On("UpdateIsolatedMargin", ctx, 5, true, 25.0).Return(nil)
+	mockClient.On("UpdateIsolatedMargin", ctx, 5, false, -10.0).Return(errors.New("insufficient margin"))
+
+	assert.NoError(t, provider.UpdateIsolatedMargin(ctx, 5, true, 25))
+	assert.Error(t, provider.UpdateIsolatedMargin(ctx, 5, false, -10))
+	assert.True(t, exchange.CapabilitiesOf(provider).IsolatedMargin)
+	mockClient.AssertExpectations(t)
+}
+
 func TestProviderIOCMarket(t *testing.T) {
 	// Create mock client
 	mockClient := &MockClient{}
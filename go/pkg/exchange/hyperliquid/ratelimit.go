@@ -0,0 +1,122 @@
+package hyperliquid
+
+import (
+	"context"
+	"net/http"
+
+	"nof0-api/pkg/ratelimit"
+)
+
+// WithRateLimiter replaces the shared per-host weight bucket, e.g. to give a
+// client its own budget when it egresses through a different IP.
+func WithRateLimiter(l *ratelimit.Limiter) ClientOption {
+	return func(c *Client) {
+		if l != nil {
+			c.rateLimiter = l
+		}
+	}
+}
+
+// WithoutRateLimit disables client-side request budgeting.
+func WithoutRateLimit() ClientOption {
+	return func(c *Client) {
+		c.rateLimitOff = true
+	}
+}
+
+func (c *Client) ipLimiter(endpoint string) *ratelimit.Limiter {
+	if c.rateLimitOff {
+		return nil
+	}
+	if c.rateLimiter != nil {
+		return c.rateLimiter
+	}
+	return ratelimit.HyperliquidIP(endpoint)
+}
+
+// waitInfo spends the weight of an info request from the account lane, ahead
+// of market-data polling and behind signed actions.
+func (c *Client) waitInfo(ctx context.Context, reqType string) error {
+	return c.ipLimiter(c.infoURL).Wait(ctx, ratelimit.LaneAccount, ratelimit.HyperliquidInfoWeight(reqType))
+}
+
+// waitAction spends the IP weight and one per-address request for a signed
+// action from the trade lane.
+func (c *Client) waitAction(ctx context.Context, action interface{}) error {
+	if err := c.ipLimiter(c.exchangeURL).Wait(ctx, ratelimit.LaneTrade, ratelimit.HyperliquidActionWeight(actionBatchLen(action))); err != nil {
+		return err
+	}
+	if c.rateLimitOff {
+		return nil
+	}
+	return ratelimit.HyperliquidAddress(c.getInfoAddress()).Wait(ctx, ratelimit.LaneTrade, 1)
+}
+
+// noteStatus drains the bucket when the venue reports it is rate limiting us,
+// so later requests back off until the budget refills.
+func (c *Client) noteStatus(endpoint string, status int) {
+	if status == http.StatusTooManyRequests {
+		c.ipLimiter(endpoint).Drain()
+	}
+}
+
+// actionBatchLen counts the orders, cancels or modifies an action carries.
+func actionBatchLen(action interface{}) int {
+	switch a := action.(type) {
+	case Action:
+		return len(a.Orders) + len(a.Cancels)
+	case *Action:
+		return len(a.Orders) + len(a.Cancels)
+	case cancelByCloidAction:
+		return len(a.Cancels)
+	case batchModifyAction:
+		return len(a.Modifies)
+	default:
+		return 1
+	}
+}
+
+// UserRateLimit is the venue's per-address request allowance.
+type UserRateLimit struct {
+	CumVlm        string `json:"cumVlm"`
+	NRequestsUsed int64  `json:"nRequestsUsed"`
+	NRequestsCap  int64  `json:"nRequestsCap"`
+}
+
+// GetUserRateLimit returns the per-address allowance of the info address.
+func (c *Client) GetUserRateLimit(ctx context.Context) (*UserRateLimit, error) {
+	var out UserRateLimit
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "userRateLimit", User: c.getInfoAddress()}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SyncAddressBudget aligns the local per-address bucket with the allowance the
+// venue reports, which grows with traded volume.
+func (c *Client) SyncAddressBudget(ctx context.Context) error {
+	if c.rateLimitOff {
+		return nil
+	}
+	limit, err := c.GetUserRateLimit(ctx)
+	if err != nil {
+		return err
+	}
+	ratelimit.HyperliquidAddress(c.getInfoAddress()).SetTokens(float64(limit.NRequestsCap - limit.NRequestsUsed))
+	return nil
+}
+
+// budgetSource is satisfied by clients that track a per-address budget.
+type budgetSource interface {
+	SyncAddressBudget(ctx context.Context) error
+}
+
+// SyncRateLimit aligns the provider's per-address request budget with the
+// venue's allowance.
+func (p *Provider) SyncRateLimit(ctx context.Context) error {
+	src, ok := p.client.(budgetSource)
+	if !ok {
+		return nil
+	}
+	return src.SyncAddressBudget(ctx)
+}
@@ -0,0 +1,68 @@
+package hyperliquid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/ratelimit"
+)
+
+func TestClientSpendsRateLimitBudget(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		if r.URL.Path == "/exchange" {
+			_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"order","data":{"statuses":[{"resting":{"oid":1}}]}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	limiter := ratelimit.NewLimiter("test", 1200, time.Minute)
+	client, err := NewClient(nonceTestKey, true, WithRateLimiter(limiter))
+	require.NoError(t, err)
+	client.infoURL = server.URL + "/info"
+	client.exchangeURL = server.URL + "/exchange"
+	ctx := context.Background()
+
+	_, err = client.GetOpenOrders(ctx)
+	require.NoError(t, err)
+	orders := make([]exchange.Order, 45)
+	for i := range orders {
+		orders[i] = exchange.Order{Asset: 0, IsBuy: true, LimitPx: "100", Sz: "1", OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}}}
+	}
+	_, err = client.PlaceOrders(ctx, orders)
+	require.NoError(t, err)
+
+	stats := limiter.Stats()
+	assert.Equal(t, ratelimit.HyperliquidInfoWeight("frontendOpenOrders"), stats.Lanes["account"].Weight)
+	assert.Equal(t, 2.0, stats.Lanes["trade"].Weight, "a 45-order batch weighs 1 + 45/40")
+
+	status = http.StatusTooManyRequests
+	_, err = client.PlaceOrder(ctx, orders[0])
+	require.Error(t, err)
+	assert.Less(t, limiter.Stats().Tokens, 1.0, "a 429 drains the bucket")
+}
+
+func TestProviderSyncsAddressBudget(t *testing.T) {
+	client := newTwapTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"cumVlm":"2500.0","nRequestsUsed":4997,"nRequestsCap":5000}`))
+	})
+	provider := &Provider{client: client}
+	ctx := context.Background()
+	// The address bucket is shared with other tests using this key.
+	bucket := ratelimit.HyperliquidAddress(client.getInfoAddress())
+	t.Cleanup(func() { bucket.SetTokens(ratelimit.HyperliquidAddressBuffer) })
+
+	require.NoError(t, provider.SyncRateLimit(ctx))
+	tokens := bucket.Stats().Tokens
+	assert.InDelta(t, 3, tokens, 0.5, "the address bucket holds the venue's remaining allowance")
+}
@@ -0,0 +1,214 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrSignRejected is returned when a signing daemon refuses an action, either
+// by policy or because the request was not authorised.
+var ErrSignRejected = errors.New("hyperliquid: signer rejected action")
+
+const (
+	defaultRemoteSignerTimeout = 5 * time.Second
+
+	signerAddressPath = "/v1/address"
+	signerSignPath    = "/v1/sign"
+)
+
+// ActionSigner signs an exchange action rather than a precomputed digest, so
+// a signer outside the process can see, and vet, what it is signing. The
+// client prefers it over Signer.Sign when a signer implements both.
+type ActionSigner interface {
+	Signer
+	SignAction(ctx context.Context, action interface{}, nonce int64, vaultAddress string, isMainnet bool) (*Signature, error)
+}
+
+// signRequest is the body of POST /v1/sign.
+type signRequest struct {
+	Action       json.RawMessage `json:"action"`
+	Nonce        int64           `json:"nonce"`
+	VaultAddress string          `json:"vaultAddress,omitempty"`
+	IsMainnet    bool            `json:"isMainnet"`
+}
+
+type signResponse struct {
+	Signature *Signature `json:"signature,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+type addressResponse struct {
+	Address string `json:"address"`
+}
+
+// RemoteSigner delegates signing to a signing daemon (see SignerServer and
+// cmd/signer) so the private key never lives in the trading process. The
+// daemon is reached over HTTP or, for endpoints of the form
+// "unix:///path/to.sock", over a Unix socket.
+type RemoteSigner struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	address    string
+}
+
+// RemoteSignerOption customises a RemoteSigner.
+type RemoteSignerOption func(*RemoteSigner)
+
+// WithRemoteSignerToken sends token as a bearer token with every request.
+func WithRemoteSignerToken(token string) RemoteSignerOption {
+	return func(s *RemoteSigner) {
+		s.token = strings.TrimSpace(token)
+	}
+}
+
+// WithRemoteSignerTimeout bounds each request to the daemon (default 5s).
+func WithRemoteSignerTimeout(timeout time.Duration) RemoteSignerOption {
+	return func(s *RemoteSigner) {
+		if timeout > 0 {
+			s.httpClient.Timeout = timeout
+		}
+	}
+}
+
+// NewRemoteSigner connects to the signing daemon at endpoint and fetches the
+// address it signs for.
+func NewRemoteSigner(ctx context.Context, endpoint string, opts ...RemoteSignerOption) (*RemoteSigner, error) {
+	baseURL, transport, err := signerTransport(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	s := &RemoteSigner{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Transport: transport, Timeout: defaultRemoteSignerTimeout},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	var resp addressResponse
+	if err := s.do(ctx, http.MethodGet, signerAddressPath, nil, &resp); err != nil {
+		return nil, fmt.Errorf("hyperliquid: remote signer address: %w", err)
+	}
+	if !common.IsHexAddress(resp.Address) {
+		return nil, fmt.Errorf("hyperliquid: remote signer returned invalid address %q", resp.Address)
+	}
+	s.address = strings.ToLower(resp.Address)
+	return s, nil
+}
+
+// Sign refuses raw digests: the daemon only signs actions it can inspect.
+func (s *RemoteSigner) Sign(message []byte) (*Signature, error) {
+	return nil, errors.New("hyperliquid: remote signer signs actions, not raw digests")
+}
+
+// GetAddress returns the address the daemon signs for.
+func (s *RemoteSigner) GetAddress() string {
+	if s == nil {
+		return ""
+	}
+	return s.address
+}
+
+// SignAction asks the daemon to sign action with nonce. Policy refusals wrap
+// ErrSignRejected.
+func (s *RemoteSigner) SignAction(ctx context.Context, action interface{}, nonce int64, vaultAddress string, isMainnet bool) (*Signature, error) {
+	raw, err := json.Marshal(action)
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: encode action for remote signer: %w", err)
+	}
+	body, err := json.Marshal(signRequest{Action: raw, Nonce: nonce, VaultAddress: vaultAddress, IsMainnet: isMainnet})
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: encode sign request: %w", err)
+	}
+	var resp signResponse
+	if err := s.do(ctx, http.MethodPost, signerSignPath, body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Signature == nil {
+		return nil, errors.New("hyperliquid: remote signer returned no signature")
+	}
+	return resp.Signature, nil
+}
+
+func (s *RemoteSigner) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("hyperliquid: build remote signer request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("hyperliquid: remote signer: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("hyperliquid: read remote signer response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var failure signResponse
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &failure) == nil && failure.Error != "" {
+			msg = failure.Error
+		}
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("%w: %s", ErrSignRejected, msg)
+		}
+		return fmt.Errorf("hyperliquid: remote signer http status %d: %s", resp.StatusCode, msg)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("hyperliquid: decode remote signer response: %w", err)
+	}
+	return nil
+}
+
+// signerTransport maps a signer endpoint to a base URL and transport; Unix
+// socket endpoints dial the socket for every request.
+func signerTransport(endpoint string) (string, http.RoundTripper, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	u, err := url.Parse(endpoint)
+	if err != nil || endpoint == "" {
+		return "", nil, fmt.Errorf("hyperliquid: invalid signer endpoint %q", endpoint)
+	}
+	switch u.Scheme {
+	case "unix":
+		path := u.Path
+		if path == "" {
+			return "", nil, fmt.Errorf("hyperliquid: signer endpoint %q has no socket path", endpoint)
+		}
+		var dialer net.Dialer
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", path)
+			},
+		}
+		return "http://signer", transport, nil
+	case "http", "https":
+		if u.Host == "" {
+			return "", nil, fmt.Errorf("hyperliquid: signer endpoint %q has no host", endpoint)
+		}
+		return strings.TrimRight(endpoint, "/"), http.DefaultTransport, nil
+	default:
+		return "", nil, fmt.Errorf("hyperliquid: unsupported signer endpoint scheme %q (want unix, http or https)", u.Scheme)
+	}
+}
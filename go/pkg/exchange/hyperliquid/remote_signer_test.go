@@ -0,0 +1,252 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+const remoteSignerTestKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082796fe3f6a4ab2ed5f8d2"
+
+func newTestSignerServer(t *testing.T, policy SignerPolicy, opts ...SignerServerOption) (*PrivateKeySigner, *httptest.Server) {
+	t.Helper()
+	key, err := NewPrivateKeySigner(remoteSignerTestKey)
+	require.NoError(t, err)
+	server := httptest.NewServer(NewSignerServer(key, policy, opts...))
+	t.Cleanup(server.Close)
+	return key, server
+}
+
+// staticMarks is a MarkSource with fixed marks.
+type staticMarks map[int]float64
+
+func (m staticMarks) MarkPrice(ctx context.Context, asset int) (float64, error) {
+	return m[asset], nil
+}
+
+func TestRemoteSignerMatchesLocalSignatures(t *testing.T) {
+	// Serve over a Unix socket; keep the path short enough for sun_path.
+	dir, err := os.MkdirTemp("", "hlsig")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	endpoint := "unix://" + filepath.Join(dir, "signer.sock")
+	listener, err := ListenSigner(endpoint)
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(dir, "signer.sock"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	key, err := NewPrivateKeySigner(remoteSignerTestKey)
+	require.NoError(t, err)
+	server := &http.Server{Handler: NewSignerServer(key, SignerPolicy{Mainnet: true, VaultAddresses: []string{
+		"0x1111111111111111111111111111111111111111",
+		"0x2222222222222222222222222222222222222222",
+		"0x3333333333333333333333333333333333333333",
+	}})}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	ctx := context.Background()
+	remote, err := NewRemoteSigner(ctx, endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, key.GetAddress(), remote.GetAddress())
+	_, err = remote.Sign([]byte("digest"))
+	assert.Error(t, err, "raw digests are never signed remotely")
+
+	order := exchange.Order{Asset: 0, IsBuy: true, LimitPx: "106770", Sz: "0.002", OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}}, Cloid: "0x00000000000000000000000000000001"}
+	stop := exchange.Order{Asset: 5, LimitPx: "140.5", Sz: "3", ReduceOnly: true, TriggerPx: "141", TriggerRel: "lte", OrderType: exchange.OrderType{Trigger: &exchange.TriggerOrderType{IsMarket: true, Tpsl: "sl"}}}
+	oid := int64(41873000001)
+
+	placeOrders, err := buildPlaceOrderAction([]exchange.Order{order, stop})
+	require.NoError(t, err)
+	cancelByCloid, err := buildCancelByCloidAction([]CancelByCloid{{Asset: 0, Cloid: order.Cloid}})
+	require.NoError(t, err)
+	modifyByOid, err := buildModifyAction(ModifyOrderRequest{Oid: &oid, Order: order})
+	require.NoError(t, err)
+	batchModify, err := buildBatchModifyAction([]ModifyOrderRequest{{Oid: &oid, Order: order}, {Cloid: order.Cloid, Order: order}})
+	require.NoError(t, err)
+	twap, err := buildTwapOrderAction(5, exchange.TWAPOrder{Coin: "SOL", IsBuy: true, Sz: "12.5", Duration: 30 * time.Minute})
+	require.NoError(t, err)
+	twapCancel, err := buildTwapCancelAction(5, 77)
+	require.NoError(t, err)
+	margin, err := buildUpdateIsolatedMarginAction(0, true, 12.5)
+	require.NoError(t, err)
+	now := time.UnixMilli(1_700_000_000_000)
+	schedule, err := buildScheduleCancelAction(now.Add(time.Minute), now)
+	require.NoError(t, err)
+	clearSchedule, err := buildScheduleCancelAction(time.Time{}, now)
+	require.NoError(t, err)
+	subTransfer, err := buildSubAccountTransferAction("0x1111111111111111111111111111111111111111", true, 25)
+	require.NoError(t, err)
+	vaultTransfer, err := buildVaultTransferAction("0x2222222222222222222222222222222222222222", false, 10)
+	require.NoError(t, err)
+
+	actions := map[string]interface{}{
+		"order":               placeOrders,
+		"cancel":              buildCancelAction([]Cancel{{Asset: 0, Oid: oid}}),
+		"cancelByCloid":       cancelByCloid,
+		"modify":              modifyByOid,
+		"batchModify":         batchModify,
+		"twapOrder":           twap,
+		"twapCancel":          twapCancel,
+		"updateMargin":        margin,
+		"scheduleCancel":      schedule,
+		"clearScheduleCancel": clearSchedule,
+		"subAccountTransfer":  subTransfer,
+		"vaultTransfer":       vaultTransfer,
+	}
+	for name, action := range actions {
+		t.Run(name, func(t *testing.T) {
+			const nonce = int64(1_700_000_000_123)
+			want, err := signAction(action, key, nonce, "", "", true)
+			require.NoError(t, err)
+			got, err := remote.SignAction(ctx, action, nonce, "", true)
+			require.NoError(t, err)
+			assert.Equal(t, want.Signature, *got)
+		})
+	}
+
+	// Vault trades hash the vault address into the digest.
+	vault := "0x3333333333333333333333333333333333333333"
+	want, err := signAction(placeOrders, key, 42, "", vault, true)
+	require.NoError(t, err)
+	got, err := remote.SignAction(ctx, placeOrders, 42, vault, true)
+	require.NoError(t, err)
+	assert.Equal(t, want.Signature, *got)
+}
+
+func TestSignerServerEnforcesPolicy(t *testing.T) {
+	_, server := newTestSignerServer(t, SignerPolicy{
+		Actions:          []ActionType{ActionTypeOrder, ActionTypeCancel, ActionTypeTwapOrder, ActionTypeSubAccountTransfer},
+		Assets:           []int{0, 5},
+		MaxOrderNotional: 1000,
+		PriceBandPct:     10,
+		MaxTransferUSD:   50,
+		VaultAddresses:   []string{"0x1111111111111111111111111111111111111111"},
+	}, WithSignerServerToken("s3cret"), WithSignerServerMarks(staticMarks{0: 100000, 5: 28}))
+	ctx := context.Background()
+
+	_, err := NewRemoteSigner(ctx, server.URL)
+	assert.ErrorIs(t, err, ErrSignRejected, "missing token")
+	_, err = NewRemoteSigner(ctx, server.URL, WithRemoteSignerToken("wrong"))
+	assert.ErrorIs(t, err, ErrSignRejected, "wrong token")
+	remote, err := NewRemoteSigner(ctx, server.URL, WithRemoteSignerToken("s3cret"))
+	require.NoError(t, err)
+
+	place := func(order exchange.Order) Action {
+		action, err := buildPlaceOrderAction([]exchange.Order{order})
+		require.NoError(t, err)
+		return action
+	}
+	gtc := exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}}
+	twap := func(sz string, reduceOnly bool) twapOrderAction {
+		action, err := buildTwapOrderAction(5, exchange.TWAPOrder{Coin: "SOL", Sz: sz, ReduceOnly: reduceOnly, Duration: 10 * time.Minute})
+		require.NoError(t, err)
+		return action
+	}
+	transfer := func(to string, usd float64) subAccountTransferAction {
+		action, err := buildSubAccountTransferAction(to, true, usd)
+		require.NoError(t, err)
+		return action
+	}
+	asset, cross := 0, true
+	leverage := Action{Type: ActionTypeUpdateLeverage, Asset: &asset, IsCross: &cross, Leverage: 3}
+
+	ioc := exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}}
+	stopLoss := exchange.OrderType{Trigger: &exchange.TriggerOrderType{IsMarket: true, Tpsl: "sl"}}
+	listed, unlisted := "0x1111111111111111111111111111111111111111", "0x4444444444444444444444444444444444444444"
+
+	cases := []struct {
+		name    string
+		action  interface{}
+		vault   string
+		mainnet bool
+		allowed bool
+	}{
+		{"within limits", place(exchange.Order{Asset: 0, IsBuy: true, LimitPx: "100000", Sz: "0.009", OrderType: gtc}), "", false, true},
+		{"notional over cap", place(exchange.Order{Asset: 0, IsBuy: true, LimitPx: "100000", Sz: "0.011", OrderType: gtc}), "", false, false},
+		{"trigger price counts", place(exchange.Order{Asset: 5, LimitPx: "29", Sz: "34.2", TriggerPx: "30", TriggerRel: "gte", OrderType: exchange.OrderType{Trigger: &exchange.TriggerOrderType{}}}), "", false, false},
+		{"notional priced at mark", place(exchange.Order{Asset: 0, LimitPx: "0.0001", Sz: "1", OrderType: ioc}), "", false, false},
+		{"limit outside band", place(exchange.Order{Asset: 0, IsBuy: true, LimitPx: "111000", Sz: "0.001", OrderType: gtc}), "", false, false},
+		{"reduce only exempt", place(exchange.Order{Asset: 0, LimitPx: "100000", Sz: "1", ReduceOnly: true, OrderType: gtc}), "", false, true},
+		{"reduce only still banded", place(exchange.Order{Asset: 0, LimitPx: "1", Sz: "1", ReduceOnly: true, OrderType: ioc}), "", false, false},
+		{"stop loss outside band", place(exchange.Order{Asset: 0, LimitPx: "70000", Sz: "1", ReduceOnly: true, TriggerPx: "80000", TriggerRel: "lte", OrderType: stopLoss}), "", false, true},
+		{"asset not allowed", place(exchange.Order{Asset: 3, IsBuy: true, LimitPx: "1", Sz: "1", OrderType: gtc}), "", false, false},
+		{"cancel any asset", buildCancelAction([]Cancel{{Asset: 9, Oid: 1}}), "", false, true},
+		{"listed vault", buildCancelAction([]Cancel{{Asset: 0, Oid: 1}}), listed, false, true},
+		{"unlisted vault", buildCancelAction([]Cancel{{Asset: 0, Oid: 1}}), unlisted, false, false},
+		{"wrong network", buildCancelAction([]Cancel{{Asset: 0, Oid: 1}}), "", true, false},
+		{"action not allowed", leverage, "", false, false},
+		{"twap under notional cap", twap("35", false), "", false, true},
+		{"twap over notional cap", twap("36", false), "", false, false},
+		{"reduce only twap", twap("100", true), "", false, true},
+		{"transfer within cap", transfer(listed, 50), "", false, true},
+		{"transfer over cap", transfer(listed, 50.01), "", false, false},
+		{"transfer to unlisted sub-account", transfer(unlisted, 1), "", false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sig, err := remote.SignAction(ctx, tc.action, 1, tc.vault, tc.mainnet)
+			if tc.allowed {
+				require.NoError(t, err)
+				assert.NotEmpty(t, sig.R)
+				return
+			}
+			assert.ErrorIs(t, err, ErrSignRejected)
+		})
+	}
+
+	_, err = remote.SignAction(ctx, map[string]interface{}{"type": "withdraw3", "amount": "1000"}, 1, "", false)
+	assert.ErrorIs(t, err, ErrSignRejected, "undecodable actions are refused")
+	_, err = remote.SignAction(ctx, map[string]interface{}{"type": "cancel", "cancels": []interface{}{}, "extra": 1}, 1, "", false)
+	assert.ErrorIs(t, err, ErrSignRejected, "unknown fields are refused")
+
+	_, unpriced := newTestSignerServer(t, SignerPolicy{MaxOrderNotional: 1000})
+	remote, err = NewRemoteSigner(ctx, unpriced.URL)
+	require.NoError(t, err)
+	_, err = remote.SignAction(ctx, place(exchange.Order{Asset: 0, IsBuy: true, LimitPx: "1", Sz: "1", OrderType: gtc}), 1, "", false)
+	assert.ErrorIs(t, err, ErrSignRejected, "capped orders are refused without reference marks")
+}
+
+func TestClientSignsThroughRemoteSigner(t *testing.T) {
+	key, signerServer := newTestSignerServer(t, SignerPolicy{Assets: []int{1}})
+	remote, err := NewRemoteSigner(context.Background(), signerServer.URL)
+	require.NoError(t, err)
+
+	var posted ExchangeRequest
+	exchangeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"order","data":{"statuses":[{"resting":{"oid":1}}]}}}`))
+	}))
+	defer exchangeServer.Close()
+
+	_, err = NewClient("", true)
+	assert.Error(t, err, "a key or a signer is required")
+	client, err := NewClient("", true, WithSigner(remote))
+	require.NoError(t, err)
+	assert.Equal(t, key.GetAddress(), client.address)
+	client.exchangeURL = exchangeServer.URL
+
+	order := exchange.Order{Asset: 1, IsBuy: true, LimitPx: "50000", Sz: "0.01", OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}}}
+	_, err = client.PlaceOrder(context.Background(), order)
+	require.NoError(t, err)
+	action, err := buildPlaceOrderAction([]exchange.Order{order})
+	require.NoError(t, err)
+	want, err := signAction(action, key, posted.Nonce, "", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, want.Signature, posted.Signature)
+
+	order.Asset = 2
+	_, err = client.PlaceOrder(context.Background(), order)
+	assert.ErrorIs(t, err, ErrSignRejected)
+}
@@ -0,0 +1,44 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MinScheduleCancelDelay is how far in the future Hyperliquid requires a
+// scheduled cancel to be.
+const MinScheduleCancelDelay = 5 * time.Second
+
+func buildScheduleCancelAction(at, now time.Time) (scheduleCancelAction, error) {
+	action := scheduleCancelAction{Type: ActionTypeScheduleCancel}
+	if at.IsZero() {
+		return action, nil
+	}
+	if at.Before(now.Add(MinScheduleCancelDelay)) {
+		return scheduleCancelAction{}, fmt.Errorf("hyperliquid: schedule cancel time %s must be at least %s in the future", at.UTC().Format(time.RFC3339), MinScheduleCancelDelay)
+	}
+	ms := at.UnixMilli()
+	action.Time = &ms
+	return action, nil
+}
+
+// ScheduleCancel arms the dead-man's switch: every resting order of the
+// account is cancelled at the given time unless ScheduleCancel is called
+// again with a later time first. A zero time clears the schedule. The venue
+// only accepts the action once the account has traded enough volume and
+// limits how many scheduled cancels may trigger per day.
+func (c *Client) ScheduleCancel(ctx context.Context, at time.Time) error {
+	action, err := buildScheduleCancelAction(at, c.clock())
+	if err != nil {
+		return err
+	}
+	var envelope exchangeEnvelope
+	if err := c.doExchangeRequest(ctx, action, &envelope); err != nil {
+		return err
+	}
+	if err := envelope.err(); err != nil {
+		return fmt.Errorf("hyperliquid: schedule cancel: %w", err)
+	}
+	return nil
+}
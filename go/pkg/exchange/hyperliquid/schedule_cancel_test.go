@@ -0,0 +1,60 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildScheduleCancelAction(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+
+	action, err := buildScheduleCancelAction(now.Add(time.Minute), now)
+	require.NoError(t, err)
+	raw, err := json.Marshal(action)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"scheduleCancel","time":1700000060000}`, string(raw))
+
+	action, err = buildScheduleCancelAction(time.Time{}, now)
+	require.NoError(t, err)
+	raw, err = json.Marshal(action)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"scheduleCancel"}`, string(raw), "a zero time clears the schedule")
+
+	_, err = buildScheduleCancelAction(now.Add(2*time.Second), now)
+	assert.Error(t, err, "the venue needs at least five seconds of notice")
+
+	_, err = buildEIP712Message(action, 1, "", true)
+	assert.NoError(t, err)
+}
+
+func TestClientScheduleCancel(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	var actions []map[string]any
+	reply := `{"status":"ok","response":{"type":"default"}}`
+	client := newTwapTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Action map[string]any `json:"action"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		actions = append(actions, req.Action)
+		_, _ = w.Write([]byte(reply))
+	})
+	client.clock = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, client.ScheduleCancel(ctx, now.Add(time.Minute)))
+	require.Len(t, actions, 1)
+	assert.Equal(t, "scheduleCancel", actions[0]["type"])
+	assert.EqualValues(t, 1_700_000_060_000, actions[0]["time"])
+
+	reply = `{"status":"err","response":"Cannot set scheduled cancel time until enough volume traded. Required: $1000000. Traded: $12.5."}`
+	assert.ErrorContains(t, client.ScheduleCancel(ctx, time.Time{}), "enough volume traded")
+	_, hasTime := actions[1]["time"]
+	assert.False(t, hasTime)
+}
@@ -0,0 +1,543 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nof0-api/pkg/exchange"
+)
+
+// SignerPolicy decides which actions a SignerServer signs. Zero values
+// disable a limit.
+type SignerPolicy struct {
+	// Mainnet is the network the server signs for; requests for the other
+	// network are refused.
+	Mainnet bool
+	// Actions lists the action types that may be signed; empty allows every
+	// type the server can decode except approveAgent, which must be listed.
+	Actions []ActionType
+	// Assets lists the asset ids that orders, modifies, TWAPs and leverage or
+	// isolated margin updates may touch; empty allows all. Cancels are
+	// always allowed.
+	Assets []int
+	// MaxOrderNotional caps the notional of each order, modify or TWAP, in
+	// USD: size × the server's reference mark, or × the order's own price
+	// when that is higher. Reduce-only orders are exempt.
+	MaxOrderNotional float64
+	// PriceBandPct refuses orders and modifies priced more than this
+	// percentage away from the reference mark. Reduce-only trigger orders
+	// (stop-loss and take-profit) are exempt.
+	PriceBandPct float64
+	// MaxTransferUSD caps each sub-account or vault transfer and isolated
+	// margin change.
+	MaxTransferUSD float64
+	// VaultAddresses lists the vaults and sub-accounts the server may act
+	// for, both as a request's vault address and as a transfer target; any
+	// other address is refused, so an empty list only signs for the key's
+	// own account.
+	VaultAddresses []string
+}
+
+// MarkSource supplies the reference marks a SignerServer prices orders
+// against, by asset id. They must come from the server's own view of the
+// market, never from the request being checked.
+type MarkSource interface {
+	MarkPrice(ctx context.Context, asset int) (float64, error)
+}
+
+// SignerServer is the signing daemon behind RemoteSigner. It decodes each
+// action into the client's own types, checks it against a SignerPolicy and
+// only then hashes and signs it, so the signature always covers exactly the
+// action that passed the policy.
+//
+// It serves GET /v1/address and POST /v1/sign.
+type SignerServer struct {
+	signer  Signer
+	policy  SignerPolicy
+	actions map[ActionType]bool
+	assets  map[int]bool
+	vaults  map[string]bool
+	marks   MarkSource
+	token   string
+	logger  *log.Logger
+}
+
+// SignerServerOption customises a SignerServer.
+type SignerServerOption func(*SignerServer)
+
+// WithSignerServerToken requires requests to carry token as a bearer token.
+func WithSignerServerToken(token string) SignerServerOption {
+	return func(s *SignerServer) {
+		s.token = strings.TrimSpace(token)
+	}
+}
+
+// WithSignerServerMarks sets the reference marks for the notional cap and
+// the price band. Without them, orders subject to either are refused.
+func WithSignerServerMarks(marks MarkSource) SignerServerOption {
+	return func(s *SignerServer) {
+		s.marks = marks
+	}
+}
+
+// WithSignerServerLogger sets the audit logger (defaults to log.Default()).
+func WithSignerServerLogger(logger *log.Logger) SignerServerOption {
+	return func(s *SignerServer) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// NewSignerServer returns a server signing with signer under policy.
+func NewSignerServer(signer Signer, policy SignerPolicy, opts ...SignerServerOption) *SignerServer {
+	s := &SignerServer{signer: signer, policy: policy, logger: log.Default()}
+	if len(policy.Actions) > 0 {
+		s.actions = make(map[ActionType]bool, len(policy.Actions))
+		for _, t := range policy.Actions {
+			s.actions[t] = true
+		}
+	}
+	if len(policy.Assets) > 0 {
+		s.assets = make(map[int]bool, len(policy.Assets))
+		for _, a := range policy.Assets {
+			s.assets[a] = true
+		}
+	}
+	s.vaults = make(map[string]bool, len(policy.VaultAddresses))
+	for _, addr := range policy.VaultAddresses {
+		s.vaults[strings.ToLower(strings.TrimSpace(addr))] = true
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *SignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+			writeSignerError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+	}
+	switch {
+	case r.URL.Path == signerAddressPath && r.Method == http.MethodGet:
+		writeSignerJSON(w, http.StatusOK, addressResponse{Address: s.signer.GetAddress()})
+	case r.URL.Path == signerSignPath && r.Method == http.MethodPost:
+		s.handleSign(w, r)
+	default:
+		writeSignerError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *SignerServer) handleSign(w http.ResponseWriter, r *http.Request) {
+	var req signRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeSignerError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	action, actionType, err := decodeAction(req.Action)
+	if err != nil {
+		s.logger.Printf("signer: refused nonce=%d: %v", req.Nonce, err)
+		writeSignerError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err := s.check(r.Context(), action, actionType, req.VaultAddress, req.IsMainnet); err != nil {
+		s.logger.Printf("signer: refused %s nonce=%d: %v", actionType, req.Nonce, err)
+		writeSignerError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	digest, err := actionDigest(action, req.Nonce, req.VaultAddress, req.IsMainnet)
+	if err != nil {
+		writeSignerError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sig, err := s.signer.Sign(digest)
+	if err != nil {
+		writeSignerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.logger.Printf("signer: signed %s nonce=%d vault=%q", actionType, req.Nonce, req.VaultAddress)
+	writeSignerJSON(w, http.StatusOK, signResponse{Signature: sig})
+}
+
+// check applies the policy to a decoded action.
+func (s *SignerServer) check(ctx context.Context, action interface{}, actionType ActionType, vault string, isMainnet bool) error {
+	if isMainnet != s.policy.Mainnet {
+		return fmt.Errorf("network mismatch: signer is configured for %s", networkName(s.policy.Mainnet))
+	}
+	if vault != "" {
+		if err := s.checkVault(vault); err != nil {
+			return err
+		}
+	}
+	if s.actions != nil && !s.actions[actionType] {
+		return fmt.Errorf("action %s is not allowed", actionType)
+	}
+	if actionType == ActionTypeApproveAgent && s.actions == nil {
+		// Approving an agent hands out trading rights; never do it by default.
+		return fmt.Errorf("action %s must be allowed explicitly", actionType)
+	}
+	switch a := action.(type) {
+	case Action:
+		if a.Asset != nil {
+			if err := s.checkAsset(*a.Asset); err != nil {
+				return err
+			}
+		}
+		for _, o := range a.Orders {
+			if err := s.checkOrder(ctx, o); err != nil {
+				return err
+			}
+		}
+	case modifyAction:
+		return s.checkOrder(ctx, a.Order)
+	case batchModifyAction:
+		for _, m := range a.Modifies {
+			if err := s.checkOrder(ctx, m.Order); err != nil {
+				return err
+			}
+		}
+	case twapOrderAction:
+		if err := s.checkAsset(a.Twap.Asset); err != nil {
+			return err
+		}
+		if s.policy.MaxOrderNotional > 0 && !a.Twap.ReduceOnly {
+			mark, err := s.referenceMark(ctx, a.Twap.Asset)
+			if err != nil {
+				return err
+			}
+			return s.checkNotional(a.Twap.Sz, mark)
+		}
+	case updateIsolatedMarginAction:
+		if err := s.checkAsset(a.Asset); err != nil {
+			return err
+		}
+		return s.checkTransfer(a.Ntli)
+	case subAccountTransferAction:
+		if err := s.checkVault(a.SubAccountUser); err != nil {
+			return err
+		}
+		return s.checkTransfer(a.Usd)
+	case vaultTransferAction:
+		if err := s.checkVault(a.VaultAddress); err != nil {
+			return err
+		}
+		return s.checkTransfer(a.Usd)
+	}
+	return nil
+}
+
+func (s *SignerServer) checkVault(addr string) error {
+	if !s.vaults[strings.ToLower(strings.TrimSpace(addr))] {
+		return fmt.Errorf("vault address %s is not allowed", addr)
+	}
+	return nil
+}
+
+func (s *SignerServer) checkAsset(asset int) error {
+	if s.assets != nil && !s.assets[asset] {
+		return fmt.Errorf("asset %d is not allowed", asset)
+	}
+	return nil
+}
+
+// checkOrder prices o against the server's reference mark, so an order
+// cannot shrink its notional with a far-off limit price.
+func (s *SignerServer) checkOrder(ctx context.Context, o orderPayload) error {
+	if err := s.checkAsset(o.Asset); err != nil {
+		return err
+	}
+	trigger := o.OrderType.Trigger
+	banded := s.policy.PriceBandPct > 0 && !(o.ReduceOnly && trigger != nil)
+	capped := s.policy.MaxOrderNotional > 0 && !o.ReduceOnly
+	if !banded && !capped {
+		return nil
+	}
+	mark, err := s.referenceMark(ctx, o.Asset)
+	if err != nil {
+		return err
+	}
+	px, err := parseSignerPrice("order price", o.LimitPx)
+	if err != nil {
+		return err
+	}
+	prices := []float64{px}
+	if trigger != nil {
+		triggerPx, err := parseSignerPrice("trigger price", trigger.TriggerPx)
+		if err != nil {
+			return err
+		}
+		// A market trigger's limit is only its slippage cap.
+		if trigger.IsMarket {
+			prices = prices[:0]
+		}
+		prices = append(prices, triggerPx)
+	}
+	if banded {
+		for _, p := range prices {
+			if deviation := math.Abs(p-mark) / mark * 100; deviation > s.policy.PriceBandPct {
+				return fmt.Errorf("order price %.8g is %.2f%% from mark %.8g, outside band %.2f%%", p, deviation, mark, s.policy.PriceBandPct)
+			}
+		}
+	}
+	if !capped {
+		return nil
+	}
+	notionalPx := mark
+	for _, p := range prices {
+		notionalPx = math.Max(notionalPx, p)
+	}
+	return s.checkNotional(o.Sz, notionalPx)
+}
+
+// checkNotional caps sz × px against MaxOrderNotional.
+func (s *SignerServer) checkNotional(sz string, px float64) error {
+	size, err := exchange.ParseDecimal(sz)
+	if err != nil {
+		return fmt.Errorf("order size %q: %w", sz, err)
+	}
+	if notional := size.Abs().Float64() * px; notional > s.policy.MaxOrderNotional {
+		return fmt.Errorf("order notional %.2f exceeds max %.2f", notional, s.policy.MaxOrderNotional)
+	}
+	return nil
+}
+
+// referenceMark returns the server's own mark for asset.
+func (s *SignerServer) referenceMark(ctx context.Context, asset int) (float64, error) {
+	if s.marks == nil {
+		return 0, errors.New("no reference marks configured to check the order against")
+	}
+	mark, err := s.marks.MarkPrice(ctx, asset)
+	if err != nil {
+		return 0, fmt.Errorf("reference mark for asset %d: %w", asset, err)
+	}
+	if !(mark > 0) || math.IsInf(mark, 0) {
+		return 0, fmt.Errorf("no reference mark for asset %d", asset)
+	}
+	return mark, nil
+}
+
+func parseSignerPrice(what, raw string) (float64, error) {
+	d, err := exchange.ParseDecimal(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q: %w", what, raw, err)
+	}
+	return d.Float64(), nil
+}
+
+// checkTransfer caps a USDC amount expressed in micro-units (× 1e6).
+func (s *SignerServer) checkTransfer(micros int64) error {
+	if s.policy.MaxTransferUSD <= 0 {
+		return nil
+	}
+	if usd := math.Abs(float64(micros)) / 1e6; usd > s.policy.MaxTransferUSD {
+		return fmt.Errorf("transfer %.2f exceeds max %.2f", usd, s.policy.MaxTransferUSD)
+	}
+	return nil
+}
+
+// ClientMarks is a MarkSource reading perpetual marks from the venue's
+// metaAndAssetCtxs through its own client, refetched once older than maxAge.
+type ClientMarks struct {
+	client *Client
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	marks   []float64 // by asset id
+	fetched time.Time
+}
+
+// NewClientMarks returns marks fetched through client; maxAge <= 0 defaults
+// to two seconds.
+func NewClientMarks(client *Client, maxAge time.Duration) *ClientMarks {
+	if maxAge <= 0 {
+		maxAge = 2 * time.Second
+	}
+	return &ClientMarks{client: client, maxAge: maxAge}
+}
+
+// MarkPrice implements MarkSource.
+func (m *ClientMarks) MarkPrice(ctx context.Context, asset int) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.marks == nil || time.Since(m.fetched) > m.maxAge {
+		var resp MetaAndAssetCtxsResponse
+		if err := m.client.doInfoRequest(ctx, InfoRequest{Type: "metaAndAssetCtxs"}, &resp); err != nil {
+			return 0, err
+		}
+		marks := make([]float64, len(resp.AssetCtxs))
+		for i, assetCtx := range resp.AssetCtxs {
+			marks[i], _ = strconv.ParseFloat(assetCtx.MarkPx, 64)
+		}
+		m.marks, m.fetched = marks, time.Now()
+	}
+	if asset < 0 || asset >= len(m.marks) || m.marks[asset] <= 0 {
+		return 0, fmt.Errorf("hyperliquid: no mark for asset %d", asset)
+	}
+	return m.marks[asset], nil
+}
+
+// decodeAction decodes raw into the type the client built it from, so it
+// re-encodes to the same msgpack bytes. Unknown types and fields are refused:
+// the server cannot vet what it cannot decode.
+func decodeAction(raw json.RawMessage) (interface{}, ActionType, error) {
+	var head struct {
+		Type ActionType `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, "", fmt.Errorf("decode action: %w", err)
+	}
+	var err error
+	var action interface{}
+	switch head.Type {
+	case ActionTypeOrder, ActionTypeCancel, ActionTypeCancelAll, ActionTypeUpdateLeverage:
+		var a Action
+		err = decodeStrict(raw, &a)
+		action = a
+	case ActionTypeCancelByCloid:
+		var a cancelByCloidAction
+		err = decodeStrict(raw, &a)
+		action = a
+	case ActionTypeModify:
+		var a modifyAction
+		if err = decodeStrict(raw, &a); err == nil {
+			a.Oid, err = normaliseOid(a.Oid)
+		}
+		action = a
+	case ActionTypeBatchModify:
+		var a batchModifyAction
+		err = decodeStrict(raw, &a)
+		for i := range a.Modifies {
+			if err != nil {
+				break
+			}
+			a.Modifies[i].Oid, err = normaliseOid(a.Modifies[i].Oid)
+		}
+		action = a
+	case ActionTypeTwapOrder:
+		var a twapOrderAction
+		err = decodeStrict(raw, &a)
+		action = a
+	case ActionTypeTwapCancel:
+		var a twapCancelAction
+		err = decodeStrict(raw, &a)
+		action = a
+	case ActionTypeUpdateIsolatedMargin:
+		var a updateIsolatedMarginAction
+		err = decodeStrict(raw, &a)
+		action = a
+	case ActionTypeSubAccountTransfer:
+		var a subAccountTransferAction
+		err = decodeStrict(raw, &a)
+		action = a
+	case ActionTypeVaultTransfer:
+		var a vaultTransferAction
+		err = decodeStrict(raw, &a)
+		action = a
+	case ActionTypeScheduleCancel:
+		var a scheduleCancelAction
+		err = decodeStrict(raw, &a)
+		action = a
+	case ActionTypeApproveAgent:
+		var a approveAgentAction
+		err = decodeStrict(raw, &a)
+		action = a
+	default:
+		return nil, head.Type, fmt.Errorf("unsupported action type %q", head.Type)
+	}
+	if err != nil {
+		return nil, head.Type, fmt.Errorf("decode %s action: %w", head.Type, err)
+	}
+	return action, head.Type, nil
+}
+
+func decodeStrict(raw json.RawMessage, out interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
+
+// normaliseOid turns a decoded numeric oid back into the int64 the client
+// encoded; cloids stay strings.
+func normaliseOid(oid interface{}) (interface{}, error) {
+	switch v := oid.(type) {
+	case json.Number:
+		return v.Int64()
+	case string:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("invalid oid %v", oid)
+	}
+}
+
+func networkName(mainnet bool) string {
+	if mainnet {
+		return "mainnet"
+	}
+	return "testnet"
+}
+
+func writeSignerJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeSignerError(w http.ResponseWriter, status int, msg string) {
+	writeSignerJSON(w, status, signResponse{Error: msg})
+}
+
+// ListenSigner opens the listener a SignerServer serves on: a Unix socket for
+// "unix:///path/to.sock" (replacing a stale socket file and restricting it
+// to the owner) or TCP for "http://host:port".
+func ListenSigner(endpoint string) (net.Listener, error) {
+	u, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: invalid signer endpoint %q", endpoint)
+	}
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return nil, fmt.Errorf("hyperliquid: signer endpoint %q has no socket path", endpoint)
+		}
+		if err := os.Remove(u.Path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("hyperliquid: remove stale signer socket: %w", err)
+		}
+		ln, err := net.Listen("unix", u.Path)
+		if err != nil {
+			return nil, fmt.Errorf("hyperliquid: listen on signer socket: %w", err)
+		}
+		if err := os.Chmod(u.Path, 0o600); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("hyperliquid: restrict signer socket: %w", err)
+		}
+		return ln, nil
+	case "http":
+		ln, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("hyperliquid: listen on %s: %w", u.Host, err)
+		}
+		return ln, nil
+	default:
+		return nil, fmt.Errorf("hyperliquid: unsupported signer listen scheme %q (want unix or http)", u.Scheme)
+	}
+}
@@ -0,0 +1,150 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"nof0-api/pkg/exchange"
+)
+
+// SpotAssetOffset is added to a spot pair's index to form the asset id used
+// in order actions. Perp asset ids stay below it.
+const SpotAssetOffset = 10000
+
+// spotMaxPriceDecimals bounds spot price decimals together with the base
+// token's szDecimals (perps use 6).
+const spotMaxPriceDecimals = 8
+
+// IsSpotAsset reports whether asset is a spot asset id.
+func IsSpotAsset(asset int) bool { return asset >= SpotAssetOffset }
+
+// isSpotSymbol reports whether key names a spot pair, either as "BASE/QUOTE"
+// or by its "@<index>" wire name. Bare coins always resolve to perps.
+func isSpotSymbol(key string) bool {
+	return strings.Contains(key, "/") || strings.HasPrefix(key, "@")
+}
+
+// GetSpotMetaAndAssetCtxs returns spot tokens, pairs and per-pair prices.
+func (c *Client) GetSpotMetaAndAssetCtxs(ctx context.Context) (*SpotMetaAndAssetCtxsResponse, error) {
+	var resp SpotMetaAndAssetCtxsResponse
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "spotMetaAndAssetCtxs"}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetSpotBalances returns the spot token balances of the info address.
+func (c *Client) GetSpotBalances(ctx context.Context) ([]SpotBalance, error) {
+	infoAddr := c.getInfoAddress()
+	if infoAddr == "" {
+		return nil, fmt.Errorf("hyperliquid: client address unavailable")
+	}
+	var state SpotState
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "spotClearinghouseState", User: infoAddr}, &state); err != nil {
+		return nil, err
+	}
+	return state.Balances, nil
+}
+
+// spotAssetInfo resolves a spot pair by "BASE/QUOTE" or wire name, loading
+// the spot directory on first use and whenever the asset TTL expires.
+func (c *Client) spotAssetInfo(ctx context.Context, key string) (AssetInfo, error) {
+	c.assetMu.RLock()
+	last := c.spotLastRef
+	info, ok := c.spotInfo[key]
+	c.assetMu.RUnlock()
+	now := time.Now
+	if c.clock != nil {
+		now = c.clock
+	}
+	stale := c.assetTTL > 0 && now().Sub(last) > c.assetTTL
+	if ok && !stale {
+		return info, nil
+	}
+	if err := c.refreshSpotDirectory(ctx); err != nil {
+		if ok {
+			return info, nil
+		}
+		return AssetInfo{}, err
+	}
+	c.assetMu.RLock()
+	info, ok = c.spotInfo[key]
+	c.assetMu.RUnlock()
+	if !ok {
+		return AssetInfo{}, fmt.Errorf("hyperliquid: spot pair %s not found", key)
+	}
+	return info, nil
+}
+
+func (c *Client) refreshSpotDirectory(ctx context.Context) error {
+	resp, err := c.GetSpotMetaAndAssetCtxs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(resp.Universe) == 0 {
+		return fmt.Errorf("hyperliquid: spotMetaAndAssetCtxs response contained no pairs")
+	}
+	tokens := make(map[int]SpotToken, len(resp.Tokens))
+	for _, token := range resp.Tokens {
+		tokens[token.Index] = token
+	}
+	ctxs := make(map[string]SpotAssetCtx, len(resp.AssetCtxs))
+	for _, assetCtx := range resp.AssetCtxs {
+		ctxs[assetCtx.Coin] = assetCtx
+	}
+
+	info := make(map[string]AssetInfo, 2*len(resp.Universe))
+	display := make(map[string]AssetInfo, len(resp.Universe))
+	for i, pair := range resp.Universe {
+		if len(pair.Tokens) != 2 || strings.TrimSpace(pair.Name) == "" {
+			continue
+		}
+		base, okBase := tokens[pair.Tokens[0]]
+		quote, okQuote := tokens[pair.Tokens[1]]
+		if !okBase || !okQuote {
+			continue
+		}
+		assetCtx, ok := ctxs[pair.Name]
+		if !ok && i < len(resp.AssetCtxs) {
+			assetCtx = resp.AssetCtxs[i]
+		}
+		entry := AssetInfo{
+			Name:       pair.Name,
+			SzDecimals: base.SzDecimals,
+			Index:      SpotAssetOffset + pair.Index,
+			MarkPx:     assetCtx.MarkPx,
+			MidPx:      assetCtx.MidPx,
+			IsSpot:     true,
+		}
+		info[canonicalAssetKey(pair.Name)] = entry
+		// Token names are not unique, so the canonical pair claims its
+		// "BASE/QUOTE" name first.
+		name := canonicalAssetKey(base.Name + "/" + quote.Name)
+		if _, taken := display[name]; !taken || pair.IsCanonical {
+			display[name] = entry
+		}
+	}
+	for name, entry := range display {
+		if _, taken := info[name]; !taken {
+			info[name] = entry
+		}
+	}
+
+	c.assetMu.Lock()
+	c.spotInfo = info
+	if c.clock != nil {
+		c.spotLastRef = c.clock()
+	} else {
+		c.spotLastRef = time.Now()
+	}
+	c.assetMu.Unlock()
+	return nil
+}
+
+// formatSpotPrice rounds price to sigfigs significant figures and at most
+// spotMaxPriceDecimals-szDecimals decimals.
+func formatSpotPrice(price float64, sigfigs, szDecimals int) string {
+	return roundPrice(exchange.DecimalFromFloat(price), sigfigs, AssetInfo{IsSpot: true, SzDecimals: szDecimals}).String()
+}
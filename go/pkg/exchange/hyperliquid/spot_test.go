@@ -0,0 +1,137 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpotMeta = `[
+  {
+    "tokens": [
+      {"name": "USDC", "szDecimals": 8, "weiDecimals": 8, "index": 0, "tokenId": "0x6d1e7cde53ba9467b783cb7c530ce054", "isCanonical": true},
+      {"name": "PURR", "szDecimals": 0, "weiDecimals": 5, "index": 1, "tokenId": "0xc1fb593aeffbeb02f85e0308e9956a90", "isCanonical": true},
+      {"name": "HYPE", "szDecimals": 2, "weiDecimals": 8, "index": 150, "tokenId": "0x0d01dc56dcaaca66ad901c959b4011ec", "isCanonical": false}
+    ],
+    "universe": [
+      {"name": "PURR/USDC", "tokens": [1, 0], "index": 0, "isCanonical": true},
+      {"name": "@107", "tokens": [150, 0], "index": 107, "isCanonical": false}
+    ]
+  },
+  [
+    {"coin": "PURR/USDC", "markPx": "0.1234", "midPx": "0.12345", "prevDayPx": "0.12", "dayNtlVlm": "1000"},
+    {"coin": "@107", "markPx": "25.5", "midPx": "25.512345", "prevDayPx": "24.9", "dayNtlVlm": "5000000"}
+  ]
+]`
+
+// spotServer answers spot info requests and records exchange actions.
+type spotServer struct {
+	actions   []map[string]any
+	types     []string
+	spotFails bool
+}
+
+func (s *spotServer) handle(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if action, ok := req["action"].(map[string]any); ok {
+			s.actions = append(s.actions, action)
+			_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"order","data":{"statuses":[{"filled":{"totalSz":"1.23","avgPx":"25.6","oid":1}}]}}}`))
+			return
+		}
+		reqType, _ := req["type"].(string)
+		s.types = append(s.types, reqType)
+		switch reqType {
+		case "spotMetaAndAssetCtxs":
+			_, _ = w.Write([]byte(testSpotMeta))
+		case "spotClearinghouseState":
+			if s.spotFails {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`{"balances":[{"coin":"USDC","token":0,"total":"120.5","hold":"20","entryNtl":"0.0"},{"coin":"HYPE","token":150,"total":"3.2","hold":"0","entryNtl":"80.1"}]}`))
+		case "clearinghouseState":
+			_, _ = w.Write([]byte(`{"marginSummary":{"accountValue":"1000","totalMarginUsed":"0"},"assetPositions":[]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}
+}
+
+func TestSpotAssetResolution(t *testing.T) {
+	srv := &spotServer{}
+	client := newTwapTestClient(t, srv.handle(t))
+	ctx := context.Background()
+
+	for symbol, want := range map[string]int{"HYPE/USDC": 10107, "@107": 10107, "purr/usdc": 10000} {
+		idx, err := client.GetAssetIndex(ctx, symbol)
+		require.NoError(t, err, symbol)
+		assert.Equal(t, want, idx, symbol)
+		assert.True(t, IsSpotAsset(idx))
+	}
+	info, err := client.GetAssetInfo(ctx, "HYPE/USDC")
+	require.NoError(t, err)
+	assert.Equal(t, AssetInfo{Name: "@107", SzDecimals: 2, Index: 10107, MarkPx: "25.5", MidPx: "25.512345", IsSpot: true}, *info)
+	assert.Equal(t, []string{"spotMetaAndAssetCtxs"}, srv.types, "the spot directory is cached")
+
+	idx, err := client.GetAssetIndex(ctx, "SOL")
+	require.NoError(t, err)
+	assert.False(t, IsSpotAsset(idx), "bare coins stay perps")
+
+	_, err = client.GetAssetIndex(ctx, "DOGE/USDC")
+	assert.ErrorContains(t, err, "spot pair DOGE/USDC not found")
+}
+
+func TestSpotIOCMarket(t *testing.T) {
+	srv := &spotServer{}
+	client := newTwapTestClient(t, srv.handle(t))
+	ctx := context.Background()
+
+	_, err := client.IOCMarket(ctx, "HYPE/USDC", true, 1.234, 0.01, false)
+	require.NoError(t, err)
+	require.Len(t, srv.actions, 1)
+	orders := srv.actions[0]["orders"].([]any)
+	order := orders[0].(map[string]any)
+	assert.EqualValues(t, 10107, order["a"])
+	assert.Equal(t, "25.767", order["p"])
+	assert.Equal(t, "1.23", order["s"])
+	assert.Equal(t, false, order["r"])
+
+	_, err = client.IOCMarket(ctx, "HYPE/USDC", false, 1, 0.01, true)
+	assert.ErrorContains(t, err, "reduce-only")
+	assert.Len(t, srv.actions, 1)
+}
+
+func TestAccountStateIncludesSpotBalances(t *testing.T) {
+	srv := &spotServer{}
+	client := newTwapTestClient(t, srv.handle(t))
+	ctx := context.Background()
+
+	state, err := client.GetAccountState(ctx)
+	require.NoError(t, err)
+	require.Len(t, state.SpotBalances, 2)
+	assert.Equal(t, SpotBalance{Coin: "HYPE", Token: 150, Total: "3.2", Hold: "0", EntryNtl: "80.1"}, state.SpotBalances[1])
+
+	srv.types = nil
+	_, err = client.GetPositions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"clearinghouseState"}, srv.types, "positions skip the spot ledger")
+
+	srv.spotFails = true
+	state, err = client.GetAccountState(ctx)
+	require.NoError(t, err, "a spot outage does not hide the perp state")
+	assert.Equal(t, "1000", state.MarginSummary.AccountValue)
+	assert.Empty(t, state.SpotBalances)
+}
+
+func TestFormatSpotPrice(t *testing.T) {
+	assert.Equal(t, "25.767", formatSpotPrice(25.76746845, 5, 2))
+	assert.Equal(t, "0.00012346", formatSpotPrice(0.000123456, 5, 0))
+	assert.Equal(t, "0.0001", formatSpotPrice(0.000123456, 5, 4))
+	assert.Equal(t, "12346", formatSpotPrice(12345.6, 5, 8))
+}
@@ -0,0 +1,126 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"nof0-api/pkg/exchange"
+)
+
+// subAccountSource is satisfied by clients that can list, fund and act on
+// behalf of sub-accounts of the signer's master account.
+type subAccountSource interface {
+	getInfoAddress() string
+	GetSubAccounts(ctx context.Context, user string) ([]SubAccount, error)
+	SubAccountTransfer(ctx context.Context, subAccountUser string, isDeposit bool, amountUSD float64) error
+	VaultTransfer(ctx context.Context, vaultAddress string, isDeposit bool, amountUSD float64) error
+	forAccount(address string) *Client
+}
+
+// EnableSubAccounts lets the provider open per-owner sub-accounts (see
+// SubAccount). mapping maps owner ids, e.g. trader ids, to Hyperliquid
+// sub-account names or addresses; ids without an entry are looked up by
+// name. A nil mapping disables sub-accounts.
+func (p *Provider) EnableSubAccounts(mapping map[string]string) {
+	p.subMu.Lock()
+	defer p.subMu.Unlock()
+	if mapping == nil {
+		p.subAccountNames = nil
+		return
+	}
+	p.subAccountNames = make(map[string]string, len(mapping))
+	for id, target := range mapping {
+		p.subAccountNames[strings.TrimSpace(id)] = strings.TrimSpace(target)
+	}
+}
+
+// SubAccount returns a provider that trades the existing Hyperliquid
+// sub-account resolved for id, signed by the master account's key.
+// initialEquity is ignored: sub-accounts start with whatever they hold and
+// are funded through TransferToSubAccount.
+func (p *Provider) SubAccount(ctx context.Context, id string, initialEquity float64) (exchange.Provider, error) {
+	sub, err := p.resolveSubAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return sub.provider, nil
+}
+
+// TransferToSubAccount moves amountUSD of perp USDC from the master account
+// to the sub-account resolved for id, or back when amountUSD is negative.
+func (p *Provider) TransferToSubAccount(ctx context.Context, id string, amountUSD float64) error {
+	sub, err := p.resolveSubAccount(ctx, id)
+	if err != nil {
+		return err
+	}
+	src := p.client.(subAccountSource)
+	if amountUSD < 0 {
+		return src.SubAccountTransfer(ctx, sub.address, false, math.Abs(amountUSD))
+	}
+	return src.SubAccountTransfer(ctx, sub.address, true, amountUSD)
+}
+
+// VaultTransfer deposits amountUSD into the vault at vaultAddress, or
+// withdraws it when isDeposit is false.
+func (p *Provider) VaultTransfer(ctx context.Context, vaultAddress string, isDeposit bool, amountUSD float64) error {
+	src, ok := p.client.(subAccountSource)
+	if !ok {
+		return fmt.Errorf("hyperliquid: client does not support vault transfers")
+	}
+	return src.VaultTransfer(ctx, vaultAddress, isDeposit, amountUSD)
+}
+
+type subAccountEntry struct {
+	address  string
+	provider *Provider
+}
+
+func (p *Provider) resolveSubAccount(ctx context.Context, id string) (*subAccountEntry, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("hyperliquid: sub-account id is required")
+	}
+	src, ok := p.client.(subAccountSource)
+	if !ok {
+		return nil, fmt.Errorf("hyperliquid: client does not support sub-accounts")
+	}
+	p.subMu.Lock()
+	defer p.subMu.Unlock()
+	if p.subAccountNames == nil {
+		return nil, fmt.Errorf("hyperliquid: sub-accounts are not enabled")
+	}
+	if entry, ok := p.subAccounts[id]; ok {
+		return entry, nil
+	}
+	want := id
+	if target := p.subAccountNames[id]; target != "" {
+		want = target
+	}
+	master := src.getInfoAddress()
+	accounts, err := src.GetSubAccounts(ctx, master)
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid: list sub-accounts: %w", err)
+	}
+	for _, account := range accounts {
+		if !strings.EqualFold(account.Name, want) && !strings.EqualFold(account.SubAccountUser, want) {
+			continue
+		}
+		if !common.IsHexAddress(account.SubAccountUser) {
+			return nil, fmt.Errorf("hyperliquid: sub-account %q has invalid address %q", want, account.SubAccountUser)
+		}
+		entry := &subAccountEntry{
+			address:  account.SubAccountUser,
+			provider: &Provider{client: src.forAccount(account.SubAccountUser)},
+		}
+		if p.subAccounts == nil {
+			p.subAccounts = make(map[string]*subAccountEntry)
+		}
+		p.subAccounts[id] = entry
+		return entry, nil
+	}
+	return nil, fmt.Errorf("hyperliquid: no sub-account %q under %s; create it on the venue first", want, master)
+}
@@ -0,0 +1,117 @@
+package hyperliquid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// usdMicros converts a positive USDC amount to the venue's integer units.
+func usdMicros(amountUSD float64) (int64, error) {
+	if math.IsNaN(amountUSD) || math.IsInf(amountUSD, 0) || amountUSD <= 0 {
+		return 0, fmt.Errorf("hyperliquid: transfer amount must be positive, got %v", amountUSD)
+	}
+	usd := int64(math.Round(amountUSD * 1e6))
+	if usd == 0 {
+		return 0, fmt.Errorf("hyperliquid: transfer amount %v rounds to zero", amountUSD)
+	}
+	return usd, nil
+}
+
+func buildSubAccountTransferAction(subAccountUser string, isDeposit bool, amountUSD float64) (subAccountTransferAction, error) {
+	if !common.IsHexAddress(subAccountUser) {
+		return subAccountTransferAction{}, fmt.Errorf("hyperliquid: invalid sub-account address %q", subAccountUser)
+	}
+	usd, err := usdMicros(amountUSD)
+	if err != nil {
+		return subAccountTransferAction{}, err
+	}
+	return subAccountTransferAction{
+		Type:           ActionTypeSubAccountTransfer,
+		SubAccountUser: strings.ToLower(common.HexToAddress(subAccountUser).Hex()),
+		IsDeposit:      isDeposit,
+		Usd:            usd,
+	}, nil
+}
+
+func buildVaultTransferAction(vaultAddress string, isDeposit bool, amountUSD float64) (vaultTransferAction, error) {
+	if !common.IsHexAddress(vaultAddress) {
+		return vaultTransferAction{}, fmt.Errorf("hyperliquid: invalid vault address %q", vaultAddress)
+	}
+	usd, err := usdMicros(amountUSD)
+	if err != nil {
+		return vaultTransferAction{}, err
+	}
+	return vaultTransferAction{
+		Type:         ActionTypeVaultTransfer,
+		VaultAddress: strings.ToLower(common.HexToAddress(vaultAddress).Hex()),
+		IsDeposit:    isDeposit,
+		Usd:          usd,
+	}, nil
+}
+
+// SubAccountTransfer moves amountUSD of perp USDC from the master account to
+// subAccountUser when isDeposit is true, or from the sub-account back to the
+// master otherwise. It is signed for the master account even when the client
+// trades on behalf of a vault or sub-account.
+func (c *Client) SubAccountTransfer(ctx context.Context, subAccountUser string, isDeposit bool, amountUSD float64) error {
+	action, err := buildSubAccountTransferAction(subAccountUser, isDeposit, amountUSD)
+	if err != nil {
+		return err
+	}
+	return c.postTransfer(ctx, action, "sub-account transfer")
+}
+
+// VaultTransfer deposits amountUSD into the vault at vaultAddress, or
+// withdraws it when isDeposit is false. Vaults lock deposits for a period
+// after each deposit, during which withdrawals are rejected.
+func (c *Client) VaultTransfer(ctx context.Context, vaultAddress string, isDeposit bool, amountUSD float64) error {
+	action, err := buildVaultTransferAction(vaultAddress, isDeposit, amountUSD)
+	if err != nil {
+		return err
+	}
+	return c.postTransfer(ctx, action, "vault transfer")
+}
+
+func (c *Client) postTransfer(ctx context.Context, action interface{}, what string) error {
+	var envelope exchangeEnvelope
+	if err := c.postAction(ctx, action, "", &envelope); err != nil {
+		return err
+	}
+	if err := envelope.err(); err != nil {
+		return fmt.Errorf("hyperliquid: %s: %w", what, err)
+	}
+	return nil
+}
+
+// forAccount returns a client that trades and queries account on behalf of
+// the sub-account or vault at address, signed by the same key. Nonces and
+// request budgets stay shared with c.
+func (c *Client) forAccount(address string) *Client {
+	addr := common.HexToAddress(address).Hex()
+	return &Client{
+		infoURL:         c.infoURL,
+		exchangeURL:     c.exchangeURL,
+		wsURL:           c.wsURL,
+		httpClient:      c.httpClient,
+		signer:          c.signer,
+		address:         c.address,
+		mainAddress:     strings.ToLower(addr),
+		isTestnet:       c.isTestnet,
+		logger:          c.logger,
+		clock:           c.clock,
+		vault:           addr,
+		nonces:          c.nonces,
+		nonceStore:      c.nonceStore,
+		rateLimiter:     c.rateLimiter,
+		rateLimitOff:    c.rateLimitOff,
+		assetIndex:      make(map[string]int),
+		assetInfo:       make(map[string]AssetInfo),
+		defaultSlippage: c.defaultSlippage,
+		priceSigFigs:    c.priceSigFigs,
+		assetTTL:        c.assetTTL,
+	}
+}
@@ -0,0 +1,134 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+const (
+	testSubAccount = "0x1111111111111111111111111111111111111111"
+	testVault      = "0x2222222222222222222222222222222222222222"
+)
+
+func TestBuildTransferActions(t *testing.T) {
+	sub, err := buildSubAccountTransferAction(testSubAccount, true, 12.345678)
+	require.NoError(t, err)
+	raw, err := json.Marshal(sub)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"subAccountTransfer","subAccountUser":"`+testSubAccount+`","isDeposit":true,"usd":12345678}`, string(raw))
+
+	vault, err := buildVaultTransferAction(testVault, false, 5)
+	require.NoError(t, err)
+	raw, err = json.Marshal(vault)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"vaultTransfer","vaultAddress":"`+testVault+`","isDeposit":false,"usd":5000000}`, string(raw))
+
+	_, err = buildSubAccountTransferAction("not-an-address", true, 1)
+	assert.Error(t, err)
+	_, err = buildVaultTransferAction(testVault, true, 0)
+	assert.Error(t, err)
+	_, err = buildSubAccountTransferAction(testSubAccount, true, 1e-7)
+	assert.ErrorContains(t, err, "rounds to zero")
+
+	_, err = buildEIP712Message(sub, 1, "", true)
+	assert.NoError(t, err)
+}
+
+// subAccountServer answers subAccounts and clearinghouseState info requests
+// and records exchange actions with their vault address.
+type subAccountServer struct {
+	actions []map[string]any
+	vaults  []string
+	users   []string
+}
+
+func (s *subAccountServer) handle(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if action, ok := req["action"].(map[string]any); ok {
+			s.actions = append(s.actions, action)
+			vault, _ := req["vaultAddress"].(string)
+			s.vaults = append(s.vaults, vault)
+			_, _ = w.Write([]byte(`{"status":"ok","response":{"type":"default"}}`))
+			return
+		}
+		user, _ := req["user"].(string)
+		s.users = append(s.users, strings.ToLower(user))
+		switch req["type"] {
+		case "subAccounts":
+			_, _ = w.Write([]byte(`[{"name":"alpha","subAccountUser":"` + testSubAccount + `","master":"` + user + `","clearinghouseState":{}}]`))
+		case "clearinghouseState":
+			_, _ = w.Write([]byte(`{"marginSummary":{"accountValue":"250.5","totalMarginUsed":"0"},"assetPositions":[]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}
+}
+
+func TestClientTransfersSignForMaster(t *testing.T) {
+	srv := &subAccountServer{}
+	client := newTwapTestClient(t, srv.handle(t))
+	client.vault = testVault // trading on behalf of a vault must not leak into transfers
+	ctx := context.Background()
+
+	require.NoError(t, client.SubAccountTransfer(ctx, testSubAccount, false, 10))
+	require.NoError(t, client.VaultTransfer(ctx, testVault, true, 20))
+	require.Len(t, srv.actions, 2)
+	assert.Equal(t, "subAccountTransfer", srv.actions[0]["type"])
+	assert.Equal(t, false, srv.actions[0]["isDeposit"])
+	assert.EqualValues(t, 10_000_000, srv.actions[0]["usd"])
+	assert.Equal(t, "vaultTransfer", srv.actions[1]["type"])
+	assert.Equal(t, []string{"", ""}, srv.vaults)
+}
+
+func TestProviderSubAccounts(t *testing.T) {
+	srv := &subAccountServer{}
+	client := newTwapTestClient(t, srv.handle(t))
+	provider := &Provider{client: client}
+	ctx := context.Background()
+
+	assert.False(t, exchange.CapabilitiesOf(provider).SubAccounts, "sub-accounts are opt-in")
+	_, err := provider.SubAccount(ctx, "t1", 0)
+	assert.ErrorContains(t, err, "not enabled")
+
+	provider.EnableSubAccounts(map[string]string{"t1": "alpha"})
+	caps := exchange.CapabilitiesOf(provider)
+	assert.True(t, caps.SubAccounts)
+	assert.True(t, caps.FundTransfers)
+
+	sub, err := provider.SubAccount(ctx, "t1", 1000)
+	require.NoError(t, err)
+	again, err := provider.SubAccount(ctx, "t1", 0)
+	require.NoError(t, err)
+	assert.Same(t, sub, again)
+	assert.False(t, exchange.CapabilitiesOf(sub).SubAccounts, "sub-accounts cannot nest")
+
+	value, err := sub.GetAccountValue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 250.5, value)
+	assert.Equal(t, strings.ToLower(testSubAccount), srv.users[len(srv.users)-1], "the sub-account queries its own state")
+
+	_, err = provider.SubAccount(ctx, "t2", 0)
+	assert.ErrorContains(t, err, `no sub-account "t2"`)
+
+	require.NoError(t, provider.TransferToSubAccount(ctx, "t1", 40))
+	require.NoError(t, provider.TransferToSubAccount(ctx, "t1", -15))
+	require.Len(t, srv.actions, 2)
+	assert.Equal(t, true, srv.actions[0]["isDeposit"])
+	assert.EqualValues(t, 40_000_000, srv.actions[0]["usd"])
+	assert.Equal(t, false, srv.actions[1]["isDeposit"])
+	assert.EqualValues(t, 15_000_000, srv.actions[1]["usd"])
+	assert.Equal(t, testSubAccount, srv.actions[0]["subAccountUser"])
+
+	require.NoError(t, sub.UpdateLeverage(ctx, 5, false, 3))
+	assert.Equal(t, strings.ToLower(testSubAccount), strings.ToLower(srv.vaults[2]), "sub-account orders are signed on its behalf")
+}
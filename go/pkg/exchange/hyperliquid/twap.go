@@ -0,0 +1,241 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+)
+
+// Hyperliquid accepts TWAP durations between 5 minutes and 24 hours, in whole
+// minutes.
+const (
+	minTwapMinutes = 5
+	maxTwapMinutes = 24 * 60
+)
+
+// TwapState describes a TWAP as reported by twapHistory.
+type TwapState struct {
+	Coin        string `json:"coin"`
+	User        string `json:"user"`
+	Side        string `json:"side"`
+	Sz          string `json:"sz"`
+	ExecutedSz  string `json:"executedSz"`
+	ExecutedNtl string `json:"executedNtl"`
+	Minutes     int    `json:"minutes"`
+	ReduceOnly  bool   `json:"reduceOnly"`
+	Randomize   bool   `json:"randomize"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// TwapHistoryEntry is one record returned by the twapHistory info endpoint.
+type TwapHistoryEntry struct {
+	Time   int64     `json:"time"` // seconds
+	State  TwapState `json:"state"`
+	Status struct {
+		Status      string `json:"status"`
+		Description string `json:"description,omitempty"`
+	} `json:"status"`
+	TwapID *int64 `json:"twapId,omitempty"`
+}
+
+// exchangeEnvelope is the outer shape of /exchange responses: response is an
+// object on success and an error string when status is "err".
+type exchangeEnvelope struct {
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+func (e exchangeEnvelope) err() error {
+	if e.Status == "ok" {
+		return nil
+	}
+	var msg string
+	if err := json.Unmarshal(e.Response, &msg); err != nil || msg == "" {
+		msg = string(e.Response)
+	}
+	return fmt.Errorf("hyperliquid: exchange rejected action: %s", msg)
+}
+
+func (e exchangeEnvelope) data(out interface{}) error {
+	if err := e.err(); err != nil {
+		return err
+	}
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(e.Response, &body); err != nil {
+		return fmt.Errorf("hyperliquid: decode exchange response: %w", err)
+	}
+	if err := json.Unmarshal(body.Data, out); err != nil {
+		return fmt.Errorf("hyperliquid: decode exchange response data: %w", err)
+	}
+	return nil
+}
+
+func buildTwapOrderAction(asset int, order exchange.TWAPOrder) (twapOrderAction, error) {
+	if asset < 0 {
+		return twapOrderAction{}, errInvalidAsset
+	}
+	sz := strings.TrimSpace(order.Sz)
+	if !isPositiveDecimal(sz) {
+		return twapOrderAction{}, errInvalidSize
+	}
+	minutes := int(math.Round(order.Duration.Minutes()))
+	if minutes < minTwapMinutes || minutes > maxTwapMinutes {
+		return twapOrderAction{}, fmt.Errorf("hyperliquid: twap duration %s must be between %dm and %dh", order.Duration, minTwapMinutes, maxTwapMinutes/60)
+	}
+	return twapOrderAction{
+		Type: ActionTypeTwapOrder,
+		Twap: twapPayload{
+			Asset:      asset,
+			IsBuy:      order.IsBuy,
+			Sz:         sz,
+			ReduceOnly: order.ReduceOnly,
+			Minutes:    minutes,
+			Randomize:  order.Randomize,
+		},
+	}, nil
+}
+
+func buildTwapCancelAction(asset int, twapID int64) (twapCancelAction, error) {
+	if asset < 0 {
+		return twapCancelAction{}, errInvalidAsset
+	}
+	if twapID <= 0 {
+		return twapCancelAction{}, fmt.Errorf("hyperliquid: twap id must be positive")
+	}
+	return twapCancelAction{Type: ActionTypeTwapCancel, Asset: asset, TwapID: twapID}, nil
+}
+
+// PlaceTWAP starts a native TWAP execution for order.Coin. The venue rounds
+// the duration to whole minutes and places a slice every 30 seconds.
+func (c *Client) PlaceTWAP(ctx context.Context, order exchange.TWAPOrder) (*exchange.TWAPStatus, error) {
+	asset, err := c.GetAssetIndex(ctx, order.Coin)
+	if err != nil {
+		return nil, err
+	}
+	action, err := buildTwapOrderAction(asset, order)
+	if err != nil {
+		return nil, err
+	}
+	var envelope exchangeEnvelope
+	if err := c.doExchangeRequest(ctx, action, &envelope); err != nil {
+		return nil, err
+	}
+	var data struct {
+		Status struct {
+			Running *struct {
+				TwapID int64 `json:"twapId"`
+			} `json:"running,omitempty"`
+			Error string `json:"error,omitempty"`
+		} `json:"status"`
+	}
+	if err := envelope.data(&data); err != nil {
+		return nil, err
+	}
+	if data.Status.Error != "" {
+		return nil, fmt.Errorf("hyperliquid: twap order %s: %s", order.Coin, data.Status.Error)
+	}
+	if data.Status.Running == nil {
+		return nil, fmt.Errorf("hyperliquid: twap order %s: missing twap id", order.Coin)
+	}
+	side := "A"
+	if order.IsBuy {
+		side = "B"
+	}
+	return &exchange.TWAPStatus{
+		TwapID:      data.Status.Running.TwapID,
+		Coin:        order.Coin,
+		Side:        side,
+		Sz:          action.Twap.Sz,
+		ExecutedSz:  "0",
+		ExecutedNtl: "0",
+		Minutes:     action.Twap.Minutes,
+		Randomize:   order.Randomize,
+		ReduceOnly:  order.ReduceOnly,
+		Status:      exchange.TWAPStatusActive,
+		Timestamp:   c.clock().UnixMilli(),
+	}, nil
+}
+
+// CancelTWAP stops a running TWAP; slices already executed are kept.
+func (c *Client) CancelTWAP(ctx context.Context, coin string, twapID int64) error {
+	asset, err := c.GetAssetIndex(ctx, coin)
+	if err != nil {
+		return err
+	}
+	action, err := buildTwapCancelAction(asset, twapID)
+	if err != nil {
+		return err
+	}
+	var envelope exchangeEnvelope
+	if err := c.doExchangeRequest(ctx, action, &envelope); err != nil {
+		return err
+	}
+	var data struct {
+		Status json.RawMessage `json:"status"`
+	}
+	if err := envelope.data(&data); err != nil {
+		return err
+	}
+	var failure struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data.Status, &failure) == nil && failure.Error != "" {
+		return fmt.Errorf("hyperliquid: cancel twap %d: %s", twapID, failure.Error)
+	}
+	return nil
+}
+
+// GetTWAPHistory returns the TWAP records for the info address, including
+// running ones.
+func (c *Client) GetTWAPHistory(ctx context.Context) ([]TwapHistoryEntry, error) {
+	infoAddr := c.getInfoAddress()
+	if infoAddr == "" {
+		return nil, fmt.Errorf("hyperliquid: client address unavailable")
+	}
+	var entries []TwapHistoryEntry
+	if err := c.doInfoRequest(ctx, InfoRequest{Type: "twapHistory", User: infoAddr}, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetTWAPStatus returns the latest recorded state of a TWAP.
+func (c *Client) GetTWAPStatus(ctx context.Context, twapID int64) (*exchange.TWAPStatus, error) {
+	entries, err := c.GetTWAPHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var latest *TwapHistoryEntry
+	for i := range entries {
+		e := &entries[i]
+		if e.TwapID == nil || *e.TwapID != twapID {
+			continue
+		}
+		if latest == nil || e.Time >= latest.Time {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("hyperliquid: twap %d not found", twapID)
+	}
+	return &exchange.TWAPStatus{
+		TwapID:      twapID,
+		Coin:        latest.State.Coin,
+		Side:        latest.State.Side,
+		Sz:          latest.State.Sz,
+		ExecutedSz:  latest.State.ExecutedSz,
+		ExecutedNtl: latest.State.ExecutedNtl,
+		Minutes:     latest.State.Minutes,
+		Randomize:   latest.State.Randomize,
+		ReduceOnly:  latest.State.ReduceOnly,
+		Status:      latest.Status.Status,
+		Description: latest.Status.Description,
+		Timestamp:   latest.State.Timestamp,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+func newTwapTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewClient("0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a741b52d7c5d5095e2f", false)
+	require.NoError(t, err)
+	client.infoURL = server.URL
+	client.exchangeURL = server.URL
+	client.assetMu.Lock()
+	client.assetIndex = map[string]int{"SOL": 5}
+	client.assetInfo = map[string]AssetInfo{"SOL": {Name: "SOL", Index: 5, SzDecimals: 2}}
+	client.assetLastRef = time.Now()
+	client.assetMu.Unlock()
+	return client
+}
+
+func TestBuildTwapOrderAction(t *testing.T) {
+	action, err := buildTwapOrderAction(5, exchange.TWAPOrder{Coin: "SOL", IsBuy: true, Sz: "12.5", Duration: 30 * time.Minute, Randomize: true})
+	require.NoError(t, err)
+	raw, err := json.Marshal(action)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"twapOrder","twap":{"a":5,"b":true,"s":"12.5","r":false,"m":30,"t":true}}`, string(raw))
+
+	_, err = buildTwapOrderAction(5, exchange.TWAPOrder{Sz: "1", Duration: 2 * time.Minute})
+	assert.Error(t, err, "durations under five minutes are rejected")
+	_, err = buildTwapOrderAction(5, exchange.TWAPOrder{Sz: "1", Duration: 25 * time.Hour})
+	assert.Error(t, err, "durations over a day are rejected")
+	_, err = buildTwapOrderAction(5, exchange.TWAPOrder{Sz: "0", Duration: time.Hour})
+	assert.Error(t, err)
+
+	cancel, err := buildTwapCancelAction(5, 77)
+	require.NoError(t, err)
+	raw, err = json.Marshal(cancel)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"twapCancel","a":5,"t":77}`, string(raw))
+	_, err = buildTwapCancelAction(5, 0)
+	assert.Error(t, err)
+
+	_, err = buildEIP712Message(action, 1, "", true)
+	assert.NoError(t, err, "twap actions sign like other L1 actions")
+}
+
+func TestClientPlaceAndCancelTWAP(t *testing.T) {
+	var actions []map[string]any
+	reply := `{"status":"ok","response":{"type":"twapOrder","data":{"status":{"running":{"twapId":77}}}}}`
+	client := newTwapTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Action map[string]any `json:"action"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		actions = append(actions, req.Action)
+		_, _ = w.Write([]byte(reply))
+	})
+	ctx := context.Background()
+
+	status, err := client.PlaceTWAP(ctx, exchange.TWAPOrder{Coin: "SOL", IsBuy: false, Sz: "3", Duration: 10 * time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, int64(77), status.TwapID)
+	assert.Equal(t, "A", status.Side)
+	assert.Equal(t, 10, status.Minutes)
+	assert.True(t, status.Active())
+	require.Len(t, actions, 1)
+	assert.Equal(t, "twapOrder", actions[0]["type"])
+
+	reply = `{"status":"ok","response":{"type":"twapOrder","data":{"status":{"error":"Order too small."}}}}`
+	_, err = client.PlaceTWAP(ctx, exchange.TWAPOrder{Coin: "SOL", Sz: "0.01", Duration: 10 * time.Minute})
+	assert.ErrorContains(t, err, "Order too small.")
+
+	reply = `{"status":"ok","response":{"type":"twapCancel","data":{"status":"success"}}}`
+	require.NoError(t, client.CancelTWAP(ctx, "SOL", 77))
+	assert.Equal(t, "twapCancel", actions[len(actions)-1]["type"])
+
+	reply = `{"status":"ok","response":{"type":"twapCancel","data":{"status":{"error":"TWAP was never placed."}}}}`
+	assert.ErrorContains(t, client.CancelTWAP(ctx, "SOL", 78), "never placed")
+
+	reply = `{"status":"err","response":"User or API Wallet does not exist."}`
+	assert.ErrorContains(t, client.CancelTWAP(ctx, "SOL", 77), "does not exist")
+}
+
+func TestClientGetTWAPStatus(t *testing.T) {
+	client := newTwapTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req InfoRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "twapHistory", req.Type)
+		_, _ = w.Write([]byte(`[
+			{"time":1700000000,"state":{"coin":"SOL","side":"B","sz":"10","executedSz":"0","executedNtl":"0","minutes":30,"randomize":true,"reduceOnly":false,"timestamp":1700000000000},"status":{"status":"activated"},"twapId":77},
+			{"time":1700001800,"state":{"coin":"SOL","side":"B","sz":"10","executedSz":"10","executedNtl":"1500","minutes":30,"randomize":true,"reduceOnly":false,"timestamp":1700000000000},"status":{"status":"finished"},"twapId":77},
+			{"time":1700000100,"state":{"coin":"SOL","side":"A","sz":"1","executedSz":"0","executedNtl":"0","minutes":5,"randomize":false,"reduceOnly":true,"timestamp":1700000100000},"status":{"status":"error","description":"Insufficient margin"},"twapId":78}
+		]`))
+	})
+
+	status, err := client.GetTWAPStatus(context.Background(), 77)
+	require.NoError(t, err)
+	assert.Equal(t, exchange.TWAPStatusFinished, status.Status, "the latest record wins")
+	assert.Equal(t, "10", status.ExecutedSz)
+	assert.False(t, status.Active())
+
+	status, err = client.GetTWAPStatus(context.Background(), 78)
+	require.NoError(t, err)
+	assert.Equal(t, "Insufficient margin", status.Description)
+
+	_, err = client.GetTWAPStatus(context.Background(), 99)
+	assert.Error(t, err)
+}
@@ -21,10 +21,25 @@ const (
 	ActionTypeCancelAll ActionType = "cancelAll"
 	// ActionTypeUpdateLeverage adjusts leverage settings.
 	ActionTypeUpdateLeverage ActionType = "updateLeverage"
+	// ActionTypeUpdateIsolatedMargin adds or removes isolated position margin.
+	ActionTypeUpdateIsolatedMargin ActionType = "updateIsolatedMargin"
 	// ActionTypeModify updates a single resting order.
 	ActionTypeModify ActionType = "modify"
 	// ActionTypeBatchModify updates multiple resting orders.
 	ActionTypeBatchModify ActionType = "batchModify"
+	// ActionTypeTwapOrder starts a TWAP execution.
+	ActionTypeTwapOrder ActionType = "twapOrder"
+	// ActionTypeTwapCancel stops a running TWAP execution.
+	ActionTypeTwapCancel ActionType = "twapCancel"
+	// ActionTypeScheduleCancel arms or clears the dead-man's switch.
+	ActionTypeScheduleCancel ActionType = "scheduleCancel"
+	// ActionTypeSubAccountTransfer moves USDC between master and sub-account.
+	ActionTypeSubAccountTransfer ActionType = "subAccountTransfer"
+	// ActionTypeVaultTransfer deposits into or withdraws from a vault.
+	ActionTypeVaultTransfer ActionType = "vaultTransfer"
+	// ActionTypeApproveAgent authorises an agent (API) wallet to trade for
+	// the signing account.
+	ActionTypeApproveAgent ActionType = "approveAgent"
 )
 
 // Action encodes the payload sent to the Hyperliquid exchange endpoint.
@@ -123,6 +138,71 @@ type batchModifyAction struct {
 	Modifies []modifyPayload `json:"modifies" msgpack:"modifies"`
 }
 
+type twapPayload struct {
+	Asset      int    `json:"a" msgpack:"a"`
+	IsBuy      bool   `json:"b" msgpack:"b"`
+	Sz         string `json:"s" msgpack:"s"`
+	ReduceOnly bool   `json:"r" msgpack:"r"`
+	Minutes    int    `json:"m" msgpack:"m"`
+	Randomize  bool   `json:"t" msgpack:"t"`
+}
+
+type twapOrderAction struct {
+	Type ActionType  `json:"type" msgpack:"type"`
+	Twap twapPayload `json:"twap" msgpack:"twap"`
+}
+
+type twapCancelAction struct {
+	Type   ActionType `json:"type" msgpack:"type"`
+	Asset  int        `json:"a" msgpack:"a"`
+	TwapID int64      `json:"t" msgpack:"t"`
+}
+
+// updateIsolatedMarginAction moves Ntli (USDC × 1e6; negative removes) into
+// the isolated position on Asset.
+type updateIsolatedMarginAction struct {
+	Type  ActionType `json:"type" msgpack:"type"`
+	Asset int        `json:"asset" msgpack:"asset"`
+	IsBuy bool       `json:"isBuy" msgpack:"isBuy"`
+	Ntli  int64      `json:"ntli" msgpack:"ntli"`
+}
+
+// subAccountTransferAction moves Usd (USDC × 1e6) from the master account to
+// SubAccountUser, or back when IsDeposit is false.
+type subAccountTransferAction struct {
+	Type           ActionType `json:"type" msgpack:"type"`
+	SubAccountUser string     `json:"subAccountUser" msgpack:"subAccountUser"`
+	IsDeposit      bool       `json:"isDeposit" msgpack:"isDeposit"`
+	Usd            int64      `json:"usd" msgpack:"usd"`
+}
+
+// vaultTransferAction deposits Usd (USDC × 1e6) into VaultAddress, or
+// withdraws it when IsDeposit is false.
+type vaultTransferAction struct {
+	Type         ActionType `json:"type" msgpack:"type"`
+	VaultAddress string     `json:"vaultAddress" msgpack:"vaultAddress"`
+	IsDeposit    bool       `json:"isDeposit" msgpack:"isDeposit"`
+	Usd          int64      `json:"usd" msgpack:"usd"`
+}
+
+// scheduleCancelAction omits Time to clear a scheduled cancel.
+type scheduleCancelAction struct {
+	Type ActionType `json:"type" msgpack:"type"`
+	Time *int64     `json:"time,omitempty" msgpack:"time,omitempty"`
+}
+
+// approveAgentAction is a user-signed action: it is signed over its own
+// fields (see approveAgentDigest) and carries the request nonce. An empty
+// AgentName is signed as "" and omitted from the request.
+type approveAgentAction struct {
+	Type             ActionType `json:"type" msgpack:"type"`
+	SignatureChainID string     `json:"signatureChainId" msgpack:"signatureChainId"`
+	HyperliquidChain string     `json:"hyperliquidChain" msgpack:"hyperliquidChain"`
+	AgentAddress     string     `json:"agentAddress" msgpack:"agentAddress"`
+	AgentName        string     `json:"agentName,omitempty" msgpack:"agentName,omitempty"`
+	Nonce            int64      `json:"nonce" msgpack:"nonce"`
+}
+
 // ExchangeRequest is the signed request envelope for exchange actions.
 type ExchangeRequest struct {
 	Action       interface{} `json:"action"`
@@ -145,6 +225,9 @@ type InfoRequest struct {
 	User string `json:"user,omitempty"`
 	// For vaultDetails endpoint
 	VaultAddress string `json:"vaultAddress,omitempty"`
+	// For time-ranged history endpoints (userFillsByTime, ...), in milliseconds.
+	StartTime int64 `json:"startTime,omitempty"`
+	EndTime   int64 `json:"endTime,omitempty"`
 }
 
 // AccountStateResponse wraps account state returned by Hyperliquid.
@@ -236,6 +319,70 @@ type AssetInfo struct {
 	MidPx        string
 	OraclePx     string
 	ImpactPxs    []string
+	IsSpot       bool // Index is SpotAssetOffset plus the spot pair index.
+}
+
+// SpotMeta (info endpoint: type=spotMeta) lists spot tokens and pairs.
+type SpotMeta struct {
+	Tokens   []SpotToken `json:"tokens"`
+	Universe []SpotPair  `json:"universe"`
+}
+
+// SpotToken describes a token tradable on the spot book.
+type SpotToken struct {
+	Name        string `json:"name"`
+	SzDecimals  int    `json:"szDecimals"`
+	WeiDecimals int    `json:"weiDecimals"`
+	Index       int    `json:"index"`
+	TokenID     string `json:"tokenId"`
+	IsCanonical bool   `json:"isCanonical"`
+}
+
+// SpotPair describes a spot market. Tokens holds the base and quote token
+// indices; Name is the coin used on the wire, e.g. "PURR/USDC" or "@107".
+type SpotPair struct {
+	Name        string `json:"name"`
+	Tokens      []int  `json:"tokens"`
+	Index       int    `json:"index"`
+	IsCanonical bool   `json:"isCanonical"`
+}
+
+// SpotAssetCtx provides per-pair prices and volume.
+type SpotAssetCtx struct {
+	Coin              string `json:"coin"`
+	PrevDayPx         string `json:"prevDayPx"`
+	DayNtlVlm         string `json:"dayNtlVlm"`
+	DayBaseVlm        string `json:"dayBaseVlm"`
+	MarkPx            string `json:"markPx"`
+	MidPx             string `json:"midPx"`
+	CirculatingSupply string `json:"circulatingSupply"`
+	TotalSupply       string `json:"totalSupply"`
+}
+
+// SpotMetaAndAssetCtxsResponse includes spot meta plus per-pair context.
+type SpotMetaAndAssetCtxsResponse struct {
+	SpotMeta
+	AssetCtxs []SpotAssetCtx
+}
+
+// UnmarshalJSON decodes the [meta, assetCtxs] array payload.
+func (m *SpotMetaAndAssetCtxsResponse) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("hyperliquid: spotMetaAndAssetCtxs decode: %w", err)
+	}
+	if len(raw) == 0 {
+		return fmt.Errorf("hyperliquid: spotMetaAndAssetCtxs empty payload")
+	}
+	if err := json.Unmarshal(raw[0], &m.SpotMeta); err != nil {
+		return fmt.Errorf("hyperliquid: spotMetaAndAssetCtxs meta: %w", err)
+	}
+	if len(raw) > 1 {
+		if err := json.Unmarshal(raw[1], &m.AssetCtxs); err != nil {
+			return fmt.Errorf("hyperliquid: spotMetaAndAssetCtxs assetCtxs: %w", err)
+		}
+	}
+	return nil
 }
 
 // NonceResponse returns the exchange nonce used for signing.
@@ -270,13 +417,7 @@ type SpotState struct {
 	Balances []SpotBalance `json:"balances"`
 }
 
-type SpotBalance struct {
-	Coin     string `json:"coin"`
-	Token    int    `json:"token"`
-	Total    string `json:"total"`
-	Hold     string `json:"hold"`
-	EntryNtl string `json:"entryNtl"`
-}
+type SpotBalance = exchange.SpotBalance
 
 // VaultDetails (info endpoint: type=vaultDetails) response.
 type VaultDetails struct {
@@ -300,3 +441,75 @@ type VaultFollower struct {
 	AllTimePnL    string `json:"allTimePnl"`
 	DaysFollowing int    `json:"daysFollowing"`
 }
+
+// UserFill is a single execution as returned by userFills/userFillsByTime and
+// the userFills websocket channel.
+type UserFill struct {
+	Coin          string `json:"coin"`
+	Px            string `json:"px"`
+	Sz            string `json:"sz"`
+	Side          string `json:"side"`
+	Time          int64  `json:"time"`
+	StartPosition string `json:"startPosition"`
+	Dir           string `json:"dir"`
+	ClosedPnl     string `json:"closedPnl"`
+	Hash          string `json:"hash"`
+	Oid           int64  `json:"oid"`
+	Crossed       bool   `json:"crossed"`
+	Fee           string `json:"fee"`
+	Tid           int64  `json:"tid"`
+	FeeToken      string `json:"feeToken"`
+	Cloid         string `json:"cloid,omitempty"`
+}
+
+// ToExchangeFill converts the venue payload into the shared exchange.Fill.
+func (f UserFill) ToExchangeFill() exchange.Fill {
+	return exchange.Fill{
+		Coin:          f.Coin,
+		Side:          f.Side,
+		Px:            f.Px,
+		AvgPx:         f.Px,
+		TotalSz:       f.Sz,
+		Sz:            f.Sz,
+		Oid:           f.Oid,
+		Cloid:         f.Cloid,
+		Crossed:       f.Crossed,
+		Fee:           f.Fee,
+		FeeToken:      f.FeeToken,
+		Tid:           f.Tid,
+		Timestamp:     f.Time,
+		Dir:           f.Dir,
+		StartPosition: f.StartPosition,
+		ClosedPnl:     f.ClosedPnl,
+		Hash:          f.Hash,
+	}
+}
+
+// UserFunding is a single userFunding history entry.
+type UserFunding struct {
+	Time  int64            `json:"time"`
+	Hash  string           `json:"hash"`
+	Delta UserFundingDelta `json:"delta"`
+}
+
+// UserFundingDelta carries the funding cash flow for one position.
+type UserFundingDelta struct {
+	Type        string `json:"type"` // "funding"
+	Coin        string `json:"coin"`
+	USDC        string `json:"usdc"`
+	Szi         string `json:"szi"`
+	FundingRate string `json:"fundingRate"`
+}
+
+// ToExchangeFunding converts the venue payload into the shared
+// exchange.FundingPayment.
+func (f UserFunding) ToExchangeFunding() exchange.FundingPayment {
+	return exchange.FundingPayment{
+		Coin:        f.Delta.Coin,
+		USDC:        f.Delta.USDC,
+		Szi:         f.Delta.Szi,
+		FundingRate: f.Delta.FundingRate,
+		Timestamp:   f.Time,
+		Hash:        f.Hash,
+	}
+}
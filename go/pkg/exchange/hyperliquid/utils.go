@@ -4,9 +4,10 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"strconv"
 	"strings"
 	"time"
+
+	"nof0-api/pkg/exchange"
 )
 
 // GetAssetIndex resolves the exchange asset index for the given coin.
@@ -15,6 +16,13 @@ func (c *Client) GetAssetIndex(ctx context.Context, coin string) (int, error) {
 	if key == "" {
 		return 0, fmt.Errorf("hyperliquid: empty coin symbol")
 	}
+	if isSpotSymbol(key) {
+		info, err := c.spotAssetInfo(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		return info.Index, nil
+	}
 
 	_ = c.maybeRefreshAssetDirectory(ctx)
 
@@ -36,6 +44,13 @@ func (c *Client) GetAssetInfo(ctx context.Context, coin string) (*AssetInfo, err
 	if key == "" {
 		return nil, fmt.Errorf("hyperliquid: empty coin symbol")
 	}
+	if isSpotSymbol(key) {
+		info, err := c.spotAssetInfo(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &info, nil
+	}
 	_ = c.maybeRefreshAssetDirectory(ctx)
 	if info, ok := c.cachedAssetInfo(key); ok {
 		return &info, nil
@@ -136,59 +151,52 @@ func (c *Client) maybeRefreshAssetDirectory(ctx context.Context) error {
 }
 
 // RoundPriceToSigFigs rounds the price to the specified number of significant
-// figures. It returns a decimal string with trimmed trailing zeros.
+// figures. It returns a decimal string with trimmed trailing zeros; whole
+// numbers keep their magnitude (110823.5 -> "110820").
 func RoundPriceToSigFigs(price float64, sigfigs int) string {
 	if sigfigs <= 0 || !isFinite(price) || price == 0 {
 		return "0"
 	}
-	sign := 1.0
-	if price < 0 {
-		sign = -1
-		price = -price
-	}
-	exp := math.Floor(math.Log10(price))
-	scale := math.Pow(10, float64(sigfigs)-1-exp)
-	rounded := sign * math.Round(price*scale) / scale
-	if rounded == 0 || !isFinite(rounded) {
-		return "0"
-	}
-	absr := rounded
-	if absr < 0 {
-		absr = -absr
-	}
-	// decimals required to preserve sigfigs in fixed notation
-	decExp := math.Floor(math.Log10(absr))
-	decimals := int(math.Max(0, float64(sigfigs-1)-decExp))
-	s := strconv.FormatFloat(rounded, 'f', decimals, 64)
-	if decimals > 0 {
-		// Only trim trailing zeros when fractional digits are present. Hyperliquid strictly validates
-		// the magnitude we submit; previously we stripped zeros from whole-number prices
-		// (e.g. 110820 -> 11082) which turned a ~110k quote into a ~11k quote and the exchange
-		// would reject the payload with HTTP 422 "Failed to deserialize the JSON body into the target type".
-		s = strings.TrimRight(s, "0")
-		s = strings.TrimRight(s, ".")
-	}
-	if s == "" || s == "-" {
-		return "0"
-	}
-	return s
+	return exchange.DecimalFromFloat(price).RoundSigFigs(sigfigs, exchange.RoundHalfUp).String()
+}
+
+// perpMaxPriceDecimals bounds perp price decimals together with the asset's
+// szDecimals, as spotMaxPriceDecimals does for spot.
+const perpMaxPriceDecimals = 6
+
+// roundPrice applies the venue's price rules: at most sigfigs significant
+// figures and at most maxDecimals-szDecimals fractional digits, where
+// maxDecimals is 6 for perps and 8 for spot. Prices off this grid are
+// rejected by the venue.
+func roundPrice(price exchange.Decimal, sigfigs int, info AssetInfo) exchange.Decimal {
+	maxDecimals := perpMaxPriceDecimals
+	if info.IsSpot {
+		maxDecimals = spotMaxPriceDecimals
+	}
+	places := maxDecimals - info.SzDecimals
+	if places < 0 {
+		places = 0
+	}
+	return price.RoundSigFigs(sigfigs, exchange.RoundHalfUp).Round(int32(places), exchange.RoundHalfUp)
 }
 
 func isFinite(f float64) bool { return !math.IsNaN(f) && !math.IsInf(f, 0) }
 
 // FormatPrice rounds a raw price to the client's configured significant figures
-// and returns a trimmed decimal string. It optionally checks the asset exists
+// and the asset's decimal limit (see roundPrice) and returns a trimmed decimal
+// string. It optionally checks the asset exists
 // to surface early errors when an unknown symbol is provided.
 func (c *Client) FormatPrice(ctx context.Context, coin string, price float64) (string, error) {
 	if price <= 0 || !isFinite(price) {
 		return "0", fmt.Errorf("hyperliquid: invalid price")
 	}
 	// Ensure asset directory is primed so callers get early symbol errors.
-	if _, err := c.GetAssetInfo(ctx, coin); err != nil {
+	info, err := c.GetAssetInfo(ctx, coin)
+	if err != nil {
 		return "", err
 	}
 	if c.priceSigFigs <= 0 {
 		c.priceSigFigs = 5
 	}
-	return RoundPriceToSigFigs(price, c.priceSigFigs), nil
+	return roundPrice(exchange.DecimalFromFloat(price), c.priceSigFigs, *info).String(), nil
 }
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"nof0-api/pkg/exchange"
 )
 
 func TestGetAssetIndex(t *testing.T) {
@@ -246,6 +248,18 @@ func TestRoundPriceToSigFigs_IntPreservesMagnitude(t *testing.T) {
 	})
 }
 
+func TestRoundPriceRespectsMaxDecimals(t *testing.T) {
+	d := exchange.MustParseDecimal
+	// Perps allow 6-szDecimals decimals: 5 sig figs of 1.234567 would be
+	// 1.2346, but an asset with szDecimals 4 only takes 2 decimals.
+	assert.Equal(t, "1.23", roundPrice(d("1.234567"), 5, AssetInfo{SzDecimals: 4}).String())
+	assert.Equal(t, "1.2346", roundPrice(d("1.234567"), 5, AssetInfo{SzDecimals: 2}).String())
+	assert.Equal(t, "0.0123", roundPrice(d("0.0123456"), 5, AssetInfo{IsSpot: true, SzDecimals: 4}).String())
+	// Values already on the grid are left alone rather than nudged by
+	// float error (0.1 × 3 is 0.30000000000000004 in float64).
+	assert.Equal(t, "0.3", roundPrice(d("0.1").Mul(d("3")), 5, AssetInfo{}).String())
+}
+
 func TestIsFinite(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,376 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"nof0-api/pkg/exchange"
+)
+
+const (
+	defaultPingInterval     = 30 * time.Second
+	defaultReconnectMin     = 500 * time.Millisecond
+	defaultReconnectMax     = 30 * time.Second
+	defaultEventBufferSize  = 256
+	maxTrackedFillIDs       = 4096
+	wsWriteTimeout          = 10 * time.Second
+	wsSubscriptionUserFills = "userFills"
+	wsSubscriptionOrders    = "orderUpdates"
+	wsSubscriptionEvents    = "userEvents"
+)
+
+// wsRequest is the envelope for subscribe and ping messages.
+type wsRequest struct {
+	Method       string          `json:"method"`
+	Subscription *wsSubscription `json:"subscription,omitempty"`
+}
+
+type wsSubscription struct {
+	Type string `json:"type"`
+	User string `json:"user,omitempty"`
+}
+
+// wsMessage is the envelope for every server push.
+type wsMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type wsUserFills struct {
+	IsSnapshot bool       `json:"isSnapshot"`
+	User       string     `json:"user"`
+	Fills      []UserFill `json:"fills"`
+}
+
+// wsUserEvent is a userEvents ("user" channel) payload. Only fills are
+// surfaced; they are de-duplicated against the userFills channel by tid.
+type wsUserEvent struct {
+	Fills []UserFill `json:"fills,omitempty"`
+}
+
+// StreamOption customises a UserEventStream.
+type StreamOption func(*UserEventStream)
+
+// WithPingInterval sets how often keepalive pings are sent. The connection is
+// considered dead when nothing is received for two intervals.
+func WithPingInterval(interval time.Duration) StreamOption {
+	return func(s *UserEventStream) {
+		if interval > 0 {
+			s.pingInterval = interval
+		}
+	}
+}
+
+// WithReconnectBackoff bounds the exponential delay between reconnect attempts.
+func WithReconnectBackoff(min, max time.Duration) StreamOption {
+	return func(s *UserEventStream) {
+		if min > 0 {
+			s.minBackoff = min
+		}
+		if max >= s.minBackoff {
+			s.maxBackoff = max
+		}
+	}
+}
+
+// WithEventBufferSize sets the capacity of the channel returned by Events.
+func WithEventBufferSize(size int) StreamOption {
+	return func(s *UserEventStream) {
+		if size > 0 {
+			s.bufferSize = size
+		}
+	}
+}
+
+// UserEventStream subscribes to the userFills, orderUpdates and userEvents
+// websocket channels for the client's info address. It reconnects with
+// exponential backoff, resubscribes, and replays fills missed while
+// disconnected via userFillsByTime. Fills are de-duplicated by trade id.
+type UserEventStream struct {
+	url     string
+	user    string
+	dialer  *websocket.Dialer
+	logger  *log.Logger
+	clock   func() time.Time
+	recover func(ctx context.Context, startMs int64) ([]UserFill, error)
+
+	pingInterval time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	bufferSize   int
+
+	mu         sync.Mutex
+	started    bool
+	startedAt  time.Time
+	synced     bool // initial userFills snapshot consumed
+	seen       map[int64]struct{}
+	seenOrder  []int64
+	lastFillMs int64
+}
+
+// NewUserEventStream builds an event stream bound to the client's websocket
+// endpoint and info address. Gap recovery uses GetUserFillsByTime.
+func (c *Client) NewUserEventStream(opts ...StreamOption) *UserEventStream {
+	s := &UserEventStream{
+		url:    c.wsURL,
+		user:   c.getInfoAddress(),
+		dialer: websocket.DefaultDialer,
+		logger: c.logger,
+		clock:  c.clock,
+		recover: func(ctx context.Context, startMs int64) ([]UserFill, error) {
+			return c.GetUserFillsByTime(ctx, startMs, 0)
+		},
+		pingInterval: defaultPingInterval,
+		minBackoff:   defaultReconnectMin,
+		maxBackoff:   defaultReconnectMax,
+		bufferSize:   defaultEventBufferSize,
+		seen:         make(map[int64]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.clock == nil {
+		s.clock = time.Now
+	}
+	return s
+}
+
+// Events starts the stream and returns a channel closed once ctx is done.
+// A stream can only be started once.
+func (s *UserEventStream) Events(ctx context.Context) (<-chan exchange.Event, error) {
+	if s.user == "" {
+		return nil, fmt.Errorf("hyperliquid: client address unavailable")
+	}
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return nil, errors.New("hyperliquid: user event stream already started")
+	}
+	s.started = true
+	s.startedAt = s.clock()
+	s.mu.Unlock()
+
+	out := make(chan exchange.Event, s.bufferSize)
+	go s.run(ctx, out)
+	return out, nil
+}
+
+func (s *UserEventStream) run(ctx context.Context, out chan<- exchange.Event) {
+	defer close(out)
+	backoff := s.minBackoff
+	for attempt := 0; ; attempt++ {
+		connected, err := s.session(ctx, out, attempt > 0)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = s.minBackoff
+		}
+		s.logf("hyperliquid: user event stream disconnected: %v (reconnect in %s)", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// session runs a single connection until it fails or ctx is cancelled. It
+// reports whether the connection was established so the caller can reset
+// its backoff.
+func (s *UserEventStream) session(ctx context.Context, out chan<- exchange.Event, reconnect bool) (bool, error) {
+	conn, _, err := s.dialer.DialContext(ctx, s.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("hyperliquid: dial %s: %w", s.url, err)
+	}
+	defer conn.Close()
+
+	for _, typ := range []string{wsSubscriptionUserFills, wsSubscriptionOrders, wsSubscriptionEvents} {
+		if err := s.write(conn, wsRequest{Method: "subscribe", Subscription: &wsSubscription{Type: typ, User: s.user}}); err != nil {
+			return true, fmt.Errorf("hyperliquid: subscribe %s: %w", typ, err)
+		}
+	}
+	if reconnect {
+		if err := s.recoverGap(ctx, out); err != nil {
+			s.logf("hyperliquid: user event gap recovery failed: %v", err)
+		}
+	}
+
+	msgs := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(2 * s.pingInterval))
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case msgs <- data:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+			return true, ctx.Err()
+		case err := <-readErr:
+			return true, err
+		case <-ticker.C:
+			if err := s.write(conn, wsRequest{Method: "ping"}); err != nil {
+				return true, fmt.Errorf("hyperliquid: ping: %w", err)
+			}
+		case data := <-msgs:
+			if err := s.handleMessage(ctx, data, out); err != nil {
+				s.logf("hyperliquid: user event stream: %v", err)
+			}
+		}
+	}
+}
+
+func (s *UserEventStream) write(conn *websocket.Conn, msg wsRequest) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(msg)
+}
+
+func (s *UserEventStream) handleMessage(ctx context.Context, data []byte, out chan<- exchange.Event) error {
+	var msg wsMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("decode message: %w", err)
+	}
+	switch msg.Channel {
+	case "userFills":
+		var payload wsUserFills
+		if err := json.Unmarshal(msg.Data, &payload); err != nil {
+			return fmt.Errorf("decode userFills: %w", err)
+		}
+		if payload.IsSnapshot {
+			s.mu.Lock()
+			first := !s.synced
+			s.synced = true
+			startMs := s.startedAt.UnixMilli()
+			s.mu.Unlock()
+			if first {
+				// Fills from before the stream started are history, not
+				// news: seed the de-duplication state with them. Newer ones
+				// landed while the first connection was still coming up (or
+				// dropped before its snapshot) and are emitted.
+				var fresh []UserFill
+				for _, f := range payload.Fills {
+					if f.Time < startMs {
+						s.markSeen(f)
+						continue
+					}
+					fresh = append(fresh, f)
+				}
+				s.emitFills(ctx, out, fresh, true)
+				return nil
+			}
+			s.emitFills(ctx, out, payload.Fills, true)
+			return nil
+		}
+		s.emitFills(ctx, out, payload.Fills, false)
+	case "orderUpdates":
+		var updates []exchange.OrderStatus
+		if err := json.Unmarshal(msg.Data, &updates); err != nil {
+			return fmt.Errorf("decode orderUpdates: %w", err)
+		}
+		for i := range updates {
+			update := updates[i]
+			s.emit(ctx, out, exchange.Event{Type: exchange.EventOrderUpdate, Order: &update, ReceivedAt: s.clock()})
+		}
+	case "user":
+		var payload wsUserEvent
+		if err := json.Unmarshal(msg.Data, &payload); err != nil {
+			return fmt.Errorf("decode userEvents: %w", err)
+		}
+		s.emitFills(ctx, out, payload.Fills, false)
+	case "error":
+		return fmt.Errorf("server error: %s", string(msg.Data))
+	}
+	return nil
+}
+
+// recoverGap replays fills that landed while the stream was disconnected.
+func (s *UserEventStream) recoverGap(ctx context.Context, out chan<- exchange.Event) error {
+	s.mu.Lock()
+	since := s.lastFillMs
+	if start := s.startedAt.UnixMilli(); since < start {
+		since = start
+	}
+	synced := s.synced
+	s.mu.Unlock()
+	if !synced || s.recover == nil {
+		return nil
+	}
+	fills, err := s.recover(ctx, since)
+	if err != nil {
+		return err
+	}
+	s.emitFills(ctx, out, fills, true)
+	return nil
+}
+
+func (s *UserEventStream) emitFills(ctx context.Context, out chan<- exchange.Event, fills []UserFill, recovered bool) {
+	sort.SliceStable(fills, func(i, j int) bool { return fills[i].Time < fills[j].Time })
+	for _, f := range fills {
+		if !s.markSeen(f) {
+			continue
+		}
+		fill := f.ToExchangeFill()
+		s.emit(ctx, out, exchange.Event{Type: exchange.EventFill, Fill: &fill, Recovered: recovered, ReceivedAt: s.clock()})
+	}
+}
+
+func (s *UserEventStream) emit(ctx context.Context, out chan<- exchange.Event, ev exchange.Event) {
+	select {
+	case out <- ev:
+	case <-ctx.Done():
+	}
+}
+
+// markSeen records the fill and reports whether it had not been seen before.
+func (s *UserEventStream) markSeen(f UserFill) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Time > s.lastFillMs {
+		s.lastFillMs = f.Time
+	}
+	if _, ok := s.seen[f.Tid]; ok {
+		return false
+	}
+	s.seen[f.Tid] = struct{}{}
+	s.seenOrder = append(s.seenOrder, f.Tid)
+	if len(s.seenOrder) > maxTrackedFillIDs {
+		delete(s.seen, s.seenOrder[0])
+		s.seenOrder = s.seenOrder[1:]
+	}
+	return true
+}
+
+func (s *UserEventStream) logf(format string, args ...interface{}) {
+	if s.logger != nil {
+		s.logger.Printf(format, args...)
+	}
+}
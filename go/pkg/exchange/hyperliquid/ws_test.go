@@ -0,0 +1,216 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+const wsTestPrivateKey = "0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a741b52d7c5d5095e2f"
+
+// wsStandIn is a local websocket server that plays a scripted session per
+// connection and records subscriptions.
+type wsStandIn struct {
+	t        *testing.T
+	upgrader websocket.Upgrader
+	sessions []func(conn *websocket.Conn)
+	conns    atomic.Int32
+
+	mu   sync.Mutex
+	subs []wsSubscription
+}
+
+func (s *wsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	require.NoError(s.t, err)
+	defer conn.Close()
+	idx := int(s.conns.Add(1)) - 1
+	for i := 0; i < 3; i++ {
+		var req wsRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		if req.Method == "subscribe" && req.Subscription != nil {
+			s.mu.Lock()
+			s.subs = append(s.subs, *req.Subscription)
+			s.mu.Unlock()
+		}
+	}
+	if idx < len(s.sessions) {
+		s.sessions[idx](conn)
+		return
+	}
+	// Idle until the client disconnects.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func wsSend(t *testing.T, conn *websocket.Conn, channel string, data interface{}) {
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteJSON(wsMessage{Channel: channel, Data: raw}))
+}
+
+func testFill(tid int64, ts int64, dir string) UserFill {
+	return UserFill{Coin: "BTC", Px: "100", Sz: "0.1", Side: "A", Time: ts, Dir: dir, ClosedPnl: "1.5", Oid: 10 + tid, Tid: tid, Fee: "0.01", FeeToken: "USDC"}
+}
+
+func nextEvent(t *testing.T, ch <-chan exchange.Event) exchange.Event {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		require.True(t, ok, "event channel closed unexpectedly")
+		return ev
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return exchange.Event{}
+}
+
+func TestUserEventStreamReconnectAndRecover(t *testing.T) {
+	standIn := &wsStandIn{t: t}
+	standIn.sessions = []func(conn *websocket.Conn){
+		func(conn *websocket.Conn) {
+			wsSend(t, conn, "subscriptionResponse", map[string]string{"method": "subscribe"})
+			wsSend(t, conn, "userFills", wsUserFills{IsSnapshot: true, Fills: []UserFill{testFill(1, 1000, "Open Long")}})
+			wsSend(t, conn, "userFills", wsUserFills{Fills: []UserFill{testFill(2, 2000, "Close Long")}})
+			wsSend(t, conn, "orderUpdates", []exchange.OrderStatus{{
+				Order:  exchange.OrderInfo{Coin: "BTC", Side: "A", LimitPx: "99", Sz: "0", Oid: 12, OrigSz: "0.1"},
+				Status: "triggered",
+			}})
+			// Same fill again through userEvents must be de-duplicated.
+			wsSend(t, conn, "user", wsUserEvent{Fills: []UserFill{testFill(2, 2000, "Close Long")}})
+			// Drop the connection to force a reconnect.
+		},
+		func(conn *websocket.Conn) {
+			wsSend(t, conn, "userFills", wsUserFills{IsSnapshot: true, Fills: []UserFill{
+				testFill(1, 1000, "Open Long"),
+				testFill(2, 2000, "Close Long"),
+				testFill(3, 3000, "Open Short"),
+				testFill(4, 4000, "Close Short"),
+			}})
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		},
+	}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	client, err := NewClient(wsTestPrivateKey, true, WithWebSocketURL("ws"+strings.TrimPrefix(server.URL, "http")))
+	require.NoError(t, err)
+	stream := client.NewUserEventStream(WithReconnectBackoff(10*time.Millisecond, 50*time.Millisecond), WithPingInterval(time.Second))
+	var recoveredSince atomic.Int64
+	stream.recover = func(ctx context.Context, startMs int64) ([]UserFill, error) {
+		recoveredSince.Store(startMs)
+		return []UserFill{testFill(2, 2000, "Close Long"), testFill(3, 3000, "Open Short")}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := stream.Events(ctx)
+	require.NoError(t, err)
+	_, err = stream.Events(ctx)
+	require.Error(t, err, "stream can only be started once")
+
+	ev := nextEvent(t, events)
+	require.Equal(t, exchange.EventFill, ev.Type)
+	require.Equal(t, int64(2), ev.Fill.Tid)
+	require.False(t, ev.Recovered)
+	require.True(t, ev.Fill.IsClose())
+	require.Equal(t, "1.5", ev.Fill.ClosedPnl)
+
+	ev = nextEvent(t, events)
+	require.Equal(t, exchange.EventOrderUpdate, ev.Type)
+	require.Equal(t, "triggered", ev.Order.Status)
+	require.Equal(t, int64(12), ev.Order.Order.Oid)
+
+	ev = nextEvent(t, events)
+	require.Equal(t, int64(3), ev.Fill.Tid, "REST recovery replays the missed fill")
+	require.True(t, ev.Recovered)
+	require.GreaterOrEqual(t, recoveredSince.Load(), int64(2000))
+
+	ev = nextEvent(t, events)
+	require.Equal(t, int64(4), ev.Fill.Tid, "reconnect snapshot surfaces remaining unseen fills")
+	require.True(t, ev.Recovered)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		require.False(t, ok, "no further events expected")
+	case <-time.After(3 * time.Second):
+		t.Fatal("event channel not closed after cancel")
+	}
+
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+	require.Len(t, standIn.subs, 6, "three subscriptions per connection")
+	require.Equal(t, wsSubscriptionUserFills, standIn.subs[3].Type, "resubscribed after reconnect")
+	require.Equal(t, client.getInfoAddress(), standIn.subs[0].User)
+}
+
+func TestUserEventStreamDropBeforeSnapshot(t *testing.T) {
+	standIn := &wsStandIn{t: t}
+	standIn.sessions = []func(conn *websocket.Conn){
+		func(conn *websocket.Conn) {
+			// Drop before the initial snapshot arrives.
+			wsSend(t, conn, "subscriptionResponse", map[string]string{"method": "subscribe"})
+		},
+		func(conn *websocket.Conn) {
+			wsSend(t, conn, "userFills", wsUserFills{IsSnapshot: true, Fills: []UserFill{
+				testFill(1, 4000, "Open Long"),
+				testFill(2, 6000, "Close Long"),
+			}})
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		},
+	}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	client, err := NewClient(wsTestPrivateKey, true, WithWebSocketURL("ws"+strings.TrimPrefix(server.URL, "http")))
+	require.NoError(t, err)
+	stream := client.NewUserEventStream(WithReconnectBackoff(10*time.Millisecond, 50*time.Millisecond), WithPingInterval(time.Second))
+	stream.clock = func() time.Time { return time.UnixMilli(5000) }
+	stream.recover = func(ctx context.Context, startMs int64) ([]UserFill, error) { return nil, nil }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := stream.Events(ctx)
+	require.NoError(t, err)
+
+	ev := nextEvent(t, events)
+	require.Equal(t, int64(2), ev.Fill.Tid, "a stop fill after the stream started is not swallowed as history")
+	require.True(t, ev.Recovered)
+	require.True(t, ev.Fill.IsClose())
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected event %+v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestProviderEventsRequiresStreamingClient(t *testing.T) {
+	p := &Provider{client: new(MockClient)}
+	_, err := p.Events(context.Background())
+	require.Error(t, err)
+	require.True(t, exchange.CapabilitiesOf(p).EventStream)
+}
@@ -0,0 +1,548 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OrderState is the lifecycle state of an order tracked by OrderTracker.
+type OrderState string
+
+const (
+	// OrderStatePending marks an order submitted without a venue answer yet.
+	OrderStatePending OrderState = "pending"
+	// OrderStateResting marks an order open on the book with nothing filled.
+	OrderStateResting OrderState = "resting"
+	// OrderStatePartiallyFilled marks an open order with some size filled.
+	OrderStatePartiallyFilled OrderState = "partially_filled"
+	// OrderStateFilled marks an order whose full size executed.
+	OrderStateFilled OrderState = "filled"
+	// OrderStateCancelled marks an order that left the book before filling
+	// completely, including the unfilled remainder of IOC orders.
+	OrderStateCancelled OrderState = "cancelled"
+	// OrderStateRejected marks an order the venue refused.
+	OrderStateRejected OrderState = "rejected"
+	// OrderStateUnknown marks an order whose submission returned neither an
+	// error nor a venue answer. Reconcile resolves it by cloid or expires it.
+	OrderStateUnknown OrderState = "unknown"
+)
+
+// Terminal reports whether the state is final.
+func (s OrderState) Terminal() bool {
+	return s == OrderStateFilled || s == OrderStateCancelled || s == OrderStateRejected
+}
+
+// defaultOrderCapacity bounds how many orders an OrderTracker remembers.
+const defaultOrderCapacity = 4096
+
+// defaultPendingExpiry is how long an order may go without a venue answer
+// before Reconcile stops waiting for one.
+const defaultPendingExpiry = 2 * time.Minute
+
+// sizeEpsilon absorbs decimal rounding when comparing filled and requested
+// sizes.
+const sizeEpsilon = 1e-9
+
+// TrackedOrder is an OrderTracker's view of one submitted order.
+type TrackedOrder struct {
+	Cloid       string     `json:"cloid"`
+	Oid         int64      `json:"oid,omitempty"`
+	TwapID      int64      `json:"twapId,omitempty"` // venue TWAP working the order, if any
+	Account     string     `json:"account"`          // scope for Reconcile, e.g. the provider or sub-account name
+	Owner       string     `json:"owner"`            // e.g. the trader id
+	Coin        string     `json:"coin"`
+	IsBuy       bool       `json:"isBuy"`
+	Size        float64    `json:"size"` // requested size; 0 when unknown, e.g. position closes
+	LimitPx     string     `json:"limitPx,omitempty"`
+	FilledSz    float64    `json:"filledSz"`
+	AvgPx       float64    `json:"avgPx"`
+	State       OrderState `json:"state"`
+	Error       string     `json:"error,omitempty"`
+	SubmittedAt time.Time  `json:"submittedAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// trackedOrder keeps the executions reported by order responses and updates
+// apart from those summed from fills, so a fill seen through both is only
+// counted once.
+type trackedOrder struct {
+	TrackedOrder
+	reportedSz   float64
+	reportedPx   float64
+	fillSz       float64
+	fillNotional float64
+	tids         map[int64]struct{}
+}
+
+// settle derives FilledSz and AvgPx from whichever source saw more.
+func (o *trackedOrder) settle() {
+	if o.fillSz > 0 && o.fillSz >= o.reportedSz-sizeEpsilon {
+		o.FilledSz = o.fillSz
+		o.AvgPx = o.fillNotional / o.fillSz
+		return
+	}
+	o.FilledSz = o.reportedSz
+	if o.reportedPx > 0 {
+		o.AvgPx = o.reportedPx
+	}
+}
+
+// OrderTracker records submitted orders by client order id (cloid) and
+// derives their state from order responses, streamed order updates, fills
+// and GetOpenOrders polling. The cloid only has to be unique locally; orders
+// sent without one are matched by the oid from their response. It is safe
+// for concurrent use.
+type OrderTracker struct {
+	mu       sync.Mutex
+	orders   map[string]*trackedOrder
+	byOid    map[int64]string
+	sequence []string
+	capacity int
+	expiry   time.Duration
+	clock    func() time.Time
+	listener func(TrackedOrder)
+}
+
+// OrderTrackerOption customises an OrderTracker.
+type OrderTrackerOption func(*OrderTracker)
+
+// WithOrderCapacity bounds how many orders are remembered (default 4096);
+// the oldest finished orders are forgotten first.
+func WithOrderCapacity(n int) OrderTrackerOption {
+	return func(t *OrderTracker) {
+		if n > 0 {
+			t.capacity = n
+		}
+	}
+}
+
+// WithPendingExpiry sets how long an order may stay pending or unknown
+// without an oid before Reconcile settles it (default 2m).
+func WithPendingExpiry(d time.Duration) OrderTrackerOption {
+	return func(t *OrderTracker) {
+		if d > 0 {
+			t.expiry = d
+		}
+	}
+}
+
+// WithOrderListener registers fn to receive every tracked order after it is
+// added or changes state or fill. fn runs synchronously, outside the
+// tracker's lock.
+func WithOrderListener(fn func(TrackedOrder)) OrderTrackerOption {
+	return func(t *OrderTracker) {
+		t.listener = fn
+	}
+}
+
+// WithOrderClock overrides the clock used for timestamps.
+func WithOrderClock(clock func() time.Time) OrderTrackerOption {
+	return func(t *OrderTracker) {
+		if clock != nil {
+			t.clock = clock
+		}
+	}
+}
+
+// NewOrderTracker returns an empty tracker.
+func NewOrderTracker(opts ...OrderTrackerOption) *OrderTracker {
+	t := &OrderTracker{
+		orders:   make(map[string]*trackedOrder),
+		byOid:    make(map[int64]string),
+		capacity: defaultOrderCapacity,
+		expiry:   defaultPendingExpiry,
+		clock:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Track registers order as pending. Cloid is required and must not already
+// be tracked.
+func (t *OrderTracker) Track(order TrackedOrder) error {
+	order.Cloid = strings.ToLower(strings.TrimSpace(order.Cloid))
+	if order.Cloid == "" {
+		return fmt.Errorf("exchange: track order: cloid is required")
+	}
+	t.mu.Lock()
+	if _, ok := t.orders[order.Cloid]; ok {
+		t.mu.Unlock()
+		return fmt.Errorf("exchange: track order: cloid %s already tracked", order.Cloid)
+	}
+	now := t.clock()
+	if order.SubmittedAt.IsZero() {
+		order.SubmittedAt = now
+	}
+	order.UpdatedAt = now
+	order.State = OrderStatePending
+	order.FilledSz, order.AvgPx, order.Error = 0, 0, ""
+	entry := &trackedOrder{TrackedOrder: order}
+	t.orders[order.Cloid] = entry
+	if order.Oid != 0 {
+		t.byOid[order.Oid] = order.Cloid
+	}
+	t.sequence = append(t.sequence, order.Cloid)
+	t.evictLocked()
+	snapshot := entry.TrackedOrder
+	t.mu.Unlock()
+	t.notify([]TrackedOrder{snapshot})
+	return nil
+}
+
+// ApplyResponse records the outcome of submitting the order with cloid: err
+// or an "err" response rejects it, otherwise the first order status decides
+// between resting, filled and cancelled (a fill short of Size leaves no
+// remainder on the book). It returns the updated order.
+func (t *OrderTracker) ApplyResponse(cloid string, resp *OrderResponse, err error) (TrackedOrder, bool) {
+	t.mu.Lock()
+	entry, ok := t.orders[strings.ToLower(strings.TrimSpace(cloid))]
+	if !ok {
+		t.mu.Unlock()
+		return TrackedOrder{}, false
+	}
+	before := entry.TrackedOrder
+	switch {
+	case err != nil:
+		t.rejectLocked(entry, err.Error())
+	case resp == nil:
+		if entry.State == OrderStatePending {
+			entry.State = OrderStateUnknown
+		}
+	case resp.Status == "err":
+		t.rejectLocked(entry, firstNonEmpty(resp.ErrorMessage, "order rejected"))
+	case len(resp.Response.Data.Statuses) > 0:
+		status := resp.Response.Data.Statuses[0]
+		switch {
+		case status.Error != "":
+			t.rejectLocked(entry, status.Error)
+		case status.Filled != nil:
+			t.setOidLocked(entry, status.Filled.Oid)
+			if filled := parseOrderFloat(status.Filled.TotalSz); filled > entry.reportedSz {
+				entry.reportedSz = filled
+				entry.reportedPx = parseOrderFloat(status.Filled.AvgPx)
+			}
+			entry.settle()
+			if entry.Size > 0 && entry.FilledSz < entry.Size-sizeEpsilon {
+				entry.State = OrderStateCancelled
+			} else {
+				entry.State = OrderStateFilled
+			}
+		case status.Resting != nil:
+			t.setOidLocked(entry, status.Resting.Oid)
+			if !entry.State.Terminal() && entry.State != OrderStatePartiallyFilled {
+				entry.State = OrderStateResting
+			}
+		}
+	}
+	return t.finishLocked(entry, before)
+}
+
+// SetTWAP records the venue TWAP working the order with cloid; the order
+// rests until the TWAP stops. It returns the updated order.
+func (t *OrderTracker) SetTWAP(cloid string, twapID int64) (TrackedOrder, bool) {
+	t.mu.Lock()
+	entry, ok := t.orders[strings.ToLower(strings.TrimSpace(cloid))]
+	if !ok {
+		t.mu.Unlock()
+		return TrackedOrder{}, false
+	}
+	before := entry.TrackedOrder
+	entry.TwapID = twapID
+	if !entry.State.Terminal() && entry.State != OrderStatePartiallyFilled {
+		entry.State = OrderStateResting
+	}
+	return t.finishLocked(entry, before)
+}
+
+// ApplyUpdate applies a streamed order update, matched by cloid or oid.
+// Hyperliquid statuses map as: "open"/"triggered" rest, "filled" fills,
+// "*canceled" cancels and "*rejected" rejects.
+func (t *OrderTracker) ApplyUpdate(update OrderStatus) (TrackedOrder, bool) {
+	t.mu.Lock()
+	entry := t.lookupLocked(update.Order.Cloid, update.Order.Oid)
+	if entry == nil {
+		t.mu.Unlock()
+		return TrackedOrder{}, false
+	}
+	before := entry.TrackedOrder
+	t.setOidLocked(entry, update.Order.Oid)
+	status := strings.ToLower(strings.TrimSpace(update.Status))
+	switch {
+	case status == "open" || status == "triggered":
+		t.applyOpenLocked(entry, update.Order)
+	case status == "filled":
+		if entry.Size > 0 && entry.reportedSz < entry.Size {
+			entry.reportedSz = entry.Size
+			entry.settle()
+		}
+		entry.State = OrderStateFilled
+	case strings.HasSuffix(status, "canceled") || strings.HasSuffix(status, "cancelled"):
+		if !entry.State.Terminal() {
+			entry.State = OrderStateCancelled
+		}
+	case strings.HasSuffix(status, "rejected"):
+		if !entry.State.Terminal() {
+			t.rejectLocked(entry, update.Status)
+		}
+	}
+	return t.finishLocked(entry, before)
+}
+
+// ApplyFill adds an execution to the order it belongs to, matched by cloid
+// or oid. Fills are de-duplicated by trade id.
+func (t *OrderTracker) ApplyFill(fill Fill) (TrackedOrder, bool) {
+	t.mu.Lock()
+	entry := t.lookupLocked(fill.Cloid, fill.Oid)
+	if entry == nil {
+		t.mu.Unlock()
+		return TrackedOrder{}, false
+	}
+	before := entry.TrackedOrder
+	if fill.Tid != 0 {
+		if _, seen := entry.tids[fill.Tid]; seen {
+			t.mu.Unlock()
+			return before, true
+		}
+		if entry.tids == nil {
+			entry.tids = make(map[int64]struct{})
+		}
+		entry.tids[fill.Tid] = struct{}{}
+	}
+	t.setOidLocked(entry, fill.Oid)
+	px := parseOrderFloat(fill.Px)
+	if px <= 0 {
+		px = parseOrderFloat(fill.AvgPx)
+	}
+	if sz := parseOrderFloat(fill.Sz); sz > 0 && px > 0 {
+		entry.fillSz += sz
+		entry.fillNotional += px * sz
+		entry.settle()
+	}
+	if entry.Size > 0 && entry.FilledSz >= entry.Size-sizeEpsilon {
+		entry.State = OrderStateFilled
+	} else if !entry.State.Terminal() && entry.FilledSz > 0 {
+		entry.State = OrderStatePartiallyFilled
+	}
+	return t.finishLocked(entry, before)
+}
+
+// Reconcile updates the unfinished orders of account from a GetOpenOrders
+// result, matched by cloid or oid. Orders still listed rest or are partially
+// filled; orders with an oid that are no longer listed are filled when their
+// recorded fills cover Size (or any fill when Size is unknown) and cancelled
+// otherwise, so apply fills before reconciling. Pending or unknown orders
+// without an oid are settled the same way once they are older than the
+// pending expiry; orders the venue reported open, such as TWAPs, never
+// expire. It returns the orders that changed.
+func (t *OrderTracker) Reconcile(account string, open []OrderStatus) []TrackedOrder {
+	t.mu.Lock()
+	listed := make(map[*trackedOrder]OrderInfo, len(open))
+	for _, status := range open {
+		if entry := t.lookupLocked(status.Order.Cloid, status.Order.Oid); entry != nil {
+			listed[entry] = status.Order
+		}
+	}
+	var changed []TrackedOrder
+	for _, cloid := range t.sequence {
+		entry := t.orders[cloid]
+		if entry == nil || entry.Account != account || entry.State.Terminal() {
+			continue
+		}
+		before := entry.TrackedOrder
+		if info, ok := listed[entry]; ok {
+			t.setOidLocked(entry, info.Oid)
+			t.applyOpenLocked(entry, info)
+		} else if entry.Oid != 0 || t.expiredLocked(entry) {
+			if entry.FilledSz > 0 && (entry.Size == 0 || entry.FilledSz >= entry.Size-sizeEpsilon) {
+				entry.State = OrderStateFilled
+			} else {
+				if entry.Oid == 0 && entry.FilledSz == 0 {
+					entry.Error = "no venue answer"
+				}
+				entry.State = OrderStateCancelled
+			}
+		}
+		if entry.TrackedOrder != before {
+			entry.UpdatedAt = t.clock()
+			changed = append(changed, entry.TrackedOrder)
+		}
+	}
+	t.mu.Unlock()
+	t.notify(changed)
+	return changed
+}
+
+// Get returns the order tracked under cloid.
+func (t *OrderTracker) Get(cloid string) (TrackedOrder, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.orders[strings.ToLower(strings.TrimSpace(cloid))]
+	if !ok {
+		return TrackedOrder{}, false
+	}
+	return entry.TrackedOrder, true
+}
+
+// GetByOid returns the order the venue assigned oid to.
+func (t *OrderTracker) GetByOid(oid int64) (TrackedOrder, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry := t.lookupLocked("", oid)
+	if entry == nil {
+		return TrackedOrder{}, false
+	}
+	return entry.TrackedOrder, true
+}
+
+// Orders returns the tracked orders of owner, oldest first. An empty owner
+// returns every order.
+func (t *OrderTracker) Orders(owner string) []TrackedOrder {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []TrackedOrder
+	for _, cloid := range t.sequence {
+		if entry := t.orders[cloid]; entry != nil && (owner == "" || entry.Owner == owner) {
+			out = append(out, entry.TrackedOrder)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].SubmittedAt.Before(out[j].SubmittedAt) })
+	return out
+}
+
+// HasActive reports whether account has unfinished orders.
+func (t *OrderTracker) HasActive(account string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, entry := range t.orders {
+		if entry.Account == account && !entry.State.Terminal() {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *OrderTracker) lookupLocked(cloid string, oid int64) *trackedOrder {
+	if cloid = strings.ToLower(strings.TrimSpace(cloid)); cloid != "" {
+		if entry, ok := t.orders[cloid]; ok {
+			return entry
+		}
+	}
+	if oid != 0 {
+		if cloid, ok := t.byOid[oid]; ok {
+			return t.orders[cloid]
+		}
+	}
+	return nil
+}
+
+func (t *OrderTracker) setOidLocked(entry *trackedOrder, oid int64) {
+	if oid == 0 || entry.Oid == oid {
+		return
+	}
+	if entry.Oid != 0 {
+		delete(t.byOid, entry.Oid)
+	}
+	entry.Oid = oid
+	t.byOid[oid] = entry.Cloid
+}
+
+// applyOpenLocked handles an order reported open with info's remaining size.
+// expiredLocked reports whether entry has waited too long for a venue answer.
+func (t *OrderTracker) expiredLocked(entry *trackedOrder) bool {
+	if entry.State != OrderStatePending && entry.State != OrderStateUnknown {
+		return false
+	}
+	return t.clock().Sub(entry.SubmittedAt) >= t.expiry
+}
+
+func (t *OrderTracker) applyOpenLocked(entry *trackedOrder, info OrderInfo) {
+	if entry.State.Terminal() {
+		return
+	}
+	orig := parseOrderFloat(info.OrigSz)
+	remaining := parseOrderFloat(info.Sz)
+	if entry.Size == 0 && orig > 0 {
+		entry.Size = orig
+	}
+	if orig > 0 && orig-remaining > entry.reportedSz {
+		entry.reportedSz = orig - remaining
+		entry.settle()
+	}
+	if entry.FilledSz > 0 {
+		entry.State = OrderStatePartiallyFilled
+	} else {
+		entry.State = OrderStateResting
+	}
+}
+
+func (t *OrderTracker) rejectLocked(entry *trackedOrder, reason string) {
+	entry.State = OrderStateRejected
+	entry.Error = reason
+}
+
+// finishLocked stamps entry when it changed, releases the lock and notifies
+// the listener.
+func (t *OrderTracker) finishLocked(entry *trackedOrder, before TrackedOrder) (TrackedOrder, bool) {
+	changed := entry.TrackedOrder != before
+	if changed {
+		entry.UpdatedAt = t.clock()
+	}
+	snapshot := entry.TrackedOrder
+	t.mu.Unlock()
+	if changed {
+		t.notify([]TrackedOrder{snapshot})
+	}
+	return snapshot, true
+}
+
+func (t *OrderTracker) notify(orders []TrackedOrder) {
+	if t.listener == nil {
+		return
+	}
+	for _, order := range orders {
+		t.listener(order)
+	}
+}
+
+// evictLocked forgets the oldest orders beyond capacity, preferring
+// finished ones.
+func (t *OrderTracker) evictLocked() {
+	for len(t.sequence) > t.capacity {
+		victim := 0
+		for i, cloid := range t.sequence {
+			if entry := t.orders[cloid]; entry == nil || entry.State.Terminal() {
+				victim = i
+				break
+			}
+		}
+		cloid := t.sequence[victim]
+		if entry := t.orders[cloid]; entry != nil && entry.Oid != 0 {
+			delete(t.byOid, entry.Oid)
+		}
+		delete(t.orders, cloid)
+		t.sequence = append(t.sequence[:victim], t.sequence[victim+1:]...)
+	}
+}
+
+func parseOrderFloat(value string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
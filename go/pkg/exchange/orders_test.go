@@ -0,0 +1,181 @@
+package exchange_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	exchange "nof0-api/pkg/exchange"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func filledResponse(oid int64, totalSz, avgPx string) *exchange.OrderResponse {
+	resp := &exchange.OrderResponse{Status: "ok"}
+	resp.Response.Data.Statuses = []exchange.OrderStatusResponse{{Filled: &exchange.FilledOrder{Oid: oid, TotalSz: totalSz, AvgPx: avgPx}}}
+	return resp
+}
+
+func restingResponse(oid int64) *exchange.OrderResponse {
+	resp := &exchange.OrderResponse{Status: "ok"}
+	resp.Response.Data.Statuses = []exchange.OrderStatusResponse{{Resting: &exchange.RestingOrder{Oid: oid}}}
+	return resp
+}
+
+func TestOrderTrackerResponses(t *testing.T) {
+	var updates []exchange.TrackedOrder
+	tracker := exchange.NewOrderTracker(exchange.WithOrderListener(func(o exchange.TrackedOrder) {
+		updates = append(updates, o)
+	}))
+
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0xAA", Account: "hl", Owner: "t1", Coin: "BTC", IsBuy: true, Size: 2}))
+	assert.ErrorContains(t, tracker.Track(exchange.TrackedOrder{Cloid: "0xaa"}), "already tracked")
+	assert.ErrorContains(t, tracker.Track(exchange.TrackedOrder{}), "cloid is required")
+
+	order, ok := tracker.ApplyResponse("0xaa", filledResponse(11, "2", "100.5"), nil)
+	require.True(t, ok)
+	assert.Equal(t, exchange.OrderStateFilled, order.State)
+	assert.Equal(t, int64(11), order.Oid)
+	assert.InDelta(t, 100.5, order.AvgPx, 1e-9)
+
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0xbb", Owner: "t1", Coin: "ETH", Size: 3}))
+	order, _ = tracker.ApplyResponse("0xbb", filledResponse(12, "1", "2000"), nil)
+	assert.Equal(t, exchange.OrderStateCancelled, order.State, "IOC remainder is cancelled")
+	assert.InDelta(t, 1, order.FilledSz, 1e-9)
+
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0xcc", Owner: "t2", Coin: "SOL", Size: 1}))
+	order, _ = tracker.ApplyResponse("0xcc", nil, errors.New("insufficient margin"))
+	assert.Equal(t, exchange.OrderStateRejected, order.State)
+	assert.Equal(t, "insufficient margin", order.Error)
+
+	_, ok = tracker.ApplyResponse("0xdd", nil, nil)
+	assert.False(t, ok)
+
+	require.Len(t, updates, 6)
+	assert.Equal(t, exchange.OrderStatePending, updates[0].State)
+
+	got, ok := tracker.GetByOid(12)
+	require.True(t, ok)
+	assert.Equal(t, "0xbb", got.Cloid)
+	assert.Len(t, tracker.Orders("t1"), 2)
+	assert.Len(t, tracker.Orders(""), 3)
+}
+
+func TestOrderTrackerUpdatesAndFills(t *testing.T) {
+	tracker := exchange.NewOrderTracker()
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x01", Account: "hl", Coin: "BTC", Size: 4}))
+	order, _ := tracker.ApplyResponse("0x01", restingResponse(21), nil)
+	assert.Equal(t, exchange.OrderStateResting, order.State)
+	assert.True(t, tracker.HasActive("hl"))
+
+	order, ok := tracker.ApplyFill(exchange.Fill{Oid: 21, Tid: 1, Px: "100", Sz: "1"})
+	require.True(t, ok)
+	assert.Equal(t, exchange.OrderStatePartiallyFilled, order.State)
+	order, _ = tracker.ApplyFill(exchange.Fill{Oid: 21, Tid: 1, Px: "100", Sz: "1"})
+	assert.InDelta(t, 1, order.FilledSz, 1e-9, "fills are de-duplicated by tid")
+
+	order, _ = tracker.ApplyUpdate(exchange.OrderStatus{Status: "open", Order: exchange.OrderInfo{Oid: 21, Sz: "2", OrigSz: "4"}})
+	assert.Equal(t, exchange.OrderStatePartiallyFilled, order.State)
+	assert.InDelta(t, 2, order.FilledSz, 1e-9)
+
+	order, _ = tracker.ApplyFill(exchange.Fill{Oid: 21, Tid: 2, Px: "110", Sz: "3"})
+	assert.Equal(t, exchange.OrderStateFilled, order.State)
+	assert.InDelta(t, 4, order.FilledSz, 1e-9)
+	assert.InDelta(t, 107.5, order.AvgPx, 1e-9)
+	assert.False(t, tracker.HasActive("hl"))
+
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x02", Coin: "ETH", Size: 1}))
+	order, _ = tracker.ApplyUpdate(exchange.OrderStatus{Status: "marginCanceled", Order: exchange.OrderInfo{Cloid: "0x02", Oid: 22}})
+	assert.Equal(t, exchange.OrderStateCancelled, order.State)
+	assert.Equal(t, int64(22), order.Oid)
+	order, _ = tracker.ApplyUpdate(exchange.OrderStatus{Status: "open", Order: exchange.OrderInfo{Oid: 22, Sz: "1", OrigSz: "1"}})
+	assert.Equal(t, exchange.OrderStateCancelled, order.State, "terminal states stick")
+}
+
+func TestOrderTrackerReconcile(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := exchange.NewOrderTracker(exchange.WithOrderClock(func() time.Time { return now }))
+	for i, cloid := range []string{"0x01", "0x02", "0x03", "0x04"} {
+		require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: cloid, Account: "hl", Coin: "BTC", Size: 2}))
+		if i < 3 {
+			tracker.ApplyResponse(cloid, restingResponse(int64(31+i)), nil)
+		}
+	}
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x05", Account: "other", Coin: "BTC", Size: 2}))
+	tracker.ApplyResponse("0x05", restingResponse(35), nil)
+	tracker.ApplyFill(exchange.Fill{Oid: 32, Tid: 9, Px: "100", Sz: "2"})
+	tracker.ApplyFill(exchange.Fill{Oid: 33, Tid: 10, Px: "100", Sz: "0.5"})
+
+	changed := tracker.Reconcile("hl", []exchange.OrderStatus{
+		{Status: "open", Order: exchange.OrderInfo{Oid: 31, Sz: "1.5", OrigSz: "2"}},
+	})
+	require.Len(t, changed, 2)
+
+	states := map[string]exchange.OrderState{}
+	for _, o := range tracker.Orders("") {
+		states[o.Cloid] = o.State
+	}
+	assert.Equal(t, map[string]exchange.OrderState{
+		"0x01": exchange.OrderStatePartiallyFilled, // still listed
+		"0x02": exchange.OrderStateFilled,          // filled by ApplyFill already
+		"0x03": exchange.OrderStateCancelled,       // gone with a partial fill
+		"0x04": exchange.OrderStatePending,         // no oid yet
+		"0x05": exchange.OrderStateResting,         // other account
+	}, states)
+}
+
+func TestOrderTrackerExpiresUnansweredOrders(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := exchange.NewOrderTracker(
+		exchange.WithOrderClock(func() time.Time { return now }),
+		exchange.WithPendingExpiry(time.Minute),
+	)
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x01", Account: "hl", Coin: "BTC"}))
+	order, _ := tracker.ApplyResponse("0x01", nil, nil)
+	assert.Equal(t, exchange.OrderStateUnknown, order.State, "no error and no answer is not pending forever")
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x02", Account: "hl", Coin: "ETH", Size: 1}))
+	tracker.ApplyResponse("0x02", nil, nil)
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x03", Account: "hl", Coin: "SOL", Size: 5}))
+	twap, ok := tracker.SetTWAP("0x03", 77)
+	require.True(t, ok)
+	assert.Equal(t, exchange.OrderStateResting, twap.State)
+	assert.Equal(t, int64(77), twap.TwapID)
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x04", Account: "hl", Coin: "BTC", Size: 1}))
+	tracker.ApplyFill(exchange.Fill{Cloid: "0x04", Px: "100", Sz: "1"})
+
+	assert.Empty(t, tracker.Reconcile("hl", nil), "orders are given time to be answered")
+
+	now = now.Add(time.Minute)
+	changed := tracker.Reconcile("hl", []exchange.OrderStatus{
+		{Status: "open", Order: exchange.OrderInfo{Cloid: "0x02", Oid: 42, Sz: "1", OrigSz: "1"}},
+	})
+	require.Len(t, changed, 2)
+	states := map[string]exchange.OrderState{}
+	for _, o := range tracker.Orders("") {
+		states[o.Cloid] = o.State
+	}
+	assert.Equal(t, map[string]exchange.OrderState{
+		"0x01": exchange.OrderStateCancelled, // expired
+		"0x02": exchange.OrderStateResting,   // found open by cloid
+		"0x03": exchange.OrderStateResting,   // reported open, never expires
+		"0x04": exchange.OrderStateFilled,    // settled by its fill
+	}, states)
+	got, _ := tracker.Get("0x01")
+	assert.Equal(t, "no venue answer", got.Error)
+	got, _ = tracker.GetByOid(42)
+	assert.Equal(t, "0x02", got.Cloid)
+}
+
+func TestOrderTrackerCapacity(t *testing.T) {
+	tracker := exchange.NewOrderTracker(exchange.WithOrderCapacity(2))
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x01"}))
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x02"}))
+	tracker.ApplyResponse("0x02", nil, errors.New("rejected"))
+	require.NoError(t, tracker.Track(exchange.TrackedOrder{Cloid: "0x03"}))
+
+	_, ok := tracker.Get("0x02")
+	assert.False(t, ok, "finished orders are evicted first")
+	_, ok = tracker.Get("0x01")
+	assert.True(t, ok)
+}
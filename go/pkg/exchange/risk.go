@@ -0,0 +1,605 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRiskRejected is wrapped by every error a RiskGateway returns for an
+// order it refused to forward.
+var ErrRiskRejected = errors.New("exchange: risk limit exceeded")
+
+// RiskLimits are hard order-level limits enforced by RiskGateway, configured
+// per provider under the risk block of etc/exchange.yaml. Zero values disable
+// the corresponding check.
+type RiskLimits struct {
+	// MaxOrderNotionalUSD caps price x size of a single opening order.
+	MaxOrderNotionalUSD float64 `yaml:"max_order_notional_usd"`
+	// PriceBandPct rejects orders priced more than this percentage away from
+	// the latest mark (fat-finger protection). Market orders are checked by
+	// their slippage bound.
+	PriceBandPct float64 `yaml:"price_band_pct"`
+	// MaxOrdersPerMinute caps opening orders submitted in any rolling minute.
+	MaxOrdersPerMinute int `yaml:"max_orders_per_minute"`
+	// MaxGrossExposureUSD caps the summed absolute position value of the
+	// account, including the order being placed.
+	MaxGrossExposureUSD float64 `yaml:"max_gross_exposure_usd"`
+	// AllowedSymbols restricts opening orders to these coins; empty allows
+	// every coin.
+	AllowedSymbols []string `yaml:"allowed_symbols"`
+}
+
+// Enabled reports whether any limit is configured.
+func (l RiskLimits) Enabled() bool {
+	return l.MaxOrderNotionalUSD > 0 || l.PriceBandPct > 0 || l.MaxOrdersPerMinute > 0 ||
+		l.MaxGrossExposureUSD > 0 || len(l.AllowedSymbols) > 0
+}
+
+func (l RiskLimits) validate(name string) error {
+	if l.MaxOrderNotionalUSD < 0 || l.MaxGrossExposureUSD < 0 || l.MaxOrdersPerMinute < 0 {
+		return fmt.Errorf("exchange config: provider %s risk limits must be non-negative", name)
+	}
+	if l.PriceBandPct < 0 || l.PriceBandPct >= 100 {
+		return fmt.Errorf("exchange config: provider %s risk.price_band_pct must be in [0, 100)", name)
+	}
+	for _, coin := range l.AllowedSymbols {
+		if coin == "" {
+			return fmt.Errorf("exchange config: provider %s risk.allowed_symbols contains an empty symbol", name)
+		}
+	}
+	return nil
+}
+
+// Compile-time checks for the optional extensions the gateway forwards.
+var (
+	_ Provider              = (*RiskGateway)(nil)
+	_ MarketOrderer         = (*RiskGateway)(nil)
+	_ ProtectiveOrderer     = (*RiskGateway)(nil)
+	_ Formatter             = (*RiskGateway)(nil)
+	_ SymbolCanceller       = (*RiskGateway)(nil)
+	_ MarkPriceSetter       = (*RiskGateway)(nil)
+	_ FundingRateSetter     = (*RiskGateway)(nil)
+	_ EventStream           = (*RiskGateway)(nil)
+	_ FillHistory           = (*RiskGateway)(nil)
+	_ FundingHistory        = (*RiskGateway)(nil)
+	_ SubAccountProvider    = (*RiskGateway)(nil)
+	_ TWAPOrderer           = (*RiskGateway)(nil)
+	_ CancelScheduler       = (*RiskGateway)(nil)
+	_ IsolatedMarginUpdater = (*RiskGateway)(nil)
+	_ FundTransferer        = (*RiskGateway)(nil)
+	_ CredentialExpirer     = (*RiskGateway)(nil)
+	_ RateLimitSyncer       = (*RiskGateway)(nil)
+	_ CapabilityReporter    = (*RiskGateway)(nil)
+)
+
+// RiskGateway wraps a provider and refuses orders that breach its limits
+// before they reach the venue, independently of the checks callers run on
+// their own. Reduce-only orders are only held to the price band; closes,
+// stop-loss and take-profit orders and cancels are always forwarded, so a
+// breached limit never traps a position. Everything else passes straight
+// through.
+type RiskGateway struct {
+	inner   Provider
+	limits  RiskLimits
+	allowed map[string]bool
+	now     func() time.Time
+	marks   *riskMarks // shared with sub-account gateways
+
+	mu     sync.Mutex
+	coins  map[int]string // asset index -> coin
+	recent []time.Time    // opening orders within the last minute
+}
+
+// riskMarks holds the latest mark per coin.
+type riskMarks struct {
+	mu     sync.RWMutex
+	prices map[string]float64
+}
+
+func (m *riskMarks) get(coin string) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.prices[coin]
+}
+
+func (m *riskMarks) set(coin string, price float64) {
+	m.mu.Lock()
+	m.prices[coin] = price
+	m.mu.Unlock()
+}
+
+// RiskOption customises a RiskGateway.
+type RiskOption func(*RiskGateway)
+
+// WithRiskClock overrides the clock used for the order rate window.
+func WithRiskClock(now func() time.Time) RiskOption {
+	return func(g *RiskGateway) {
+		if now != nil {
+			g.now = now
+		}
+	}
+}
+
+// NewRiskGateway wraps inner with the given limits.
+func NewRiskGateway(inner Provider, limits RiskLimits, opts ...RiskOption) *RiskGateway {
+	g := newRiskGateway(inner, limits, &riskMarks{prices: make(map[string]float64)})
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+func newRiskGateway(inner Provider, limits RiskLimits, marks *riskMarks) *RiskGateway {
+	g := &RiskGateway{
+		inner:  inner,
+		limits: limits,
+		now:    time.Now,
+		marks:  marks,
+		coins:  make(map[int]string),
+	}
+	if len(limits.AllowedSymbols) > 0 {
+		g.allowed = make(map[string]bool, len(limits.AllowedSymbols))
+		for _, coin := range limits.AllowedSymbols {
+			g.allowed[normaliseCoin(coin)] = true
+		}
+	}
+	return g
+}
+
+// Unwrap returns the provider the gateway forwards to.
+func (g *RiskGateway) Unwrap() Provider { return g.inner }
+
+// Limits returns the limits the gateway enforces.
+func (g *RiskGateway) Limits() RiskLimits { return g.limits }
+
+// Capabilities reports the wrapped provider's capabilities; the gateway
+// always accepts marks for its price band.
+func (g *RiskGateway) Capabilities() Capabilities {
+	caps := CapabilitiesOf(g.inner)
+	caps.MarkPrice = true
+	return caps
+}
+
+// GetAssetIndex resolves coin on the wrapped provider and remembers the
+// mapping so orders can be checked by coin.
+func (g *RiskGateway) GetAssetIndex(ctx context.Context, coin string) (int, error) {
+	idx, err := g.inner.GetAssetIndex(ctx, coin)
+	if err != nil {
+		return 0, err
+	}
+	g.mu.Lock()
+	g.coins[idx] = normaliseCoin(coin)
+	g.mu.Unlock()
+	return idx, nil
+}
+
+// SetMarkPrice records the mark for the price band and forwards it when the
+// wrapped provider accepts marks.
+func (g *RiskGateway) SetMarkPrice(ctx context.Context, coin string, price float64) error {
+	if price > 0 && !math.IsInf(price, 0) {
+		g.marks.set(normaliseCoin(coin), price)
+	}
+	if setter, ok := g.inner.(MarkPriceSetter); ok {
+		return setter.SetMarkPrice(ctx, coin, price)
+	}
+	return nil
+}
+
+// PlaceOrder checks order against the limits and forwards it.
+func (g *RiskGateway) PlaceOrder(ctx context.Context, order Order) (*OrderResponse, error) {
+	g.mu.Lock()
+	coin, known := g.coins[order.Asset]
+	g.mu.Unlock()
+	if !known {
+		if g.limits.PriceBandPct > 0 || (g.allowed != nil && !order.ReduceOnly) {
+			return nil, g.reject("asset %d was not resolved through the gateway", order.Asset)
+		}
+		coin = strconv.Itoa(order.Asset)
+	}
+	if err := g.checkSymbol(coin, order.ReduceOnly); err != nil {
+		return nil, err
+	}
+	// Opening orders must carry well-formed amounts: a malformed price or
+	// size would otherwise read as zero and pass every check.
+	var px, triggerPx, sz float64
+	if order.ReduceOnly {
+		px, triggerPx = parseRiskFloat(order.LimitPx), parseRiskFloat(order.TriggerPx)
+	} else {
+		var err error
+		if px, err = g.orderAmount(coin, "limit price", order.LimitPx); err != nil {
+			return nil, err
+		}
+		if sz, err = g.orderAmount(coin, "size", order.Sz); err != nil {
+			return nil, err
+		}
+		if order.OrderType.Trigger != nil {
+			if triggerPx, err = g.orderAmount(coin, "trigger price", order.TriggerPx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if trigger := order.OrderType.Trigger; trigger != nil {
+		if err := g.checkBand(coin, triggerPx); err != nil {
+			return nil, err
+		}
+		// Market triggers carry a slippage cap as their limit price; size
+		// them at the trigger instead.
+		if trigger.IsMarket && triggerPx > 0 {
+			px = triggerPx
+		}
+	}
+	if err := g.checkBand(coin, px); err != nil {
+		return nil, err
+	}
+	if !order.ReduceOnly {
+		if err := g.checkOpening(ctx, coin, px*sz); err != nil {
+			return nil, err
+		}
+	}
+	return g.inner.PlaceOrder(ctx, order)
+}
+
+// IOCMarket checks the order's notional at the latest mark and its slippage
+// bound against the price band, then forwards it.
+func (g *RiskGateway) IOCMarket(ctx context.Context, coin string, isBuy bool, qty float64, slippage float64, reduceOnly bool) (*OrderResponse, error) {
+	orderer, ok := g.inner.(MarketOrderer)
+	if !ok {
+		return nil, g.unsupported(CapabilityMarketOrders)
+	}
+	key := normaliseCoin(coin)
+	if err := g.checkSymbol(key, reduceOnly); err != nil {
+		return nil, err
+	}
+	if band := g.limits.PriceBandPct; band > 0 && math.Abs(slippage)*100 > band {
+		return nil, g.reject("%s slippage %.2f%% exceeds price_band_pct %.2f", key, math.Abs(slippage)*100, band)
+	}
+	if !reduceOnly {
+		if qty <= 0 || math.IsNaN(qty) || math.IsInf(qty, 0) {
+			return nil, g.reject("%s order size %g is not positive", key, qty)
+		}
+		mark := g.marks.get(key)
+		if mark <= 0 && (g.limits.MaxOrderNotionalUSD > 0 || g.limits.MaxGrossExposureUSD > 0) {
+			return nil, g.reject("%s has no mark price to size the order", key)
+		}
+		if err := g.checkOpening(ctx, key, mark*qty); err != nil {
+			return nil, err
+		}
+	}
+	return orderer.IOCMarket(ctx, coin, isBuy, qty, slippage, reduceOnly)
+}
+
+// PlaceTWAP checks the TWAP like an order of its full size at the latest
+// mark and forwards it.
+func (g *RiskGateway) PlaceTWAP(ctx context.Context, order TWAPOrder) (*TWAPStatus, error) {
+	orderer, ok := g.inner.(TWAPOrderer)
+	if !ok {
+		return nil, g.unsupported(CapabilityTWAPOrders)
+	}
+	if !order.ReduceOnly {
+		key := normaliseCoin(order.Coin)
+		if err := g.checkSymbol(key, false); err != nil {
+			return nil, err
+		}
+		sz, err := g.orderAmount(key, "size", order.Sz)
+		if err != nil {
+			return nil, err
+		}
+		mark := g.marks.get(key)
+		if mark <= 0 && (g.limits.MaxOrderNotionalUSD > 0 || g.limits.MaxGrossExposureUSD > 0) {
+			return nil, g.reject("%s has no mark price to size the order", key)
+		}
+		if err := g.checkOpening(ctx, key, mark*sz); err != nil {
+			return nil, err
+		}
+	}
+	return orderer.PlaceTWAP(ctx, order)
+}
+
+// checkSymbol rejects opening orders for coins outside the allow-list.
+func (g *RiskGateway) checkSymbol(coin string, reduceOnly bool) error {
+	if g.allowed != nil && !reduceOnly && !g.allowed[coin] {
+		return g.reject("%s is not in allowed_symbols", coin)
+	}
+	return nil
+}
+
+// checkBand rejects px when it lies outside the price band around coin's
+// latest mark. Without a mark the band cannot be enforced and the order is
+// refused.
+func (g *RiskGateway) checkBand(coin string, px float64) error {
+	band := g.limits.PriceBandPct
+	if band <= 0 || px <= 0 {
+		return nil
+	}
+	mark := g.marks.get(coin)
+	if mark <= 0 {
+		return g.reject("%s has no mark price for the price band", coin)
+	}
+	if deviation := math.Abs(px-mark) / mark * 100; deviation > band {
+		return g.reject("%s price %.8g is %.2f%% from mark %.8g, outside price_band_pct %.2f", coin, px, deviation, mark, band)
+	}
+	return nil
+}
+
+// checkOpening applies the limits that only constrain orders adding
+// exposure, and counts the order towards the rate limit when it passes.
+func (g *RiskGateway) checkOpening(ctx context.Context, coin string, notional float64) error {
+	if limit := g.limits.MaxOrderNotionalUSD; limit > 0 && notional > limit+1e-9 {
+		return g.reject("%s order notional %.2f exceeds max_order_notional_usd %.2f", coin, notional, limit)
+	}
+	if limit := g.limits.MaxGrossExposureUSD; limit > 0 {
+		positions, err := g.inner.GetPositions(ctx)
+		if err != nil {
+			return fmt.Errorf("exchange: risk gateway: fetch positions for exposure: %w", err)
+		}
+		gross := notional
+		for _, pos := range positions {
+			gross += math.Abs(parseRiskFloat(pos.PositionValue))
+		}
+		if gross > limit+1e-9 {
+			return g.reject("%s order takes gross exposure to %.2f, above max_gross_exposure_usd %.2f", coin, gross, limit)
+		}
+	}
+	if limit := g.limits.MaxOrdersPerMinute; limit > 0 {
+		now := g.now()
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		cutoff := now.Add(-time.Minute)
+		kept := g.recent[:0]
+		for _, at := range g.recent {
+			if at.After(cutoff) {
+				kept = append(kept, at)
+			}
+		}
+		g.recent = kept
+		if len(g.recent) >= limit {
+			return g.reject("%s order exceeds max_orders_per_minute %d", coin, limit)
+		}
+		g.recent = append(g.recent, now)
+	}
+	return nil
+}
+
+// orderAmount parses a price or size of an opening order, which must be a
+// positive decimal.
+func (g *RiskGateway) orderAmount(coin, field, s string) (float64, error) {
+	d, err := ParseDecimal(s)
+	if err != nil || d.Sign() <= 0 {
+		return 0, g.reject("%s order %s %q is not a positive decimal", coin, field, s)
+	}
+	return d.Float64(), nil
+}
+
+func (g *RiskGateway) reject(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrRiskRejected, fmt.Sprintf(format, args...))
+}
+
+func (g *RiskGateway) unsupported(capability string) error {
+	return fmt.Errorf("exchange: risk gateway: wrapped provider does not support %s", capability)
+}
+
+// ClosePosition forwards; closing never adds exposure.
+func (g *RiskGateway) ClosePosition(ctx context.Context, coin string) (*OrderResponse, error) {
+	return g.inner.ClosePosition(ctx, coin)
+}
+
+// CancelOrder forwards.
+func (g *RiskGateway) CancelOrder(ctx context.Context, asset int, oid int64) error {
+	return g.inner.CancelOrder(ctx, asset, oid)
+}
+
+// GetOpenOrders forwards.
+func (g *RiskGateway) GetOpenOrders(ctx context.Context) ([]OrderStatus, error) {
+	return g.inner.GetOpenOrders(ctx)
+}
+
+// GetPositions forwards.
+func (g *RiskGateway) GetPositions(ctx context.Context) ([]Position, error) {
+	return g.inner.GetPositions(ctx)
+}
+
+// UpdateLeverage forwards.
+func (g *RiskGateway) UpdateLeverage(ctx context.Context, asset int, isCross bool, leverage int) error {
+	return g.inner.UpdateLeverage(ctx, asset, isCross, leverage)
+}
+
+// GetAccountState forwards.
+func (g *RiskGateway) GetAccountState(ctx context.Context) (*AccountState, error) {
+	return g.inner.GetAccountState(ctx)
+}
+
+// GetAccountValue forwards.
+func (g *RiskGateway) GetAccountValue(ctx context.Context) (float64, error) {
+	return g.inner.GetAccountValue(ctx)
+}
+
+// FormatPrice forwards.
+func (g *RiskGateway) FormatPrice(ctx context.Context, coin string, price float64) (string, error) {
+	formatter, ok := g.inner.(Formatter)
+	if !ok {
+		return "", g.unsupported(CapabilityFormatting)
+	}
+	return formatter.FormatPrice(ctx, coin, price)
+}
+
+// FormatSize forwards.
+func (g *RiskGateway) FormatSize(ctx context.Context, coin string, qty float64) (string, error) {
+	formatter, ok := g.inner.(Formatter)
+	if !ok {
+		return "", g.unsupported(CapabilityFormatting)
+	}
+	return formatter.FormatSize(ctx, coin, qty)
+}
+
+// SizeStep forwards.
+func (g *RiskGateway) SizeStep(ctx context.Context, coin string) (Decimal, error) {
+	formatter, ok := g.inner.(Formatter)
+	if !ok {
+		return Decimal{}, g.unsupported(CapabilityFormatting)
+	}
+	return formatter.SizeStep(ctx, coin)
+}
+
+// SetStopLoss forwards; protective orders are reduce-only.
+func (g *RiskGateway) SetStopLoss(ctx context.Context, coin string, positionSide string, qty float64, stopPrice float64) error {
+	orderer, ok := g.inner.(ProtectiveOrderer)
+	if !ok {
+		return g.unsupported(CapabilityProtectiveOrders)
+	}
+	return orderer.SetStopLoss(ctx, coin, positionSide, qty, stopPrice)
+}
+
+// SetTakeProfit forwards; protective orders are reduce-only.
+func (g *RiskGateway) SetTakeProfit(ctx context.Context, coin string, positionSide string, qty float64, takeProfit float64) error {
+	orderer, ok := g.inner.(ProtectiveOrderer)
+	if !ok {
+		return g.unsupported(CapabilityProtectiveOrders)
+	}
+	return orderer.SetTakeProfit(ctx, coin, positionSide, qty, takeProfit)
+}
+
+// CancelAllBySymbol forwards.
+func (g *RiskGateway) CancelAllBySymbol(ctx context.Context, coin string) error {
+	canceller, ok := g.inner.(SymbolCanceller)
+	if !ok {
+		return g.unsupported(CapabilityCancelAll)
+	}
+	return canceller.CancelAllBySymbol(ctx, coin)
+}
+
+// SetFundingRate forwards when the wrapped provider accepts rates.
+func (g *RiskGateway) SetFundingRate(ctx context.Context, coin string, rate float64) error {
+	if setter, ok := g.inner.(FundingRateSetter); ok {
+		return setter.SetFundingRate(ctx, coin, rate)
+	}
+	return nil
+}
+
+// Events forwards.
+func (g *RiskGateway) Events(ctx context.Context) (<-chan Event, error) {
+	stream, ok := g.inner.(EventStream)
+	if !ok {
+		return nil, g.unsupported(CapabilityEventStream)
+	}
+	return stream.Events(ctx)
+}
+
+// GetFills forwards.
+func (g *RiskGateway) GetFills(ctx context.Context, since time.Time) ([]Fill, error) {
+	history, ok := g.inner.(FillHistory)
+	if !ok {
+		return nil, g.unsupported(CapabilityFillHistory)
+	}
+	return history.GetFills(ctx, since)
+}
+
+// GetFundingPayments forwards.
+func (g *RiskGateway) GetFundingPayments(ctx context.Context, since time.Time) ([]FundingPayment, error) {
+	history, ok := g.inner.(FundingHistory)
+	if !ok {
+		return nil, g.unsupported(CapabilityFundingHistory)
+	}
+	return history.GetFundingPayments(ctx, since)
+}
+
+// SubAccount opens the sub-account on the wrapped provider and gates it with
+// the same limits. Exposure and order rate are tracked per account; marks
+// are shared.
+func (g *RiskGateway) SubAccount(ctx context.Context, id string, initialEquity float64) (Provider, error) {
+	subs, ok := g.inner.(SubAccountProvider)
+	if !ok {
+		return nil, g.unsupported(CapabilitySubAccounts)
+	}
+	sub, err := subs.SubAccount(ctx, id, initialEquity)
+	if err != nil {
+		return nil, err
+	}
+	gated := newRiskGateway(sub, g.limits, g.marks)
+	gated.now = g.now
+	return gated, nil
+}
+
+// CancelTWAP forwards.
+func (g *RiskGateway) CancelTWAP(ctx context.Context, coin string, twapID int64) error {
+	orderer, ok := g.inner.(TWAPOrderer)
+	if !ok {
+		return g.unsupported(CapabilityTWAPOrders)
+	}
+	return orderer.CancelTWAP(ctx, coin, twapID)
+}
+
+// GetTWAPStatus forwards.
+func (g *RiskGateway) GetTWAPStatus(ctx context.Context, twapID int64) (*TWAPStatus, error) {
+	orderer, ok := g.inner.(TWAPOrderer)
+	if !ok {
+		return nil, g.unsupported(CapabilityTWAPOrders)
+	}
+	return orderer.GetTWAPStatus(ctx, twapID)
+}
+
+// ScheduleCancel forwards.
+func (g *RiskGateway) ScheduleCancel(ctx context.Context, at time.Time) error {
+	scheduler, ok := g.inner.(CancelScheduler)
+	if !ok {
+		return g.unsupported(CapabilityScheduleCancel)
+	}
+	return scheduler.ScheduleCancel(ctx, at)
+}
+
+// CredentialExpiry forwards; credentials of providers that cannot report an
+// expiry never expire.
+func (g *RiskGateway) CredentialExpiry(ctx context.Context) (time.Time, error) {
+	expirer, ok := g.inner.(CredentialExpirer)
+	if !ok {
+		return time.Time{}, nil
+	}
+	return expirer.CredentialExpiry(ctx)
+}
+
+// SyncRateLimit forwards; providers without a venue-reported allowance have
+// nothing to sync.
+func (g *RiskGateway) SyncRateLimit(ctx context.Context) error {
+	syncer, ok := g.inner.(RateLimitSyncer)
+	if !ok {
+		return nil
+	}
+	return syncer.SyncRateLimit(ctx)
+}
+
+// UpdateIsolatedMargin forwards.
+func (g *RiskGateway) UpdateIsolatedMargin(ctx context.Context, asset int, isBuy bool, amountUSD float64) error {
+	updater, ok := g.inner.(IsolatedMarginUpdater)
+	if !ok {
+		return g.unsupported(CapabilityIsolatedMargin)
+	}
+	return updater.UpdateIsolatedMargin(ctx, asset, isBuy, amountUSD)
+}
+
+// TransferToSubAccount forwards.
+func (g *RiskGateway) TransferToSubAccount(ctx context.Context, id string, amountUSD float64) error {
+	transferer, ok := g.inner.(FundTransferer)
+	if !ok {
+		return g.unsupported(CapabilityFundTransfers)
+	}
+	return transferer.TransferToSubAccount(ctx, id, amountUSD)
+}
+
+func normaliseCoin(coin string) string {
+	return strings.ToUpper(strings.TrimSpace(coin))
+}
+
+// parseRiskFloat reads venue-reported values and reduce-only amounts, which
+// are never rejected; malformed values count as zero.
+func parseRiskFloat(s string) float64 {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		return 0
+	}
+	return d.Float64()
+}
@@ -0,0 +1,183 @@
+package exchange_test
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	exchange "nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func iocOrder(asset int, isBuy bool, px, sz string) exchange.Order {
+	return exchange.Order{Asset: asset, IsBuy: isBuy, LimitPx: px, Sz: sz,
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}}}
+}
+
+func TestRiskGatewayOrderLimits(t *testing.T) {
+	ctx := context.Background()
+	g := exchange.NewRiskGateway(sim.New(sim.WithInitialEquity(100000)), exchange.RiskLimits{
+		MaxOrderNotionalUSD: 1000,
+		PriceBandPct:        5,
+		AllowedSymbols:      []string{"BTC"},
+	})
+	require.NoError(t, g.SetMarkPrice(ctx, "btc", 100))
+	btc, err := g.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+	eth, err := g.GetAssetIndex(ctx, "ETH")
+	require.NoError(t, err)
+
+	_, err = g.PlaceOrder(ctx, iocOrder(btc, true, "100", "11"))
+	assert.ErrorIs(t, err, exchange.ErrRiskRejected)
+	assert.ErrorContains(t, err, "max_order_notional_usd")
+
+	_, err = g.PlaceOrder(ctx, iocOrder(btc, true, "110", "1"))
+	assert.ErrorContains(t, err, "price_band_pct", "10% above the mark")
+
+	_, err = g.PlaceOrder(ctx, iocOrder(eth, true, "100", "1"))
+	assert.ErrorContains(t, err, "allowed_symbols")
+
+	_, err = g.PlaceOrder(ctx, iocOrder(99, true, "100", "1"))
+	assert.ErrorContains(t, err, "not resolved")
+
+	_, err = g.PlaceOrder(ctx, iocOrder(btc, true, "104", "9"))
+	require.NoError(t, err)
+
+	// Reducing is never capped by notional, only held to the band.
+	reduce := iocOrder(btc, false, "96", "9")
+	reduce.ReduceOnly = true
+	_, err = g.PlaceOrder(ctx, reduce)
+	require.NoError(t, err)
+
+	stop := exchange.Order{Asset: btc, IsBuy: false, LimitPx: "1", Sz: "1", TriggerPx: "80",
+		OrderType: exchange.OrderType{Trigger: &exchange.TriggerOrderType{IsMarket: true, Tpsl: "sl"}}}
+	_, err = g.PlaceOrder(ctx, stop)
+	assert.ErrorContains(t, err, "price_band_pct", "market triggers are checked at the trigger price")
+	stop.TriggerPx = "97"
+	_, err = g.PlaceOrder(ctx, stop)
+	require.NoError(t, err)
+
+	_, err = g.IOCMarket(ctx, "BTC", true, 1, 0.10, false)
+	assert.ErrorContains(t, err, "slippage")
+	_, err = g.IOCMarket(ctx, "BTC", true, 20, 0.01, false)
+	assert.ErrorContains(t, err, "max_order_notional_usd")
+	_, err = g.IOCMarket(ctx, "SOL", true, 1, 0.01, false)
+	assert.ErrorContains(t, err, "allowed_symbols")
+	_, err = g.IOCMarket(ctx, "BTC", true, 5, 0.01, false)
+	require.NoError(t, err)
+
+	_, err = g.ClosePosition(ctx, "BTC")
+	require.NoError(t, err)
+}
+
+func TestRiskGatewayRejectsMalformedOrders(t *testing.T) {
+	ctx := context.Background()
+	g := exchange.NewRiskGateway(sim.New(sim.WithInitialEquity(100000)), exchange.RiskLimits{MaxOrderNotionalUSD: 1000})
+	require.NoError(t, g.SetMarkPrice(ctx, "BTC", 100))
+	btc, err := g.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+
+	for _, order := range []exchange.Order{
+		iocOrder(btc, true, "", "abc"),
+		iocOrder(btc, true, "100", "abc"),
+		iocOrder(btc, true, "", "1"),
+		iocOrder(btc, true, "NaN", "1"),
+		iocOrder(btc, true, "100", "-1"),
+		iocOrder(btc, true, "100", "0"),
+		{Asset: btc, IsBuy: true, LimitPx: "100", Sz: "1", TriggerPx: "",
+			OrderType: exchange.OrderType{Trigger: &exchange.TriggerOrderType{IsMarket: true, Tpsl: "tp"}}},
+	} {
+		_, err = g.PlaceOrder(ctx, order)
+		assert.ErrorIs(t, err, exchange.ErrRiskRejected, "px=%q sz=%q trigger=%q", order.LimitPx, order.Sz, order.TriggerPx)
+		assert.ErrorContains(t, err, "not a positive decimal")
+	}
+	_, err = g.IOCMarket(ctx, "BTC", true, math.NaN(), 0.01, false)
+	assert.ErrorIs(t, err, exchange.ErrRiskRejected)
+
+	positions, err := g.GetPositions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, positions, "nothing reached the venue")
+}
+
+func TestRiskGatewayExposureAndRate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	g := exchange.NewRiskGateway(sim.New(sim.WithInitialEquity(100000)), exchange.RiskLimits{
+		MaxGrossExposureUSD: 2500,
+		MaxOrdersPerMinute:  3,
+	}, exchange.WithRiskClock(func() time.Time { return now }))
+	require.NoError(t, g.SetMarkPrice(ctx, "BTC", 100))
+	require.NoError(t, g.SetMarkPrice(ctx, "ETH", 50))
+
+	_, err := g.IOCMarket(ctx, "BTC", true, 10, 0.01, false)
+	require.NoError(t, err)
+	_, err = g.IOCMarket(ctx, "ETH", false, 20, 0.01, false)
+	require.NoError(t, err)
+	_, err = g.IOCMarket(ctx, "ETH", false, 11, 0.01, false)
+	assert.ErrorContains(t, err, "max_gross_exposure_usd", "short exposure counts towards the gross")
+
+	_, err = g.IOCMarket(ctx, "BTC", true, 1, 0.01, false)
+	require.NoError(t, err)
+	_, err = g.IOCMarket(ctx, "BTC", true, 1, 0.01, false)
+	assert.ErrorContains(t, err, "max_orders_per_minute")
+	_, err = g.IOCMarket(ctx, "BTC", false, 1, 0.01, true)
+	require.NoError(t, err, "reduce-only orders are not rate limited")
+
+	now = now.Add(61 * time.Second)
+	_, err = g.IOCMarket(ctx, "BTC", true, 1, 0.01, false)
+	require.NoError(t, err)
+}
+
+func TestRiskGatewaySubAccountsAndCapabilities(t *testing.T) {
+	ctx := context.Background()
+	g := exchange.NewRiskGateway(sim.New(sim.WithInitialEquity(100000)), exchange.RiskLimits{MaxOrderNotionalUSD: 500})
+	require.NoError(t, g.SetMarkPrice(ctx, "BTC", 100))
+
+	caps := g.Capabilities()
+	assert.True(t, caps.MarkPrice)
+	assert.True(t, caps.SubAccounts)
+
+	sub, err := g.SubAccount(ctx, "t1", 1000)
+	require.NoError(t, err)
+	gated, ok := sub.(*exchange.RiskGateway)
+	require.True(t, ok, "sub-accounts are gated too")
+	_, err = gated.IOCMarket(ctx, "BTC", true, 6, 0.01, false)
+	assert.ErrorIs(t, err, exchange.ErrRiskRejected, "marks are shared with sub-account gateways")
+	_, err = gated.IOCMarket(ctx, "SOL", true, 1, 0.01, false)
+	assert.ErrorContains(t, err, "no mark price")
+	_, isSim := g.Unwrap().(*sim.Provider)
+	assert.True(t, isSim)
+}
+
+func TestLoadConfigRiskLimits(t *testing.T) {
+	cfg, err := exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  paper:
+    type: sim
+    risk:
+      max_order_notional_usd: 1000
+      price_band_pct: 2.5
+      allowed_symbols: [" btc ", eth]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTC", "ETH"}, cfg.Providers["paper"].Risk.AllowedSymbols)
+	providers, err := cfg.BuildProviders()
+	require.NoError(t, err)
+	gateway, ok := providers["paper"].(*exchange.RiskGateway)
+	require.True(t, ok)
+	assert.InDelta(t, 2.5, gateway.Limits().PriceBandPct, 1e-9)
+
+	_, err = exchange.LoadConfigFromReader(strings.NewReader(`
+providers:
+  paper:
+    type: sim
+    risk:
+      price_band_pct: 150
+`))
+	assert.ErrorContains(t, err, "price_band_pct")
+}
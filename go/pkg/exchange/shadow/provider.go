@@ -0,0 +1,684 @@
+// Package shadow provides an exchange.Provider decorator that mirrors every
+// order into a simulator, so live execution can be compared against paper
+// fills, or a trader can run against the simulator alone (dry run).
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+)
+
+const defaultMaxRecords = 1000
+
+// Order kinds recorded in OrderComparison.Kind.
+const (
+	KindOrder  = "order"  // PlaceOrder
+	KindMarket = "market" // IOCMarket
+	KindClose  = "close"  // ClosePosition
+)
+
+// Compile-time checks for the optional extensions the shadow forwards.
+var (
+	_ exchange.Provider           = (*Provider)(nil)
+	_ exchange.MarketOrderer      = (*Provider)(nil)
+	_ exchange.ProtectiveOrderer  = (*Provider)(nil)
+	_ exchange.Formatter          = (*Provider)(nil)
+	_ exchange.SymbolCanceller    = (*Provider)(nil)
+	_ exchange.MarkPriceSetter    = (*Provider)(nil)
+	_ exchange.FundingRateSetter  = (*Provider)(nil)
+	_ exchange.EventStream        = (*Provider)(nil)
+	_ exchange.FillHistory        = (*Provider)(nil)
+	_ exchange.FundingHistory     = (*Provider)(nil)
+	_ exchange.CancelScheduler    = (*Provider)(nil)
+	_ exchange.CredentialExpirer  = (*Provider)(nil)
+	_ exchange.RateLimitSyncer    = (*Provider)(nil)
+	_ exchange.CapabilityReporter = (*Provider)(nil)
+)
+
+// OrderComparison records how one order executed on the primary provider
+// ("live") and in the simulator. Live fields are empty in dry run.
+type OrderComparison struct {
+	Kind  string `json:"kind"`
+	Coin  string `json:"coin"`
+	IsBuy bool   `json:"isBuy"`
+	Cloid string `json:"cloid,omitempty"`
+	// Size is the requested size; 0 for position closes.
+	Size float64 `json:"size"`
+
+	LiveStatus string  `json:"liveStatus,omitempty"` // filled, resting, rejected or error
+	LiveOid    int64   `json:"liveOid,omitempty"`
+	LivePx     float64 `json:"livePx,omitempty"`
+	LiveSz     float64 `json:"liveSz,omitempty"`
+	LiveError  string  `json:"liveError,omitempty"`
+
+	SimStatus string  `json:"simStatus"`
+	SimOid    int64   `json:"simOid,omitempty"`
+	SimPx     float64 `json:"simPx,omitempty"`
+	SimSz     float64 `json:"simSz,omitempty"`
+	SimError  string  `json:"simError,omitempty"`
+
+	// SlippageBps is the live average price relative to the simulated one,
+	// positive when live executed worse (paid more on a buy, received less
+	// on a sell). It is only set when both sides filled.
+	SlippageBps float64 `json:"slippageBps"`
+	// SizeDiff is LiveSz - SimSz.
+	SizeDiff float64   `json:"sizeDiff"`
+	At       time.Time `json:"at"`
+}
+
+// Compared reports whether both sides filled, so SlippageBps is meaningful.
+func (c OrderComparison) Compared() bool {
+	return c.LiveSz > 0 && c.SimSz > 0 && c.LivePx > 0 && c.SimPx > 0
+}
+
+// PositionDivergence compares one coin's position across the two ledgers.
+type PositionDivergence struct {
+	Coin        string  `json:"coin"`
+	LiveSzi     float64 `json:"liveSzi"`
+	SimSzi      float64 `json:"simSzi"`
+	LiveEntryPx float64 `json:"liveEntryPx"`
+	SimEntryPx  float64 `json:"simEntryPx"`
+	// SizeDiff is LiveSzi - SimSzi.
+	SizeDiff float64 `json:"sizeDiff"`
+}
+
+// Summary aggregates the recorded comparisons.
+type Summary struct {
+	Orders            int     `json:"orders"`
+	Compared          int     `json:"compared"` // both sides filled
+	MeanSlippageBps   float64 `json:"meanSlippageBps"`
+	MaxAbsSlippageBps float64 `json:"maxAbsSlippageBps"`
+	// StatusMismatches counts orders that filled on one side only or were
+	// rejected on one side only.
+	StatusMismatches int `json:"statusMismatches"`
+}
+
+// Provider forwards to a primary provider and mirrors every order into a
+// simulator. Reads (positions, account state, fills, events) come from the
+// primary, or from the simulator in dry run. Mirror failures are recorded
+// and logged but never fail the caller.
+type Provider struct {
+	primary exchange.Provider // nil in dry run
+	sim     *sim.Provider
+
+	mu         sync.Mutex
+	coins      map[int]string  // ledger asset index -> coin
+	simOids    map[int64]int64 // live oid -> simulator oid of resting orders
+	records    []OrderComparison
+	maxRecords int
+	now        func() time.Time
+	listener   func(OrderComparison)
+}
+
+// Option customises a shadow provider.
+type Option func(*Provider)
+
+// WithMaxRecords bounds the comparisons kept in memory (default 1000).
+func WithMaxRecords(n int) Option {
+	return func(p *Provider) {
+		if n > 0 {
+			p.maxRecords = n
+		}
+	}
+}
+
+// WithComparisonListener registers fn to receive every comparison as it is
+// recorded.
+func WithComparisonListener(fn func(OrderComparison)) Option {
+	return func(p *Provider) {
+		p.listener = fn
+	}
+}
+
+// WithClock overrides the clock used to timestamp comparisons.
+func WithClock(now func() time.Time) Option {
+	return func(p *Provider) {
+		if now != nil {
+			p.now = now
+		}
+	}
+}
+
+// New wraps primary, mirroring its orders into simulator. A nil primary runs
+// in dry run: the simulator serves every call.
+func New(primary exchange.Provider, simulator *sim.Provider, opts ...Option) *Provider {
+	if simulator == nil {
+		simulator = sim.New()
+	}
+	p := &Provider{
+		primary:    primary,
+		sim:        simulator,
+		coins:      make(map[int]string),
+		simOids:    make(map[int64]int64),
+		maxRecords: defaultMaxRecords,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Registry hook for exchange.Config.
+func init() {
+	exchange.RegisterDecorator("shadow", func(name string, cfg *exchange.ProviderConfig, inner exchange.Provider) (exchange.Provider, error) {
+		simulator, err := sim.NewFromConfig(cfg.Sim)
+		if err != nil {
+			return nil, err
+		}
+		var opts []Option
+		if cfg.Shadow != nil {
+			opts = append(opts, WithMaxRecords(cfg.Shadow.MaxRecords))
+			if cfg.Shadow.DryRun {
+				inner = nil
+			}
+		}
+		if inner == nil && (cfg.Shadow == nil || !cfg.Shadow.DryRun) {
+			return nil, fmt.Errorf("shadow: wrapped provider is required unless dry_run is set")
+		}
+		return New(inner, simulator, opts...), nil
+	})
+}
+
+// DryRun reports whether orders only reach the simulator.
+func (p *Provider) DryRun() bool { return p.primary == nil }
+
+// Primary returns the wrapped provider, nil in dry run.
+func (p *Provider) Primary() exchange.Provider { return p.primary }
+
+// Simulator returns the mirror ledger, e.g. to attach a state store.
+func (p *Provider) Simulator() *sim.Provider { return p.sim }
+
+// SetComparisonListener replaces the comparison listener, for providers
+// built from config where WithComparisonListener cannot be passed.
+func (p *Provider) SetComparisonListener(fn func(OrderComparison)) {
+	p.mu.Lock()
+	p.listener = fn
+	p.mu.Unlock()
+}
+
+// ledger is the provider whose state callers see.
+func (p *Provider) ledger() exchange.Provider {
+	if p.primary == nil {
+		return p.sim
+	}
+	return p.primary
+}
+
+// Capabilities reports the ledger's capabilities the shadow forwards, plus
+// mark price and funding rate feeds for the simulator.
+func (p *Provider) Capabilities() exchange.Capabilities {
+	caps := exchange.CapabilitiesOf(p.ledger())
+	caps.MarkPrice = true
+	caps.FundingRate = true
+	caps.SubAccounts = false
+	caps.TWAPOrders = false
+	caps.IsolatedMargin = false
+	caps.FundTransfers = false
+	return caps
+}
+
+// GetAssetIndex resolves coin on the ledger and remembers the mapping so
+// mirrored orders can be routed by coin.
+func (p *Provider) GetAssetIndex(ctx context.Context, coin string) (int, error) {
+	idx, err := p.ledger().GetAssetIndex(ctx, coin)
+	if err != nil {
+		return 0, err
+	}
+	p.mu.Lock()
+	p.coins[idx] = strings.ToUpper(strings.TrimSpace(coin))
+	p.mu.Unlock()
+	return idx, nil
+}
+
+// PlaceOrder submits order to the primary and mirrors it into the
+// simulator.
+func (p *Provider) PlaceOrder(ctx context.Context, order exchange.Order) (*exchange.OrderResponse, error) {
+	p.mu.Lock()
+	coin := p.coins[order.Asset]
+	p.mu.Unlock()
+	size, _ := strconv.ParseFloat(strings.TrimSpace(order.Sz), 64)
+	cmp := OrderComparison{Kind: KindOrder, Coin: coin, IsBuy: order.IsBuy, Cloid: order.Cloid, Size: size}
+	if p.DryRun() {
+		resp, err := p.sim.PlaceOrder(ctx, order)
+		cmp.SimStatus, cmp.SimOid, cmp.SimPx, cmp.SimSz, cmp.SimError = outcome(resp, err)
+		p.record(cmp)
+		return resp, err
+	}
+
+	resp, err := p.primary.PlaceOrder(ctx, order)
+	cmp.LiveStatus, cmp.LiveOid, cmp.LivePx, cmp.LiveSz, cmp.LiveError = outcome(resp, err)
+	if coin == "" {
+		cmp.SimStatus, cmp.SimError = "error", fmt.Sprintf("unknown asset index %d", order.Asset)
+	} else {
+		mirror := order
+		simResp, simErr := p.mirrorOrder(ctx, coin, &mirror)
+		cmp.SimStatus, cmp.SimOid, cmp.SimPx, cmp.SimSz, cmp.SimError = outcome(simResp, simErr)
+		if cmp.LiveStatus == "resting" && cmp.SimStatus == "resting" {
+			p.mu.Lock()
+			p.simOids[cmp.LiveOid] = cmp.SimOid
+			p.mu.Unlock()
+		}
+	}
+	p.record(cmp)
+	return resp, err
+}
+
+func (p *Provider) mirrorOrder(ctx context.Context, coin string, order *exchange.Order) (*exchange.OrderResponse, error) {
+	idx, err := p.sim.GetAssetIndex(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+	order.Asset = idx
+	return p.sim.PlaceOrder(ctx, *order)
+}
+
+// IOCMarket submits a marketable IOC order to the primary and mirrors it.
+func (p *Provider) IOCMarket(ctx context.Context, coin string, isBuy bool, qty float64, slippage float64, reduceOnly bool) (*exchange.OrderResponse, error) {
+	cmp := OrderComparison{Kind: KindMarket, Coin: strings.ToUpper(strings.TrimSpace(coin)), IsBuy: isBuy, Size: qty}
+	var resp *exchange.OrderResponse
+	var err error
+	if !p.DryRun() {
+		orderer, ok := p.primary.(exchange.MarketOrderer)
+		if !ok {
+			return nil, unsupported(exchange.CapabilityMarketOrders)
+		}
+		resp, err = orderer.IOCMarket(ctx, coin, isBuy, qty, slippage, reduceOnly)
+		cmp.LiveStatus, cmp.LiveOid, cmp.LivePx, cmp.LiveSz, cmp.LiveError = outcome(resp, err)
+	}
+	simResp, simErr := p.sim.IOCMarket(ctx, coin, isBuy, qty, slippage, reduceOnly)
+	cmp.SimStatus, cmp.SimOid, cmp.SimPx, cmp.SimSz, cmp.SimError = outcome(simResp, simErr)
+	p.record(cmp)
+	if p.DryRun() {
+		return simResp, simErr
+	}
+	return resp, err
+}
+
+// ClosePosition closes coin on the primary and in the simulator.
+func (p *Provider) ClosePosition(ctx context.Context, coin string) (*exchange.OrderResponse, error) {
+	cmp := OrderComparison{Kind: KindClose, Coin: strings.ToUpper(strings.TrimSpace(coin))}
+	// The close direction follows the simulated position; without one the
+	// comparison has no simulated fill anyway.
+	if positions, err := p.sim.GetPositions(ctx); err == nil {
+		for _, pos := range positions {
+			if strings.EqualFold(pos.Coin, cmp.Coin) {
+				cmp.IsBuy = parseFloat(pos.Szi) < 0
+			}
+		}
+	}
+	var resp *exchange.OrderResponse
+	var err error
+	if !p.DryRun() {
+		resp, err = p.primary.ClosePosition(ctx, coin)
+		cmp.LiveStatus, cmp.LiveOid, cmp.LivePx, cmp.LiveSz, cmp.LiveError = outcome(resp, err)
+	}
+	simResp, simErr := p.sim.ClosePosition(ctx, coin)
+	cmp.SimStatus, cmp.SimOid, cmp.SimPx, cmp.SimSz, cmp.SimError = outcome(simResp, simErr)
+	p.record(cmp)
+	if p.DryRun() {
+		return simResp, simErr
+	}
+	return resp, err
+}
+
+// CancelOrder cancels on the primary and cancels the mirrored order when it
+// is still resting in the simulator.
+func (p *Provider) CancelOrder(ctx context.Context, asset int, oid int64) error {
+	if p.DryRun() {
+		return p.sim.CancelOrder(ctx, asset, oid)
+	}
+	err := p.primary.CancelOrder(ctx, asset, oid)
+	p.mu.Lock()
+	simOid, ok := p.simOids[oid]
+	delete(p.simOids, oid)
+	coin := p.coins[asset]
+	p.mu.Unlock()
+	if ok && coin != "" {
+		if idx, idxErr := p.sim.GetAssetIndex(ctx, coin); idxErr == nil {
+			if simErr := p.sim.CancelOrder(ctx, idx, simOid); simErr != nil {
+				logx.WithContext(ctx).Infof("shadow: mirror cancel coin=%s oid=%d sim_oid=%d err=%v", coin, oid, simOid, simErr)
+			}
+		}
+	}
+	return err
+}
+
+// CancelAllBySymbol cancels coin's resting orders on the ledger and in the
+// simulator.
+func (p *Provider) CancelAllBySymbol(ctx context.Context, coin string) error {
+	if p.DryRun() {
+		return p.sim.CancelAllBySymbol(ctx, coin)
+	}
+	canceller, ok := p.primary.(exchange.SymbolCanceller)
+	if !ok {
+		return unsupported(exchange.CapabilityCancelAll)
+	}
+	err := canceller.CancelAllBySymbol(ctx, coin)
+	if simErr := p.sim.CancelAllBySymbol(ctx, coin); simErr != nil {
+		logx.WithContext(ctx).Infof("shadow: mirror cancel all coin=%s err=%v", coin, simErr)
+	}
+	return err
+}
+
+// GetOpenOrders returns the ledger's resting orders.
+func (p *Provider) GetOpenOrders(ctx context.Context) ([]exchange.OrderStatus, error) {
+	return p.ledger().GetOpenOrders(ctx)
+}
+
+// GetPositions returns the ledger's positions.
+func (p *Provider) GetPositions(ctx context.Context) ([]exchange.Position, error) {
+	return p.ledger().GetPositions(ctx)
+}
+
+// UpdateLeverage sets leverage on the primary and in the simulator.
+func (p *Provider) UpdateLeverage(ctx context.Context, asset int, isCross bool, leverage int) error {
+	if p.DryRun() {
+		return p.sim.UpdateLeverage(ctx, asset, isCross, leverage)
+	}
+	err := p.primary.UpdateLeverage(ctx, asset, isCross, leverage)
+	p.mu.Lock()
+	coin := p.coins[asset]
+	p.mu.Unlock()
+	if coin != "" {
+		if idx, idxErr := p.sim.GetAssetIndex(ctx, coin); idxErr == nil {
+			if simErr := p.sim.UpdateLeverage(ctx, idx, isCross, leverage); simErr != nil {
+				logx.WithContext(ctx).Infof("shadow: mirror leverage coin=%s leverage=%d err=%v", coin, leverage, simErr)
+			}
+		}
+	}
+	return err
+}
+
+// GetAccountState returns the ledger's account state.
+func (p *Provider) GetAccountState(ctx context.Context) (*exchange.AccountState, error) {
+	return p.ledger().GetAccountState(ctx)
+}
+
+// GetAccountValue returns the ledger's account value.
+func (p *Provider) GetAccountValue(ctx context.Context) (float64, error) {
+	return p.ledger().GetAccountValue(ctx)
+}
+
+// FormatPrice uses the ledger's tick rules.
+func (p *Provider) FormatPrice(ctx context.Context, coin string, price float64) (string, error) {
+	formatter, ok := p.ledger().(exchange.Formatter)
+	if !ok {
+		return "", unsupported(exchange.CapabilityFormatting)
+	}
+	return formatter.FormatPrice(ctx, coin, price)
+}
+
+// FormatSize uses the ledger's lot rules.
+func (p *Provider) FormatSize(ctx context.Context, coin string, qty float64) (string, error) {
+	formatter, ok := p.ledger().(exchange.Formatter)
+	if !ok {
+		return "", unsupported(exchange.CapabilityFormatting)
+	}
+	return formatter.FormatSize(ctx, coin, qty)
+}
+
+// SizeStep uses the ledger's lot rules.
+func (p *Provider) SizeStep(ctx context.Context, coin string) (exchange.Decimal, error) {
+	formatter, ok := p.ledger().(exchange.Formatter)
+	if !ok {
+		return exchange.Decimal{}, unsupported(exchange.CapabilityFormatting)
+	}
+	return formatter.SizeStep(ctx, coin)
+}
+
+// SetStopLoss places the stop on the primary and mirrors it.
+func (p *Provider) SetStopLoss(ctx context.Context, coin string, positionSide string, qty float64, stopPrice float64) error {
+	return p.protective(ctx, coin, func(o exchange.ProtectiveOrderer) error {
+		return o.SetStopLoss(ctx, coin, positionSide, qty, stopPrice)
+	})
+}
+
+// SetTakeProfit places the take-profit on the primary and mirrors it.
+func (p *Provider) SetTakeProfit(ctx context.Context, coin string, positionSide string, qty float64, takeProfit float64) error {
+	return p.protective(ctx, coin, func(o exchange.ProtectiveOrderer) error {
+		return o.SetTakeProfit(ctx, coin, positionSide, qty, takeProfit)
+	})
+}
+
+func (p *Provider) protective(ctx context.Context, coin string, place func(exchange.ProtectiveOrderer) error) error {
+	if p.DryRun() {
+		return place(p.sim)
+	}
+	orderer, ok := p.primary.(exchange.ProtectiveOrderer)
+	if !ok {
+		return unsupported(exchange.CapabilityProtectiveOrders)
+	}
+	err := place(orderer)
+	if simErr := place(p.sim); simErr != nil {
+		logx.WithContext(ctx).Infof("shadow: mirror protective order coin=%s err=%v", coin, simErr)
+	}
+	return err
+}
+
+// SetMarkPrice feeds the simulator, and the primary when it accepts marks.
+func (p *Provider) SetMarkPrice(ctx context.Context, coin string, price float64) error {
+	if setter, ok := p.primary.(exchange.MarkPriceSetter); ok {
+		if err := setter.SetMarkPrice(ctx, coin, price); err != nil {
+			return err
+		}
+	}
+	return p.sim.SetMarkPrice(ctx, coin, price)
+}
+
+// SetFundingRate feeds the simulator, and the primary when it accepts rates.
+func (p *Provider) SetFundingRate(ctx context.Context, coin string, rate float64) error {
+	if setter, ok := p.primary.(exchange.FundingRateSetter); ok {
+		if err := setter.SetFundingRate(ctx, coin, rate); err != nil {
+			return err
+		}
+	}
+	return p.sim.SetFundingRate(ctx, coin, rate)
+}
+
+// Events streams the ledger's events.
+func (p *Provider) Events(ctx context.Context) (<-chan exchange.Event, error) {
+	stream, ok := p.ledger().(exchange.EventStream)
+	if !ok {
+		return nil, unsupported(exchange.CapabilityEventStream)
+	}
+	return stream.Events(ctx)
+}
+
+// GetFills returns the ledger's fills.
+func (p *Provider) GetFills(ctx context.Context, since time.Time) ([]exchange.Fill, error) {
+	history, ok := p.ledger().(exchange.FillHistory)
+	if !ok {
+		return nil, unsupported(exchange.CapabilityFillHistory)
+	}
+	return history.GetFills(ctx, since)
+}
+
+// GetFundingPayments returns the ledger's funding payments.
+func (p *Provider) GetFundingPayments(ctx context.Context, since time.Time) ([]exchange.FundingPayment, error) {
+	history, ok := p.ledger().(exchange.FundingHistory)
+	if !ok {
+		return nil, unsupported(exchange.CapabilityFundingHistory)
+	}
+	return history.GetFundingPayments(ctx, since)
+}
+
+// ScheduleCancel forwards the dead-man's switch to the ledger.
+func (p *Provider) ScheduleCancel(ctx context.Context, at time.Time) error {
+	scheduler, ok := p.ledger().(exchange.CancelScheduler)
+	if !ok {
+		return unsupported(exchange.CapabilityScheduleCancel)
+	}
+	return scheduler.ScheduleCancel(ctx, at)
+}
+
+// CredentialExpiry reports when the ledger's signing credential lapses.
+func (p *Provider) CredentialExpiry(ctx context.Context) (time.Time, error) {
+	expirer, ok := p.ledger().(exchange.CredentialExpirer)
+	if !ok {
+		return time.Time{}, nil
+	}
+	return expirer.CredentialExpiry(ctx)
+}
+
+// SyncRateLimit syncs the ledger's request budget.
+func (p *Provider) SyncRateLimit(ctx context.Context) error {
+	syncer, ok := p.ledger().(exchange.RateLimitSyncer)
+	if !ok {
+		return nil
+	}
+	return syncer.SyncRateLimit(ctx)
+}
+
+// Comparisons returns the recorded per-order comparisons, oldest first.
+func (p *Provider) Comparisons() []OrderComparison {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]OrderComparison(nil), p.records...)
+}
+
+// Summary aggregates the recorded comparisons.
+func (p *Provider) Summary() Summary {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var s Summary
+	var total float64
+	for _, c := range p.records {
+		s.Orders++
+		if c.Compared() {
+			s.Compared++
+			total += c.SlippageBps
+			s.MaxAbsSlippageBps = math.Max(s.MaxAbsSlippageBps, math.Abs(c.SlippageBps))
+		}
+		if c.LiveStatus != "" && ((c.LiveSz > 0) != (c.SimSz > 0) || (c.LiveStatus == "rejected") != (c.SimStatus == "rejected")) {
+			s.StatusMismatches++
+		}
+	}
+	if s.Compared > 0 {
+		s.MeanSlippageBps = total / float64(s.Compared)
+	}
+	return s
+}
+
+// Divergence compares the primary's positions with the simulator's, one
+// entry per coin held on either side, sorted by coin.
+func (p *Provider) Divergence(ctx context.Context) ([]PositionDivergence, error) {
+	if p.DryRun() {
+		return nil, fmt.Errorf("shadow: dry run has no live ledger to compare")
+	}
+	live, err := p.primary.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("shadow: live positions: %w", err)
+	}
+	simulated, err := p.sim.GetPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("shadow: simulated positions: %w", err)
+	}
+	byCoin := make(map[string]*PositionDivergence)
+	entry := func(coin string) *PositionDivergence {
+		coin = strings.ToUpper(strings.TrimSpace(coin))
+		d, ok := byCoin[coin]
+		if !ok {
+			d = &PositionDivergence{Coin: coin}
+			byCoin[coin] = d
+		}
+		return d
+	}
+	for _, pos := range live {
+		d := entry(pos.Coin)
+		d.LiveSzi = parseFloat(pos.Szi)
+		d.LiveEntryPx = parsePtrFloat(pos.EntryPx)
+	}
+	for _, pos := range simulated {
+		d := entry(pos.Coin)
+		d.SimSzi = parseFloat(pos.Szi)
+		d.SimEntryPx = parsePtrFloat(pos.EntryPx)
+	}
+	out := make([]PositionDivergence, 0, len(byCoin))
+	for _, d := range byCoin {
+		if d.LiveSzi == 0 && d.SimSzi == 0 {
+			continue
+		}
+		d.SizeDiff = d.LiveSzi - d.SimSzi
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Coin < out[j].Coin })
+	return out, nil
+}
+
+func (p *Provider) record(c OrderComparison) {
+	c.At = p.now()
+	if c.Compared() {
+		c.SlippageBps = (c.LivePx - c.SimPx) / c.SimPx * 10000
+		if !c.IsBuy {
+			c.SlippageBps = -c.SlippageBps
+		}
+	}
+	c.SizeDiff = c.LiveSz - c.SimSz
+	p.mu.Lock()
+	p.records = append(p.records, c)
+	if len(p.records) > p.maxRecords {
+		p.records = append([]OrderComparison(nil), p.records[len(p.records)-p.maxRecords:]...)
+	}
+	listener := p.listener
+	p.mu.Unlock()
+	logx.Infof("shadow: %s coin=%s is_buy=%t live=%s sz=%.6f px=%.6f sim=%s sz=%.6f px=%.6f slippage_bps=%.2f sim_err=%q",
+		c.Kind, c.Coin, c.IsBuy, c.LiveStatus, c.LiveSz, c.LivePx, c.SimStatus, c.SimSz, c.SimPx, c.SlippageBps, c.SimError)
+	if listener != nil {
+		listener(c)
+	}
+}
+
+// outcome summarises the first order status of resp.
+func outcome(resp *exchange.OrderResponse, err error) (status string, oid int64, px, sz float64, errMsg string) {
+	switch {
+	case err != nil:
+		return "error", 0, 0, 0, err.Error()
+	case resp == nil:
+		return "none", 0, 0, 0, ""
+	case resp.Status == "err":
+		return "rejected", 0, 0, 0, resp.ErrorMessage
+	case len(resp.Response.Data.Statuses) == 0:
+		return "none", 0, 0, 0, ""
+	}
+	st := resp.Response.Data.Statuses[0]
+	switch {
+	case st.Error != "":
+		return "rejected", 0, 0, 0, st.Error
+	case st.Filled != nil:
+		return "filled", st.Filled.Oid, parseFloat(st.Filled.AvgPx), parseFloat(st.Filled.TotalSz), ""
+	case st.Resting != nil:
+		return "resting", st.Resting.Oid, 0, 0, ""
+	}
+	return "none", 0, 0, 0, ""
+}
+
+func unsupported(capability string) error {
+	return fmt.Errorf("shadow: primary provider does not support %s", capability)
+}
+
+func parseFloat(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func parsePtrFloat(s *string) float64 {
+	if s == nil {
+		return 0
+	}
+	return parseFloat(*s)
+}
@@ -0,0 +1,151 @@
+package shadow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+)
+
+func TestShadowMirrorsOrders(t *testing.T) {
+	ctx := context.Background()
+	live := sim.New(sim.WithInitialEquity(10000))
+	// Give the primary its own asset numbering so indices must be translated.
+	_, err := live.GetAssetIndex(ctx, "ETH")
+	require.NoError(t, err)
+	var seen []OrderComparison
+	p := New(live, sim.New(sim.WithInitialEquity(10000)), WithComparisonListener(func(c OrderComparison) {
+		seen = append(seen, c)
+	}))
+
+	require.NoError(t, p.SetMarkPrice(ctx, "BTC", 100))
+	require.NoError(t, live.SetMarkPrice(ctx, "BTC", 101))
+
+	_, err = p.IOCMarket(ctx, "BTC", true, 1, 0.01, false)
+	require.NoError(t, err)
+	asset, err := p.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+	_, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "103", Sz: "1", Cloid: "0x01",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}}})
+	require.NoError(t, err)
+
+	records := p.Comparisons()
+	require.Len(t, records, 2)
+	assert.Equal(t, seen, records)
+	market := records[0]
+	assert.Equal(t, KindMarket, market.Kind)
+	assert.Equal(t, "filled", market.LiveStatus)
+	assert.Equal(t, "filled", market.SimStatus)
+	assert.InDelta(t, 102.01, market.LivePx, 1e-9)
+	assert.InDelta(t, 101, market.SimPx, 1e-9)
+	assert.InDelta(t, 100, market.SlippageBps, 1e-6, "live paid 1% more than the simulator")
+	limit := records[1]
+	assert.Equal(t, "BTC", limit.Coin)
+	assert.Equal(t, "0x01", limit.Cloid)
+	assert.InDelta(t, 0, limit.SlippageBps, 1e-9)
+
+	divergence, err := p.Divergence(ctx)
+	require.NoError(t, err)
+	require.Len(t, divergence, 1)
+	assert.InDelta(t, 2, divergence[0].LiveSzi, 1e-9)
+	assert.InDelta(t, 0, divergence[0].SizeDiff, 1e-9)
+
+	_, err = p.ClosePosition(ctx, "BTC")
+	require.NoError(t, err)
+	closing := p.Comparisons()[2]
+	assert.Equal(t, KindClose, closing.Kind)
+	assert.False(t, closing.IsBuy)
+	assert.InDelta(t, 2, closing.LiveSz, 1e-9)
+	assert.InDelta(t, 2, closing.SimSz, 1e-9)
+
+	summary := p.Summary()
+	assert.Equal(t, 3, summary.Orders)
+	assert.Equal(t, 3, summary.Compared)
+	assert.Zero(t, summary.StatusMismatches)
+	assert.InDelta(t, 100, summary.MaxAbsSlippageBps, 1e-6)
+}
+
+func TestShadowTracksDivergenceAndCancels(t *testing.T) {
+	ctx := context.Background()
+	live := sim.New(sim.WithInitialEquity(50)) // too small for the order below
+	p := New(live, sim.New(sim.WithInitialEquity(10000)))
+	require.NoError(t, p.SetMarkPrice(ctx, "BTC", 100))
+	asset, err := p.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+
+	_, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "100", Sz: "10",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}}})
+	require.NoError(t, err)
+	record := p.Comparisons()[0]
+	assert.Equal(t, "rejected", record.LiveStatus)
+	assert.Equal(t, "filled", record.SimStatus)
+	assert.InDelta(t, -10, record.SizeDiff, 1e-9)
+	assert.Equal(t, 1, p.Summary().StatusMismatches)
+
+	divergence, err := p.Divergence(ctx)
+	require.NoError(t, err)
+	require.Len(t, divergence, 1)
+	assert.Equal(t, PositionDivergence{Coin: "BTC", SimSzi: 10, SimEntryPx: 100, SizeDiff: -10}, divergence[0])
+
+	resp, err := p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "90", Sz: "0.1",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}}})
+	require.NoError(t, err)
+	oids := resp.OrderIDs()
+	require.Len(t, oids, 1)
+	simOpen, err := p.Simulator().GetOpenOrders(ctx)
+	require.NoError(t, err)
+	require.Len(t, simOpen, 1, "resting orders are mirrored")
+
+	require.NoError(t, p.CancelOrder(ctx, asset, oids[0]))
+	simOpen, err = p.Simulator().GetOpenOrders(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, simOpen, "cancels follow the live oid to the mirrored order")
+}
+
+func TestShadowDryRun(t *testing.T) {
+	ctx := context.Background()
+	p := New(nil, sim.New())
+	assert.True(t, p.DryRun())
+	var seen []OrderComparison
+	p.SetComparisonListener(func(c OrderComparison) { seen = append(seen, c) })
+	require.NoError(t, p.SetMarkPrice(ctx, "SOL", 20))
+
+	resp, err := p.IOCMarket(ctx, "SOL", false, 3, 0.01, false)
+	require.NoError(t, err)
+	require.Len(t, resp.OrderIDs(), 1)
+	positions, err := p.GetPositions(ctx)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, "-3", positions[0].Szi)
+
+	record := p.Comparisons()[0]
+	assert.Equal(t, []OrderComparison{record}, seen)
+	assert.Empty(t, record.LiveStatus)
+	assert.Equal(t, "filled", record.SimStatus)
+	assert.False(t, record.Compared())
+
+	caps := p.Capabilities()
+	assert.True(t, caps.MarketOrders)
+	assert.True(t, caps.FillHistory)
+	assert.False(t, caps.SubAccounts)
+
+	_, err = p.Divergence(ctx)
+	assert.ErrorContains(t, err, "dry run")
+}
+
+func TestShadowReportsUnsupportedPrimaryExtensions(t *testing.T) {
+	p := New(bareProvider{sim.New()}, sim.New())
+	caps := p.Capabilities()
+	assert.False(t, caps.MarketOrders)
+	assert.True(t, caps.MarkPrice, "the simulator always takes marks")
+	_, err := p.IOCMarket(context.Background(), "BTC", true, 1, 0, false)
+	assert.ErrorContains(t, err, "does not support market_orders")
+	assert.Empty(t, p.Comparisons())
+}
+
+// bareProvider exposes only the core exchange.Provider methods.
+type bareProvider struct{ exchange.Provider }
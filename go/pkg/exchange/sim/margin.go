@@ -0,0 +1,169 @@
+package sim
+
+import (
+	"math"
+	"sort"
+
+	"nof0-api/pkg/exchange"
+)
+
+const (
+	// defaultMaxLeverage applies to assets without configured margin tiers.
+	defaultMaxLeverage = 50
+	insufficientMargin = "Insufficient margin to place order."
+)
+
+// MarginTier caps leverage for positions whose notional is at least
+// MinNotional. As on Hyperliquid, maintenance margin is half the initial
+// margin at the tier's maximum leverage.
+type MarginTier struct {
+	MinNotional float64
+	MaxLeverage int
+}
+
+// WithMarginTiers sets the margin tiers for coin.
+func WithMarginTiers(coin string, tiers ...MarginTier) Option {
+	return func(p *Provider) {
+		if t := normaliseTiers(tiers); t != nil {
+			p.marginTiers[canonical(coin)] = t
+		}
+	}
+}
+
+// WithDefaultMarginTiers sets the margin tiers for coins without their own.
+func WithDefaultMarginTiers(tiers ...MarginTier) Option {
+	return func(p *Provider) {
+		if t := normaliseTiers(tiers); t != nil {
+			p.defaultTiers = t
+		}
+	}
+}
+
+// normaliseTiers drops invalid tiers and orders the rest by notional. The
+// first tier always starts at zero notional.
+func normaliseTiers(tiers []MarginTier) []MarginTier {
+	out := make([]MarginTier, 0, len(tiers))
+	for _, t := range tiers {
+		if t.MaxLeverage > 0 && t.MinNotional >= 0 {
+			out = append(out, t)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].MinNotional < out[j].MinNotional })
+	out[0].MinNotional = 0
+	return out
+}
+
+func (p *Provider) tiersLocked(coin string) []MarginTier {
+	if tiers, ok := p.marginTiers[coin]; ok {
+		return tiers
+	}
+	return p.defaultTiers
+}
+
+// tierLocked returns the margin tier covering notional for coin.
+func (p *Provider) tierLocked(coin string, notional float64) MarginTier {
+	tiers := p.tiersLocked(coin)
+	tier := tiers[0]
+	for _, t := range tiers[1:] {
+		if notional < t.MinNotional {
+			break
+		}
+		tier = t
+	}
+	return tier
+}
+
+// initialMarginLocked is notional over the account leverage for coin, capped
+// by the tier's maximum leverage.
+func (p *Provider) initialMarginLocked(coin string, notional float64) float64 {
+	lev := p.leverageForCoinLocked(coin).Value
+	if max := p.tierLocked(coin, notional).MaxLeverage; lev <= 0 || lev > max {
+		lev = max
+	}
+	return notional / float64(lev)
+}
+
+func (p *Provider) maintenanceMarginLocked(coin string, notional float64) float64 {
+	return notional / float64(2*p.tierLocked(coin, notional).MaxLeverage)
+}
+
+// hasMarginLocked reports whether the account can fund the initial margin
+// and fee added by executing size at price. Orders that only reduce exposure
+// always pass.
+func (p *Provider) hasMarginLocked(coin string, priceD, sizeD exchange.Decimal, isBuy, crossed bool) bool {
+	price, size := priceD.Float64(), sizeD.Float64()
+	oldQty := 0.0
+	if state := p.positions[coin]; state != nil {
+		oldQty = state.Qty.Float64()
+	}
+	newQty := oldQty + size
+	if !isBuy {
+		newQty = oldQty - size
+	}
+	added := p.initialMarginLocked(coin, math.Abs(newQty)*price) - p.initialMarginLocked(coin, math.Abs(oldQty)*price)
+	if added <= 0 {
+		return true
+	}
+	feeRate := p.makerFee
+	if crossed {
+		feeRate = p.takerFee
+	}
+	snap := p.buildAccountSnapshotLocked()
+	free := snap.equity(p.cash) - snap.initialMargin
+	return added+size*price*feeRate.Float64() <= free+1e-9
+}
+
+// liquidationPxLocked solves for the mark at which account equity meets
+// total maintenance margin, holding the other positions' maintenance margin
+// (otherMaintenance) fixed. It returns nil when no positive price qualifies.
+func (p *Provider) liquidationPxLocked(coin string, qty, equity, otherMaintenance float64) *string {
+	if qty == 0 {
+		return nil
+	}
+	mark := p.resolveMarkPriceLocked(coin)
+	side := 1.0
+	if qty < 0 {
+		side = -1.0
+	}
+	l := 1 / float64(2*p.tierLocked(coin, math.Abs(qty)*mark).MaxLeverage)
+	px := (mark - side*(equity-otherMaintenance)/math.Abs(qty)) / (1 - side*l)
+	if !(px > 0) || math.IsInf(px, 0) {
+		return nil
+	}
+	s := formatDecimal(px)
+	return &s
+}
+
+// liquidateLocked closes every position at its mark, as a taker, once equity
+// falls below total maintenance margin, and cancels the liquidated coins'
+// resting orders. A shortfall beyond the account's equity is absorbed, like
+// the venue's backstop, so cash never goes negative.
+func (p *Provider) liquidateLocked() {
+	if len(p.positions) == 0 {
+		return
+	}
+	snap := p.buildAccountSnapshotLocked()
+	if snap.equity(p.cash) >= snap.maintenance {
+		return
+	}
+	for _, pos := range snap.positions {
+		coin := pos.Coin
+		state := p.positions[coin]
+		mark := p.resolveMarkPriceLocked(coin)
+		oid := p.newOidLocked()
+		filled, err := p.applyOrderLocked(coin, exchange.DecimalFromFloat(mark), state.Qty.Abs(), state.Qty.Sign() < 0, true, true, oid, "")
+		if err == nil && filled.Sign() > 0 {
+			fill := p.fills[len(p.fills)-1]
+			p.publishLocked(exchange.Event{Type: exchange.EventFill, Fill: &fill, ReceivedAt: p.now()})
+		}
+		for _, o := range p.ordersForCoinLocked(coin) {
+			p.cancelLocked(o)
+		}
+	}
+	if p.cash.Sign() < 0 {
+		p.cash = exchange.Decimal{}
+	}
+}
@@ -0,0 +1,87 @@
+package sim
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+func TestSimProvider_MarginRequirements(t *testing.T) {
+	ctx := context.Background()
+	p := New(WithInitialEquity(1000), WithMarginTiers("BTC", MarginTier{MaxLeverage: 20}, MarginTier{MinNotional: 5000, MaxLeverage: 10}))
+	asset, err := p.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Error(t, p.UpdateLeverage(ctx, asset, true, 25), "leverage above the first tier is rejected")
+	require.NoError(t, p.UpdateLeverage(ctx, asset, true, 20))
+	require.NoError(t, p.SetMarkPrice(ctx, "BTC", 100))
+
+	// 15000 notional falls in the 10x tier: 1500 margin exceeds 1000 equity.
+	resp, err := p.IOCMarket(ctx, "BTC", true, 150, 0.0001, false)
+	require.NoError(t, err)
+	assert.Equal(t, insufficientMargin, resp.Response.Data.Statuses[0].Error)
+	positions, _ := p.GetPositions(ctx)
+	assert.Empty(t, positions)
+
+	_, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "100", Sz: "90",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}}})
+	require.NoError(t, err)
+	state, err := p.GetAccountState(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "900", state.MarginSummary.TotalMarginUsed, "9000 notional at the 10x tier cap")
+
+	require.Len(t, state.AssetPositions, 1)
+	require.NotNil(t, state.AssetPositions[0].LiquidationPx)
+	liqPx, err := strconv.ParseFloat(*state.AssetPositions[0].LiquidationPx, 64)
+	require.NoError(t, err)
+	// Equity 1000 meets maintenance margin (notional / 20) at (100 - 1000/90) / (1 - 1/20).
+	assert.InDelta(t, (100-1000.0/90)/0.95, liqPx, 1e-6)
+
+	// A closing order needs no margin.
+	resp, err = p.IOCMarket(ctx, "BTC", false, 10, 0.0001, false)
+	require.NoError(t, err)
+	assert.NotNil(t, resp.Response.Data.Statuses[0].Filled)
+
+	require.NoError(t, p.SetMarkPrice(ctx, "BTC", 95))
+	positions, _ = p.GetPositions(ctx)
+	assert.Len(t, positions, 1, "above the liquidation price the position survives")
+}
+
+func TestSimProvider_Liquidation(t *testing.T) {
+	ctx := context.Background()
+	p := New(WithInitialEquity(1000), WithDefaultMarginTiers(MarginTier{MaxLeverage: 10}))
+	events, err := p.Events(ctx)
+	require.NoError(t, err)
+	asset, err := p.GetAssetIndex(ctx, "ETH")
+	require.NoError(t, err)
+	require.NoError(t, p.UpdateLeverage(ctx, asset, true, 10))
+	require.NoError(t, p.SetMarkPrice(ctx, "ETH", 100))
+	_, err = p.IOCMarket(ctx, "ETH", true, 90, 0.0001, false)
+	require.NoError(t, err)
+	require.NoError(t, p.SetTakeProfit(ctx, "ETH", "LONG", 90, 120))
+
+	// A gap far through the liquidation price closes the position and the
+	// backstop absorbs the shortfall.
+	require.NoError(t, p.SetMarkPrice(ctx, "ETH", 80))
+	positions, _ := p.GetPositions(ctx)
+	assert.Empty(t, positions, "position should be liquidated")
+	open, _ := p.GetOpenOrders(ctx)
+	assert.Empty(t, open, "liquidation cancels resting orders")
+	value, err := p.GetAccountValue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, value, "losses stop at the account's equity")
+
+	var fill *exchange.Fill
+	for _, ev := range drainEvents(events) {
+		if ev.Type == exchange.EventFill {
+			fill = ev.Fill
+		}
+	}
+	require.NotNil(t, fill, "liquidation fill is streamed")
+	assert.Equal(t, "Close Long", fill.Dir)
+	assert.Equal(t, "80", fill.Px)
+}
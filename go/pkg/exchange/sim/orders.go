@@ -0,0 +1,296 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"nof0-api/pkg/exchange"
+)
+
+// eventBufferSize is the capacity of each channel returned by Events. Events
+// are dropped rather than blocking the simulator when a subscriber lags.
+const eventBufferSize = 256
+
+// OpenOrder is a GTC/ALO limit order or a trigger order resting on the book.
+type OpenOrder struct {
+	Oid        int64            `json:"oid"`
+	Cloid      string           `json:"cloid,omitempty"`
+	Asset      int              `json:"asset"`
+	Coin       string           `json:"coin"`
+	IsBuy      bool             `json:"is_buy"`
+	LimitPx    exchange.Decimal `json:"limit_px"`
+	Sz         exchange.Decimal `json:"sz"`
+	ReduceOnly bool             `json:"reduce_only,omitempty"`
+	Timestamp  int64            `json:"timestamp"`
+
+	// Trigger is set until the order triggers; a triggered limit (non-market)
+	// order keeps resting as a plain limit order.
+	Trigger    *exchange.TriggerOrderType `json:"trigger,omitempty"`
+	TriggerPx  exchange.Decimal           `json:"trigger_px,omitempty"`
+	TriggerRel string                     `json:"trigger_rel,omitempty"` // "gte" fires when mark >= TriggerPx, "lte" when mark <= TriggerPx
+}
+
+// triggerRelation resolves when a trigger order fires. An explicit rel wins;
+// otherwise a take-profit fires as price moves in the position's favour and
+// a stop-loss (the default) as it moves against it, matching the venue.
+func triggerRelation(isBuy bool, tpsl, rel string) string {
+	switch strings.ToLower(strings.TrimSpace(rel)) {
+	case "gte", "lte":
+		return strings.ToLower(strings.TrimSpace(rel))
+	}
+	takeProfit := strings.EqualFold(tpsl, "tp")
+	// A sell closes a long: its take-profit is above, its stop below.
+	if isBuy == takeProfit {
+		return "lte"
+	}
+	return "gte"
+}
+
+func (o *OpenOrder) triggeredBy(mark exchange.Decimal) bool {
+	if o.TriggerRel == "gte" {
+		return mark.Cmp(o.TriggerPx) >= 0
+	}
+	return mark.Cmp(o.TriggerPx) <= 0
+}
+
+func (o *OpenOrder) status(status string, ts int64) exchange.OrderStatus {
+	side := "A"
+	if o.IsBuy {
+		side = "B"
+	}
+	return exchange.OrderStatus{
+		Order: exchange.OrderInfo{
+			Coin:      o.Coin,
+			Side:      side,
+			LimitPx:   o.LimitPx.String(),
+			Sz:        o.Sz.String(),
+			Oid:       o.Oid,
+			Timestamp: o.Timestamp,
+			OrigSz:    o.Sz.String(),
+			Cloid:     o.Cloid,
+		},
+		Status:          status,
+		StatusTimestamp: ts,
+	}
+}
+
+func (p *Provider) restOrderLocked(coin string, order exchange.Order, price, size exchange.Decimal, cloid string) *OpenOrder {
+	o := &OpenOrder{
+		Oid:        p.newOidLocked(),
+		Cloid:      cloid,
+		Asset:      order.Asset,
+		Coin:       coin,
+		IsBuy:      order.IsBuy,
+		LimitPx:    price,
+		Sz:         size,
+		ReduceOnly: order.ReduceOnly,
+		Timestamp:  p.now().UnixMilli(),
+	}
+	p.orders[o.Oid] = o
+	return o
+}
+
+// ordersForCoinLocked returns the resting orders for coin in submission order.
+func (p *Provider) ordersForCoinLocked(coin string) []*OpenOrder {
+	var out []*OpenOrder
+	for _, o := range p.orders {
+		if coin == "" || o.Coin == coin {
+			out = append(out, o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Oid < out[j].Oid })
+	return out
+}
+
+func (p *Provider) orderByCloidLocked(asset int, cloid string) *OpenOrder {
+	for _, o := range p.orders {
+		if o.Asset == asset && strings.EqualFold(o.Cloid, cloid) {
+			return o
+		}
+	}
+	return nil
+}
+
+// matchOrdersLocked fires trigger orders reached by mark and fills resting
+// limit orders it crosses. Limit orders fill at their limit price as maker;
+// market triggers fill at the mark as taker.
+func (p *Provider) matchOrdersLocked(coin string, markPx float64) {
+	mark := exchange.DecimalFromFloat(markPx)
+	for _, o := range p.ordersForCoinLocked(coin) {
+		if _, live := p.orders[o.Oid]; !live {
+			continue // cancelled by an earlier fill in this pass
+		}
+		if o.Trigger != nil {
+			if !o.triggeredBy(mark) {
+				continue
+			}
+			p.publishOrderLocked(o, "triggered")
+			if o.Trigger.IsMarket {
+				p.executeRestingLocked(o, mark, true)
+				continue
+			}
+			o.Trigger = nil
+			if marketable(o.IsBuy, o.LimitPx, mark) {
+				p.executeRestingLocked(o, o.LimitPx, true)
+			}
+			continue
+		}
+		if marketable(o.IsBuy, o.LimitPx, mark) {
+			p.executeRestingLocked(o, o.LimitPx, false)
+		}
+	}
+}
+
+func (p *Provider) executeRestingLocked(o *OpenOrder, price exchange.Decimal, crossed bool) {
+	delete(p.orders, o.Oid)
+	if !o.ReduceOnly && !p.hasMarginLocked(o.Coin, price, o.Sz, o.IsBuy, crossed) {
+		p.publishOrderLocked(o, "marginCanceled")
+		return
+	}
+	filled, err := p.applyOrderLocked(o.Coin, price, o.Sz, o.IsBuy, o.ReduceOnly, crossed, o.Oid, o.Cloid)
+	if err != nil || filled.IsZero() {
+		// Only reduce-only orders fail here: nothing left to reduce, or the
+		// position flipped to the same side.
+		p.publishOrderLocked(o, "reduceOnlyCanceled")
+		return
+	}
+	fill := p.fills[len(p.fills)-1]
+	p.publishLocked(exchange.Event{Type: exchange.EventFill, Fill: &fill, ReceivedAt: p.now()})
+	p.publishOrderLocked(o, "filled")
+	p.pruneReduceOnlyLocked(o.Coin)
+}
+
+// pruneReduceOnlyLocked cancels reduce-only orders once coin has no position
+// left to reduce.
+func (p *Provider) pruneReduceOnlyLocked(coin string) {
+	if _, ok := p.positions[coin]; ok {
+		return
+	}
+	for _, o := range p.ordersForCoinLocked(coin) {
+		if o.ReduceOnly {
+			delete(p.orders, o.Oid)
+			p.publishOrderLocked(o, "reduceOnlyCanceled")
+		}
+	}
+}
+
+// CancelOrder removes a resting order by oid.
+func (p *Provider) CancelOrder(ctx context.Context, asset int, oid int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.saveLocked(ctx)
+	o, ok := p.orders[oid]
+	if !ok || o.Asset != asset {
+		return fmt.Errorf("sim: order %d was never placed, already canceled, or filled", oid)
+	}
+	p.cancelLocked(o)
+	return nil
+}
+
+// CancelByCloid removes a resting order by client order id.
+func (p *Provider) CancelByCloid(ctx context.Context, asset int, cloid string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.saveLocked(ctx)
+	o := p.orderByCloidLocked(asset, strings.TrimSpace(cloid))
+	if o == nil {
+		return fmt.Errorf("sim: order with cloid %q was never placed, already canceled, or filled", cloid)
+	}
+	p.cancelLocked(o)
+	return nil
+}
+
+// CancelAllBySymbol cancels every resting limit and trigger order for coin.
+func (p *Provider) CancelAllBySymbol(ctx context.Context, coin string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.saveLocked(ctx)
+	for _, o := range p.ordersForCoinLocked(canonical(coin)) {
+		p.cancelLocked(o)
+	}
+	return nil
+}
+
+func (p *Provider) cancelLocked(o *OpenOrder) {
+	delete(p.orders, o.Oid)
+	p.publishOrderLocked(o, "canceled")
+}
+
+// GetOpenOrders returns resting limit and untriggered trigger orders in
+// submission order.
+func (p *Provider) GetOpenOrders(ctx context.Context) ([]exchange.OrderStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []exchange.OrderStatus
+	now := p.now().UnixMilli()
+	for _, o := range p.ordersForCoinLocked("") {
+		out = append(out, o.status("open", now))
+	}
+	return out, nil
+}
+
+// Events streams fills and order updates for orders that execute or change
+// state after submission: resting fills, trigger activations and cancels.
+// Synchronous fills are reported only in the PlaceOrder response. Each call
+// returns an independent subscription closed once ctx is done.
+func (p *Provider) Events(ctx context.Context) (<-chan exchange.Event, error) {
+	ch := make(chan exchange.Event, eventBufferSize)
+	p.mu.Lock()
+	p.subscribers = append(p.subscribers, ch)
+	p.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for i, sub := range p.subscribers {
+			if sub == ch {
+				p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (p *Provider) publishOrderLocked(o *OpenOrder, status string) {
+	st := o.status(status, p.now().UnixMilli())
+	p.publishLocked(exchange.Event{Type: exchange.EventOrderUpdate, Order: &st, ReceivedAt: p.now()})
+}
+
+func (p *Provider) publishLocked(ev exchange.Event) {
+	for _, sub := range p.subscribers {
+		select {
+		case sub <- ev:
+		default:
+		}
+	}
+}
+
+func restingResponse(oid int64) *exchange.OrderResponse {
+	return orderResponse(exchange.OrderStatusResponse{Resting: &exchange.RestingOrder{Oid: oid}})
+}
+
+func filledResponse(filled, price exchange.Decimal, oid int64) *exchange.OrderResponse {
+	return orderResponse(exchange.OrderStatusResponse{Filled: &exchange.FilledOrder{
+		TotalSz: filled.String(),
+		AvgPx:   price.String(),
+		Oid:     oid,
+	}})
+}
+
+func errorResponse(msg string) *exchange.OrderResponse {
+	return orderResponse(exchange.OrderStatusResponse{Error: msg})
+}
+
+func orderResponse(status exchange.OrderStatusResponse) *exchange.OrderResponse {
+	return &exchange.OrderResponse{
+		Status: "ok",
+		Response: exchange.OrderResponseData{
+			Type: "order",
+			Data: exchange.OrderResponseDataDetail{Statuses: []exchange.OrderStatusResponse{status}},
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package sim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+func drainEvents(ch <-chan exchange.Event) []exchange.Event {
+	var out []exchange.Event
+	for {
+		select {
+		case ev := <-ch:
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
+
+func TestSimProvider_RestingLimitOrders(t *testing.T) {
+	p := New(WithFees(1, 5))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := p.Events(ctx)
+	require.NoError(t, err)
+
+	asset, err := p.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+	require.NoError(t, p.SetMarkPrice(ctx, "BTC", 100))
+	gtc := exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}}
+
+	resp, err := p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "95", Sz: "2", OrderType: gtc, Cloid: "0x01"})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Response.Data.Statuses[0].Resting, "non-marketable GTC should rest")
+	oid := resp.Response.Data.Statuses[0].Resting.Oid
+	_, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "90", Sz: "1", OrderType: gtc, Cloid: "0x01"})
+	assert.Error(t, err, "cloid must be unique among open orders")
+
+	resp, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "101", Sz: "1",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Alo"}}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Response.Data.Statuses[0].Error, "marketable ALO should be rejected")
+	resp, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "99", Sz: "1",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Response.Data.Statuses[0].Error, "IOC that cannot cross should be rejected")
+
+	open, err := p.GetOpenOrders(ctx)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, oid, open[0].Order.Oid)
+	assert.Equal(t, "0x01", open[0].Order.Cloid)
+	assert.Equal(t, "B", open[0].Order.Side)
+
+	require.NoError(t, p.SetMarkPrice(ctx, "BTC", 96))
+	positions, _ := p.GetPositions(ctx)
+	assert.Empty(t, positions, "mark above the limit should not fill")
+
+	require.NoError(t, p.SetMarkPrice(ctx, "BTC", 94))
+	positions, _ = p.GetPositions(ctx)
+	require.Len(t, positions, 1)
+	assert.Equal(t, "95", *positions[0].EntryPx, "resting orders fill at their limit")
+	open, _ = p.GetOpenOrders(ctx)
+	assert.Empty(t, open)
+
+	evs := drainEvents(events)
+	require.Len(t, evs, 2)
+	require.Equal(t, exchange.EventFill, evs[0].Type)
+	assert.Equal(t, oid, evs[0].Fill.Oid)
+	assert.Equal(t, "0x01", evs[0].Fill.Cloid)
+	assert.False(t, evs[0].Fill.Crossed, "resting fills are maker fills")
+	assert.InDelta(t, 2*95*0.0001, parseDecimal(t, evs[0].Fill.Fee), 1e-9)
+	assert.Equal(t, "filled", evs[1].Order.Status)
+}
+
+func TestSimProvider_TriggerOrders(t *testing.T) {
+	p := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := p.Events(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, p.SetMarkPrice(ctx, "ETH", 100))
+	_, err = p.IOCMarket(ctx, "ETH", true, 2, 0.01, false)
+	require.NoError(t, err)
+	require.NoError(t, p.SetStopLoss(ctx, "ETH", "LONG", 2, 90))
+	require.NoError(t, p.SetTakeProfit(ctx, "ETH", "LONG", 2, 120))
+
+	// A limit (non-market) stop triggers into a resting limit order.
+	asset, err := p.GetAssetIndex(ctx, "ETH")
+	require.NoError(t, err)
+	_, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: false, LimitPx: "97", Sz: "1", ReduceOnly: true,
+		TriggerPx: "95", OrderType: exchange.OrderType{Trigger: &exchange.TriggerOrderType{Tpsl: "sl"}}})
+	require.NoError(t, err)
+	open, err := p.GetOpenOrders(ctx)
+	require.NoError(t, err)
+	require.Len(t, open, 3)
+
+	require.NoError(t, p.SetMarkPrice(ctx, "ETH", 94))
+	positions, _ := p.GetPositions(ctx)
+	require.Len(t, positions, 1)
+	assert.Equal(t, "2", positions[0].Szi, "triggered limit above the mark keeps resting")
+	open, _ = p.GetOpenOrders(ctx)
+	assert.Len(t, open, 3)
+
+	require.NoError(t, p.SetMarkPrice(ctx, "ETH", 98))
+	positions, _ = p.GetPositions(ctx)
+	require.Len(t, positions, 1)
+	assert.Equal(t, "1", positions[0].Szi, "triggered limit fills once the mark crosses it")
+
+	require.NoError(t, p.SetMarkPrice(ctx, "ETH", 89))
+	positions, _ = p.GetPositions(ctx)
+	assert.Empty(t, positions, "stop loss should close the rest of the position")
+	open, _ = p.GetOpenOrders(ctx)
+	assert.Empty(t, open, "take profit is cancelled with the position")
+
+	var statuses []string
+	var closeFill *exchange.Fill
+	for _, ev := range drainEvents(events) {
+		if ev.Type == exchange.EventOrderUpdate {
+			statuses = append(statuses, ev.Order.Status)
+		} else {
+			closeFill = ev.Fill
+		}
+	}
+	assert.Equal(t, []string{"triggered", "filled", "triggered", "filled", "reduceOnlyCanceled"}, statuses)
+	require.NotNil(t, closeFill)
+	assert.True(t, closeFill.IsClose())
+	assert.True(t, closeFill.Crossed, "market triggers fill as taker")
+	assert.Equal(t, "89", closeFill.Px)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "channel should close with the context")
+	case <-time.After(time.Second):
+		t.Fatal("event channel not closed")
+	}
+	assert.True(t, p.Capabilities().EventStream)
+}
@@ -8,13 +8,17 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"nof0-api/pkg/exchange"
 )
 
 const (
-	defaultInitialEquity = 100000.0
-	defaultFallbackPrice = 100.0
+	defaultInitialEquity   = 100000.0
+	defaultFallbackPrice   = 100.0
+	defaultFundingInterval = time.Hour
+	maxFillHistory         = 10000
+	maxFundingHistory      = 10000
 )
 
 // Provider is a paper-trading exchange implementation that keeps positions,
@@ -29,32 +33,129 @@ type Provider struct {
 	leverage    map[int]exchange.Leverage
 
 	markPx    map[string]float64 // latest mark price per symbol
-	positions map[string]*positionState
+	positions map[string]*PositionState
+	orders    map[int64]*OpenOrder // resting limit and trigger orders by oid
 
 	initialEquity float64
-	cash          float64
+	cash          exchange.Decimal
+
+	now   func() time.Time
+	ids   *idCounter      // oid/tid source, shared with sub-accounts
+	fills []exchange.Fill // execution history, oldest first
+
+	makerFee exchange.Decimal // fraction of fill notional charged to resting orders
+	takerFee exchange.Decimal // fraction of fill notional charged to crossing orders
+
+	fundingInterval time.Duration
+	fundingRate     map[string]float64 // latest per-interval rate per symbol
+	lastFunding     time.Time          // last settled funding boundary
+	funding         []exchange.FundingPayment
+
+	marginTiers  map[string][]MarginTier // per-symbol tiers, lowest notional first
+	defaultTiers []MarginTier
+
+	subscribers []chan exchange.Event
+
+	store Store // optional; saves state after every change
+	dirty bool
+
+	// Background saves (see state.go), guarded by saveMu rather than mu.
+	saveMu      sync.Mutex
+	savePending *State
+	saveDone    chan struct{} // closed when the running saver exits; nil when idle
+
+	parent      *Provider // set on sub-accounts
+	accountID   string
+	subMu       sync.Mutex
+	subAccounts map[string]*Provider
 }
 
-type positionState struct {
-	Coin  string
-	Qty   float64 // positive long, negative short
-	Entry float64 // average entry price
+// PositionState is an open simulated position.
+type PositionState struct {
+	Coin  string           `json:"coin"`
+	Qty   exchange.Decimal `json:"qty"`   // positive long, negative short
+	Entry exchange.Decimal `json:"entry"` // average entry price
+}
+
+// Sizes, entries, cash, fees and realised PnL are exact decimals, so fills at
+// the prices and sizes a caller submitted settle without float drift. Marks
+// and margin estimates stay float64.
+const (
+	// cashPlaces is the precision fees, realised PnL and funding settle to.
+	cashPlaces = 8
+	// entryPlaces bounds the digits of a volume-weighted entry price.
+	entryPlaces = 12
+)
+
+var bpsUnit = exchange.NewDecimal(1, -4)
+
+// Option customises a simulator instance.
+type Option func(*Provider)
+
+// WithInitialEquity sets the starting cash balance.
+func WithInitialEquity(equity float64) Option {
+	return func(p *Provider) {
+		if equity > 0 {
+			p.initialEquity = equity
+			p.cash = exchange.DecimalFromFloat(equity)
+		}
+	}
 }
 
-// New constructs a new simulator instance with default equity.
-func New() *Provider {
-	return &Provider{
-		nextAssetID:   1,
-		assetIndex:    make(map[string]int),
-		assetSymbol:   make(map[int]string),
-		leverage:      make(map[int]exchange.Leverage),
-		markPx:        make(map[string]float64),
-		positions:     make(map[string]*positionState),
-		initialEquity: defaultInitialEquity,
-		cash:          defaultInitialEquity,
+// WithFees charges makerBps on fills of resting (non-crossing) orders and
+// takerBps on fills that cross the mark, both in basis points of notional.
+func WithFees(makerBps, takerBps float64) Option {
+	return func(p *Provider) {
+		p.makerFee = exchange.DecimalFromFloat(math.Max(0, makerBps)).Mul(bpsUnit)
+		p.takerFee = exchange.DecimalFromFloat(math.Max(0, takerBps)).Mul(bpsUnit)
 	}
 }
 
+// WithFundingInterval sets how often funding settles on open positions.
+func WithFundingInterval(interval time.Duration) Option {
+	return func(p *Provider) {
+		if interval > 0 {
+			p.fundingInterval = interval
+		}
+	}
+}
+
+// WithClock overrides the time source used for fills and funding settlement.
+func WithClock(now func() time.Time) Option {
+	return func(p *Provider) {
+		if now != nil {
+			p.now = now
+		}
+	}
+}
+
+// New constructs a new simulator instance. Without options it starts with
+// default equity and charges no fees.
+func New(opts ...Option) *Provider {
+	p := &Provider{
+		nextAssetID:     1,
+		assetIndex:      make(map[string]int),
+		assetSymbol:     make(map[int]string),
+		leverage:        make(map[int]exchange.Leverage),
+		markPx:          make(map[string]float64),
+		positions:       make(map[string]*PositionState),
+		orders:          make(map[int64]*OpenOrder),
+		initialEquity:   defaultInitialEquity,
+		cash:            exchange.DecimalFromFloat(defaultInitialEquity),
+		now:             time.Now,
+		ids:             &idCounter{oid: 1, tid: 1},
+		fundingInterval: defaultFundingInterval,
+		fundingRate:     make(map[string]float64),
+		marginTiers:     make(map[string][]MarginTier),
+		defaultTiers:    []MarginTier{{MaxLeverage: defaultMaxLeverage}},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.lastFunding = p.now().Truncate(p.fundingInterval)
+	return p
+}
+
 func canonical(coin string) string { return strings.ToUpper(strings.TrimSpace(coin)) }
 
 // GetAssetIndex resolves a stable asset identifier for the provided coin.
@@ -70,161 +171,278 @@ func (p *Provider) GetAssetIndex(ctx context.Context, coin string) (int, error)
 	p.nextAssetID++
 	p.assetIndex[c] = id
 	p.assetSymbol[id] = c
+	p.saveLocked(ctx)
 	return id, nil
 }
 
-// SetMarkPrice updates the reference price used for unrealised PnL and IOC fills.
+// SetMarkPrice updates the reference price used for unrealised PnL and IOC fills,
+// then fires trigger orders, fills resting limit orders crossed by the new
+// price and liquidates the account if equity falls below maintenance margin.
+// Funding intervals that elapsed before the update settle at the previous
+// mark. The update applies to the parent account and every sub-account.
 func (p *Provider) SetMarkPrice(ctx context.Context, coin string, price float64) error {
 	if price <= 0 {
 		return fmt.Errorf("sim: mark price must be positive")
 	}
+	for _, acct := range p.accounts() {
+		acct.applyMarkPrice(ctx, canonical(coin), price)
+	}
+	return nil
+}
+
+func (p *Provider) applyMarkPrice(ctx context.Context, coin string, price float64) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	p.markPx[canonical(coin)] = price
+	defer p.saveLocked(ctx)
+	p.accrueFundingLocked()
+	p.markPx[coin] = price
+	p.matchOrdersLocked(coin, price)
+	p.liquidateLocked()
+}
+
+// SetFundingRate updates the per-interval funding rate applied to coin at
+// each settlement: longs pay rate × notional to shorts (the reverse when
+// negative). Intervals that elapsed before the update settle at the old rate.
+// Like SetMarkPrice, the rate is shared by every sub-account.
+func (p *Provider) SetFundingRate(ctx context.Context, coin string, rate float64) error {
+	if math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return fmt.Errorf("sim: invalid funding rate %v", rate)
+	}
+	for _, acct := range p.accounts() {
+		acct.applyFundingRate(ctx, canonical(coin), rate)
+	}
 	return nil
 }
 
-// PlaceOrder synchronously fills an IOC-like order at the provided limit price.
+func (p *Provider) applyFundingRate(ctx context.Context, coin string, rate float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.saveLocked(ctx)
+	p.accrueFundingLocked()
+	p.fundingRate[coin] = rate
+}
+
+// PlaceOrder submits an order. Trigger orders and limit orders that do not
+// cross the mark rest on the book until a SetMarkPrice update reaches them;
+// marketable GTC and IOC orders fill synchronously at the limit price and pay
+// the taker fee. A marketable ALO order is rejected, as is an IOC order that
+// cannot cross, with the reason in the order status.
 func (p *Provider) PlaceOrder(ctx context.Context, order exchange.Order) (*exchange.OrderResponse, error) {
 	if order.Sz == "" {
 		return nil, fmt.Errorf("sim: order size is required")
 	}
-	price, err := strconv.ParseFloat(strings.TrimSpace(order.LimitPx), 64)
-	if err != nil || price <= 0 {
+	price, err := exchange.ParseDecimal(order.LimitPx)
+	if err != nil || price.Sign() <= 0 {
 		return nil, fmt.Errorf("sim: invalid limit price %q", order.LimitPx)
 	}
-	size, err := strconv.ParseFloat(strings.TrimSpace(order.Sz), 64)
-	if err != nil || size <= 0 {
+	size, err := exchange.ParseDecimal(order.Sz)
+	if err != nil || size.Sign() <= 0 {
 		return nil, fmt.Errorf("sim: invalid size %q", order.Sz)
 	}
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.saveLocked(ctx)
 
-	coin := ""
-	for sym, id := range p.assetIndex {
-		if id == order.Asset {
-			coin = sym
-			break
-		}
-	}
-	if coin == "" {
+	coin, ok := p.assetSymbol[order.Asset]
+	if !ok {
 		return nil, fmt.Errorf("sim: unknown asset index %d", order.Asset)
 	}
+	cloid := strings.TrimSpace(order.Cloid)
+	if cloid != "" && p.orderByCloidLocked(order.Asset, cloid) != nil {
+		return nil, fmt.Errorf("sim: duplicate cloid %q", cloid)
+	}
 
-	realized, filled, err := p.applyOrderLocked(coin, price, size, order.IsBuy, order.ReduceOnly)
+	p.accrueFundingLocked()
+	if trigger := order.OrderType.Trigger; trigger != nil {
+		triggerPx, err := exchange.ParseDecimal(order.TriggerPx)
+		if err != nil || triggerPx.Sign() <= 0 {
+			return nil, fmt.Errorf("sim: invalid trigger price %q", order.TriggerPx)
+		}
+		o := p.restOrderLocked(coin, order, price, size, cloid)
+		o.Trigger = &exchange.TriggerOrderType{IsMarket: trigger.IsMarket, Tpsl: trigger.Tpsl}
+		o.TriggerPx = triggerPx
+		o.TriggerRel = triggerRelation(order.IsBuy, trigger.Tpsl, order.TriggerRel)
+		return restingResponse(o.Oid), nil
+	}
+
+	tif := "gtc"
+	if order.OrderType.Limit != nil && order.OrderType.Limit.TIF != "" {
+		tif = strings.ToLower(order.OrderType.Limit.TIF)
+	}
+	crossed := p.crossesLocked(coin, order.IsBuy, price)
+	switch {
+	case tif == "alo" && crossed:
+		return errorResponse("Post only order would have immediately matched"), nil
+	case tif == "ioc" && !crossed:
+		return errorResponse("Order could not immediately match against any resting orders"), nil
+	case !order.ReduceOnly && !p.hasMarginLocked(coin, price, size, order.IsBuy, crossed):
+		return errorResponse(insufficientMargin), nil
+	case !crossed:
+		o := p.restOrderLocked(coin, order, price, size, cloid)
+		return restingResponse(o.Oid), nil
+	}
+
+	oid := p.newOidLocked()
+	filled, err := p.applyOrderLocked(coin, price, size, order.IsBuy, order.ReduceOnly, true, oid, cloid)
 	if err != nil {
 		return nil, err
 	}
-	if realized != 0 {
-		p.cash += realized
-	}
-	if filled > 0 {
-		p.markPx[coin] = price
-	}
-
-	resp := &exchange.OrderResponse{
-		Status: "ok",
-		Response: exchange.OrderResponseData{
-			Type: "order",
-			Data: exchange.OrderResponseDataDetail{
-				Statuses: []exchange.OrderStatusResponse{{
-					Filled: &exchange.FilledOrder{
-						TotalSz: formatDecimal(filled),
-						AvgPx:   formatDecimal(price),
-						Oid:     1,
-					},
-				}},
-			},
-		},
+	if filled.Sign() > 0 {
+		p.markPx[coin] = price.Float64()
 	}
-	return resp, nil
+	p.pruneReduceOnlyLocked(coin)
+	return filledResponse(filled, price, oid), nil
 }
 
-func (p *Provider) applyOrderLocked(coin string, price, size float64, isBuy, reduceOnly bool) (float64, float64, error) {
-	if price <= 0 {
-		return 0, 0, fmt.Errorf("sim: price must be positive")
+// crossesLocked reports whether a limit at price is marketable against the
+// mark. Without a known mark the order is treated as marketable.
+func (p *Provider) crossesLocked(coin string, isBuy bool, price exchange.Decimal) bool {
+	mark, ok := p.markPx[coin]
+	if !ok || mark <= 0 {
+		return true
 	}
-	if size <= 0 {
-		return 0, 0, fmt.Errorf("sim: size must be positive")
+	return marketable(isBuy, price, exchange.DecimalFromFloat(mark))
+}
+
+func marketable(isBuy bool, price, mark exchange.Decimal) bool {
+	if isBuy {
+		return price.Cmp(mark) >= 0
+	}
+	return price.Cmp(mark) <= 0
+}
+
+// applyOrderLocked executes size at price against the position for coin,
+// settles realised PnL and the maker or taker fee into cash, and records the
+// resulting fill under oid. It returns the executed size.
+func (p *Provider) applyOrderLocked(coin string, price, size exchange.Decimal, isBuy, reduceOnly, crossed bool, oid int64, cloid string) (exchange.Decimal, error) {
+	if price.Sign() <= 0 {
+		return exchange.Decimal{}, fmt.Errorf("sim: price must be positive")
+	}
+	if size.Sign() <= 0 {
+		return exchange.Decimal{}, fmt.Errorf("sim: size must be positive")
 	}
 
 	state := p.positions[coin]
 	if reduceOnly {
-		if state == nil || state.Qty == 0 {
-			return 0, 0, nil
+		if state == nil || state.Qty.IsZero() {
+			return exchange.Decimal{}, nil
 		}
 	} else if state == nil {
-		state = &positionState{Coin: coin}
+		state = &PositionState{Coin: coin}
 		p.positions[coin] = state
 	}
 
-	execSize := size
-	delta := execSize
-	if !isBuy {
-		delta = -execSize
+	signed := func(sz exchange.Decimal) exchange.Decimal {
+		if isBuy {
+			return sz
+		}
+		return sz.Neg()
 	}
+	delta := signed(size)
+	oldQty := state.Qty
+	increasing := oldQty.Sign()*delta.Sign() > 0
 
 	if reduceOnly {
-		if state.Qty*delta > 0 {
-			return 0, 0, fmt.Errorf("sim: reduce-only order would increase position")
-		}
-		maxQty := math.Abs(state.Qty)
-		if execSize > maxQty {
-			execSize = maxQty
+		if increasing {
+			return exchange.Decimal{}, fmt.Errorf("sim: reduce-only order would increase position")
 		}
-		if execSize <= 0 {
-			return 0, 0, nil
-		}
-		delta = execSize
-		if !isBuy {
-			delta = -execSize
+		if size.Cmp(oldQty.Abs()) > 0 {
+			delta = signed(oldQty.Abs())
 		}
 	}
 
-	oldQty := state.Qty
-	newQty := oldQty + delta
-
-	realized := 0.0
-	if oldQty != 0 && oldQty*delta < 0 {
-		closeQty := math.Min(math.Abs(oldQty), math.Abs(delta))
-		dir := 1.0
-		if oldQty < 0 {
-			dir = -1.0
+	newQty := oldQty.Add(delta)
+
+	realized := exchange.Decimal{}
+	if oldQty.Sign()*delta.Sign() < 0 {
+		closeQty := oldQty.Abs()
+		if delta.Abs().Cmp(closeQty) < 0 {
+			closeQty = delta.Abs()
+		}
+		realized = closeQty.Mul(price.Sub(state.Entry))
+		if oldQty.Sign() < 0 {
+			realized = realized.Neg()
 		}
-		realized = closeQty * (price - state.Entry) * dir
+		realized = realized.Round(cashPlaces, exchange.RoundHalfUp)
 	}
 
 	switch {
-	case oldQty == 0:
+	case oldQty.IsZero():
+		state.Entry = price
+	case increasing:
+		state.Entry = oldQty.Mul(state.Entry).Add(delta.Mul(price)).Div(newQty, entryPlaces)
+	case oldQty.Sign()*newQty.Sign() < 0:
 		state.Entry = price
-	case oldQty*delta > 0:
-		state.Entry = ((oldQty * state.Entry) + (delta * price)) / newQty
-	case oldQty*delta < 0:
-		if newQty == 0 {
-			state.Entry = price
-		} else if oldQty*newQty < 0 {
-			state.Entry = price
-		}
 	}
 
 	state.Qty = newQty
-	if math.Abs(state.Qty) < 1e-10 {
-		state.Qty = 0
-	}
-	if state.Qty == 0 {
-		state.Entry = 0
+	if state.Qty.IsZero() {
 		delete(p.positions, coin)
 	}
-	return realized, math.Abs(delta), nil
+	filled := delta.Abs()
+	feeRate := p.makerFee
+	if crossed {
+		feeRate = p.takerFee
+	}
+	fee := filled.Mul(price).Mul(feeRate).Round(cashPlaces, exchange.RoundHalfUp)
+	p.cash = p.cash.Add(realized).Sub(fee)
+	p.recordFillLocked(coin, price, filled, isBuy, crossed, oldQty, newQty, realized, fee, oid, cloid)
+	return filled, nil
 }
 
-// CancelOrder is a no-op for the simulator (orders fill immediately).
-func (p *Provider) CancelOrder(ctx context.Context, asset int, oid int64) error { return nil }
+func (p *Provider) newOidLocked() int64 {
+	return p.ids.nextOid()
+}
 
-// GetOpenOrders always returns an empty slice because fills are synchronous.
-func (p *Provider) GetOpenOrders(ctx context.Context) ([]exchange.OrderStatus, error) {
-	return nil, nil
+// recordFillLocked appends a Hyperliquid-style fill to the execution history.
+// Like the venue, ClosedPnl is gross of the fee reported alongside it.
+func (p *Provider) recordFillLocked(coin string, price, size exchange.Decimal, isBuy, crossed bool, oldQty, newQty, realized, fee exchange.Decimal, oid int64, cloid string) {
+	side := "A"
+	if isBuy {
+		side = "B"
+	}
+	tid := p.ids.nextTid()
+	p.fills = append(p.fills, exchange.Fill{
+		Coin:          coin,
+		Side:          side,
+		Px:            price.String(),
+		AvgPx:         price.String(),
+		TotalSz:       size.String(),
+		LimitPx:       price.String(),
+		Sz:            size.String(),
+		Oid:           oid,
+		Cloid:         cloid,
+		Crossed:       crossed,
+		Fee:           fee.String(),
+		FeeToken:      "USDC",
+		Tid:           tid,
+		Timestamp:     p.now().UnixMilli(),
+		Dir:           fillDirection(oldQty, newQty),
+		StartPosition: oldQty.String(),
+		ClosedPnl:     realized.String(),
+	})
+	if len(p.fills) > maxFillHistory {
+		p.fills = p.fills[len(p.fills)-maxFillHistory:]
+	}
+}
+
+// fillDirection mirrors the venue's dir labels for a position change.
+func fillDirection(oldQty, newQty exchange.Decimal) string {
+	switch {
+	case oldQty.Sign() > 0 && newQty.Sign() < 0:
+		return "Long > Short"
+	case oldQty.Sign() < 0 && newQty.Sign() > 0:
+		return "Short > Long"
+	case oldQty.Sign() > 0 && newQty.Cmp(oldQty) < 0:
+		return "Close Long"
+	case oldQty.Sign() < 0 && newQty.Cmp(oldQty) > 0:
+		return "Close Short"
+	case newQty.Sign() > 0:
+		return "Open Long"
+	default:
+		return "Open Short"
+	}
 }
 
 // IOCMarket emulates a market IOC order around the latest mark price.
@@ -267,9 +485,10 @@ func (p *Provider) IOCMarket(ctx context.Context, coin string, isBuy bool, qty f
 func (p *Provider) GetPositions(ctx context.Context) ([]exchange.Position, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.persistLocked(ctx)
 
-	positions, _, _, _ := p.buildAccountSnapshotLocked()
-	return positions, nil
+	p.accrueFundingLocked()
+	return p.buildAccountSnapshotLocked().positions, nil
 }
 
 // ClosePosition fully closes the position for the given coin at the latest mark price.
@@ -277,80 +496,74 @@ func (p *Provider) ClosePosition(ctx context.Context, coin string) (*exchange.Or
 	c := canonical(coin)
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.saveLocked(ctx)
 
+	p.accrueFundingLocked()
 	state := p.positions[c]
-	if state == nil || state.Qty == 0 {
+	if state == nil || state.Qty.IsZero() {
 		return nil, nil
 	}
-	price := p.resolveMarkPriceLocked(c)
-	if price <= 0 {
-		price = state.Entry
+	price := state.Entry
+	if mark := p.resolveMarkPriceLocked(c); mark > 0 {
+		price = exchange.DecimalFromFloat(mark)
 	}
-	size := math.Abs(state.Qty)
-	isBuy := state.Qty < 0
-	realized, filled, err := p.applyOrderLocked(c, price, size, isBuy, false)
+	size := state.Qty.Abs()
+	isBuy := state.Qty.Sign() < 0
+	oid := p.newOidLocked()
+	filled, err := p.applyOrderLocked(c, price, size, isBuy, false, true, oid, "")
 	if err != nil {
 		return nil, err
 	}
-	if realized != 0 {
-		p.cash += realized
-	}
-	if filled > 0 {
-		p.markPx[c] = price
-	}
-	resp := &exchange.OrderResponse{
-		Status: "ok",
-		Response: exchange.OrderResponseData{
-			Type: "order",
-			Data: exchange.OrderResponseDataDetail{
-				Statuses: []exchange.OrderStatusResponse{
-					{
-						Filled: &exchange.FilledOrder{
-							TotalSz: formatDecimal(filled),
-							AvgPx:   formatDecimal(price),
-							Oid:     0,
-						},
-					},
-				},
-			},
-		},
+	if filled.Sign() > 0 {
+		p.markPx[c] = price.Float64()
 	}
-	return resp, nil
+	p.pruneReduceOnlyLocked(c)
+	return filledResponse(filled, price, oid), nil
 }
 
 // UpdateLeverage stores leverage preferences for later margin calculations.
+// Leverage above the asset's first margin tier is rejected.
 func (p *Provider) UpdateLeverage(ctx context.Context, asset int, isCross bool, leverage int) error {
 	if leverage <= 0 {
 		return fmt.Errorf("sim: leverage must be positive")
 	}
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.saveLocked(ctx)
+	if coin, ok := p.assetSymbol[asset]; ok {
+		if max := p.tiersLocked(coin)[0].MaxLeverage; leverage > max {
+			return fmt.Errorf("sim: leverage %d exceeds max %d for %s", leverage, max, coin)
+		}
+	}
 	mode := map[bool]string{true: "cross", false: "isolated"}[isCross]
 	p.leverage[asset] = exchange.Leverage{Type: mode, Value: leverage}
 	return nil
 }
 
-// GetAccountState returns a snapshot with equity, margin usage and open positions.
+// GetAccountState returns a snapshot with equity, margin usage and open
+// positions. Equity is net of fees paid and funding settled so far.
 func (p *Provider) GetAccountState(ctx context.Context) (*exchange.AccountState, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.persistLocked(ctx)
 
-	positions, unrealized, notional, margin := p.buildAccountSnapshotLocked()
-	equity := p.cash + unrealized
+	p.accrueFundingLocked()
+	snap := p.buildAccountSnapshotLocked()
+	equity := snap.equity(p.cash)
 	state := &exchange.AccountState{
 		MarginSummary: exchange.MarginSummary{
 			AccountValue:    formatDecimal(equity),
-			TotalMarginUsed: formatDecimal(margin),
-			TotalNtlPos:     formatDecimal(notional),
-			TotalRawUSD:     formatDecimal(notional),
+			TotalMarginUsed: formatDecimal(snap.initialMargin),
+			TotalNtlPos:     formatDecimal(snap.notional),
+			TotalRawUSD:     formatDecimal(snap.notional),
 		},
 		CrossMarginSummary: exchange.CrossMarginSummary{
 			AccountValue:    formatDecimal(equity),
-			TotalMarginUsed: formatDecimal(margin),
-			TotalNtlPos:     formatDecimal(notional),
-			TotalRawUSD:     formatDecimal(notional),
+			TotalMarginUsed: formatDecimal(snap.initialMargin),
+			TotalNtlPos:     formatDecimal(snap.notional),
+			TotalRawUSD:     formatDecimal(snap.notional),
 		},
-		AssetPositions: positions,
+		AssetPositions: snap.positions,
 	}
 	return state, nil
 }
@@ -359,8 +572,9 @@ func (p *Provider) GetAccountState(ctx context.Context) (*exchange.AccountState,
 func (p *Provider) GetAccountValue(ctx context.Context) (float64, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	_, unrealized, _, _ := p.buildAccountSnapshotLocked()
-	return p.cash + unrealized, nil
+	defer p.persistLocked(ctx)
+	p.accrueFundingLocked()
+	return p.buildAccountSnapshotLocked().equity(p.cash), nil
 }
 
 // FormatPrice normalises price formatting to 8 decimal places.
@@ -379,15 +593,172 @@ func (p *Provider) FormatSize(ctx context.Context, coin string, size float64) (s
 	return formatDecimal(size), nil
 }
 
-// CancelAllBySymbol is a no-op for the simulator.
-func (p *Provider) CancelAllBySymbol(ctx context.Context, coin string) error {
-	return nil
+// SizeStep is 1e-8, matching FormatSize.
+func (p *Provider) SizeStep(ctx context.Context, coin string) (exchange.Decimal, error) {
+	return exchange.NewDecimal(1, -8), nil
+}
+
+// SetStopLoss places a reduce-only market trigger order at stopPrice.
+// positionSide: "LONG" or "SHORT".
+func (p *Provider) SetStopLoss(ctx context.Context, coin string, positionSide string, qty float64, stopPrice float64) error {
+	return p.placeProtective(ctx, coin, positionSide, qty, stopPrice, "sl")
+}
+
+// SetTakeProfit places a reduce-only market trigger order at takeProfit.
+// positionSide: "LONG" or "SHORT".
+func (p *Provider) SetTakeProfit(ctx context.Context, coin string, positionSide string, qty float64, takeProfit float64) error {
+	return p.placeProtective(ctx, coin, positionSide, qty, takeProfit, "tp")
+}
+
+func (p *Provider) placeProtective(ctx context.Context, coin, positionSide string, qty, triggerPx float64, tpsl string) error {
+	if triggerPx <= 0 {
+		return fmt.Errorf("sim: trigger price must be positive")
+	}
+	if qty <= 0 {
+		return fmt.Errorf("sim: trigger size must be positive")
+	}
+	asset, err := p.GetAssetIndex(ctx, coin)
+	if err != nil {
+		return err
+	}
+	_, err = p.PlaceOrder(ctx, exchange.Order{
+		Asset:      asset,
+		IsBuy:      strings.EqualFold(positionSide, "SHORT"), // buy to cover a short
+		LimitPx:    formatDecimal(triggerPx),
+		Sz:         formatDecimal(qty),
+		ReduceOnly: true,
+		TriggerPx:  formatDecimal(triggerPx),
+		OrderType:  exchange.OrderType{Trigger: &exchange.TriggerOrderType{IsMarket: true, Tpsl: tpsl}},
+	})
+	return err
+}
+
+// GetFills returns simulated executions at or after since, oldest first.
+func (p *Provider) GetFills(ctx context.Context, since time.Time) ([]exchange.Fill, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sinceMs := int64(0)
+	if !since.IsZero() {
+		sinceMs = since.UnixMilli()
+	}
+	out := make([]exchange.Fill, 0, len(p.fills))
+	for _, f := range p.fills {
+		if f.Timestamp >= sinceMs {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// GetFundingPayments returns simulated funding settlements at or after since,
+// oldest first. USDC is positive when the position received funding.
+func (p *Provider) GetFundingPayments(ctx context.Context, since time.Time) ([]exchange.FundingPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.persistLocked(ctx)
+	p.accrueFundingLocked()
+	sinceMs := int64(0)
+	if !since.IsZero() {
+		sinceMs = since.UnixMilli()
+	}
+	out := make([]exchange.FundingPayment, 0, len(p.funding))
+	for _, f := range p.funding {
+		if f.Timestamp >= sinceMs {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// accrueFundingLocked settles every funding interval boundary passed since
+// the last settlement against the open positions, using the latest mark and
+// funding rate known for each symbol.
+func (p *Provider) accrueFundingLocked() {
+	now := p.now()
+	for next := p.lastFunding.Add(p.fundingInterval); !next.After(now); next = next.Add(p.fundingInterval) {
+		p.settleFundingLocked(next)
+		p.lastFunding = next
+		p.dirty = true
+	}
+}
+
+func (p *Provider) settleFundingLocked(at time.Time) {
+	coins := make([]string, 0, len(p.positions))
+	for coin := range p.positions {
+		coins = append(coins, coin)
+	}
+	sort.Strings(coins)
+	for _, coin := range coins {
+		rate := p.fundingRate[coin]
+		state := p.positions[coin]
+		if rate == 0 || state.Qty.IsZero() {
+			continue
+		}
+		mark := exchange.DecimalFromFloat(p.resolveMarkPriceLocked(coin))
+		payment := state.Qty.Neg().Mul(mark).Mul(exchange.DecimalFromFloat(rate)).Round(cashPlaces, exchange.RoundHalfUp)
+		p.cash = p.cash.Add(payment)
+		p.funding = append(p.funding, exchange.FundingPayment{
+			Coin:        coin,
+			USDC:        payment.String(),
+			Szi:         state.Qty.String(),
+			FundingRate: strconv.FormatFloat(rate, 'f', -1, 64),
+			Timestamp:   at.UnixMilli(),
+		})
+	}
+	if len(p.funding) > maxFundingHistory {
+		p.funding = p.funding[len(p.funding)-maxFundingHistory:]
+	}
+}
+
+// Capabilities reports the optional exchange extensions this provider supports.
+func (p *Provider) Capabilities() exchange.Capabilities {
+	return exchange.DetectCapabilities(p)
+}
+
+// NewFromConfig builds a simulator from a provider's sim block; a nil cfg
+// yields the defaults. A file state backend is attached and restored here,
+// while "redis" is left to the application.
+func NewFromConfig(cfg *exchange.SimConfig) (*Provider, error) {
+	var opts []Option
+	if cfg != nil {
+		opts = append(opts,
+			WithInitialEquity(cfg.InitialEquity),
+			WithFees(cfg.MakerFeeBps, cfg.TakerFeeBps),
+			WithFundingInterval(cfg.FundingInterval),
+		)
+		for coin, tiers := range cfg.MarginTiers {
+			converted := make([]MarginTier, 0, len(tiers))
+			for _, t := range tiers {
+				converted = append(converted, MarginTier{MinNotional: t.MinNotional, MaxLeverage: t.MaxLeverage})
+			}
+			if strings.EqualFold(coin, "default") {
+				opts = append(opts, WithDefaultMarginTiers(converted...))
+			} else {
+				opts = append(opts, WithMarginTiers(coin, converted...))
+			}
+		}
+	}
+	p := New(opts...)
+	if cfg != nil && cfg.State != nil && cfg.State.Backend == "file" {
+		if err := p.AttachStore(context.Background(), NewFileStore(cfg.State.Path)); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
 }
 
 // Registry hook for exchange.Config.
 func init() {
 	exchange.RegisterProvider("sim", func(name string, cfg *exchange.ProviderConfig) (exchange.Provider, error) {
-		return New(), nil
+		var simCfg *exchange.SimConfig
+		if cfg != nil {
+			simCfg = cfg.Sim
+		}
+		p, err := NewFromConfig(simCfg)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
 	})
 }
 
@@ -395,36 +766,45 @@ func (p *Provider) resolveMarkPriceLocked(coin string) float64 {
 	if price, ok := p.markPx[coin]; ok && price > 0 {
 		return price
 	}
-	if state, ok := p.positions[coin]; ok && state.Entry > 0 {
-		return state.Entry
+	if state, ok := p.positions[coin]; ok && state.Entry.Sign() > 0 {
+		return state.Entry.Float64()
 	}
 	return defaultFallbackPrice
 }
 
-func (p *Provider) buildAccountSnapshotLocked() ([]exchange.Position, float64, float64, float64) {
-	positions := make([]exchange.Position, 0, len(p.positions))
-	totalUnreal := 0.0
-	totalNotional := 0.0
-	totalMargin := 0.0
+// accountSnapshot is the cross-margin account marked to the latest prices.
+type accountSnapshot struct {
+	positions     []exchange.Position
+	unrealized    float64
+	notional      float64
+	initialMargin float64
+	maintenance   float64
+}
+
+func (a accountSnapshot) equity(cash exchange.Decimal) float64 { return cash.Float64() + a.unrealized }
+
+func (p *Provider) buildAccountSnapshotLocked() accountSnapshot {
+	var snap accountSnapshot
+	snap.positions = make([]exchange.Position, 0, len(p.positions))
+	maintenance := make(map[string]float64, len(p.positions))
 
 	for coin, state := range p.positions {
-		qty := state.Qty
+		qty := state.Qty.Float64()
 		mark := p.resolveMarkPriceLocked(coin)
 		notional := math.Abs(qty * mark)
-		unreal := qty * (mark - state.Entry)
-		lev := p.leverageForCoinLocked(coin)
-		margin := notional
-		if lev.Value > 0 {
-			margin = notional / float64(lev.Value)
-		}
+		unrealD := state.Qty.Mul(exchange.DecimalFromFloat(mark).Sub(state.Entry))
+		unreal := unrealD.Float64()
+		margin := p.initialMarginLocked(coin, notional)
+		maintenance[coin] = p.maintenanceMarginLocked(coin, notional)
 
-		totalUnreal += unreal
-		totalNotional += notional
-		totalMargin += margin
+		snap.unrealized += unreal
+		snap.notional += notional
+		snap.initialMargin += margin
+		snap.maintenance += maintenance[coin]
 
 		var entryPtr *string
-		if state.Entry > 0 {
-			entry := formatDecimal(state.Entry)
+		if state.Entry.Sign() > 0 {
+			entry := state.Entry.String()
 			entryPtr = new(string)
 			*entryPtr = entry
 		}
@@ -436,18 +816,24 @@ func (p *Provider) buildAccountSnapshotLocked() ([]exchange.Position, float64, f
 			Coin:           coin,
 			EntryPx:        entryPtr,
 			PositionValue:  formatDecimal(notional),
-			Szi:            formatDecimal(qty),
-			UnrealizedPnl:  formatDecimal(unreal),
+			Szi:            state.Qty.String(),
+			UnrealizedPnl:  unrealD.Round(cashPlaces, exchange.RoundHalfUp).String(),
 			ReturnOnEquity: roe,
-			Leverage:       lev,
+			Leverage:       p.leverageForCoinLocked(coin),
 		}
-		positions = append(positions, pos)
+		snap.positions = append(snap.positions, pos)
 	}
 
-	sort.Slice(positions, func(i, j int) bool {
-		return positions[i].Coin < positions[j].Coin
+	equity := snap.equity(p.cash)
+	for i := range snap.positions {
+		coin := snap.positions[i].Coin
+		state := p.positions[coin]
+		snap.positions[i].LiquidationPx = p.liquidationPxLocked(coin, state.Qty.Float64(), equity, snap.maintenance-maintenance[coin])
+	}
+	sort.Slice(snap.positions, func(i, j int) bool {
+		return snap.positions[i].Coin < snap.positions[j].Coin
 	})
-	return positions, totalUnreal, totalNotional, totalMargin
+	return snap
 }
 
 func (p *Provider) leverageForCoinLocked(coin string) exchange.Leverage {
@@ -459,20 +845,8 @@ func (p *Provider) leverageForCoinLocked(coin string) exchange.Leverage {
 	return exchange.Leverage{Type: "cross", Value: 1}
 }
 
+// formatDecimal renders a float estimate (mark, margin, notional) to at
+// most 8 decimal places.
 func formatDecimal(v float64) string {
-	if math.IsNaN(v) || math.IsInf(v, 0) {
-		return "0"
-	}
-	if math.Abs(v) < 1e-9 {
-		return "0"
-	}
-	s := strconv.FormatFloat(v, 'f', 8, 64)
-	s = strings.TrimRight(s, "0")
-	if strings.HasSuffix(s, ".") {
-		s = strings.TrimSuffix(s, ".")
-	}
-	if s == "-0" {
-		return "0"
-	}
-	return s
+	return exchange.DecimalFromFloat(v).Round(cashPlaces, exchange.RoundHalfUp).String()
 }
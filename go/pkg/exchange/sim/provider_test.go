@@ -4,6 +4,7 @@ import (
 	"context"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"nof0-api/pkg/exchange"
@@ -111,3 +112,153 @@ func parseDecimal(t *testing.T, s string) float64 {
 	assert.NoError(t, err, "string should parse as float")
 	return f
 }
+
+func TestSimProvider_ProtectiveOrders(t *testing.T) {
+	p := New()
+	ctx := context.Background()
+
+	_, err := p.IOCMarket(ctx, "ETH", true, 2, 0.0001, false)
+	assert.NoError(t, err, "open long should not error")
+	assert.NoError(t, p.SetStopLoss(ctx, "ETH", "LONG", 2, 90), "SetStopLoss should not error")
+	assert.NoError(t, p.SetTakeProfit(ctx, "ETH", "LONG", 2, 120), "SetTakeProfit should not error")
+	assert.Error(t, p.SetStopLoss(ctx, "ETH", "LONG", 2, 0), "zero trigger should error")
+
+	assert.NoError(t, p.SetMarkPrice(ctx, "ETH", 95))
+	pos, _ := p.GetPositions(ctx)
+	assert.Len(t, pos, 1, "position should survive above stop")
+
+	assert.NoError(t, p.SetMarkPrice(ctx, "ETH", 89))
+	pos, _ = p.GetPositions(ctx)
+	assert.Len(t, pos, 0, "stop loss should close the position")
+
+	value, err := p.GetAccountValue(ctx)
+	assert.NoError(t, err)
+	assert.Less(t, value, defaultInitialEquity, "stop out should realise a loss")
+
+	caps := p.Capabilities()
+	assert.True(t, caps.ProtectiveOrders, "sim should report protective orders")
+	assert.True(t, caps.MarkPrice, "sim should report mark price support")
+}
+
+func TestSimProvider_GetFills(t *testing.T) {
+	p := New()
+	ctx := context.Background()
+	start := time.UnixMilli(1_700_000_000_000)
+	now := start
+	p.now = func() time.Time { return now }
+
+	assert.NoError(t, p.SetMarkPrice(ctx, "BTC", 100))
+	openResp, err := p.IOCMarket(ctx, "BTC", true, 2, 0.0001, false)
+	assert.NoError(t, err, "open long should not error")
+	now = start.Add(time.Minute)
+	assert.NoError(t, p.SetMarkPrice(ctx, "BTC", 110))
+	closeResp, err := p.ClosePosition(ctx, "BTC")
+	assert.NoError(t, err, "close should not error")
+
+	fills, err := p.GetFills(ctx, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, fills, 2, "open and close should each record a fill") {
+		assert.Equal(t, "Open Long", fills[0].Dir)
+		assert.Equal(t, openResp.Response.Data.Statuses[0].Filled.Oid, fills[0].Oid)
+		assert.Equal(t, "0", fills[0].ClosedPnl)
+		assert.True(t, fills[1].IsClose(), "second fill should close the position")
+		assert.Equal(t, closeResp.Response.Data.Statuses[0].Filled.Oid, fills[1].Oid)
+		assert.Equal(t, "A", fills[1].Side)
+		assert.Equal(t, "2", fills[1].StartPosition)
+		assert.InDelta(t, 2*(110-parseDecimal(t, fills[0].Px)), parseDecimal(t, fills[1].ClosedPnl), 1e-6)
+		assert.NotEqual(t, fills[0].Tid, fills[1].Tid)
+	}
+
+	recent, err := p.GetFills(ctx, start.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Len(t, recent, 1, "since should exclude older fills")
+	assert.True(t, p.Capabilities().FillHistory)
+}
+
+func TestSimProvider_FeesAndFunding(t *testing.T) {
+	ctx := context.Background()
+	start := time.UnixMilli(1_700_000_000_000).Truncate(time.Hour)
+	now := start.Add(10 * time.Minute)
+	p := New(WithInitialEquity(1000), WithFees(1.5, 4.5), WithClock(func() time.Time { return now }))
+
+	assert.NoError(t, p.SetMarkPrice(ctx, "BTC", 100))
+	asset, err := p.GetAssetIndex(ctx, "BTC")
+	assert.NoError(t, err)
+	// A post-only limit rests and pays the maker fee once the mark reaches it;
+	// the IOC close pays the taker fee.
+	_, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "99", Sz: "2",
+		OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Alo"}}})
+	assert.NoError(t, err)
+	assert.NoError(t, p.SetMarkPrice(ctx, "BTC", 99))
+
+	// Longs pay 0.01% of notional per interval while the rate is positive.
+	assert.NoError(t, p.SetFundingRate(ctx, "BTC", 0.0001))
+	now = start.Add(2*time.Hour + time.Minute)
+	state, err := p.GetAccountState(ctx)
+	assert.NoError(t, err)
+	fees := 2 * 99 * 0.00015
+	funding := -2 * 2 * 99 * 0.0001
+	assert.InDelta(t, 1000-fees+funding, parseDecimal(t, state.MarginSummary.AccountValue), 1e-9)
+
+	payments, err := p.GetFundingPayments(ctx, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, payments, 2, "one settlement per elapsed interval") {
+		assert.Equal(t, start.Add(time.Hour).UnixMilli(), payments[0].Timestamp)
+		assert.Equal(t, "BTC", payments[0].Coin)
+		assert.Equal(t, "2", payments[0].Szi)
+		assert.InDelta(t, -0.0198, parseDecimal(t, payments[0].USDC), 1e-9)
+	}
+
+	_, err = p.IOCMarket(ctx, "BTC", false, 2, 0.01, true)
+	assert.NoError(t, err)
+	fills, err := p.GetFills(ctx, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, fills, 2) {
+		assert.False(t, fills[0].Crossed)
+		assert.InDelta(t, fees, parseDecimal(t, fills[0].Fee), 1e-9)
+		assert.True(t, fills[1].Crossed)
+		closeNotional := 2 * parseDecimal(t, fills[1].Px)
+		assert.InDelta(t, closeNotional*0.00045, parseDecimal(t, fills[1].Fee), 1e-9)
+		value, err := p.GetAccountValue(ctx)
+		assert.NoError(t, err)
+		expected := 1000 - fees + funding + parseDecimal(t, fills[1].ClosedPnl) - parseDecimal(t, fills[1].Fee)
+		assert.InDelta(t, expected, value, 1e-6, "cash is net of fees and funding")
+	}
+	assert.True(t, p.Capabilities().FundingHistory)
+	assert.True(t, p.Capabilities().FundingRate)
+}
+
+func TestSimProvider_DecimalSettlement(t *testing.T) {
+	ctx := context.Background()
+	p := New(WithInitialEquity(1000), WithFees(0, 4.5))
+	asset, err := p.GetAssetIndex(ctx, "BTC")
+	assert.NoError(t, err)
+	ioc := func(isBuy bool, px, sz string) {
+		t.Helper()
+		mark, err := strconv.ParseFloat(px, 64)
+		assert.NoError(t, err)
+		assert.NoError(t, p.SetMarkPrice(ctx, "BTC", mark))
+		_, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: isBuy, LimitPx: px, Sz: sz,
+			OrderType: exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Ioc"}}})
+		assert.NoError(t, err)
+	}
+	ioc(true, "100.1", "0.1")
+	ioc(true, "100.2", "0.2")
+	ioc(false, "100.3", "0.3")
+
+	// In float64 these legs leave residue such as 0.039999999999993464 in the
+	// closed PnL; decimals settle to the cent fraction the venue would report.
+	fills, err := p.GetFills(ctx, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, fills, 3) {
+		assert.Equal(t, "0.0045045", fills[0].Fee)
+		assert.Equal(t, "0.009018", fills[1].Fee)
+		assert.Equal(t, "0.0135405", fills[2].Fee)
+		assert.Equal(t, "0.3", fills[2].StartPosition)
+		assert.Equal(t, "0.04", fills[2].ClosedPnl)
+	}
+	assert.Equal(t, "1000.012937", p.State().Cash.String())
+	positions, err := p.GetPositions(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, positions)
+}
@@ -0,0 +1,366 @@
+package sim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// State is a serialisable snapshot of a simulator account: balances,
+// positions, leverage, open orders and the history needed to reconcile
+// fills and funding after a restart.
+type State struct {
+	SavedAt       time.Time                    `json:"saved_at"`
+	InitialEquity float64                      `json:"initial_equity"`
+	Cash          exchange.Decimal             `json:"cash"`
+	Assets        map[string]int               `json:"assets"`             // symbol -> asset id
+	Leverage      map[string]exchange.Leverage `json:"leverage,omitempty"` // by symbol
+	Marks         map[string]float64           `json:"marks,omitempty"`
+	FundingRates  map[string]float64           `json:"funding_rates,omitempty"`
+	LastFunding   time.Time                    `json:"last_funding"`
+	Positions     []PositionState              `json:"positions"`
+	Orders        []OpenOrder                  `json:"orders"`
+	NextOid       int64                        `json:"next_oid"`
+	NextTid       int64                        `json:"next_tid"`
+	Fills         []exchange.Fill              `json:"fills,omitempty"`
+	Funding       []exchange.FundingPayment    `json:"funding,omitempty"`
+}
+
+// Store persists simulator state. Load returns nil without error when
+// nothing has been saved yet.
+type Store interface {
+	Load(ctx context.Context) (*State, error)
+	Save(ctx context.Context, state State) error
+}
+
+// SubStore is implemented by stores that can also persist sub-accounts; Sub
+// returns the store for sub-account id.
+type SubStore interface {
+	Store
+	Sub(id string) Store
+}
+
+// FileStore keeps the state as JSON in a single file, replaced atomically on
+// every save.
+type FileStore struct {
+	path string
+}
+
+// NewFileStore returns a Store backed by the file at path. Missing parent
+// directories are created on the first save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Sub stores sub-account id next to the parent file, e.g. state.json becomes
+// state.<id>.json.
+func (f *FileStore) Sub(id string) Store {
+	ext := filepath.Ext(f.path)
+	safe := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, id)
+	return NewFileStore(strings.TrimSuffix(f.path, ext) + "." + safe + ext)
+}
+
+// Load reads the saved state, if any.
+func (f *FileStore) Load(ctx context.Context) (*State, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sim: read state %s: %w", f.path, err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("sim: decode state %s: %w", f.path, err)
+	}
+	return &state, nil
+}
+
+// Save writes state to a temporary file and renames it over the previous one.
+func (f *FileStore) Save(ctx context.Context, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("sim: encode state: %w", err)
+	}
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("sim: create state dir %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("sim: create temp state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sim: write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("sim: write state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("sim: replace state %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// AttachStore restores the state saved in store, if any, and from then on
+// saves the state after every change. Funding intervals that elapsed while
+// the simulator was down settle at the saved marks and rates on next use.
+func (p *Provider) AttachStore(ctx context.Context, store Store) error {
+	if store == nil {
+		return fmt.Errorf("sim: store is nil")
+	}
+	saved, err := store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	if saved != nil {
+		if err := p.restoreLocked(*saved); err != nil {
+			p.mu.Unlock()
+			return err
+		}
+	}
+	p.store = store
+	p.saveLocked(ctx)
+	p.mu.Unlock()
+
+	sub, ok := store.(SubStore)
+	if !ok || p.parent != nil {
+		return nil
+	}
+	for _, acct := range p.accounts()[1:] {
+		if err := acct.AttachStore(ctx, sub.Sub(acct.accountID)); err != nil {
+			return fmt.Errorf("sim: sub-account %s: %w", acct.accountID, err)
+		}
+	}
+	return nil
+}
+
+// State returns a snapshot of the current account.
+func (p *Provider) State() State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stateLocked()
+}
+
+// Restore replaces the account with a previously captured snapshot.
+func (p *Provider) Restore(ctx context.Context, state State) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.restoreLocked(state); err != nil {
+		return err
+	}
+	p.saveLocked(ctx)
+	return nil
+}
+
+func (p *Provider) stateLocked() State {
+	state := State{
+		SavedAt:       p.now(),
+		InitialEquity: p.initialEquity,
+		Cash:          p.cash,
+		Assets:        make(map[string]int, len(p.assetIndex)),
+		Leverage:      make(map[string]exchange.Leverage, len(p.leverage)),
+		Marks:         make(map[string]float64, len(p.markPx)),
+		FundingRates:  make(map[string]float64, len(p.fundingRate)),
+		LastFunding:   p.lastFunding,
+		Positions:     make([]PositionState, 0, len(p.positions)),
+		Orders:        make([]OpenOrder, 0, len(p.orders)),
+		Fills:         append([]exchange.Fill(nil), p.fills...),
+		Funding:       append([]exchange.FundingPayment(nil), p.funding...),
+	}
+	state.NextOid, state.NextTid = p.ids.peek()
+	for coin, id := range p.assetIndex {
+		state.Assets[coin] = id
+		if lev, ok := p.leverage[id]; ok {
+			state.Leverage[coin] = lev
+		}
+	}
+	for coin, px := range p.markPx {
+		state.Marks[coin] = px
+	}
+	for coin, rate := range p.fundingRate {
+		state.FundingRates[coin] = rate
+	}
+	for _, pos := range p.positions {
+		state.Positions = append(state.Positions, *pos)
+	}
+	sort.Slice(state.Positions, func(i, j int) bool { return state.Positions[i].Coin < state.Positions[j].Coin })
+	for _, o := range p.ordersForCoinLocked("") {
+		order := *o
+		if o.Trigger != nil {
+			trigger := *o.Trigger
+			order.Trigger = &trigger
+		}
+		state.Orders = append(state.Orders, order)
+	}
+	return state
+}
+
+func (p *Provider) restoreLocked(state State) error {
+	assetIndex := make(map[string]int, len(state.Assets))
+	assetSymbol := make(map[int]string, len(state.Assets))
+	nextAssetID := 1
+	for coin, id := range state.Assets {
+		c := canonical(coin)
+		if _, dup := assetSymbol[id]; dup || id <= 0 {
+			return fmt.Errorf("sim: restore: invalid asset id %d for %s", id, coin)
+		}
+		assetIndex[c] = id
+		assetSymbol[id] = c
+		if id >= nextAssetID {
+			nextAssetID = id + 1
+		}
+	}
+	leverage := make(map[int]exchange.Leverage, len(state.Leverage))
+	for coin, lev := range state.Leverage {
+		if id, ok := assetIndex[canonical(coin)]; ok {
+			leverage[id] = lev
+		}
+	}
+	positions := make(map[string]*PositionState, len(state.Positions))
+	for _, pos := range state.Positions {
+		pos := pos
+		pos.Coin = canonical(pos.Coin)
+		if pos.Qty.IsZero() {
+			continue
+		}
+		if _, ok := assetIndex[pos.Coin]; !ok {
+			return fmt.Errorf("sim: restore: position %s has no asset id", pos.Coin)
+		}
+		positions[pos.Coin] = &pos
+	}
+	orders := make(map[int64]*OpenOrder, len(state.Orders))
+	maxOid := int64(0)
+	for _, o := range state.Orders {
+		o := o
+		if id, ok := assetIndex[canonical(o.Coin)]; !ok || id != o.Asset {
+			return fmt.Errorf("sim: restore: order %d has unknown asset %d", o.Oid, o.Asset)
+		}
+		orders[o.Oid] = &o
+		if o.Oid > maxOid {
+			maxOid = o.Oid
+		}
+	}
+
+	p.assetIndex, p.assetSymbol, p.nextAssetID = assetIndex, assetSymbol, nextAssetID
+	p.leverage = leverage
+	p.positions = positions
+	p.orders = orders
+	p.cash = state.Cash
+	if state.InitialEquity > 0 {
+		p.initialEquity = state.InitialEquity
+	}
+	p.markPx = make(map[string]float64, len(state.Marks))
+	for coin, px := range state.Marks {
+		p.markPx[canonical(coin)] = px
+	}
+	p.fundingRate = make(map[string]float64, len(state.FundingRates))
+	for coin, rate := range state.FundingRates {
+		p.fundingRate[canonical(coin)] = rate
+	}
+	if !state.LastFunding.IsZero() {
+		p.lastFunding = state.LastFunding
+	}
+	nextOid, nextTid := state.NextOid, state.NextTid
+	if nextOid <= maxOid {
+		nextOid = maxOid + 1
+	}
+	p.fills = append([]exchange.Fill(nil), state.Fills...)
+	for _, f := range p.fills {
+		if f.Oid >= nextOid {
+			nextOid = f.Oid + 1
+		}
+		if f.Tid >= nextTid {
+			nextTid = f.Tid + 1
+		}
+	}
+	p.ids.raise(nextOid, nextTid)
+	p.funding = append([]exchange.FundingPayment(nil), state.Funding...)
+	return nil
+}
+
+// saveLocked marks the state changed and persists it.
+func (p *Provider) saveLocked(ctx context.Context) {
+	p.dirty = true
+	p.persistLocked(ctx)
+}
+
+// persistLocked snapshots the state after a change and hands it to a
+// background saver, so trading never waits on the store. Snapshots taken
+// while a save is running replace each other and only the latest is
+// written. A failed save is logged and retried after the next change.
+func (p *Provider) persistLocked(ctx context.Context) {
+	if p.store == nil || !p.dirty {
+		return
+	}
+	state := p.stateLocked()
+	p.dirty = false
+	store := p.store
+
+	p.saveMu.Lock()
+	p.savePending = &state
+	if p.saveDone != nil {
+		p.saveMu.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	p.saveDone = done
+	p.saveMu.Unlock()
+	go p.runSaves(context.WithoutCancel(ctx), store, done)
+}
+
+// runSaves writes pending snapshots until none is left.
+func (p *Provider) runSaves(ctx context.Context, store Store, done chan struct{}) {
+	defer close(done)
+	for {
+		p.saveMu.Lock()
+		state := p.savePending
+		p.savePending = nil
+		if state == nil {
+			p.saveDone = nil
+			p.saveMu.Unlock()
+			return
+		}
+		p.saveMu.Unlock()
+		if err := store.Save(ctx, *state); err != nil {
+			logx.WithContext(ctx).Errorf("sim: save state: %v", err)
+			p.mu.Lock()
+			p.dirty = true
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Flush waits until the state of the account and its sub-accounts saved so
+// far has been written to the store.
+func (p *Provider) Flush() {
+	for _, acct := range p.accounts() {
+		for {
+			acct.saveMu.Lock()
+			done := acct.saveDone
+			acct.saveMu.Unlock()
+			if done == nil {
+				break
+			}
+			<-done
+		}
+	}
+}
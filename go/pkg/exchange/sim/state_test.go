@@ -0,0 +1,143 @@
+package sim
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+func TestSimProvider_StateRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sim", "state.json")
+	start := time.UnixMilli(1_700_000_000_000).Truncate(time.Hour)
+	now := start.Add(5 * time.Minute)
+	clock := WithClock(func() time.Time { return now })
+
+	p := New(WithInitialEquity(1000), WithFees(0, 5), clock)
+	require.NoError(t, p.AttachStore(ctx, NewFileStore(path)), "missing file starts fresh")
+	require.NoError(t, p.SetMarkPrice(ctx, "BTC", 100))
+	require.NoError(t, p.SetMarkPrice(ctx, "ETH", 50))
+	btc, err := p.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+	eth, err := p.GetAssetIndex(ctx, "ETH")
+	require.NoError(t, err)
+	require.NoError(t, p.UpdateLeverage(ctx, btc, true, 5))
+	_, err = p.IOCMarket(ctx, "BTC", true, 2, 0.01, false)
+	require.NoError(t, err)
+	_, err = p.PlaceOrder(ctx, exchange.Order{Asset: eth, IsBuy: true, LimitPx: "45", Sz: "1", Cloid: "0xabc"})
+	require.NoError(t, err)
+	require.NoError(t, p.SetFundingRate(ctx, "BTC", 0.0001))
+	before, err := p.GetAccountState(ctx)
+	require.NoError(t, err)
+	fills, err := p.GetFills(ctx, time.Time{})
+	require.NoError(t, err)
+	p.Flush()
+
+	restored := New(WithInitialEquity(1000), clock)
+	require.NoError(t, restored.AttachStore(ctx, NewFileStore(path)))
+	after, err := restored.GetAccountState(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, before.MarginSummary, after.MarginSummary, "cash and marks survive the restart")
+	require.Len(t, after.AssetPositions, 1)
+	assert.Equal(t, "2", after.AssetPositions[0].Szi)
+	assert.Equal(t, 5, after.AssetPositions[0].Leverage.Value)
+
+	orders, err := restored.GetOpenOrders(ctx)
+	require.NoError(t, err)
+	require.Len(t, orders, 1, "resting order survives the restart")
+	assert.Equal(t, "ETH", orders[0].Order.Coin)
+	restoredFills, err := restored.GetFills(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, fills, restoredFills)
+	id, err := restored.GetAssetIndex(ctx, "ETH")
+	require.NoError(t, err)
+	assert.Equal(t, eth, id, "asset ids are stable")
+
+	// New ids never collide with restored ones.
+	resp, err := restored.IOCMarket(ctx, "BTC", false, 1, 0.01, true)
+	require.NoError(t, err)
+	assert.Greater(t, resp.Response.Data.Statuses[0].Filled.Oid, orders[0].Order.Oid)
+
+	// Funding that accrued while down settles on the next read and is saved.
+	now = start.Add(time.Hour + time.Minute)
+	payments, err := restored.GetFundingPayments(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, payments, 1)
+	restored.Flush()
+	saved, err := NewFileStore(path).Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Len(t, saved.Funding, 1)
+	assert.Len(t, saved.Positions, 1)
+	assert.Equal(t, "1", saved.Positions[0].Qty.String())
+}
+
+// blockingStore holds every save until released and records what it saved.
+type blockingStore struct {
+	release chan struct{}
+	mu      sync.Mutex
+	saved   []State
+}
+
+func (b *blockingStore) Load(ctx context.Context) (*State, error) { return nil, nil }
+
+func (b *blockingStore) Save(ctx context.Context, state State) error {
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.saved = append(b.saved, state)
+	return nil
+}
+
+func TestSimProvider_SavesInBackground(t *testing.T) {
+	ctx := context.Background()
+	store := &blockingStore{release: make(chan struct{})}
+	p := New()
+	require.NoError(t, p.AttachStore(ctx, store))
+	for _, px := range []float64{100, 101, 102, 103} {
+		require.NoError(t, p.SetMarkPrice(ctx, "BTC", px), "changes do not wait on a slow store")
+	}
+	close(store.release)
+	p.Flush()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	require.NotEmpty(t, store.saved)
+	assert.LessOrEqual(t, len(store.saved), 2, "changes made during a save are coalesced into one")
+	assert.Equal(t, 103.0, store.saved[len(store.saved)-1].Marks["BTC"], "the latest state is written last")
+}
+
+func TestSimProvider_RestoreRejectsInconsistentState(t *testing.T) {
+	p := New()
+	err := p.Restore(context.Background(), State{
+		Cash:   exchange.DecimalFromInt(100),
+		Assets: map[string]int{"BTC": 1},
+		Orders: []OpenOrder{{Oid: 3, Asset: 2, Coin: "ETH"}},
+	})
+	assert.Error(t, err)
+	value, err := p.GetAccountValue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, defaultInitialEquity, value, "a rejected restore leaves the account untouched")
+}
+
+func TestSimProvider_RestoreLegacyNumericState(t *testing.T) {
+	// State files written before balances were decimals hold JSON numbers.
+	var state State
+	require.NoError(t, json.Unmarshal([]byte(`{"cash":1000.5,"positions":[{"coin":"BTC","qty":0.3,"entry":100.1}],"assets":{"BTC":1}}`), &state))
+	p := New()
+	require.NoError(t, p.Restore(context.Background(), state))
+	assert.Equal(t, "1000.5", p.State().Cash.String())
+	positions, err := p.GetPositions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, "0.3", positions[0].Szi)
+	assert.Equal(t, "100.1", *positions[0].EntryPx)
+}
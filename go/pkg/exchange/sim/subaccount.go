@@ -0,0 +1,197 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"nof0-api/pkg/exchange"
+)
+
+// idCounter hands out order and trade ids. Sub-accounts share their parent's
+// counter so ids stay unique across the whole simulator, as on a venue.
+type idCounter struct {
+	mu  sync.Mutex
+	oid int64
+	tid int64
+}
+
+func (c *idCounter) nextOid() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	oid := c.oid
+	c.oid++
+	return oid
+}
+
+func (c *idCounter) nextTid() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tid := c.tid
+	c.tid++
+	return tid
+}
+
+func (c *idCounter) peek() (oid, tid int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.oid, c.tid
+}
+
+// raise moves the counters forward so they never reissue restored ids.
+func (c *idCounter) raise(oid, tid int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if oid > c.oid {
+		c.oid = oid
+	}
+	if tid > c.tid {
+		c.tid = tid
+	}
+}
+
+// SubAccount returns the isolated account id, creating it on first use with
+// initialEquity in cash (the parent's initial equity when not positive).
+// Sub-accounts keep their own cash, positions, leverage, orders, fills and
+// funding, and share mark prices, funding rates, fees, margin tiers and order
+// ids with the parent: a mark or funding update on any account applies to
+// all of them. When the parent's store implements SubStore, sub-accounts are
+// restored from and saved to it as well.
+func (p *Provider) SubAccount(ctx context.Context, id string, initialEquity float64) (exchange.Provider, error) {
+	if p.parent != nil {
+		return p.parent.SubAccount(ctx, id, initialEquity)
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("sim: sub-account id is required")
+	}
+	p.subMu.Lock()
+	defer p.subMu.Unlock()
+	if child, ok := p.subAccounts[id]; ok {
+		return child, nil
+	}
+
+	p.mu.Lock()
+	child := p.newSubAccountLocked(id, initialEquity)
+	store := p.store
+	p.mu.Unlock()
+	if sub, ok := store.(SubStore); ok {
+		if err := child.AttachStore(ctx, sub.Sub(id)); err != nil {
+			return nil, fmt.Errorf("sim: sub-account %s: %w", id, err)
+		}
+		// Saved marks may be stale; the parent's are current.
+		p.mu.Lock()
+		marks, rates := copyPrices(p.markPx), copyPrices(p.fundingRate)
+		p.mu.Unlock()
+		child.mu.Lock()
+		for coin, px := range marks {
+			child.markPx[coin] = px
+		}
+		for coin, rate := range rates {
+			child.fundingRate[coin] = rate
+		}
+		child.mu.Unlock()
+	}
+	if p.subAccounts == nil {
+		p.subAccounts = make(map[string]*Provider)
+	}
+	p.subAccounts[id] = child
+	return child, nil
+}
+
+// TransferToSubAccount moves amountUSD of cash from the parent account into
+// sub-account id, or from the sub-account back to the parent when amountUSD
+// is negative. The sending account must hold the amount free of margin.
+func (p *Provider) TransferToSubAccount(ctx context.Context, id string, amountUSD float64) error {
+	root := p
+	if p.parent != nil {
+		root = p.parent
+	}
+	if amountUSD == 0 || math.IsNaN(amountUSD) || math.IsInf(amountUSD, 0) {
+		return fmt.Errorf("sim: invalid transfer amount %v", amountUSD)
+	}
+	root.subMu.Lock()
+	child, ok := root.subAccounts[strings.TrimSpace(id)]
+	root.subMu.Unlock()
+	if !ok {
+		return fmt.Errorf("sim: unknown sub-account %q", id)
+	}
+
+	// Always lock the parent before the child so transfers cannot deadlock.
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	child.mu.Lock()
+	defer child.mu.Unlock()
+	from, to, amount := root, child, amountUSD
+	if amountUSD < 0 {
+		from, to, amount = child, root, -amountUSD
+	}
+	from.accrueFundingLocked()
+	snap := from.buildAccountSnapshotLocked()
+	if free := snap.equity(from.cash) - snap.initialMargin; amount > free+1e-9 {
+		return fmt.Errorf("sim: transfer %.2f exceeds withdrawable %.2f", amount, math.Max(0, free))
+	}
+	moved := exchange.DecimalFromFloat(amount)
+	from.cash = from.cash.Sub(moved)
+	to.cash = to.cash.Add(moved)
+	from.saveLocked(ctx)
+	to.saveLocked(ctx)
+	return nil
+}
+
+// AccountID returns the sub-account id, or "" for the parent account.
+func (p *Provider) AccountID() string {
+	return p.accountID
+}
+
+func (p *Provider) newSubAccountLocked(id string, initialEquity float64) *Provider {
+	if initialEquity <= 0 {
+		initialEquity = p.initialEquity
+	}
+	child := New(WithClock(p.now), WithFundingInterval(p.fundingInterval), WithInitialEquity(initialEquity))
+	child.parent = p
+	child.accountID = id
+	child.ids = p.ids
+	child.makerFee, child.takerFee = p.makerFee, p.takerFee
+	child.defaultTiers = p.defaultTiers
+	for coin, tiers := range p.marginTiers {
+		child.marginTiers[coin] = tiers
+	}
+	child.markPx = copyPrices(p.markPx)
+	child.fundingRate = copyPrices(p.fundingRate)
+	child.lastFunding = p.lastFunding
+	return child
+}
+
+// accounts returns the parent account followed by its sub-accounts in id
+// order.
+func (p *Provider) accounts() []*Provider {
+	root := p
+	if p.parent != nil {
+		root = p.parent
+	}
+	root.subMu.Lock()
+	defer root.subMu.Unlock()
+	ids := make([]string, 0, len(root.subAccounts))
+	for id := range root.subAccounts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	out := make([]*Provider, 0, len(ids)+1)
+	out = append(out, root)
+	for _, id := range ids {
+		out = append(out, root.subAccounts[id])
+	}
+	return out
+}
+
+func copyPrices(in map[string]float64) map[string]float64 {
+	out := make(map[string]float64, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
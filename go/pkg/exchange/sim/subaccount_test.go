@@ -0,0 +1,129 @@
+package sim
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+func TestSimProvider_SubAccountsAreIsolated(t *testing.T) {
+	ctx := context.Background()
+	root := New(WithInitialEquity(1000), WithFees(0, 10))
+	require.NoError(t, root.SetMarkPrice(ctx, "BTC", 100))
+
+	a, err := root.SubAccount(ctx, "alice", 300)
+	require.NoError(t, err)
+	b, err := root.SubAccount(ctx, "bob", 0)
+	require.NoError(t, err)
+	again, err := root.SubAccount(ctx, " alice ", 999)
+	require.NoError(t, err)
+	assert.Same(t, a, again, "the same id returns the existing account")
+	_, err = root.SubAccount(ctx, "", 1)
+	assert.Error(t, err)
+
+	value, err := a.GetAccountValue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, value)
+	value, err = b.GetAccountValue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1000.0, value, "equity defaults to the parent's")
+
+	// Orders on one account do not touch another; marks set anywhere are shared.
+	buy, err := a.(exchange.MarketOrderer).IOCMarket(ctx, "BTC", true, 1, 0, false)
+	require.NoError(t, err)
+	positions, err := b.GetPositions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+	positions, err = root.GetPositions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+
+	require.NoError(t, b.(exchange.MarkPriceSetter).SetMarkPrice(ctx, "BTC", 110))
+	state, err := a.GetAccountState(ctx)
+	require.NoError(t, err)
+	require.Len(t, state.AssetPositions, 1)
+	entry := parseDecimal(t, buy.Response.Data.Statuses[0].Filled.AvgPx)
+	assert.InDelta(t, 110-entry, parseDecimal(t, state.AssetPositions[0].UnrealizedPnl), 1e-9, "sub-accounts see marks set on siblings")
+	assert.InDelta(t, 300-entry*0.001+110-entry, parseDecimal(t, state.MarginSummary.AccountValue), 1e-9, "fees come out of the sub-account's own cash")
+
+	sell, err := b.(exchange.MarketOrderer).IOCMarket(ctx, "BTC", false, 1, 0, false)
+	require.NoError(t, err)
+	assert.NotEqual(t, buy.Response.Data.Statuses[0].Filled.Oid, sell.Response.Data.Statuses[0].Filled.Oid, "order ids are unique across accounts")
+	assert.Equal(t, "bob", b.(*Provider).AccountID())
+}
+
+func TestSimProvider_SubAccountState(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "paper.json")
+
+	root := New()
+	require.NoError(t, root.AttachStore(ctx, NewFileStore(path)))
+	require.NoError(t, root.SetMarkPrice(ctx, "ETH", 50))
+	acct, err := root.SubAccount(ctx, "t1", 500)
+	require.NoError(t, err)
+	_, err = acct.(exchange.MarketOrderer).IOCMarket(ctx, "ETH", false, 2, 0, false)
+	require.NoError(t, err)
+	root.Flush()
+
+	saved, err := NewFileStore(filepath.Join(filepath.Dir(path), "paper.t1.json")).Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, saved, "sub-accounts save next to the parent file")
+	assert.Equal(t, 500.0, saved.InitialEquity)
+
+	restoredRoot := New()
+	require.NoError(t, restoredRoot.AttachStore(ctx, NewFileStore(path)))
+	restored, err := restoredRoot.SubAccount(ctx, "t1", 500)
+	require.NoError(t, err)
+	positions, err := restored.GetPositions(ctx)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, "-2", positions[0].Szi)
+	positions, err = restoredRoot.GetPositions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, positions, "the parent account stays flat")
+}
+
+func TestSimProvider_TransferToSubAccount(t *testing.T) {
+	ctx := context.Background()
+	root := New(WithInitialEquity(1000))
+	require.NoError(t, root.SetMarkPrice(ctx, "BTC", 100))
+	sub, err := root.SubAccount(ctx, "alice", 200)
+	require.NoError(t, err)
+
+	require.NoError(t, root.TransferToSubAccount(ctx, "alice", 150))
+	assertEquity := func(p exchange.Provider, want float64) {
+		t.Helper()
+		value, err := p.GetAccountValue(ctx)
+		require.NoError(t, err)
+		assert.InDelta(t, want, value, 1e-9)
+	}
+	assertEquity(root, 850)
+	assertEquity(sub, 350)
+
+	// 2x leveraged 3 BTC locks 150 of the sub-account's 350 as margin.
+	require.NoError(t, sub.UpdateLeverage(ctx, mustAssetIndex(t, sub, "BTC"), true, 2))
+	_, err = sub.(exchange.MarketOrderer).IOCMarket(ctx, "BTC", true, 3, 0, false)
+	require.NoError(t, err)
+	assert.ErrorContains(t, sub.(*Provider).TransferToSubAccount(ctx, "alice", -250), "exceeds withdrawable")
+	before, err := sub.GetAccountValue(ctx)
+	require.NoError(t, err)
+	require.NoError(t, sub.(*Provider).TransferToSubAccount(ctx, "alice", -190), "either side can move funds")
+	assertEquity(root, 1040)
+	assertEquity(sub, before-190)
+
+	assert.Error(t, root.TransferToSubAccount(ctx, "bob", 10))
+	assert.Error(t, root.TransferToSubAccount(ctx, "alice", 0))
+	assert.True(t, exchange.DetectCapabilities(root).FundTransfers)
+}
+
+func mustAssetIndex(t *testing.T, p exchange.Provider, coin string) int {
+	t.Helper()
+	idx, err := p.GetAssetIndex(context.Background(), coin)
+	require.NoError(t, err)
+	return idx
+}
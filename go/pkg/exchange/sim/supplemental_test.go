@@ -110,10 +110,30 @@ func TestSimProvider_CancelOrder(t *testing.T) {
 	p := New()
 	ctx := context.Background()
 
-	// Test that cancel order always succeeds (no-op implementation)
-	t.Run("cancel_order", func(t *testing.T) {
+	// Unknown order ids are rejected like on the venue
+	t.Run("unknown_order", func(t *testing.T) {
 		err := p.CancelOrder(ctx, 1, 12345)
+		assert.Error(t, err)
+	})
+
+	// Resting orders can be cancelled by oid or cloid exactly once
+	t.Run("cancel_resting", func(t *testing.T) {
+		asset, err := p.GetAssetIndex(ctx, "BTC")
+		assert.NoError(t, err)
+		assert.NoError(t, p.SetMarkPrice(ctx, "BTC", 100))
+		gtc := exchange.OrderType{Limit: &exchange.LimitOrderType{TIF: "Gtc"}}
+		resp, err := p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: true, LimitPx: "90", Sz: "1", OrderType: gtc})
+		assert.NoError(t, err)
+		oid := resp.Response.Data.Statuses[0].Resting.Oid
+		_, err = p.PlaceOrder(ctx, exchange.Order{Asset: asset, IsBuy: false, LimitPx: "110", Sz: "1", OrderType: gtc, Cloid: "0xabc"})
+		assert.NoError(t, err)
+
+		assert.NoError(t, p.CancelOrder(ctx, asset, oid))
+		assert.Error(t, p.CancelOrder(ctx, asset, oid), "second cancel should fail")
+		assert.NoError(t, p.CancelByCloid(ctx, asset, "0xabc"))
+		orders, err := p.GetOpenOrders(ctx)
 		assert.NoError(t, err)
+		assert.Empty(t, orders)
 	})
 }
 
@@ -3,6 +3,8 @@ package exchange
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Core trading domain types shared across exchange implementations.
@@ -74,6 +76,28 @@ type Position struct {
 	LiquidationPx  *string  `json:"liquidationPx,omitempty"`
 }
 
+// UnmarshalJSON accepts both a bare position and Hyperliquid's
+// clearinghouseState entry, which wraps it as {"type":"oneWay","position":{...}}.
+func (p *Position) UnmarshalJSON(data []byte) error {
+	type alias Position
+	var wrapped struct {
+		Position *alias `json:"position"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return err
+	}
+	if wrapped.Position != nil {
+		*p = Position(*wrapped.Position)
+		return nil
+	}
+	var flat alias
+	if err := json.Unmarshal(data, &flat); err != nil {
+		return err
+	}
+	*p = Position(flat)
+	return nil
+}
+
 // Leverage contains leverage settings for an instrument.
 type Leverage struct {
 	Type  string `json:"type"`  // "cross" or "isolated".
@@ -85,6 +109,16 @@ type AccountState struct {
 	MarginSummary      MarginSummary      `json:"marginSummary"`
 	CrossMarginSummary CrossMarginSummary `json:"crossMarginSummary"`
 	AssetPositions     []Position         `json:"assetPositions"`
+	SpotBalances       []SpotBalance      `json:"spotBalances,omitempty"` // Spot token holdings, when the venue has a spot ledger.
+}
+
+// SpotBalance is the holding of a single spot token.
+type SpotBalance struct {
+	Coin     string `json:"coin"`
+	Token    int    `json:"token"`    // Venue token index.
+	Total    string `json:"total"`    // Total balance, including Hold.
+	Hold     string `json:"hold"`     // Balance reserved by open orders.
+	EntryNtl string `json:"entryNtl"` // Entry notional in USD.
 }
 
 // MarginSummary consolidates margin metrics.
@@ -124,15 +158,84 @@ type OrderInfo struct {
 
 // Fill describes a match executed against an order.
 type Fill struct {
+	Coin      string `json:"coin,omitempty"`
+	Side      string `json:"side,omitempty"` // "B" (buy) or "A" (sell).
+	Px        string `json:"px,omitempty"`
 	AvgPx     string `json:"avgPx"`
 	TotalSz   string `json:"totalSz"`
 	LimitPx   string `json:"limitPx"`
 	Sz        string `json:"sz"`
 	Oid       int64  `json:"oid"`
+	Cloid     string `json:"cloid,omitempty"`
 	Crossed   bool   `json:"crossed"`
 	Fee       string `json:"fee"`
+	FeeToken  string `json:"feeToken,omitempty"`
 	Tid       int64  `json:"tid"`
 	Timestamp int64  `json:"timestamp,omitempty"`
+	// Dir describes the position effect, e.g. "Open Long" or "Close Short".
+	Dir           string `json:"dir,omitempty"`
+	StartPosition string `json:"startPosition,omitempty"`
+	ClosedPnl     string `json:"closedPnl,omitempty"`
+	Hash          string `json:"hash,omitempty"`
+}
+
+// IsClose reports whether the fill reduced an existing position, including
+// a flip ("Long > Short") that closes one side and opens the other.
+func (f Fill) IsClose() bool {
+	dir := strings.ToLower(strings.TrimSpace(f.Dir))
+	return strings.HasPrefix(dir, "close") || strings.Contains(dir, ">")
+}
+
+// FundingPayment is a perpetual funding cash flow for one position. USDC is
+// positive when the account received funding and negative when it paid.
+type FundingPayment struct {
+	Coin        string `json:"coin"`
+	USDC        string `json:"usdc"`
+	Szi         string `json:"szi"` // signed position size the payment applied to
+	FundingRate string `json:"fundingRate"`
+	Timestamp   int64  `json:"time"`
+	Hash        string `json:"hash,omitempty"`
+}
+
+// TWAPOrder requests a venue-side TWAP execution: Sz is worked in slices
+// spread evenly over Duration, with slice timing jittered when Randomize is
+// set.
+type TWAPOrder struct {
+	Coin       string        `json:"coin"`
+	IsBuy      bool          `json:"isBuy"`
+	Sz         string        `json:"sz"`
+	ReduceOnly bool          `json:"reduceOnly"`
+	Duration   time.Duration `json:"duration"`
+	Randomize  bool          `json:"randomize"`
+}
+
+// TWAP lifecycle states reported in TWAPStatus.Status.
+const (
+	TWAPStatusActive     = "activated"
+	TWAPStatusFinished   = "finished"
+	TWAPStatusTerminated = "terminated"
+	TWAPStatusError      = "error"
+)
+
+// TWAPStatus reports the progress of a TWAP execution.
+type TWAPStatus struct {
+	TwapID      int64  `json:"twapId"`
+	Coin        string `json:"coin"`
+	Side        string `json:"side"` // "B" buy, "A" sell
+	Sz          string `json:"sz"`
+	ExecutedSz  string `json:"executedSz"`
+	ExecutedNtl string `json:"executedNtl"`
+	Minutes     int    `json:"minutes"`
+	Randomize   bool   `json:"randomize"`
+	ReduceOnly  bool   `json:"reduceOnly"`
+	Status      string `json:"status"`
+	Description string `json:"description,omitempty"` // error detail
+	Timestamp   int64  `json:"timestamp"`             // start time (ms)
+}
+
+// Active reports whether the TWAP is still placing slices.
+func (s TWAPStatus) Active() bool {
+	return s.Status == TWAPStatusActive
 }
 
 // OrderResponse captures the standard exchange response after an order submission.
@@ -0,0 +1,275 @@
+// Package vcr records the HTTP traffic of an exchange provider into a go-vcr
+// cassette and replays it, so provider and manager tests can run offline
+// against real venue payloads.
+//
+// Signed requests differ on every run (nonces, timestamps, signatures), so
+// request bodies and query strings are normalised before they are saved and
+// again before a live request is matched: the values of volatile keys are
+// replaced by a placeholder. Saved cassettes therefore carry no signatures,
+// nonces or API keys.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dnaeon/go-vcr/cassette"
+)
+
+// Mode selects whether a Recorder captures live traffic or serves a cassette.
+type Mode string
+
+const (
+	// ModeRecord forwards every request and appends it to the cassette,
+	// replacing any previous recording.
+	ModeRecord Mode = "record"
+	// ModeReplay serves recorded responses and never touches the network.
+	ModeReplay Mode = "replay"
+)
+
+// Placeholder replaces the values of normalised keys.
+const Placeholder = "<normalised>"
+
+// DefaultKeys are the JSON body keys and query parameters normalised by
+// default: Hyperliquid's nonce, signature, expiry, client order id ("c"),
+// user address and time-ranged query bounds, and Binance's signed timestamp.
+var DefaultKeys = []string{
+	"nonce", "signature", "expiresAfter", "c", "user", "time", "startTime", "endTime",
+	"timestamp",
+}
+
+// sensitiveHeaders are never written to a cassette.
+var sensitiveHeaders = []string{"Authorization", "X-Mbx-Apikey"}
+
+// Recorder is an http.RoundTripper that records to or replays from a
+// cassette. Identical requests are served in recording order.
+type Recorder struct {
+	mode     Mode
+	real     http.RoundTripper
+	keys     map[string]bool
+	cassette *cassette.Cassette
+
+	mu   sync.Mutex
+	used []bool // replay: interactions already served
+}
+
+// Option customises a Recorder.
+type Option func(*Recorder)
+
+// WithTransport sets the transport live requests go through in record mode
+// (default http.DefaultTransport).
+func WithTransport(rt http.RoundTripper) Option {
+	return func(r *Recorder) {
+		if rt != nil {
+			r.real = rt
+		}
+	}
+}
+
+// WithNormalisedKeys normalises additional body keys and query parameters.
+func WithNormalisedKeys(keys ...string) Option {
+	return func(r *Recorder) {
+		for _, key := range keys {
+			r.keys[key] = true
+		}
+	}
+}
+
+// New opens the cassette at path (a .yaml file) in the given mode. Replay
+// requires the cassette to exist; record starts an empty one and writes it
+// after every interaction, so there is nothing to flush.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	name := strings.TrimSuffix(strings.TrimSpace(path), ".yaml")
+	if name == "" {
+		return nil, fmt.Errorf("vcr: cassette path is required")
+	}
+	r := &Recorder{
+		mode: mode,
+		real: http.DefaultTransport,
+		keys: make(map[string]bool, len(DefaultKeys)),
+	}
+	for _, key := range DefaultKeys {
+		r.keys[key] = true
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	switch mode {
+	case ModeRecord:
+		r.cassette = cassette.New(name)
+	case ModeReplay:
+		c, err := cassette.Load(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("vcr: cassette %s.yaml not found; record it first", name)
+			}
+			return nil, fmt.Errorf("vcr: load cassette %s.yaml: %w", name, err)
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	default:
+		return nil, fmt.Errorf("vcr: unsupported mode %q", mode)
+	}
+	return r, nil
+}
+
+// Mode reports whether the recorder records or replays.
+func (r *Recorder) Mode() Mode { return r.mode }
+
+// HTTPClient returns a client sending requests through the recorder.
+func (r *Recorder) HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: r, Timeout: timeout}
+}
+
+// Remaining returns how many recorded interactions have not been replayed.
+func (r *Recorder) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	reqURL, reqBody := r.normalise(req.URL, body)
+	if r.mode == ModeReplay {
+		return r.replay(req, reqURL, reqBody)
+	}
+
+	resp, err := r.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("vcr: read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	headers := req.Header.Clone()
+	for _, h := range sensitiveHeaders {
+		headers.Del(h)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.AddInteraction(&cassette.Interaction{
+		Request: cassette.Request{
+			Method:  req.Method,
+			URL:     reqURL,
+			Headers: headers,
+			Body:    reqBody,
+		},
+		Response: cassette.Response{
+			Status:  resp.Status,
+			Code:    resp.StatusCode,
+			Headers: resp.Header.Clone(),
+			Body:    string(respBody),
+		},
+	})
+	if err := r.cassette.Save(); err != nil {
+		return nil, fmt.Errorf("vcr: save cassette %s: %w", r.cassette.File, err)
+	}
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, reqURL, reqBody string) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || interaction.Request.Method != req.Method ||
+			interaction.Request.URL != reqURL || interaction.Request.Body != reqBody {
+			continue
+		}
+		r.used[i] = true
+		resp := interaction.Response
+		return &http.Response{
+			Status:        resp.Status,
+			StatusCode:    resp.Code,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        resp.Headers.Clone(),
+			Body:          io.NopCloser(strings.NewReader(resp.Body)),
+			ContentLength: int64(len(resp.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("vcr: no recorded interaction in %s for %s %s %s", r.cassette.File, req.Method, reqURL, reqBody)
+}
+
+// normalise returns the URL and body with volatile values replaced.
+func (r *Recorder) normalise(u *url.URL, body []byte) (string, string) {
+	normalisedURL := *u
+	if query := u.Query(); len(query) > 0 {
+		for key := range query {
+			if r.keys[key] {
+				query.Set(key, Placeholder)
+			}
+		}
+		normalisedURL.RawQuery = query.Encode()
+	}
+	if len(body) == 0 {
+		return normalisedURL.String(), ""
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return normalisedURL.String(), string(body)
+	}
+	out, err := json.Marshal(r.normaliseValue(doc))
+	if err != nil {
+		return normalisedURL.String(), string(body)
+	}
+	return normalisedURL.String(), string(out)
+}
+
+func (r *Recorder) normaliseValue(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, inner := range value {
+			if r.keys[key] {
+				value[key] = Placeholder
+				continue
+			}
+			value[key] = r.normaliseValue(inner)
+		}
+	case []any:
+		for i, inner := range value {
+			value[i] = r.normaliseValue(inner)
+		}
+	}
+	return v
+}
+
+// readBody drains req.Body and puts it back for the real transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("vcr: read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,85 @@
+package vcr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordThenReplayNormalisesSignedRequests(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(body), `"order"`) {
+			_, _ = io.WriteString(w, `{"status":"ok","response":{"type":"order","data":{"statuses":[{"resting":{"oid":`+strconv.Itoa(int(n))+`}}]}}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"assetPositions":[]}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "exchange.yaml")
+	rec, err := New(path, ModeRecord)
+	require.NoError(t, err)
+	client := rec.HTTPClient(0)
+
+	post := func(c *http.Client, url, body string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("X-MBX-APIKEY", "secret-key")
+		return c.Do(req)
+	}
+	order := func(nonce, sig string) string {
+		return `{"action":{"type":"order","orders":[{"a":0,"b":true,"p":"100","s":"1","c":"0x` + nonce + `"}]},"nonce":` + nonce + `,"signature":{"r":"` + sig + `","s":"` + sig + `","v":27}}`
+	}
+	for _, body := range []string{order("1", "0xaa"), order("2", "0xbb"), `{"type":"clearinghouseState","user":"0x1111111111111111111111111111111111111111"}`} {
+		resp, err := post(client, server.URL+"/exchange?timestamp=1", body)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	require.EqualValues(t, 3, hits.Load())
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(saved), "0xaa", "signatures are not saved")
+	assert.NotContains(t, string(saved), "secret-key", "API keys are not saved")
+	assert.NotContains(t, string(saved), "0x1111", "account addresses are not saved")
+
+	replay, err := New(path, ModeReplay)
+	require.NoError(t, err)
+	client = replay.HTTPClient(0)
+	for _, want := range []string{`"oid":1`, `"oid":2`} {
+		resp, err := post(client, server.URL+"/exchange?timestamp=99", order("7", "0xcc"))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Contains(t, string(body), want, "identical requests replay in recording order")
+	}
+	resp, err := post(client, server.URL+"/exchange?timestamp=5", `{"type":"clearinghouseState","user":"0x2222222222222222222222222222222222222222"}`)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 3, hits.Load(), "replay never reaches the server")
+	assert.Zero(t, replay.Remaining())
+
+	_, err = post(client, server.URL+"/exchange", order("8", "0xdd"))
+	assert.ErrorContains(t, err, "no recorded interaction")
+}
+
+func TestNewRejectsMissingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.yaml"), ModeReplay)
+	assert.ErrorContains(t, err, "not found")
+	_, err = New("x.yaml", Mode("rewind"))
+	assert.ErrorContains(t, err, "unsupported mode")
+}
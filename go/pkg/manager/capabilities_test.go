@@ -0,0 +1,33 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"nof0-api/pkg/exchange"
+)
+
+func TestCheckProviderCapabilities(t *testing.T) {
+	cfg := TraderConfig{ID: "t1", ExchangeProvider: "venue", OrderStyle: OrderStyleMarketIOC}
+	cfg.RiskParams.StopLossEnabled = true
+
+	err := checkProviderCapabilities(cfg, exchange.Capabilities{})
+	assert.Error(t, err, "missing capabilities should be rejected")
+	assert.Contains(t, err.Error(), exchange.CapabilityMarketOrders)
+	assert.Contains(t, err.Error(), exchange.CapabilityProtectiveOrders)
+
+	err = checkProviderCapabilities(cfg, exchange.Capabilities{MarketOrders: true})
+	assert.Error(t, err, "stop_loss_enabled requires protective orders")
+	assert.NotContains(t, err.Error(), exchange.CapabilityMarketOrders+" (")
+
+	assert.NoError(t, checkProviderCapabilities(cfg, exchange.Capabilities{MarketOrders: true, ProtectiveOrders: true}))
+
+	limit := TraderConfig{ID: "t2", OrderStyle: OrderStyleLimitIOC}
+	assert.NoError(t, checkProviderCapabilities(limit, exchange.Capabilities{}), "limit_ioc without SL/TP needs no extensions")
+
+	twap := TraderConfig{ID: "t3", OrderStyle: OrderStyleTWAP}
+	err = checkProviderCapabilities(twap, exchange.Capabilities{MarketOrders: true})
+	assert.ErrorContains(t, err, exchange.CapabilityTWAPOrders)
+	assert.NoError(t, checkProviderCapabilities(twap, exchange.Capabilities{TWAPOrders: true}))
+}
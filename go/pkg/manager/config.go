@@ -21,8 +21,29 @@ type OrderStyle string
 const (
 	OrderStyleLimitIOC  OrderStyle = "limit_ioc"
 	OrderStyleMarketIOC OrderStyle = "market_ioc"
+	// OrderStyleTWAP works opening orders through the venue's native TWAP.
+	OrderStyleTWAP OrderStyle = "twap"
 
 	defaultMarketIOCSlippageBps = 50.0 // 0.50% slippage
+	defaultTWAPDuration         = "30m"
+	minTWAPDuration             = 5 * time.Minute
+	maxTWAPDuration             = 24 * time.Hour
+	// decisionTimeout bounds one executor decision, LLM call included.
+	decisionTimeout = 60 * time.Second
+	// deadManSwitchMargin is how far the dead-man's switch timeout must
+	// exceed decisionTimeout, so a slow decision cycle cannot let it fire.
+	deadManSwitchMargin = 30 * time.Second
+)
+
+// MarginMode selects how a trader's positions are margined.
+type MarginMode string
+
+const (
+	// MarginModeCross shares the account's free collateral across positions.
+	MarginModeCross MarginMode = "cross"
+	// MarginModeIsolated confines each position's margin to what was posted
+	// for it. Assets the venue marks onlyIsolated always use it.
+	MarginModeIsolated MarginMode = "isolated"
 )
 
 // Config defines the overall manager configuration schema.
@@ -41,8 +62,18 @@ type ManagerConfig struct {
 	RebalanceInterval   time.Duration `yaml:"-"`
 	StateStorageBackend string        `yaml:"state_storage_backend"`
 	StateStoragePath    string        `yaml:"state_storage_path"`
-
-	RebalanceIntervalRaw string `yaml:"rebalance_interval"`
+	// DeadManSwitchTimeout cancels every resting order on exchanges that
+	// support scheduled cancels if the trading loop stops refreshing the
+	// schedule for this long. Zero disables the switch.
+	DeadManSwitchTimeout time.Duration `yaml:"-"`
+	// CredentialExpiryWarning logs a warning once an exchange provider's
+	// signing credential, e.g. a Hyperliquid agent wallet approval, is due
+	// to lapse within this window. Zero disables the check.
+	CredentialExpiryWarning time.Duration `yaml:"-"`
+
+	RebalanceIntervalRaw       string `yaml:"rebalance_interval"`
+	DeadManSwitchTimeoutRaw    string `yaml:"dead_man_switch_timeout"`
+	CredentialExpiryWarningRaw string `yaml:"credential_expiry_warning"`
 }
 
 type TraderConfig struct {
@@ -52,6 +83,9 @@ type TraderConfig struct {
 	MarketProvider       string         `yaml:"market_provider"`
 	OrderStyle           OrderStyle     `yaml:"order_style"`
 	MarketIOCSlippageBps float64        `yaml:"market_ioc_slippage_bps"`
+	TWAPDuration         time.Duration  `yaml:"-"`
+	TWAPRandomize        bool           `yaml:"twap_randomize"`
+	MarginMode           MarginMode     `yaml:"margin_mode"`
 	PromptTemplate       string         `yaml:"prompt_template"`
 	ExecutorTemplate     string         `yaml:"executor_prompt_template"`
 	Model                string         `yaml:"model"`
@@ -64,6 +98,7 @@ type TraderConfig struct {
 	JournalDir           string         `yaml:"journal_dir"`
 
 	DecisionIntervalRaw string `yaml:"decision_interval"`
+	TWAPDurationRaw     string `yaml:"twap_duration"`
 }
 
 // ExecGuards defines optional hard guards applied at execution/validation time.
@@ -179,6 +214,12 @@ func (c *Config) applyDefaults() {
 		if c.Traders[i].MarketIOCSlippageBps <= 0 {
 			c.Traders[i].MarketIOCSlippageBps = defaultMarketIOCSlippageBps
 		}
+		if strings.TrimSpace(string(c.Traders[i].MarginMode)) == "" {
+			c.Traders[i].MarginMode = MarginModeCross
+		}
+		if strings.TrimSpace(c.Traders[i].TWAPDurationRaw) == "" {
+			c.Traders[i].TWAPDurationRaw = defaultTWAPDuration
+		}
 	}
 	if strings.TrimSpace(c.Monitoring.UpdateIntervalRaw) == "" {
 		c.Monitoring.UpdateIntervalRaw = "30s"
@@ -191,12 +232,31 @@ func (c *Config) parseDurations() error {
 	if err != nil {
 		return err
 	}
+	if raw := strings.TrimSpace(c.Manager.DeadManSwitchTimeoutRaw); raw != "" {
+		c.Manager.DeadManSwitchTimeout, err = time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("manager config: invalid manager.dead_man_switch_timeout %q: %w", raw, err)
+		}
+	}
+	if raw := strings.TrimSpace(c.Manager.CredentialExpiryWarningRaw); raw != "" {
+		c.Manager.CredentialExpiryWarning, err = time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("manager config: invalid manager.credential_expiry_warning %q: %w", raw, err)
+		}
+		if c.Manager.CredentialExpiryWarning < 0 {
+			return fmt.Errorf("manager config: manager.credential_expiry_warning must not be negative")
+		}
+	}
 	for i := range c.Traders {
 		d, err := parsePositiveDuration(fmt.Sprintf("traders[%d].decision_interval", i), c.Traders[i].DecisionIntervalRaw)
 		if err != nil {
 			return err
 		}
 		c.Traders[i].DecisionInterval = d
+		c.Traders[i].TWAPDuration, err = parsePositiveDuration(fmt.Sprintf("traders[%d].twap_duration", i), c.Traders[i].TWAPDurationRaw)
+		if err != nil {
+			return err
+		}
 		// ExecGuards cooldown is optional; parse if provided and non-empty.
 		raw := strings.TrimSpace(c.Traders[i].ExecGuards.CooldownAfterCloseRaw)
 		if raw != "" {
@@ -232,6 +292,7 @@ func (c *Config) expandFields() {
 		c.Traders[i].ExchangeProvider = strings.TrimSpace(c.Traders[i].ExchangeProvider)
 		c.Traders[i].MarketProvider = strings.TrimSpace(c.Traders[i].MarketProvider)
 		c.Traders[i].OrderStyle = OrderStyle(strings.ToLower(strings.TrimSpace(string(c.Traders[i].OrderStyle))))
+		c.Traders[i].MarginMode = MarginMode(strings.ToLower(strings.TrimSpace(string(c.Traders[i].MarginMode))))
 		c.Traders[i].PromptTemplate = c.resolvePath(c.Traders[i].PromptTemplate)
 		c.Traders[i].ExecutorTemplate = c.resolvePath(c.Traders[i].ExecutorTemplate)
 		c.Traders[i].JournalDir = c.resolvePath(c.Traders[i].JournalDir)
@@ -262,6 +323,10 @@ func (c *Config) Validate() error {
 	if strings.TrimSpace(c.Manager.StateStoragePath) == "" {
 		return errors.New("manager config: manager.state_storage_path is required")
 	}
+	if c.Manager.DeadManSwitchTimeout != 0 && c.Manager.DeadManSwitchTimeout < decisionTimeout+deadManSwitchMargin {
+		return fmt.Errorf("manager config: manager.dead_man_switch_timeout must be 0 (disabled) or at least %s (decision timeout %s plus %s)",
+			decisionTimeout+deadManSwitchMargin, decisionTimeout, deadManSwitchMargin)
+	}
 	if len(c.Traders) == 0 {
 		return errors.New("manager config: at least one trader must be defined")
 	}
@@ -308,6 +373,11 @@ func (c *Config) Validate() error {
 		if err := trader.validateOrderStyle(i); err != nil {
 			return err
 		}
+		switch trader.MarginMode {
+		case "", MarginModeCross, MarginModeIsolated:
+		default:
+			return fmt.Errorf("manager config: traders[%d].margin_mode %q unsupported", i, trader.MarginMode)
+		}
 		// ExecGuards validation (optional; non-negative checks)
 		if trader.ExecGuards.MaxNewPositionsPerCycle < 0 {
 			return fmt.Errorf("manager config: traders[%d].exec_guards.max_new_positions_per_cycle cannot be negative", i)
@@ -334,13 +404,16 @@ func (c *Config) Validate() error {
 
 func (t TraderConfig) validateOrderStyle(index int) error {
 	switch t.OrderStyle {
-	case OrderStyleLimitIOC, OrderStyleMarketIOC:
+	case OrderStyleLimitIOC, OrderStyleMarketIOC, OrderStyleTWAP:
 	default:
 		return fmt.Errorf("manager config: traders[%d].order_style %q unsupported", index, t.OrderStyle)
 	}
 	if t.OrderStyle == OrderStyleMarketIOC && t.MarketIOCSlippageBps <= 0 {
 		return fmt.Errorf("manager config: traders[%d].market_ioc_slippage_bps must be positive", index)
 	}
+	if t.OrderStyle == OrderStyleTWAP && (t.TWAPDuration < minTWAPDuration || t.TWAPDuration > maxTWAPDuration) {
+		return fmt.Errorf("manager config: traders[%d].twap_duration %s must be between %s and %s", index, t.TWAPDuration, minTWAPDuration, maxTWAPDuration)
+	}
 	return nil
 }
 
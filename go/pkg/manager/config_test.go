@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,6 +35,7 @@ manager:
   reserve_equity_pct: 10
   allocation_strategy: performance_based
   rebalance_interval: 2h
+  credential_expiry_warning: 48h
   state_storage_backend: file
   state_storage_path: ./state/manager.json
 
@@ -92,11 +94,14 @@ monitoring:
 	assert.NotNil(t, cfg, "config should not be nil")
 
 	assert.Equal(t, "2h0m0s", cfg.Manager.RebalanceInterval.String(), "RebalanceInterval should be parsed correctly")
+	assert.Equal(t, 48*time.Hour, cfg.Manager.CredentialExpiryWarning, "CredentialExpiryWarning should be parsed correctly")
 	assert.Equal(t, "4m0s", cfg.Traders[0].DecisionInterval.String(), "DecisionInterval should be parsed correctly")
 	assert.Equal(t, "hyperliquid_primary", cfg.Traders[0].ExchangeProvider, "ExchangeProvider should be trimmed")
 	assert.Equal(t, "hl_market", cfg.Traders[0].MarketProvider, "MarketProvider should be trimmed")
 	assert.Equal(t, OrderStyleLimitIOC, cfg.Traders[0].OrderStyle, "OrderStyle should default to limit_ioc")
 	assert.Equal(t, defaultMarketIOCSlippageBps, cfg.Traders[0].MarketIOCSlippageBps, "MarketIOCSlippageBps should default")
+	assert.Equal(t, 30*time.Minute, cfg.Traders[0].TWAPDuration, "TWAPDuration should default")
+	assert.Equal(t, MarginModeCross, cfg.Traders[0].MarginMode, "MarginMode should default to cross")
 
 	wantStatePath := filepath.Join(dir, "state/manager.json")
 	assert.Equal(t, wantStatePath, cfg.Manager.StateStoragePath, "StateStoragePath should match expected path")
@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// credentialCheckInterval spaces out expiry lookups per provider; approvals
+// are granted for days, so hourly checks leave ample warning.
+const credentialCheckInterval = time.Hour
+
+// credentialTick is how often runCredentialExpiryCheck looks for providers
+// whose check is due.
+const credentialTick = time.Minute
+
+// runCredentialExpiryCheck checks signing credentials from its own ticker,
+// so a slow expiry lookup never stalls the trading loop. It returns when
+// ctx is done or the manager stops.
+func (m *Manager) runCredentialExpiryCheck(ctx context.Context) {
+	if m == nil || m.config == nil || m.config.Manager.CredentialExpiryWarning <= 0 {
+		return
+	}
+	m.checkCredentialExpiry(ctx)
+	ticker := time.NewTicker(credentialTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.checkCredentialExpiry(ctx)
+		}
+	}
+}
+
+// checkCredentialExpiry warns when an exchange provider's signing credential
+// lapses within manager.credential_expiry_warning, so an agent wallet can be
+// rotated (cmd/agent) before orders start failing. Each provider is checked
+// at most once per credentialCheckInterval.
+func (m *Manager) checkCredentialExpiry(ctx context.Context) {
+	if m == nil || m.config == nil || m.config.Manager.CredentialExpiryWarning <= 0 {
+		return
+	}
+	window := m.config.Manager.CredentialExpiryWarning
+
+	m.mu.RLock()
+	names := make([]string, 0, len(m.exchangeProviders))
+	expirers := make(map[string]exchange.CredentialExpirer, len(m.exchangeProviders))
+	for name, provider := range m.exchangeProviders {
+		if expirer, ok := provider.(exchange.CredentialExpirer); ok {
+			names = append(names, name)
+			expirers[name] = expirer
+		}
+	}
+	m.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		now := time.Now()
+		m.credentialMu.Lock()
+		due := now.Sub(m.credentialChecked[name]) >= credentialCheckInterval
+		if due {
+			m.credentialChecked[name] = now
+		}
+		m.credentialMu.Unlock()
+		if !due {
+			continue
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		expiry, err := expirers[name].CredentialExpiry(reqCtx)
+		cancel()
+		switch {
+		case err != nil:
+			logx.WithContext(ctx).Errorf("manager: credential expiry check failed exchange=%s err=%v", name, err)
+		case expiry.IsZero():
+		case !expiry.After(now):
+			logx.WithContext(ctx).Errorf("manager: signing credential expired exchange=%s expired_at=%s", name, expiry.UTC().Format(time.RFC3339))
+		case expiry.Sub(now) <= window:
+			logx.WithContext(ctx).Errorf("manager: signing credential expires soon exchange=%s expires_at=%s remaining=%s; rotate it with cmd/agent", name, expiry.UTC().Format(time.RFC3339), expiry.Sub(now).Round(time.Minute))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+)
+
+// expiringSim reports a signing credential expiry on top of the simulator.
+type expiringSim struct {
+	*sim.Provider
+
+	mu     sync.Mutex
+	expiry time.Time
+	err    error
+	calls  int
+}
+
+func (s *expiringSim) CredentialExpiry(ctx context.Context) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.expiry, s.err
+}
+
+func TestCheckCredentialExpiry(t *testing.T) {
+	venue := &expiringSim{Provider: sim.New(), expiry: time.Now().Add(24 * time.Hour)}
+	cfg := &Config{Manager: ManagerConfig{CredentialExpiryWarning: 72 * time.Hour}}
+	risk := exchange.NewRiskGateway(venue, exchange.RiskLimits{})
+	m := NewManager(cfg, nil, map[string]exchange.Provider{"hl": risk, "paper": sim.New()}, nil, nil)
+	ctx := context.Background()
+
+	m.checkCredentialExpiry(ctx)
+	require.Equal(t, 1, venue.calls, "decorators forward the expiry")
+	m.checkCredentialExpiry(ctx)
+	assert.Equal(t, 1, venue.calls, "checks are throttled")
+
+	m.credentialChecked["hl"] = time.Now().Add(-credentialCheckInterval)
+	venue.err = errors.New("info endpoint down")
+	m.checkCredentialExpiry(ctx)
+	assert.Equal(t, 2, venue.calls)
+
+	disabled := NewManager(&Config{}, nil, map[string]exchange.Provider{"hl": venue}, nil, nil)
+	disabled.checkCredentialExpiry(ctx)
+	assert.Equal(t, 2, venue.calls, "a zero warning window disables the check")
+}
+
+func TestRunCredentialExpiryCheckRunsOnItsOwn(t *testing.T) {
+	venue := &expiringSim{Provider: sim.New(), expiry: time.Now().Add(24 * time.Hour)}
+	cfg := &Config{Manager: ManagerConfig{CredentialExpiryWarning: 72 * time.Hour}}
+	m := NewManager(cfg, nil, map[string]exchange.Provider{"hl": venue}, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.runCredentialExpiryCheck(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		venue.mu.Lock()
+		defer venue.mu.Unlock()
+		return venue.calls == 1
+	}, time.Second, 10*time.Millisecond, "checked without a trading loop tick")
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runCredentialExpiryCheck did not stop with its context")
+	}
+}
@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// deadManRefreshFraction sets how often the scheduled cancel is pushed back,
+// as a fraction of the timeout, so a slow request or two does not let it fire.
+const deadManRefreshFraction = 3
+
+// deadManTick is how often runDeadManSwitch checks whether a refresh is due.
+const deadManTick = time.Second
+
+// runDeadManSwitch keeps the dead-man's switch armed from its own ticker,
+// so slow decision cycles do not delay refreshes. It returns when ctx is
+// done or the manager stops; the venue then cancels all resting orders once
+// the last schedule expires.
+func (m *Manager) runDeadManSwitch(ctx context.Context) {
+	if m == nil || m.config == nil || m.config.Manager.DeadManSwitchTimeout <= 0 {
+		return
+	}
+	m.refreshDeadManSwitch(ctx)
+	ticker := time.NewTicker(deadManTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.refreshDeadManSwitch(ctx)
+		}
+	}
+}
+
+// refreshDeadManSwitch pushes the scheduled cancel of every exchange provider
+// and trader sub-account that supports one to now +
+// manager.dead_man_switch_timeout, at most every third of the timeout.
+func (m *Manager) refreshDeadManSwitch(ctx context.Context) {
+	if m == nil || m.config == nil || m.config.Manager.DeadManSwitchTimeout <= 0 {
+		return
+	}
+	timeout := m.config.Manager.DeadManSwitchTimeout
+
+	m.mu.RLock()
+	names := make([]string, 0, len(m.exchangeProviders))
+	schedulers := make(map[string]exchange.CancelScheduler, len(m.exchangeProviders))
+	add := func(name string, provider exchange.Provider) {
+		// Decorators such as the risk gateway implement CancelScheduler
+		// whatever they wrap, so ask for the capability instead.
+		if scheduler, ok := provider.(exchange.CancelScheduler); ok && exchange.CapabilitiesOf(provider).ScheduleCancel {
+			if _, seen := schedulers[name]; !seen {
+				names = append(names, name)
+				schedulers[name] = scheduler
+			}
+		}
+	}
+	for name, provider := range m.exchangeProviders {
+		add(name, provider)
+	}
+	// Sub-accounts keep their own schedule on the venue.
+	for _, trader := range m.traders {
+		if trader.SubAccount != "" && trader.ExchangeProvider != nil {
+			add(trader.accountKey(), trader.ExchangeProvider)
+		}
+	}
+	m.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		now := time.Now()
+		m.deadManMu.Lock()
+		first := m.deadManSent[name].IsZero()
+		due := now.Sub(m.deadManSent[name]) >= timeout/deadManRefreshFraction
+		if due {
+			m.deadManSent[name] = now
+		}
+		m.deadManMu.Unlock()
+		if !due {
+			continue
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, timeout/deadManRefreshFraction)
+		err := schedulers[name].ScheduleCancel(reqCtx, now.Add(timeout))
+		cancel()
+
+		m.deadManMu.Lock()
+		failing := m.deadManErr[name]
+		m.deadManErr[name] = err != nil
+		m.deadManMu.Unlock()
+		switch {
+		case err != nil && !failing:
+			logx.WithContext(ctx).Errorf("manager: dead man's switch refresh failed exchange=%s timeout=%s err=%v", name, timeout, err)
+		case err == nil && (failing || first):
+			logx.WithContext(ctx).Infof("manager: dead man's switch armed exchange=%s timeout=%s", name, timeout)
+		}
+	}
+}
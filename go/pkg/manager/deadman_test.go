@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+)
+
+// schedulingSim records scheduled cancels on top of the simulator.
+type schedulingSim struct {
+	*sim.Provider
+
+	mu  sync.Mutex
+	at  []time.Time
+	err error
+}
+
+func (s *schedulingSim) ScheduleCancel(ctx context.Context, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.at = append(s.at, at)
+	return s.err
+}
+
+// Capabilities reports the extensions added on top of the simulator.
+func (s *schedulingSim) Capabilities() exchange.Capabilities { return exchange.DetectCapabilities(s) }
+
+func (s *schedulingSim) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.at)
+}
+
+func TestRefreshDeadManSwitch(t *testing.T) {
+	venue := &schedulingSim{Provider: sim.New()}
+	cfg := &Config{Manager: ManagerConfig{DeadManSwitchTimeout: time.Minute}}
+	gated := exchange.NewRiskGateway(sim.New(), exchange.RiskLimits{MaxOrdersPerMinute: 10})
+	m := NewManager(cfg, nil, map[string]exchange.Provider{"hl": venue, "paper": sim.New(), "gated": gated}, nil, nil)
+	ctx := context.Background()
+
+	before := time.Now()
+	m.refreshDeadManSwitch(ctx)
+	require.Equal(t, 1, venue.calls())
+	assert.NotContains(t, m.deadManSent, "gated", "a gateway over a provider without scheduled cancels is skipped")
+	assert.WithinDuration(t, before.Add(time.Minute), venue.at[0], time.Second)
+
+	m.refreshDeadManSwitch(ctx)
+	assert.Equal(t, 1, venue.calls(), "refreshes are throttled to a third of the timeout")
+
+	m.deadManSent["hl"] = time.Now().Add(-21 * time.Second)
+	venue.err = errors.New("not enough volume")
+	m.refreshDeadManSwitch(ctx)
+	assert.Equal(t, 2, venue.calls())
+	assert.True(t, m.deadManErr["hl"])
+
+	m.deadManSent["hl"] = time.Time{}
+	venue.err = nil
+	m.refreshDeadManSwitch(ctx)
+	assert.Equal(t, 3, venue.calls(), "failures are retried on the next refresh")
+	assert.False(t, m.deadManErr["hl"])
+
+	sub := &schedulingSim{Provider: sim.New()}
+	m.traders["t1"] = &VirtualTrader{ID: "t1", Exchange: "hl", SubAccount: "t1", ExchangeProvider: sub}
+	m.refreshDeadManSwitch(ctx)
+	assert.Equal(t, 1, sub.calls(), "sub-accounts are armed too")
+	assert.Equal(t, 3, venue.calls())
+
+	disabled := NewManager(&Config{}, nil, map[string]exchange.Provider{"hl": venue}, nil, nil)
+	disabled.refreshDeadManSwitch(ctx)
+	assert.Equal(t, 3, venue.calls(), "a zero timeout disables the switch")
+}
+
+func TestRunDeadManSwitchRefreshesOnItsOwn(t *testing.T) {
+	venue := &schedulingSim{Provider: sim.New()}
+	cfg := &Config{Manager: ManagerConfig{DeadManSwitchTimeout: 2 * time.Minute}}
+	m := NewManager(cfg, nil, map[string]exchange.Provider{"hl": venue}, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.runDeadManSwitch(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return venue.calls() == 1 }, time.Second, 10*time.Millisecond, "armed without a trading loop tick")
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runDeadManSwitch did not stop with its context")
+	}
+}
+
+func TestValidateDeadManSwitchTimeout(t *testing.T) {
+	cfg := Config{Manager: ManagerConfig{StateStorageBackend: "file", StateStoragePath: "state.json", DeadManSwitchTimeout: 5 * time.Second}}
+	assert.ErrorContains(t, cfg.Validate(), "dead_man_switch_timeout")
+
+	cfg.Manager.DeadManSwitchTimeout = time.Minute
+	assert.ErrorContains(t, cfg.Validate(), "dead_man_switch_timeout", "the switch must outlast a decision cycle")
+
+	cfg.Manager.DeadManSwitchTimeout = 2 * time.Minute
+	err := cfg.Validate()
+	require.Error(t, err, "no traders defined")
+	assert.NotContains(t, err.Error(), "dead_man_switch_timeout")
+}
@@ -0,0 +1,257 @@
+package manager
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// eventRetryDelay spaces out resubscription attempts to a stream that failed
+// to start.
+const eventRetryDelay = 30 * time.Second
+
+// maxManagedOrders bounds the set of cloids and order IDs the manager
+// remembers submitting itself.
+const maxManagedOrders = 4096
+
+// startEventStreams subscribes to every exchange account used by a
+// registered trader whose provider implements exchange.EventStream and has no
+// stream yet; traders on their own sub-account get their own stream. The
+// trading loop calls it on every tick, so traders registered later are
+// picked up and a stream that ended is resubscribed.
+func (m *Manager) startEventStreams(ctx context.Context) {
+	m.mu.RLock()
+	streams := make(map[string]exchange.EventStream)
+	for _, t := range m.traders {
+		if stream, ok := t.ExchangeProvider.(exchange.EventStream); ok && exchange.CapabilitiesOf(t.ExchangeProvider).EventStream {
+			streams[t.accountKey()] = stream
+		}
+	}
+	m.mu.RUnlock()
+
+	for name, stream := range streams {
+		now := time.Now()
+		m.eventMu.Lock()
+		skip := m.eventStreams[name] || now.Before(m.eventRetry[name])
+		if !skip {
+			m.eventStreams[name] = true
+		}
+		m.eventMu.Unlock()
+		if skip {
+			continue
+		}
+		events, err := stream.Events(ctx)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("manager: subscribe exchange events provider=%s err=%v", name, err)
+			m.eventMu.Lock()
+			delete(m.eventStreams, name)
+			m.eventRetry[name] = now.Add(eventRetryDelay)
+			m.eventMu.Unlock()
+			continue
+		}
+		logx.WithContext(ctx).Infof("manager: exchange event stream started provider=%s", name)
+		m.wg.Add(1)
+		go m.consumeEvents(name, events)
+	}
+}
+
+func (m *Manager) consumeEvents(provider string, events <-chan exchange.Event) {
+	defer m.wg.Done()
+	for ev := range events {
+		m.handleExchangeEvent(provider, ev)
+	}
+	m.eventMu.Lock()
+	delete(m.eventStreams, provider)
+	m.eventMu.Unlock()
+}
+
+// handleExchangeEvent records close fills the manager did not submit itself,
+// i.e. stop-loss/take-profit triggers and venue-side liquidations, as
+// PositionEventClose events for the owning trader. Every order update and
+// fill is also fed to the order tracker.
+func (m *Manager) handleExchangeEvent(provider string, ev exchange.Event) {
+	if ev.Type == exchange.EventOrderUpdate && ev.Order != nil {
+		m.orders.ApplyUpdate(*ev.Order)
+		if strings.EqualFold(ev.Order.Status, "triggered") {
+			logx.Infof("manager: provider %s trigger order fired coin=%s oid=%d", provider, ev.Order.Order.Coin, ev.Order.Order.Oid)
+		}
+		return
+	}
+	if ev.Type != exchange.EventFill || ev.Fill == nil {
+		return
+	}
+	fill := ev.Fill
+	m.orders.ApplyFill(*fill)
+	if fill.IsClose() {
+		m.recordExchangeClose(provider, ev)
+	}
+}
+
+// recordExchangeClose records a close fill unless the manager submitted the
+// order itself. While a manager order on the same coin awaits its response,
+// the fill may belong to it before its oid is known, so it is held until the
+// submission finishes (see beginSubmission).
+func (m *Manager) recordExchangeClose(provider string, ev exchange.Event) {
+	fill := ev.Fill
+	key := symbolOwnerKey(provider, fill.Coin)
+	m.eventMu.Lock()
+	if m.isManagedLocked(fill.Cloid, fill.Oid) {
+		m.eventMu.Unlock()
+		return
+	}
+	if m.inFlight[key] > 0 {
+		m.deferredFills[key] = append(m.deferredFills[key], ev)
+		m.eventMu.Unlock()
+		return
+	}
+	m.eventMu.Unlock()
+
+	trader := m.traderForSymbol(provider, fill.Coin)
+	if trader == nil {
+		logx.Infof("manager: provider %s close fill coin=%s oid=%d has no owning trader", provider, fill.Coin, fill.Oid)
+		return
+	}
+	action, size := closedLeg(*fill)
+	occurredAt := ev.ReceivedAt
+	if fill.Timestamp > 0 {
+		occurredAt = time.UnixMilli(fill.Timestamp)
+	}
+	trader.mu.Lock()
+	trader.Cooldown[fill.Coin] = occurredAt
+	trader.mu.Unlock()
+	logx.Infof("manager: trader %s exchange-side close fill symbol=%s dir=%s px=%s sz=%s closed_pnl=%s recovered=%t", trader.ID, fill.Coin, fill.Dir, fill.Px, fill.Sz, fill.ClosedPnl, ev.Recovered)
+	m.recordPositionEvent(PositionEvent{
+		TraderID:   trader.ID,
+		Trader:     trader,
+		Decision:   executorpkg.Decision{Symbol: fill.Coin, Action: action},
+		Event:      PositionEventClose,
+		OccurredAt: occurredAt,
+		FillPrice:  parseFloat(fill.Px),
+		FillSize:   size,
+		Fills:      []exchange.Fill{*fill},
+	})
+}
+
+// closedLeg returns the close action and size of a close fill. A flip
+// ("Long > Short") closes the starting position and opens the rest on the
+// other side.
+func closedLeg(fill exchange.Fill) (string, float64) {
+	dir := strings.ToLower(fill.Dir)
+	size := parseFloat(fill.Sz)
+	if before, _, flip := strings.Cut(dir, ">"); flip {
+		dir = before
+		if start := math.Abs(parseFloat(fill.StartPosition)); start > 0 && start < size {
+			size = start
+		}
+	}
+	if strings.Contains(dir, "long") {
+		return "close_long", size
+	}
+	return "close_short", size
+}
+
+// beginSubmission registers an order the manager is about to submit on
+// coin, so its fills are not recorded a second time when they arrive on the
+// event stream, possibly before the venue's response. cloid may be empty
+// for orders sent without one. The returned func must be called with the
+// response once the submission finishes; it registers the response's oids
+// and releases close fills held meanwhile.
+func (m *Manager) beginSubmission(provider, coin, cloid string) func(*exchange.OrderResponse) {
+	key := symbolOwnerKey(provider, coin)
+	m.eventMu.Lock()
+	if cloid != "" {
+		m.addManagedLocked(strings.ToLower(cloid))
+	}
+	m.inFlight[key]++
+	m.eventMu.Unlock()
+
+	var once sync.Once
+	return func(resp *exchange.OrderResponse) {
+		once.Do(func() {
+			m.eventMu.Lock()
+			if resp != nil {
+				for _, oid := range resp.OrderIDs() {
+					m.addManagedLocked(strconv.FormatInt(oid, 10))
+				}
+			}
+			var held []exchange.Event
+			if m.inFlight[key]--; m.inFlight[key] <= 0 {
+				delete(m.inFlight, key)
+				held = m.deferredFills[key]
+				delete(m.deferredFills, key)
+			}
+			m.eventMu.Unlock()
+			for _, ev := range held {
+				m.recordExchangeClose(provider, ev)
+			}
+		})
+	}
+}
+
+func (m *Manager) addManagedLocked(id string) {
+	if _, ok := m.managed[id]; ok {
+		return
+	}
+	m.managed[id] = struct{}{}
+	m.managedOrder = append(m.managedOrder, id)
+	if len(m.managedOrder) > maxManagedOrders {
+		delete(m.managed, m.managedOrder[0])
+		m.managedOrder = m.managedOrder[1:]
+	}
+}
+
+// isManagedLocked reports whether a fill with this cloid or oid belongs to a
+// manager-submitted order.
+func (m *Manager) isManagedLocked(cloid string, oid int64) bool {
+	if cloid != "" {
+		if _, ok := m.managed[strings.ToLower(cloid)]; ok {
+			return true
+		}
+	}
+	_, ok := m.managed[strconv.FormatInt(oid, 10)]
+	return ok
+}
+
+// setSymbolOwner records which trader last opened coin on an exchange account
+// (see VirtualTrader.accountKey) so exchange-side fills can be attributed
+// when several traders share it.
+func (m *Manager) setSymbolOwner(provider, coin, traderID string) {
+	m.eventMu.Lock()
+	defer m.eventMu.Unlock()
+	m.symbolOwners[symbolOwnerKey(provider, coin)] = traderID
+}
+
+func (m *Manager) traderForSymbol(provider, coin string) *VirtualTrader {
+	m.eventMu.Lock()
+	owner := m.symbolOwners[symbolOwnerKey(provider, coin)]
+	m.eventMu.Unlock()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if t, ok := m.traders[owner]; ok {
+		return t
+	}
+	var match *VirtualTrader
+	for _, t := range m.traders {
+		if t.accountKey() != provider {
+			continue
+		}
+		if match != nil {
+			return nil // ambiguous without an owner record
+		}
+		match = t
+	}
+	return match
+}
+
+func symbolOwnerKey(provider, coin string) string {
+	return provider + "|" + strings.ToUpper(strings.TrimSpace(coin))
+}
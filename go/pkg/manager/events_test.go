@@ -0,0 +1,179 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+type capturePersistence struct {
+	noopPersistenceService
+	mu        sync.Mutex
+	events    []PositionEvent
+	funding   []FundingRecord
+	snapshots []AccountSyncSnapshot
+	orders    []OrderRecord
+}
+
+func (c *capturePersistence) RecordOrderUpdate(ctx context.Context, record OrderRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.orders = append(c.orders, record)
+	return nil
+}
+
+func (c *capturePersistence) RecordFundingPayments(ctx context.Context, record FundingRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.funding = append(c.funding, record)
+	return nil
+}
+
+func (c *capturePersistence) RecordAccountSnapshot(ctx context.Context, snapshot AccountSyncSnapshot) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshots = append(c.snapshots, snapshot)
+	return nil
+}
+
+func (c *capturePersistence) RecordPositionEvent(ctx context.Context, event PositionEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+	return nil
+}
+
+func (c *capturePersistence) snapshot() []PositionEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]PositionEvent(nil), c.events...)
+}
+
+// streamingProvider is an exchange.Provider that pushes scripted events.
+type streamingProvider struct {
+	exchange.Provider
+	events chan exchange.Event
+}
+
+func (s *streamingProvider) Events(ctx context.Context) (<-chan exchange.Event, error) {
+	return s.events, nil
+}
+
+func newEventTestManager(persist PersistenceService, traders ...*VirtualTrader) *Manager {
+	m := NewManager(nil, nil, nil, nil, persist)
+	for _, t := range traders {
+		m.traders[t.ID] = t
+	}
+	return m
+}
+
+func TestHandleExchangeEventRecordsTriggeredClose(t *testing.T) {
+	persist := &capturePersistence{}
+	trader := &VirtualTrader{ID: "t1", Exchange: "hl", Cooldown: map[string]time.Time{}}
+	m := newEventTestManager(persist, trader)
+
+	m.beginSubmission("hl", "BTC", "")(filledResponse(7))
+
+	// Manager-submitted close: already recorded by ExecuteDecision.
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "BTC", Oid: 7, Dir: "Close Long", Px: "100", Sz: "1"}})
+	// Opening fill: not a close.
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "BTC", Oid: 8, Dir: "Open Long", Px: "100", Sz: "1"}})
+	// Triggered stop-loss.
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "BTC", Oid: 9, Dir: "Close Short", Px: "105", Sz: "0.5", Timestamp: 1_700_000_000_000}})
+
+	events := persist.snapshot()
+	assert.Len(t, events, 1, "only the triggered close should be recorded")
+	ev := events[0]
+	assert.Equal(t, PositionEventClose, ev.Event)
+	assert.Equal(t, "t1", ev.TraderID)
+	assert.Equal(t, "close_short", ev.Decision.Action)
+	assert.Equal(t, "BTC", ev.Decision.Symbol)
+	assert.InDelta(t, 105, ev.FillPrice, 1e-9)
+	assert.InDelta(t, 0.5, ev.FillSize, 1e-9)
+	assert.Equal(t, time.UnixMilli(1_700_000_000_000), ev.OccurredAt)
+	assert.Len(t, ev.Fills, 1)
+	assert.Contains(t, trader.Cooldown, "BTC")
+}
+
+func filledResponse(oid int64) *exchange.OrderResponse {
+	return &exchange.OrderResponse{Response: exchange.OrderResponseData{Data: exchange.OrderResponseDataDetail{
+		Statuses: []exchange.OrderStatusResponse{{Filled: &exchange.FilledOrder{Oid: oid}}},
+	}}}
+}
+
+func TestHandleExchangeEventDedupesFillsBeforeResponse(t *testing.T) {
+	persist := &capturePersistence{}
+	trader := &VirtualTrader{ID: "t1", Exchange: "hl", Cooldown: map[string]time.Time{}}
+	m := newEventTestManager(persist, trader)
+
+	// The websocket fill of a manager close lands before the REST response.
+	finish := m.beginSubmission("hl", "BTC", "")
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "BTC", Oid: 11, Dir: "Close Long", Px: "100", Sz: "1"}})
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "BTC", Oid: 12, Dir: "Close Long", Px: "99", Sz: "1"}})
+	assert.Empty(t, persist.snapshot(), "close fills are held while the submission is in flight")
+	finish(filledResponse(11))
+	events := persist.snapshot()
+	require.Len(t, events, 1, "only the fill of another order is recorded")
+	assert.InDelta(t, 99, events[0].FillPrice, 1e-9)
+
+	// Orders submitted with a venue cloid are matched on it.
+	m.beginSubmission("hl", "ETH", "0x00000000000000000000000000000abc")(nil)
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "ETH", Oid: 13, Cloid: "0x00000000000000000000000000000ABC", Dir: "Close Short", Px: "10", Sz: "1"}})
+	assert.Len(t, persist.snapshot(), 1)
+}
+
+func TestHandleExchangeEventRecordsFlipAsClose(t *testing.T) {
+	persist := &capturePersistence{}
+	trader := &VirtualTrader{ID: "t1", Exchange: "hl", Cooldown: map[string]time.Time{}}
+	m := newEventTestManager(persist, trader)
+
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "SOL", Oid: 5, Dir: "Short > Long", StartPosition: "-1", Px: "20", Sz: "3"}})
+	events := persist.snapshot()
+	require.Len(t, events, 1)
+	assert.Equal(t, "close_short", events[0].Decision.Action)
+	assert.InDelta(t, 1, events[0].FillSize, 1e-9, "only the starting position was closed")
+}
+
+func TestTraderForSymbolUsesOwnerWhenShared(t *testing.T) {
+	a := &VirtualTrader{ID: "a", Exchange: "hl", Cooldown: map[string]time.Time{}}
+	b := &VirtualTrader{ID: "b", Exchange: "hl", Cooldown: map[string]time.Time{}}
+	m := newEventTestManager(nil, a, b)
+
+	assert.Nil(t, m.traderForSymbol("hl", "ETH"), "ambiguous without owner")
+	m.setSymbolOwner("hl", "eth", "b")
+	assert.Equal(t, b, m.traderForSymbol("hl", "ETH"))
+}
+
+func TestStartEventStreamsConsumesProviderEvents(t *testing.T) {
+	persist := &capturePersistence{}
+	provider := &streamingProvider{events: make(chan exchange.Event, 1)}
+	trader := &VirtualTrader{ID: "t1", Exchange: "hl", ExchangeProvider: provider, Cooldown: map[string]time.Time{}}
+	m := newEventTestManager(persist, trader)
+
+	m.startEventStreams(context.Background())
+	m.startEventStreams(context.Background()) // idempotent
+	provider.events <- exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "SOL", Oid: 1, Dir: "Close Long", Px: "10", Sz: "2"}}
+	close(provider.events)
+	m.wg.Wait()
+
+	events := persist.snapshot()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "close_long", events[0].Decision.Action)
+
+	// Traders registered after the loop started get a stream too.
+	later := &streamingProvider{events: make(chan exchange.Event, 1)}
+	m.mu.Lock()
+	m.traders["t2"] = &VirtualTrader{ID: "t2", Exchange: "bn", ExchangeProvider: later, Cooldown: map[string]time.Time{}}
+	m.mu.Unlock()
+	m.startEventStreams(context.Background())
+	later.events <- exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "ETH", Oid: 2, Dir: "Close Short", Px: "10", Sz: "1"}}
+	close(later.events)
+	m.wg.Wait()
+	assert.Len(t, persist.snapshot(), 2)
+}
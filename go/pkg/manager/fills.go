@@ -0,0 +1,44 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// fillLookback widens the fill history query to tolerate clock skew between
+// the host and the venue.
+const fillLookback = 5 * time.Second
+
+// orderFills returns the venue fills for the orders in resp when the provider
+// implements exchange.FillHistory. Lookup failures are logged and yield nil
+// so callers fall back to the order response.
+func orderFills(ctx context.Context, trader *VirtualTrader, resp *exchange.OrderResponse, submittedAt time.Time) []exchange.Fill {
+	oids := resp.OrderIDs()
+	if trader == nil || len(oids) == 0 {
+		return nil
+	}
+	history, ok := trader.ExchangeProvider.(exchange.FillHistory)
+	if !ok || !exchange.CapabilitiesOf(trader.ExchangeProvider).FillHistory {
+		return nil
+	}
+	fills, err := history.GetFills(ctx, submittedAt.Add(-fillLookback))
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: fetch fills trader=%s oids=%v err=%v", trader.ID, oids, err)
+		return nil
+	}
+	return exchange.FillsForOrders(fills, oids...)
+}
+
+// applyFills overrides the estimated fill price and size with the venue's
+// volume-weighted execution when fills are available.
+func applyFills(fills []exchange.Fill, price, qty float64) (float64, float64) {
+	summary, ok := exchange.SummarizeFills(fills)
+	if !ok {
+		return price, qty
+	}
+	return summary.AvgPx, summary.Size
+}
@@ -0,0 +1,52 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+type fixedPriceMarket struct{ price float64 }
+
+func (f fixedPriceMarket) Snapshot(ctx context.Context, symbol string) (*market.Snapshot, error) {
+	return &market.Snapshot{Symbol: symbol, Price: market.PriceInfo{Last: f.price}}, nil
+}
+
+func (f fixedPriceMarket) ListAssets(ctx context.Context) ([]market.Asset, error) { return nil, nil }
+
+func TestExecuteDecisionAttachesVenueFills(t *testing.T) {
+	persist := &capturePersistence{}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: sim.New(),
+		MarketProvider:   fixedPriceMarket{price: 100},
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		OrderStyle:       OrderStyleMarketIOC,
+		Cooldown:         map[string]time.Time{},
+	}
+	m := newEventTestManager(persist, trader)
+
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 100, PositionSizeUSD: 200}))
+	trader.MarketProvider = fixedPriceMarket{price: 110}
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "close_long"}))
+
+	events := persist.snapshot()
+	require.Len(t, events, 2)
+	open, closing := events[0], events[1]
+	require.Len(t, open.Fills, 1, "open event carries the venue fill")
+	assert.Equal(t, "Open Long", open.Fills[0].Dir)
+	assert.InDelta(t, parseFloat(open.Fills[0].Px), open.FillPrice, 1e-9, "fill price comes from the venue fill, not the decision")
+	require.Len(t, closing.Fills, 1, "close event carries the venue fill")
+	assert.True(t, closing.Fills[0].IsClose())
+	assert.InDelta(t, 110, closing.FillPrice, 1e-9)
+	assert.InDelta(t, 2, closing.FillSize, 1e-9)
+	assert.Greater(t, parseFloat(closing.Fills[0].ClosedPnl), 0.0)
+}
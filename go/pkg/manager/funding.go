@@ -0,0 +1,145 @@
+package manager
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/market"
+)
+
+// fundingAttributionGrace is how long a payment for a coin no trader owns
+// holds the funding cursor back, in case the open that owns it has not been
+// recorded yet. Older unattributed payments, e.g. for positions opened by
+// hand, are skipped.
+const fundingAttributionGrace = 2 * time.Hour
+
+// syncFunding pulls funding payments made since the trader's cursor when the
+// provider implements exchange.FundingHistory, keeps those for coins the
+// trader owns, persists them and adds them to ResourceAlloc.FundingPnLUSD.
+// The first sync resumes from the cursor the persistence service reports, or
+// from the trader's registration. The cursor only advances once persistence
+// succeeds and never past a recent payment no trader owns yet, and payments
+// are applied once each however often they are fetched.
+func (m *Manager) syncFunding(ctx context.Context, t *VirtualTrader) {
+	history, ok := t.ExchangeProvider.(exchange.FundingHistory)
+	if !ok || !exchange.CapabilitiesOf(t.ExchangeProvider).FundingHistory {
+		return
+	}
+	since, err := m.fundingSince(ctx, t)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: load funding cursor trader=%s err=%v", t.ID, err)
+		return
+	}
+
+	payments, err := history.GetFundingPayments(ctx, since)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: fetch funding trader=%s err=%v", t.ID, err)
+		return
+	}
+	now := time.Now()
+	next := since
+	var held time.Time
+	var owned []exchange.FundingPayment
+	var total float64
+	for _, p := range payments {
+		at := time.UnixMilli(p.Timestamp)
+		owner := m.traderForSymbol(t.accountKey(), p.Coin)
+		if owner == nil && now.Sub(at) < fundingAttributionGrace {
+			if held.IsZero() || at.Before(held) {
+				held = at
+			}
+			continue
+		}
+		if ts := at.Add(time.Millisecond); ts.After(next) {
+			next = ts
+		}
+		if owner == nil || owner.ID != t.ID {
+			continue
+		}
+		m.eventMu.Lock()
+		_, done := m.fundingApplied[t.ID][fundingKey(p)]
+		m.eventMu.Unlock()
+		if done {
+			continue
+		}
+		owned = append(owned, p)
+		total += parseFloat(p.USDC)
+	}
+	if !held.IsZero() && held.Before(next) {
+		next = held
+	}
+	if len(owned) > 0 {
+		if err := m.persistence.RecordFundingPayments(ctx, FundingRecord{TraderID: t.ID, Exchange: t.Exchange, Payments: owned}); err != nil {
+			logPersistenceError(err, "funding persistence failed", map[string]any{"trader_id": t.ID, "payments": len(owned)})
+			return
+		}
+		t.mu.Lock()
+		t.ResourceAlloc.FundingPnLUSD += total
+		t.mu.Unlock()
+		logx.WithContext(ctx).Infof("manager: trader %s funding payments=%d usd=%.4f", t.ID, len(owned), total)
+	}
+	m.eventMu.Lock()
+	defer m.eventMu.Unlock()
+	m.fundingCursor[t.ID] = next
+	applied := m.fundingApplied[t.ID]
+	if applied == nil {
+		applied = make(map[string]int64)
+		m.fundingApplied[t.ID] = applied
+	}
+	for _, p := range owned {
+		applied[fundingKey(p)] = p.Timestamp
+	}
+	// Payments before the cursor are not fetched again.
+	for key, ts := range applied {
+		if ts < next.UnixMilli() {
+			delete(applied, key)
+		}
+	}
+}
+
+// fundingSince returns the trader's funding cursor, loading it from the
+// persistence service on the first sync.
+func (m *Manager) fundingSince(ctx context.Context, t *VirtualTrader) (time.Time, error) {
+	m.eventMu.Lock()
+	since, tracked := m.fundingCursor[t.ID]
+	m.eventMu.Unlock()
+	if tracked {
+		return since, nil
+	}
+	if store, ok := m.persistence.(FundingCursorStore); ok {
+		cursor, err := store.FundingCursor(ctx, t.ID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !cursor.IsZero() {
+			return cursor, nil
+		}
+	}
+	since = t.CreatedAt
+	if since.IsZero() {
+		since = time.Now()
+	}
+	return since, nil
+}
+
+// fundingKey identifies a payment by its hash and time.
+func fundingKey(p exchange.FundingPayment) string {
+	return p.Hash + "|" + p.Coin + "|" + strconv.FormatInt(p.Timestamp, 10)
+}
+
+// feedFundingRate forwards the snapshot's funding rate to providers without a
+// market feed of their own (e.g. the simulator) so their funding accrual
+// tracks the live venue.
+func feedFundingRate(ctx context.Context, t *VirtualTrader, symbol string, snap *market.Snapshot) {
+	setter, ok := t.ExchangeProvider.(exchange.FundingRateSetter)
+	if !ok || snap == nil || snap.Funding == nil {
+		return
+	}
+	if err := setter.SetFundingRate(ctx, symbol, snap.Funding.Rate); err != nil {
+		logx.WithContext(ctx).Errorf("manager: set funding rate trader=%s symbol=%s err=%v", t.ID, symbol, err)
+	}
+}
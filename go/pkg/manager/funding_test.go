@@ -0,0 +1,123 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+)
+
+// fundingProvider serves a fixed account state and funding history.
+type fundingProvider struct {
+	exchange.Provider
+	payments []exchange.FundingPayment
+	since    []time.Time
+}
+
+func (f *fundingProvider) GetAccountState(ctx context.Context) (*exchange.AccountState, error) {
+	return &exchange.AccountState{MarginSummary: exchange.MarginSummary{AccountValue: "1000", TotalMarginUsed: "100"}}, nil
+}
+
+func (f *fundingProvider) GetFundingPayments(ctx context.Context, since time.Time) ([]exchange.FundingPayment, error) {
+	f.since = append(f.since, since)
+	var out []exchange.FundingPayment
+	for _, p := range f.payments {
+		if p.Timestamp >= since.UnixMilli() {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func TestSyncTraderPositionsAttributesFunding(t *testing.T) {
+	created := time.UnixMilli(1_700_000_000_000)
+	provider := &fundingProvider{payments: []exchange.FundingPayment{
+		{Coin: "BTC", USDC: "-0.5", Timestamp: created.UnixMilli() + 1},
+		{Coin: "ETH", USDC: "0.2", Timestamp: created.UnixMilli() + 2},
+		{Coin: "BTC", USDC: "-0.25", Timestamp: created.UnixMilli() + 3},
+	}}
+	persist := &capturePersistence{}
+	t1 := &VirtualTrader{ID: "t1", Exchange: "hl", ExchangeProvider: provider, CreatedAt: created}
+	t2 := &VirtualTrader{ID: "t2", Exchange: "hl", ExchangeProvider: provider, CreatedAt: created}
+	m := newEventTestManager(persist, t1, t2)
+	m.setSymbolOwner("hl", "BTC", "t1")
+	m.setSymbolOwner("hl", "ETH", "t2")
+
+	require.NoError(t, m.SyncTraderPositions("t1"))
+	require.Len(t, persist.funding, 1)
+	record := persist.funding[0]
+	assert.Equal(t, "t1", record.TraderID)
+	assert.Equal(t, "hl", record.Exchange)
+	require.Len(t, record.Payments, 2, "only coins owned by the trader are attributed")
+	assert.InDelta(t, -0.75, t1.ResourceAlloc.FundingPnLUSD, 1e-9)
+	require.Len(t, persist.snapshots, 1)
+	assert.InDelta(t, -0.75, persist.snapshots[0].FundingUSD, 1e-9)
+	assert.Equal(t, created, provider.since[0], "the first sync starts at registration")
+
+	// The cursor advances past the last payment, so nothing is re-counted.
+	require.NoError(t, m.SyncTraderPositions("t1"))
+	assert.Len(t, persist.funding, 1)
+	assert.InDelta(t, -0.75, t1.ResourceAlloc.FundingPnLUSD, 1e-9)
+	assert.Equal(t, time.UnixMilli(created.UnixMilli()+4), provider.since[1])
+}
+
+// cursorPersistence reports a stored funding cursor.
+type cursorPersistence struct {
+	capturePersistence
+	cursor time.Time
+}
+
+func (c *cursorPersistence) FundingCursor(ctx context.Context, traderID string) (time.Time, error) {
+	return c.cursor, nil
+}
+
+func TestSyncFundingResumesFromStoredCursor(t *testing.T) {
+	provider := &fundingProvider{}
+	persist := &cursorPersistence{cursor: time.UnixMilli(1_700_000_000_000)}
+	t1 := &VirtualTrader{ID: "t1", Exchange: "hl", ExchangeProvider: provider, CreatedAt: time.Now()}
+	m := newEventTestManager(persist, t1)
+
+	m.syncFunding(context.Background(), t1)
+	require.Len(t, provider.since, 1)
+	assert.Equal(t, persist.cursor, provider.since[0])
+}
+
+func TestSyncFundingHoldsCursorForUnattributedPayments(t *testing.T) {
+	now := time.Now()
+	created := now.Add(-3 * time.Hour)
+	provider := &fundingProvider{payments: []exchange.FundingPayment{
+		{Coin: "SOL", USDC: "1", Hash: "0xold", Timestamp: now.Add(-150 * time.Minute).UnixMilli()},
+		{Coin: "BTC", USDC: "-0.5", Hash: "0xa", Timestamp: now.Add(-90 * time.Minute).UnixMilli()},
+		{Coin: "ETH", USDC: "0.2", Hash: "0xb", Timestamp: now.Add(-60 * time.Minute).UnixMilli()},
+		{Coin: "BTC", USDC: "-0.25", Hash: "0xc", Timestamp: now.Add(-30 * time.Minute).UnixMilli()},
+	}}
+	persist := &capturePersistence{}
+	t1 := &VirtualTrader{ID: "t1", Exchange: "hl", ExchangeProvider: provider, CreatedAt: created}
+	t2 := &VirtualTrader{ID: "t2", Exchange: "hl", ExchangeProvider: provider, CreatedAt: created}
+	m := newEventTestManager(persist, t1, t2)
+	m.setSymbolOwner("hl", "BTC", "t1")
+	ctx := context.Background()
+
+	m.syncFunding(ctx, t1)
+	assert.InDelta(t, -0.75, t1.ResourceAlloc.FundingPnLUSD, 1e-9)
+	assert.Equal(t, time.UnixMilli(provider.payments[2].Timestamp), m.fundingCursor["t1"],
+		"a recent payment nobody owns holds the cursor; an old one does not")
+
+	// The ETH open is recorded after its payment; the payment is picked up
+	// and the BTC payment fetched again is not counted twice.
+	m.setSymbolOwner("hl", "ETH", "t1")
+	m.syncFunding(ctx, t1)
+	assert.InDelta(t, -0.55, t1.ResourceAlloc.FundingPnLUSD, 1e-9)
+	require.Len(t, persist.funding, 2)
+	require.Len(t, persist.funding[1].Payments, 1)
+	assert.Equal(t, "0xb", persist.funding[1].Payments[0].Hash)
+	assert.Equal(t, time.UnixMilli(provider.payments[3].Timestamp+1), m.fundingCursor["t1"])
+
+	m.syncFunding(ctx, t1)
+	assert.InDelta(t, -0.55, t1.ResourceAlloc.FundingPnLUSD, 1e-9)
+	assert.Len(t, persist.funding, 2)
+}
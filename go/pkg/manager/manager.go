@@ -70,8 +70,8 @@ func (f *BasicExecutorFactory) NewExecutor(traderCfg TraderConfig) (executorpkg.
 		MaxPositions:           traderCfg.RiskParams.MaxPositions,
 		DecisionIntervalRaw:    intervalRaw,
 		DecisionInterval:       interval,
-		DecisionTimeoutRaw:     "60s",
-		DecisionTimeout:        60 * time.Second,
+		DecisionTimeoutRaw:     decisionTimeout.String(),
+		DecisionTimeout:        decisionTimeout,
 		MaxConcurrentDecisions: 1,
 		AllowedTraderIDs:       []string{traderCfg.ID},
 	}
@@ -104,6 +104,44 @@ type Manager struct {
 	executorFactory ExecutorFactory
 	persistence     PersistenceService
 
+	// Exchange event stream bookkeeping (see events.go).
+	eventMu       sync.Mutex
+	eventStreams  map[string]bool      // account key -> stream running
+	eventRetry    map[string]time.Time // account key -> next subscribe attempt after a failure
+	managed       map[string]struct{}  // cloids and oids of manager-submitted orders
+	managedOrder  []string
+	inFlight      map[string]int              // provider|coin -> submissions awaiting a response
+	deferredFills map[string][]exchange.Event // provider|coin -> close fills held until then
+	symbolOwners  map[string]string           // provider|coin -> trader ID
+
+	// Lifecycle of manager-submitted orders by cloid (see orders.go).
+	orders *exchange.OrderTracker
+
+	// Funding sync cursors (see funding.go), guarded by eventMu.
+	fundingCursor  map[string]time.Time        // trader ID -> next since
+	fundingApplied map[string]map[string]int64 // trader ID -> fundingKey -> payment time (ms)
+
+	// Dead-man's switch refresh times per exchange provider (see deadman.go).
+	deadManMu   sync.Mutex
+	deadManSent map[string]time.Time
+	deadManErr  map[string]bool
+
+	// Last signing credential expiry check per provider (see credentials.go).
+	credentialMu      sync.Mutex
+	credentialChecked map[string]time.Time
+
+	// Running TWAPs by order cloid, for their order records (see twap.go).
+	twapMu     sync.Mutex
+	twapOrders map[string]*twapExecution
+
+	// Last request budget sync per exchange account (see ratelimit.go).
+	rateLimitMu     sync.Mutex
+	rateLimitSynced map[string]time.Time
+
+	// Last sub-account funding pass (see rebalance.go).
+	rebalanceMu   sync.Mutex
+	lastRebalance time.Time
+
 	stopChan chan struct{}
 	stopOnce sync.Once
 	wg       sync.WaitGroup
@@ -130,8 +168,22 @@ func NewManager(
 		marketProviders:   make(map[string]market.Provider),
 		executorFactory:   execFactory,
 		persistence:       persist,
+		eventStreams:      make(map[string]bool),
+		eventRetry:        make(map[string]time.Time),
+		managed:           make(map[string]struct{}),
+		inFlight:          make(map[string]int),
+		deferredFills:     make(map[string][]exchange.Event),
+		symbolOwners:      make(map[string]string),
+		fundingCursor:     make(map[string]time.Time),
+		fundingApplied:    make(map[string]map[string]int64),
+		deadManSent:       make(map[string]time.Time),
+		deadManErr:        make(map[string]bool),
+		credentialChecked: make(map[string]time.Time),
+		rateLimitSynced:   make(map[string]time.Time),
+		twapOrders:        make(map[string]*twapExecution),
 		stopChan:          make(chan struct{}),
 	}
+	m.orders = exchange.NewOrderTracker(exchange.WithOrderListener(m.recordOrderUpdate))
 	for k, v := range exch {
 		m.exchangeProviders[k] = v
 	}
@@ -184,6 +236,23 @@ func (m *Manager) RegisterTrader(cfg TraderConfig) (*VirtualTrader, error) {
 	if !ok {
 		return nil, fmt.Errorf("manager: unknown exchange provider %q for trader %s", cfg.ExchangeProvider, cfg.ID)
 	}
+	if err := checkProviderCapabilities(cfg, exchange.CapabilitiesOf(ex)); err != nil {
+		return nil, err
+	}
+	// Providers with sub-accounts give each trader an isolated ledger funded
+	// with its share of the total equity.
+	var subAccount string
+	allocated := 0.0
+	if m.config != nil {
+		allocated = m.config.Manager.TotalEquityUSD * cfg.AllocationPct / 100
+	}
+	if sub, ok := ex.(exchange.SubAccountProvider); ok && exchange.CapabilitiesOf(ex).SubAccounts {
+		account, err := sub.SubAccount(context.Background(), cfg.ID, allocated)
+		if err != nil {
+			return nil, fmt.Errorf("manager: open sub-account for trader %s: %w", cfg.ID, err)
+		}
+		ex, subAccount = account, cfg.ID
+	}
 	mk, ok := m.marketProviders[cfg.MarketProvider]
 	if !ok {
 		return nil, fmt.Errorf("manager: unknown market provider %q for trader %s", cfg.MarketProvider, cfg.ID)
@@ -200,16 +269,21 @@ func (m *Manager) RegisterTrader(cfg TraderConfig) (*VirtualTrader, error) {
 		ID:                   cfg.ID,
 		Name:                 cfg.Name,
 		Exchange:             cfg.ExchangeProvider,
+		SubAccount:           subAccount,
 		ExchangeProvider:     ex,
 		MarketProvider:       mk,
 		Executor:             exec,
 		PromptTemplate:       cfg.PromptTemplate,
 		OrderStyle:           cfg.OrderStyle,
 		MarketIOCSlippageBps: cfg.MarketIOCSlippageBps,
+		TWAPDuration:         cfg.TWAPDuration,
+		TWAPRandomize:        cfg.TWAPRandomize,
+		MarginMode:           cfg.MarginMode,
 		RiskParams:           cfg.RiskParams,
 		ExecGuards:           cfg.ExecGuards,
 		ResourceAlloc: ResourceAllocation{
-			AllocationPct: cfg.AllocationPct,
+			AllocatedEquityUSD: allocated,
+			AllocationPct:      cfg.AllocationPct,
 		},
 		State:            TraderStateStopped,
 		DecisionInterval: cfg.DecisionInterval,
@@ -231,7 +305,7 @@ func (m *Manager) RegisterTrader(cfg TraderConfig) (*VirtualTrader, error) {
 	if cfg.AutoStart {
 		_ = vt.Start()
 	}
-	logx.Infof("manager: registered trader id=%s name=%s allocation=%.2f%% exchange=%s market=%s model=%s order_style=%s auto_start=%t", vt.ID, vt.Name, cfg.AllocationPct, cfg.ExchangeProvider, cfg.MarketProvider, cfg.Model, cfg.OrderStyle, cfg.AutoStart)
+	logx.Infof("manager: registered trader id=%s name=%s allocation=%.2f%% exchange=%s market=%s model=%s order_style=%s margin_mode=%s auto_start=%t", vt.ID, vt.Name, cfg.AllocationPct, cfg.ExchangeProvider, cfg.MarketProvider, cfg.Model, cfg.OrderStyle, cfg.MarginMode, cfg.AutoStart)
 	return vt, nil
 }
 
@@ -269,6 +343,10 @@ func (m *Manager) RunTradingLoop(ctx context.Context) error {
 		return errors.New("manager: nil manager")
 	}
 	logx.WithContext(ctx).Infof("manager: trading loop starting tick=1s active_traders=%d", len(m.GetActiveTraders()))
+	m.startEventStreams(ctx)
+	go m.runDeadManSwitch(ctx)
+	go m.runMarkFeed(ctx)
+	go m.runCredentialExpiryCheck(ctx)
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -281,6 +359,8 @@ func (m *Manager) RunTradingLoop(ctx context.Context) error {
 			logx.WithContext(ctx).Infof("manager: trading loop stopping (stop signal)")
 			return nil
 		case <-ticker.C:
+			m.startEventStreams(ctx)
+			m.rebalanceAllocations(ctx)
 			traders := m.GetActiveTraders()
 			for _, t := range traders {
 				if !t.ShouldMakeDecision() {
@@ -378,14 +458,15 @@ func (m *Manager) RunTradingLoop(ctx context.Context) error {
 				}
 				t.Performance.UpdatedAt = time.Now()
 				m.recordAnalytics(AnalyticsSnapshot{
-					TraderID:       t.ID,
-					TotalPnLUSD:    t.Performance.TotalPnLUSD,
-					TotalPnLPct:    t.Performance.TotalPnLPct,
-					SharpeRatio:    t.Performance.SharpeRatio,
-					WinRate:        t.Performance.WinRate,
-					TotalTrades:    t.Performance.TotalTrades,
-					MaxDrawdownPct: t.Performance.MaxDrawdownPct,
-					UpdatedAt:      t.Performance.UpdatedAt,
+					TraderID:        t.ID,
+					TotalPnLUSD:     t.Performance.TotalPnLUSD,
+					TotalPnLPct:     t.Performance.TotalPnLPct,
+					SharpeRatio:     t.Performance.SharpeRatio,
+					WinRate:         t.Performance.WinRate,
+					TotalTrades:     t.Performance.TotalTrades,
+					MaxDrawdownPct:  t.Performance.MaxDrawdownPct,
+					TotalFundingUSD: t.ResourceAlloc.FundingPnLUSD,
+					UpdatedAt:       t.Performance.UpdatedAt,
 				})
 
 				// Journal the cycle if configured
@@ -432,22 +513,26 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		var closeSnapPrice float64
-		if setter, ok := trader.ExchangeProvider.(interface {
-			SetMarkPrice(context.Context, string, float64) error
-		}); ok {
+		if setter, ok := trader.ExchangeProvider.(exchange.MarkPriceSetter); ok {
 			if snap, err := trader.MarketProvider.Snapshot(ctx, decision.Symbol); err == nil && snap != nil && snap.Price.Last > 0 {
 				closeSnapPrice = snap.Price.Last
 				_ = setter.SetMarkPrice(ctx, decision.Symbol, snap.Price.Last)
+				feedFundingRate(ctx, trader, decision.Symbol, snap)
 			}
 		}
+		// Stop any running TWAP first so it does not reopen the position.
+		m.cancelTWAP(ctx, trader, decision.Symbol)
 		// Attempt to cancel resting orders via optional extension
-		if p, ok := trader.ExchangeProvider.(interface {
-			CancelAllBySymbol(context.Context, string) error
-		}); ok {
+		if p, ok := trader.ExchangeProvider.(exchange.SymbolCanceller); ok {
 			_ = p.CancelAllBySymbol(ctx, decision.Symbol)
 		}
+		closeSubmittedAt := time.Now()
+		closeCloid := m.trackOrder(trader, decision.Symbol, decision.Action, decision.Action == "close_short", exchange.Decimal{}, "", "")
+		finish := m.beginSubmission(trader.accountKey(), decision.Symbol, closeCloid)
 		orderResp, err := trader.ExchangeProvider.ClosePosition(ctx, decision.Symbol)
+		finish(orderResp)
 		if err != nil {
+			m.applyOrderResult(closeCloid, orderResp, err, nil)
 			return err
 		}
 		logx.Infof("manager: trader %s closed position symbol=%s action=%s", trader.ID, decision.Symbol, decision.Action)
@@ -465,6 +550,9 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 		if fillQty <= 0 && fillPrice > 0 && decision.PositionSizeUSD > 0 {
 			fillQty = decision.PositionSizeUSD / fillPrice
 		}
+		fills := orderFills(ctx, trader, orderResp, closeSubmittedAt)
+		m.applyOrderResult(closeCloid, orderResp, nil, fills)
+		fillPrice, fillQty = applyFills(fills, fillPrice, fillQty)
 		m.recordPositionEvent(PositionEvent{
 			TraderID:         trader.ID,
 			Trader:           trader,
@@ -473,6 +561,7 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 			ExchangeResponse: orderResp,
 			FillPrice:        fillPrice,
 			FillSize:         fillQty,
+			Fills:            fills,
 			OccurredAt:       time.Now(),
 		})
 		return nil
@@ -499,41 +588,68 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	if lev <= 0 {
+		return fmt.Errorf("manager: no leverage for %s: set risk_params leverage or the decision's", decision.Symbol)
+	}
 	assetIdx, err := trader.ExchangeProvider.GetAssetIndex(ctx, decision.Symbol)
-	if err == nil && lev > 0 {
-		_ = trader.ExchangeProvider.UpdateLeverage(ctx, assetIdx, true, lev)
+	if err != nil {
+		return fmt.Errorf("manager: asset index %s: %w", decision.Symbol, err)
+	}
+	isCross, err := useCrossMargin(ctx, trader, decision.Symbol)
+	if err != nil {
+		return err
+	}
+	if err := trader.ExchangeProvider.UpdateLeverage(ctx, assetIdx, isCross, lev); err != nil && !isCross {
+		// An isolated asset opened on cross margin would be rejected or
+		// mis-margined; do not submit the order.
+		return fmt.Errorf("manager: set isolated leverage %s: %w", decision.Symbol, err)
 	}
 
-	// Determine price: use decision price or query market snapshot.
+	// The mark always comes from market data. The decision's entry price is
+	// only the order price, so a risk gateway's price band checks it against
+	// the market rather than against itself.
+	var mark float64
+	snap, snapErr := trader.MarketProvider.Snapshot(ctx, decision.Symbol)
+	if snapErr == nil && snap != nil {
+		mark = snap.Price.Last
+		feedFundingRate(ctx, trader, decision.Symbol, snap)
+	}
+	// Determine price: use decision price or the market snapshot.
 	price := decision.EntryPrice
 	if !(price > 0) {
-		snap, err := trader.MarketProvider.Snapshot(ctx, decision.Symbol)
-		if err != nil {
-			return fmt.Errorf("manager: fetch market snapshot for %s: %w", decision.Symbol, err)
+		if snapErr != nil {
+			return fmt.Errorf("manager: fetch market snapshot for %s: %w", decision.Symbol, snapErr)
 		}
-		price = snap.Price.Last
+		price = mark
 	}
 	if !(price > 0) {
 		return fmt.Errorf("manager: invalid price resolved for %s", decision.Symbol)
 	}
-
-	// Compute size and direction.
-	if setter, ok := trader.ExchangeProvider.(interface {
-		SetMarkPrice(context.Context, string, float64) error
-	}); ok {
-		if err := setter.SetMarkPrice(ctx, decision.Symbol, price); err != nil {
-			logx.WithContext(ctx).Errorf("manager: set mark price trader=%s symbol=%s err=%v", trader.ID, decision.Symbol, err)
+	if mark > 0 {
+		if setter, ok := trader.ExchangeProvider.(exchange.MarkPriceSetter); ok {
+			if err := setter.SetMarkPrice(ctx, decision.Symbol, mark); err != nil {
+				logx.WithContext(ctx).Errorf("manager: set mark price trader=%s symbol=%s err=%v", trader.ID, decision.Symbol, err)
+			}
 		}
+	} else if snapErr != nil {
+		logx.WithContext(ctx).Errorf("manager: no market mark trader=%s symbol=%s err=%v", trader.ID, decision.Symbol, snapErr)
 	}
 
-	qty := decision.PositionSizeUSD / price
-	if qty <= 0 || math.IsNaN(qty) || math.IsInf(qty, 0) {
-		return fmt.Errorf("manager: invalid position size for %s: qty=%.6f", decision.Symbol, qty)
+	// Compute size and direction. The size stays a Decimal on the venue's
+	// lot step, so the wire string, the tracked order and what is recorded
+	// agree exactly.
+	size, err := orderSize(ctx, trader, decision.Symbol, decision.PositionSizeUSD, price)
+	if err != nil {
+		return err
 	}
+	sizeStr := size.String()
+	qty := size.Float64()
 	isBuy := decision.Action == "open_long"
-	priceStr := fmt.Sprintf("%.8f", price)
-	sizeStr := fmt.Sprintf("%.8f", qty)
+	// Fallback price string when the provider cannot format for the venue.
+	priceStr := exchange.DecimalFromFloat(price).Round(8, exchange.RoundHalfUp).String()
 	var orderResp *exchange.OrderResponse
+	var tracked string // cloid of the tracked order, see orders.go
+	submittedAt := time.Now()
 
 	switch trader.OrderStyle {
 	case OrderStyleMarketIOC:
@@ -541,42 +657,35 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 		if slippage <= 0 {
 			slippage = defaultMarketIOCSlippageBps / 10000.0
 		}
-		execProvider, ok := trader.ExchangeProvider.(interface {
-			IOCMarket(context.Context, string, bool, float64, float64, bool) (*exchange.OrderResponse, error)
-		})
-		if !ok {
+		execProvider, ok := trader.ExchangeProvider.(exchange.MarketOrderer)
+		if !ok || !exchange.CapabilitiesOf(trader.ExchangeProvider).MarketOrders {
 			return fmt.Errorf("manager: trader %s order_style=market_ioc unsupported by exchange provider", trader.ID)
 		}
 		logx.WithContext(ctx).Infof(
-			"manager: trader %s prepared market_ioc order symbol=%s is_buy=%t raw_price=%.8f raw_qty=%.8f asset_idx=%d leverage=%d",
-			trader.ID, decision.Symbol, isBuy, price, qty, assetIdx, lev,
+			"manager: trader %s prepared market_ioc order symbol=%s is_buy=%t raw_price=%.8f size_str=%s asset_idx=%d leverage=%d",
+			trader.ID, decision.Symbol, isBuy, price, sizeStr, assetIdx, lev,
 		)
+		// IOCMarket does not take a cloid, so this one stays local and the
+		// order is matched by the oid in its response.
+		tracked = m.trackOrder(trader, decision.Symbol, decision.Action, isBuy, size, "", "")
+		finish := m.beginSubmission(trader.accountKey(), decision.Symbol, tracked)
 		resp, err := execProvider.IOCMarket(ctx, decision.Symbol, isBuy, qty, slippage, false)
+		finish(resp)
 		if err != nil {
+			m.applyOrderResult(tracked, resp, err, nil)
 			return fmt.Errorf("manager: market_ioc order %s %s: %w", decision.Symbol, decision.Action, err)
 		}
 		orderResp = resp
 		summary := summarizeOrderResponse(resp)
-		logx.Infof("manager: trader %s submitted market_ioc order symbol=%s notional=%.2f usd qty=%.6f slippage_bps=%.2f response=%s", trader.ID, decision.Symbol, decision.PositionSizeUSD, qty, trader.MarketIOCSlippageBps, summary)
+		logx.Infof("manager: trader %s submitted market_ioc order symbol=%s notional=%.2f usd qty=%s slippage_bps=%.2f response=%s", trader.ID, decision.Symbol, decision.PositionSizeUSD, sizeStr, trader.MarketIOCSlippageBps, summary)
 	case OrderStyleLimitIOC, "":
-		if p, ok := trader.ExchangeProvider.(interface {
-			FormatPrice(context.Context, string, float64) (string, error)
-		}); ok {
+		if p, ok := trader.ExchangeProvider.(exchange.Formatter); ok {
 			if s, err := p.FormatPrice(ctx, decision.Symbol, price); err == nil && s != "" {
 				priceStr = s
 			} else if err != nil {
 				logx.WithContext(ctx).Infof("manager: format price fallback trader=%s symbol=%s price=%.8f err=%v", trader.ID, decision.Symbol, price, err)
 			}
 		}
-		if p, ok := trader.ExchangeProvider.(interface {
-			FormatSize(context.Context, string, float64) (string, error)
-		}); ok {
-			if s, err := p.FormatSize(ctx, decision.Symbol, qty); err == nil && s != "" {
-				sizeStr = s
-			} else if err != nil {
-				logx.WithContext(ctx).Infof("manager: format size fallback trader=%s symbol=%s qty=%.8f err=%v", trader.ID, decision.Symbol, qty, err)
-			}
-		}
 
 		cloid := buildCloid(trader.ID, decision.Symbol, decision.Action, qty, time.Now())
 		order := exchange.Order{
@@ -589,45 +698,81 @@ func (m *Manager) ExecuteDecision(trader *VirtualTrader, decision *executorpkg.D
 			Cloid:      cloid,
 		}
 		logx.WithContext(ctx).Infof(
-			"manager: trader %s prepared limit_ioc order symbol=%s is_buy=%t raw_price=%.8f price_str=%s raw_qty=%.8f size_str=%s asset_idx=%d leverage=%d",
-			trader.ID, decision.Symbol, isBuy, price, priceStr, qty, sizeStr, assetIdx, lev,
+			"manager: trader %s prepared limit_ioc order symbol=%s is_buy=%t raw_price=%.8f price_str=%s size_str=%s asset_idx=%d leverage=%d",
+			trader.ID, decision.Symbol, isBuy, price, priceStr, sizeStr, assetIdx, lev,
 		)
+		tracked = m.trackOrder(trader, decision.Symbol, decision.Action, isBuy, size, priceStr, cloid)
+		finish := m.beginSubmission(trader.accountKey(), decision.Symbol, cloid)
 		resp, err := trader.ExchangeProvider.PlaceOrder(ctx, order)
+		finish(resp)
 		if err != nil {
+			m.applyOrderResult(tracked, resp, err, nil)
 			return fmt.Errorf("manager: place order %s %s: %w", decision.Symbol, decision.Action, err)
 		}
 		orderResp = resp
 		summary := summarizeOrderResponse(resp)
-		logx.Infof("manager: trader %s submitted limit_ioc order symbol=%s notional=%.2f usd qty=%.6f cloid=%s response=%s", trader.ID, decision.Symbol, decision.PositionSizeUSD, qty, cloid, summary)
+		logx.Infof("manager: trader %s submitted limit_ioc order symbol=%s notional=%.2f usd qty=%s cloid=%s response=%s", trader.ID, decision.Symbol, decision.PositionSizeUSD, sizeStr, cloid, summary)
+	case OrderStyleTWAP:
+		logx.WithContext(ctx).Infof(
+			"manager: trader %s prepared twap order symbol=%s is_buy=%t size_str=%s duration=%s randomize=%t leverage=%d",
+			trader.ID, decision.Symbol, isBuy, sizeStr, trader.TWAPDuration, trader.TWAPRandomize, lev,
+		)
+		tracked = m.trackOrder(trader, decision.Symbol, decision.Action, isBuy, size, "", "")
+		status, err := m.placeTWAP(ctx, trader, decision, isBuy, sizeStr, tracked)
+		if err != nil {
+			return fmt.Errorf("manager: twap order %s %s: %w", decision.Symbol, decision.Action, err)
+		}
+		m.setSymbolOwner(trader.accountKey(), decision.Symbol, trader.ID)
+		// Slices fill over the TWAP duration, so syncTWAPs records the open
+		// and places SL/TP from the executed size rather than the request.
+		logx.Infof("manager: trader %s submitted twap order symbol=%s notional=%.2f usd qty=%s twap_id=%d minutes=%d", trader.ID, decision.Symbol, decision.PositionSizeUSD, sizeStr, status.TwapID, status.Minutes)
+		return nil
 	default:
 		return fmt.Errorf("manager: trader %s unsupported order_style=%s", trader.ID, trader.OrderStyle)
 	}
-	// Configure reduce-only SL/TP best-effort
-	side := "LONG"
-	if !isBuy { // open_short
-		side = "SHORT"
-	}
-	// Best-effort SL/TP via optional provider extension.
-	if p, ok := trader.ExchangeProvider.(interface {
-		SetStopLoss(context.Context, string, string, float64, float64) error
-		SetTakeProfit(context.Context, string, string, float64, float64) error
-	}); ok {
-		_ = p.SetStopLoss(ctx, decision.Symbol, side, qty, decision.StopLoss)
-		_ = p.SetTakeProfit(ctx, decision.Symbol, side, qty, decision.TakeProfit)
-	}
+	m.setSymbolOwner(trader.accountKey(), decision.Symbol, trader.ID)
+	m.placeProtectiveOrders(ctx, trader, decision, isBuy, qty)
+	fills := orderFills(ctx, trader, orderResp, submittedAt)
+	m.applyOrderResult(tracked, orderResp, nil, fills)
+	fillPrice, fillQty := applyFills(fills, price, qty)
 	m.recordPositionEvent(PositionEvent{
 		TraderID:         trader.ID,
 		Trader:           trader,
 		Decision:         *decision,
 		Event:            PositionEventOpen,
 		ExchangeResponse: orderResp,
-		FillPrice:        price,
-		FillSize:         qty,
+		FillPrice:        fillPrice,
+		FillSize:         fillQty,
+		Fills:            fills,
 		OccurredAt:       time.Now(),
 	})
 	return nil
 }
 
+// placeProtectiveOrders configures reduce-only SL/TP for an opened position
+// of qty, best-effort.
+func (m *Manager) placeProtectiveOrders(ctx context.Context, trader *VirtualTrader, decision *executorpkg.Decision, isBuy bool, qty float64) {
+	side := "LONG"
+	if !isBuy { // open_short
+		side = "SHORT"
+	}
+	// RegisterTrader guarantees the capability when enabled.
+	if p, ok := trader.ExchangeProvider.(exchange.ProtectiveOrderer); ok && exchange.CapabilitiesOf(trader.ExchangeProvider).ProtectiveOrders {
+		if decision.StopLoss > 0 {
+			if err := p.SetStopLoss(ctx, decision.Symbol, side, qty, decision.StopLoss); err != nil {
+				logx.WithContext(ctx).Errorf("manager: set stop loss trader=%s symbol=%s px=%.8f err=%v", trader.ID, decision.Symbol, decision.StopLoss, err)
+			}
+		}
+		if decision.TakeProfit > 0 {
+			if err := p.SetTakeProfit(ctx, decision.Symbol, side, qty, decision.TakeProfit); err != nil {
+				logx.WithContext(ctx).Errorf("manager: set take profit trader=%s symbol=%s px=%.8f err=%v", trader.ID, decision.Symbol, decision.TakeProfit, err)
+			}
+		}
+	} else if trader.RiskParams.StopLossEnabled || trader.RiskParams.TakeProfitEnabled {
+		logx.WithContext(ctx).Errorf("manager: trader %s has stop loss/take profit enabled but provider lacks protective orders", trader.ID)
+	}
+}
+
 // SyncAllPositions updates cached account/position state for all traders (stub).
 func (m *Manager) SyncAllPositions() error {
 	m.mu.RLock()
@@ -652,10 +797,15 @@ func (m *Manager) SyncTraderPositions(traderID string) error {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	m.feedPositionMarks(ctx, t)
 	acct, err := t.ExchangeProvider.GetAccountState(ctx)
 	if err != nil {
 		return err
 	}
+	m.syncRateLimit(ctx, t)
+	m.syncFunding(ctx, t)
+	m.syncTWAPs(ctx, t)
+	m.reconcileOrders(ctx, t)
 	// Parse commonly used fields from strings.
 	acctVal := parseFloat(acct.MarginSummary.AccountValue)
 	marginUsed := parseFloat(acct.MarginSummary.TotalMarginUsed)
@@ -678,23 +828,49 @@ func (m *Manager) SyncTraderPositions(traderID string) error {
 		MarginUsedUSD:       marginUsed,
 		AvailableBalanceUSD: t.ResourceAlloc.AvailableBalanceUSD,
 		UnrealizedPnLUSD:    unreal,
+		FundingUSD:          t.ResourceAlloc.FundingPnLUSD,
 		SyncedAt:            time.Now(),
 	})
 	if t.Performance != nil {
 		m.recordAnalytics(AnalyticsSnapshot{
-			TraderID:       traderID,
-			TotalPnLUSD:    t.Performance.TotalPnLUSD,
-			TotalPnLPct:    t.Performance.TotalPnLPct,
-			SharpeRatio:    t.Performance.SharpeRatio,
-			WinRate:        t.Performance.WinRate,
-			TotalTrades:    t.Performance.TotalTrades,
-			MaxDrawdownPct: t.Performance.MaxDrawdownPct,
-			UpdatedAt:      t.Performance.UpdatedAt,
+			TraderID:        traderID,
+			TotalPnLUSD:     t.Performance.TotalPnLUSD,
+			TotalPnLPct:     t.Performance.TotalPnLPct,
+			SharpeRatio:     t.Performance.SharpeRatio,
+			WinRate:         t.Performance.WinRate,
+			TotalTrades:     t.Performance.TotalTrades,
+			MaxDrawdownPct:  t.Performance.MaxDrawdownPct,
+			TotalFundingUSD: t.ResourceAlloc.FundingPnLUSD,
+			UpdatedAt:       t.Performance.UpdatedAt,
 		})
 	}
 	return nil
 }
 
+// orderSize converts usd at price into a size on the venue's lot step
+// (Formatter.SizeStep, 1e-8 without one), rounded down so the order never
+// exceeds the decision's notional.
+func orderSize(ctx context.Context, trader *VirtualTrader, symbol string, usd, price float64) (exchange.Decimal, error) {
+	step := exchange.NewDecimal(1, -8)
+	if p, ok := trader.ExchangeProvider.(exchange.Formatter); ok {
+		if s, err := p.SizeStep(ctx, symbol); err == nil && s.Sign() > 0 {
+			step = s
+		} else if err != nil {
+			logx.WithContext(ctx).Infof("manager: size step fallback trader=%s symbol=%s err=%v", trader.ID, symbol, err)
+		}
+	}
+	notional, px := exchange.DecimalFromFloat(usd), exchange.DecimalFromFloat(price)
+	if notional.Sign() <= 0 || px.Sign() <= 0 {
+		return exchange.Decimal{}, fmt.Errorf("manager: invalid position size for %s: notional=%.6f price=%.6f", symbol, usd, price)
+	}
+	// Extra places keep the half-up quotient from crossing a step boundary.
+	size := notional.Div(px, step.Places()+12).RoundStep(step, exchange.RoundDown)
+	if size.Sign() <= 0 {
+		return exchange.Decimal{}, fmt.Errorf("manager: position size for %s: %.2f usd at %s is below the lot step %s", symbol, usd, px, step)
+	}
+	return size, nil
+}
+
 func parseFloat(s string) float64 {
 	if s == "" {
 		return 0
@@ -722,6 +898,26 @@ func parsePtrFloat(ps *string) float64 {
 	return parseFloat(*ps)
 }
 
+// checkProviderCapabilities rejects trader configs whose execution settings
+// depend on optional extensions the exchange provider does not implement.
+func checkProviderCapabilities(cfg TraderConfig, caps exchange.Capabilities) error {
+	var missing []string
+	if cfg.OrderStyle == OrderStyleMarketIOC && !caps.MarketOrders {
+		missing = append(missing, fmt.Sprintf("%s (order_style=%s)", exchange.CapabilityMarketOrders, cfg.OrderStyle))
+	}
+	if cfg.OrderStyle == OrderStyleTWAP && !caps.TWAPOrders {
+		missing = append(missing, fmt.Sprintf("%s (order_style=%s)", exchange.CapabilityTWAPOrders, cfg.OrderStyle))
+	}
+	if (cfg.RiskParams.StopLossEnabled || cfg.RiskParams.TakeProfitEnabled) && !caps.ProtectiveOrders {
+		missing = append(missing, fmt.Sprintf("%s (stop_loss_enabled/take_profit_enabled)", exchange.CapabilityProtectiveOrders))
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("manager: exchange provider %q for trader %s lacks required capabilities: %s (supported: %s)",
+		cfg.ExchangeProvider, cfg.ID, strings.Join(missing, ", "), caps)
+}
+
 func isBTCorETH(symbol string) bool {
 	switch symbol {
 	case "BTC", "ETH", "BTCUSDT", "ETHUSDT":
@@ -1034,7 +1230,6 @@ func (m *Manager) buildExecutorContext(t *VirtualTrader) executorpkg.Context {
 				continue
 			}
 			ml := 0.0
-			onlyIso := false
 			if a.RawMetadata != nil {
 				if v, ok := a.RawMetadata["maxLeverage"]; ok {
 					switch x := v.(type) {
@@ -1044,13 +1239,8 @@ func (m *Manager) buildExecutorContext(t *VirtualTrader) executorpkg.Context {
 						ml = float64(x)
 					}
 				}
-				if v, ok := a.RawMetadata["onlyIsolated"]; ok {
-					if b, ok := v.(bool); ok {
-						onlyIso = b
-					}
-				}
 			}
-			assetMeta[a.Symbol] = executorpkg.AssetMeta{MaxLeverage: ml, Precision: a.Precision, OnlyIsolated: onlyIso}
+			assetMeta[a.Symbol] = executorpkg.AssetMeta{MaxLeverage: ml, Precision: a.Precision, OnlyIsolated: assetOnlyIsolated(a)}
 		}
 	}
 
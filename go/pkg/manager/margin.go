@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/market"
+)
+
+// assetListTTL is how long a trader's cached asset list is used for margin
+// mode lookups before it is fetched again.
+const assetListTTL = 10 * time.Minute
+
+// useCrossMargin reports whether an opening order on symbol should be
+// margined cross: only when the trader is configured for cross margin and the
+// venue allows cross margin on the asset. It fails when the asset list cannot
+// be fetched, rather than assuming cross margin.
+func useCrossMargin(ctx context.Context, trader *VirtualTrader, symbol string) (bool, error) {
+	if trader.MarginMode == MarginModeIsolated {
+		return false, nil
+	}
+	if trader.MarketProvider == nil {
+		return true, nil
+	}
+	assets, err := traderAssets(ctx, trader)
+	if err != nil {
+		return false, fmt.Errorf("manager: list assets for margin mode %s: %w", symbol, err)
+	}
+	for _, a := range assets {
+		if strings.EqualFold(a.Symbol, symbol) {
+			return !assetOnlyIsolated(a), nil
+		}
+	}
+	return true, nil
+}
+
+// traderAssets returns the trader's market assets, cached for assetListTTL.
+// A stale list is used when refreshing it fails.
+func traderAssets(ctx context.Context, trader *VirtualTrader) ([]market.Asset, error) {
+	trader.mu.RLock()
+	assets, fetched := trader.assets, trader.assetsFetched
+	trader.mu.RUnlock()
+	if assets != nil && time.Since(fetched) < assetListTTL {
+		return assets, nil
+	}
+	fresh, err := trader.MarketProvider.ListAssets(ctx)
+	if err != nil {
+		if assets != nil {
+			logx.WithContext(ctx).Infof("manager: refresh assets trader=%s err=%v; using list from %s", trader.ID, err, fetched.Format(time.RFC3339))
+			return assets, nil
+		}
+		return nil, err
+	}
+	trader.mu.Lock()
+	trader.assets, trader.assetsFetched = fresh, time.Now()
+	trader.mu.Unlock()
+	return fresh, nil
+}
+
+// assetOnlyIsolated reads the venue's onlyIsolated flag from asset metadata.
+func assetOnlyIsolated(a market.Asset) bool {
+	if a.RawMetadata == nil {
+		return false
+	}
+	b, _ := a.RawMetadata["onlyIsolated"].(bool)
+	return b
+}
+
+// AdjustIsolatedMargin tops up (positive amountUSD) or reduces (negative) the
+// margin posted for a trader's isolated position on symbol. It fails when the
+// exchange provider cannot move isolated margin or the trader has no isolated
+// position on symbol.
+func (m *Manager) AdjustIsolatedMargin(ctx context.Context, traderID, symbol string, amountUSD float64) error {
+	m.mu.RLock()
+	trader := m.traders[traderID]
+	m.mu.RUnlock()
+	if trader == nil {
+		return fmt.Errorf("manager: adjust isolated margin: trader %s not found", traderID)
+	}
+	if amountUSD == 0 || math.IsNaN(amountUSD) || math.IsInf(amountUSD, 0) {
+		return fmt.Errorf("manager: adjust isolated margin: invalid amount %v", amountUSD)
+	}
+	updater, ok := trader.ExchangeProvider.(exchange.IsolatedMarginUpdater)
+	if !ok || !exchange.CapabilitiesOf(trader.ExchangeProvider).IsolatedMargin {
+		return fmt.Errorf("manager: trader %s exchange provider lacks %s", trader.ID, exchange.CapabilityIsolatedMargin)
+	}
+	positions, err := trader.ExchangeProvider.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("manager: adjust isolated margin: get positions: %w", err)
+	}
+	var target *exchange.Position
+	for i := range positions {
+		if strings.EqualFold(positions[i].Coin, symbol) && parseFloat(positions[i].Szi) != 0 {
+			target = &positions[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("manager: adjust isolated margin: trader %s has no position on %s", trader.ID, symbol)
+	}
+	if !strings.EqualFold(target.Leverage.Type, string(MarginModeIsolated)) {
+		return fmt.Errorf("manager: adjust isolated margin: %s position of trader %s is %s margined", symbol, trader.ID, target.Leverage.Type)
+	}
+	assetIdx, err := trader.ExchangeProvider.GetAssetIndex(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("manager: adjust isolated margin: asset index %s: %w", symbol, err)
+	}
+	isLong := parseFloat(target.Szi) > 0
+	if err := updater.UpdateIsolatedMargin(ctx, assetIdx, isLong, amountUSD); err != nil {
+		return fmt.Errorf("manager: adjust isolated margin %s: %w", symbol, err)
+	}
+	logx.WithContext(ctx).Infof("manager: trader %s adjusted isolated margin symbol=%s amount=%.2f usd", trader.ID, symbol, amountUSD)
+	return nil
+}
@@ -0,0 +1,157 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+// marginSim records isolated margin transfers on top of the simulator.
+type marginSim struct {
+	*sim.Provider
+	transfers []isolatedTransfer
+}
+
+type isolatedTransfer struct {
+	asset  int
+	isBuy  bool
+	amount float64
+}
+
+func (s *marginSim) UpdateIsolatedMargin(ctx context.Context, asset int, isBuy bool, amountUSD float64) error {
+	s.transfers = append(s.transfers, isolatedTransfer{asset: asset, isBuy: isBuy, amount: amountUSD})
+	return nil
+}
+
+// Capabilities reports the extensions added on top of the simulator.
+func (s *marginSim) Capabilities() exchange.Capabilities { return exchange.DetectCapabilities(s) }
+
+// isolatedOnlyMarket lists XYZ as an onlyIsolated asset.
+type isolatedOnlyMarket struct{ fixedPriceMarket }
+
+func (isolatedOnlyMarket) ListAssets(ctx context.Context) ([]market.Asset, error) {
+	return []market.Asset{
+		{Symbol: "BTC", RawMetadata: map[string]any{"onlyIsolated": false}},
+		{Symbol: "XYZ", RawMetadata: map[string]any{"onlyIsolated": true}},
+	}, nil
+}
+
+func marginTypeOf(t *testing.T, p exchange.Provider, coin string) string {
+	t.Helper()
+	positions, err := p.GetPositions(context.Background())
+	require.NoError(t, err)
+	for _, pos := range positions {
+		if pos.Coin == coin {
+			return pos.Leverage.Type
+		}
+	}
+	t.Fatalf("no %s position", coin)
+	return ""
+}
+
+func TestExecuteDecisionMarginMode(t *testing.T) {
+	venue := &marginSim{Provider: sim.New()}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: venue,
+		MarketProvider:   isolatedOnlyMarket{fixedPriceMarket{price: 100}},
+		OrderStyle:       OrderStyleMarketIOC,
+		MarginMode:       MarginModeCross,
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		Cooldown:         map[string]time.Time{},
+	}
+	m := newEventTestManager(&capturePersistence{}, trader)
+
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 100, PositionSizeUSD: 200}))
+	assert.Equal(t, "cross", marginTypeOf(t, venue, "BTC"))
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "XYZ", Action: "open_short", EntryPrice: 100, PositionSizeUSD: 200}))
+	assert.Equal(t, "isolated", marginTypeOf(t, venue, "XYZ"), "onlyIsolated assets ignore cross mode")
+
+	trader.MarginMode = MarginModeIsolated
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "ETH", Action: "open_long", EntryPrice: 100, PositionSizeUSD: 200}))
+	assert.Equal(t, "isolated", marginTypeOf(t, venue, "ETH"))
+}
+
+// flakyAssetsMarket counts asset listings and fails them on demand.
+type flakyAssetsMarket struct {
+	fixedPriceMarket
+	calls int
+	err   error
+}
+
+func (f *flakyAssetsMarket) ListAssets(ctx context.Context) ([]market.Asset, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []market.Asset{{Symbol: "BTC"}}, nil
+}
+
+func TestExecuteDecisionMarginModeLookup(t *testing.T) {
+	mkt := &flakyAssetsMarket{fixedPriceMarket: fixedPriceMarket{price: 100}, err: errors.New("meta unavailable")}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: sim.New(),
+		MarketProvider:   mkt,
+		OrderStyle:       OrderStyleMarketIOC,
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		Cooldown:         map[string]time.Time{},
+	}
+	m := newEventTestManager(&capturePersistence{}, trader)
+	open := func(lev int) error {
+		return m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 100, PositionSizeUSD: 200, Leverage: lev})
+	}
+
+	assert.ErrorContains(t, open(0), "list assets", "a failed lookup does not fall back to cross margin")
+	mkt.err = nil
+	require.NoError(t, open(0))
+	require.NoError(t, open(0))
+	assert.Equal(t, 2, mkt.calls, "the asset list is cached")
+
+	trader.RiskParams = RiskParameters{}
+	assert.ErrorContains(t, open(0), "no leverage")
+}
+
+func TestAdjustIsolatedMargin(t *testing.T) {
+	venue := &marginSim{Provider: sim.New()}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: venue,
+		MarketProvider:   isolatedOnlyMarket{fixedPriceMarket{price: 100}},
+		OrderStyle:       OrderStyleMarketIOC,
+		MarginMode:       MarginModeCross,
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		Cooldown:         map[string]time.Time{},
+	}
+	m := newEventTestManager(&capturePersistence{}, trader)
+	ctx := context.Background()
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 100, PositionSizeUSD: 200}))
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "XYZ", Action: "open_short", EntryPrice: 100, PositionSizeUSD: 200}))
+
+	require.NoError(t, m.AdjustIsolatedMargin(ctx, "t1", "XYZ", 25))
+	require.NoError(t, m.AdjustIsolatedMargin(ctx, "t1", "xyz", -10))
+	xyz, err := venue.GetAssetIndex(ctx, "XYZ")
+	require.NoError(t, err)
+	assert.Equal(t, []isolatedTransfer{{asset: xyz, isBuy: false, amount: 25}, {asset: xyz, isBuy: false, amount: -10}}, venue.transfers)
+
+	assert.ErrorContains(t, m.AdjustIsolatedMargin(ctx, "t1", "BTC", 25), "cross margined")
+	assert.ErrorContains(t, m.AdjustIsolatedMargin(ctx, "t1", "ETH", 25), "no position")
+	assert.Error(t, m.AdjustIsolatedMargin(ctx, "t1", "XYZ", 0))
+	assert.ErrorContains(t, m.AdjustIsolatedMargin(ctx, "missing", "XYZ", 25), "not found")
+
+	trader.ExchangeProvider = venue.Provider
+	assert.ErrorContains(t, m.AdjustIsolatedMargin(ctx, "t1", "XYZ", 25), exchange.CapabilityIsolatedMargin)
+	assert.Len(t, venue.transfers, 2)
+}
@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// markFeedInterval is how often open positions' marks and funding rates are
+// pushed to providers that take them from the manager.
+const markFeedInterval = 15 * time.Second
+
+// runMarkFeed feeds every trader's open positions from its own ticker, so a
+// simulator's PnL, liquidations, protective triggers and funding accrual
+// follow the market between decision cycles. It returns when ctx is done or
+// the manager stops.
+func (m *Manager) runMarkFeed(ctx context.Context) {
+	ticker := time.NewTicker(markFeedInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.mu.RLock()
+			traders := make([]*VirtualTrader, 0, len(m.traders))
+			for _, t := range m.traders {
+				traders = append(traders, t)
+			}
+			m.mu.RUnlock()
+			sort.Slice(traders, func(i, j int) bool { return traders[i].ID < traders[j].ID })
+			for _, t := range traders {
+				m.feedPositionMarks(ctx, t)
+			}
+		}
+	}
+}
+
+// feedPositionMarks pushes the market mark and funding rate of each of the
+// trader's open positions to exchange providers implementing
+// exchange.MarkPriceSetter, e.g. the simulator or a risk gateway.
+func (m *Manager) feedPositionMarks(ctx context.Context, t *VirtualTrader) {
+	setter, ok := t.ExchangeProvider.(exchange.MarkPriceSetter)
+	if !ok || !exchange.CapabilitiesOf(t.ExchangeProvider).MarkPrice || t.MarketProvider == nil {
+		return
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	positions, err := t.ExchangeProvider.GetPositions(reqCtx)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: feed marks trader=%s get positions err=%v", t.ID, err)
+		return
+	}
+	for _, p := range positions {
+		if parseFloat(p.Szi) == 0 {
+			continue
+		}
+		snap, err := t.MarketProvider.Snapshot(reqCtx, p.Coin)
+		if err != nil || snap == nil || !(snap.Price.Last > 0) {
+			logx.WithContext(ctx).Errorf("manager: feed marks trader=%s symbol=%s no market mark err=%v", t.ID, p.Coin, err)
+			continue
+		}
+		if err := setter.SetMarkPrice(reqCtx, p.Coin, snap.Price.Last); err != nil {
+			logx.WithContext(ctx).Errorf("manager: set mark price trader=%s symbol=%s err=%v", t.ID, p.Coin, err)
+		}
+		feedFundingRate(reqCtx, t, p.Coin, snap)
+	}
+}
@@ -0,0 +1,67 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+// fundingMarket quotes a fixed price and funding rate.
+type fundingMarket struct {
+	fixedPriceMarket
+	rate float64
+}
+
+func (f fundingMarket) Snapshot(ctx context.Context, symbol string) (*market.Snapshot, error) {
+	snap, _ := f.fixedPriceMarket.Snapshot(ctx, symbol)
+	snap.Funding = &market.FundingInfo{Rate: f.rate}
+	return snap, nil
+}
+
+// markRecordingSim records the funding rates fed to the simulator.
+type markRecordingSim struct {
+	*sim.Provider
+	rates map[string]float64
+}
+
+func (s *markRecordingSim) SetFundingRate(ctx context.Context, coin string, rate float64) error {
+	s.rates[coin] = rate
+	return s.Provider.SetFundingRate(ctx, coin, rate)
+}
+
+// Capabilities reports the extensions added on top of the simulator.
+func (s *markRecordingSim) Capabilities() exchange.Capabilities {
+	return exchange.DetectCapabilities(s)
+}
+
+func TestSyncTraderPositionsFeedsSimMarks(t *testing.T) {
+	venue := &markRecordingSim{Provider: sim.New(), rates: map[string]float64{}}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: venue,
+		MarketProvider:   fundingMarket{fixedPriceMarket: fixedPriceMarket{price: 100}},
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		OrderStyle:       OrderStyleMarketIOC,
+		Cooldown:         map[string]time.Time{},
+	}
+	m := newEventTestManager(&capturePersistence{}, trader)
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 100, PositionSizeUSD: 200}))
+
+	trader.MarketProvider = fundingMarket{fixedPriceMarket: fixedPriceMarket{price: 120}, rate: 0.0001}
+	require.NoError(t, m.SyncTraderPositions("t1"))
+
+	positions, err := venue.GetPositions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Greater(t, parseFloat(positions[0].UnrealizedPnl), 0.0, "the open position is marked without a decision")
+	assert.Equal(t, 0.0001, venue.rates["BTC"])
+}
@@ -0,0 +1,100 @@
+package manager
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// trackOrder registers an order the manager is about to submit and returns
+// the cloid it is tracked under, or "" when it could not be tracked (e.g. the
+// same intent was already submitted this minute). size is the size sent to
+// the venue, zero for closes. Orders sent without a venue cloid are matched
+// by the oid in their response.
+func (m *Manager) trackOrder(trader *VirtualTrader, symbol, action string, isBuy bool, size exchange.Decimal, limitPx, cloid string) string {
+	if cloid == "" {
+		cloid = buildCloid(trader.ID, symbol, action, size.Float64(), time.Now())
+	}
+	err := m.orders.Track(exchange.TrackedOrder{
+		Cloid:   cloid,
+		Account: trader.accountKey(),
+		Owner:   trader.ID,
+		Coin:    symbol,
+		IsBuy:   isBuy,
+		Size:    size.Float64(),
+		LimitPx: limitPx,
+	})
+	if err != nil {
+		logx.Errorf("manager: trader %s track order symbol=%s action=%s err=%v", trader.ID, symbol, action, err)
+		return ""
+	}
+	return cloid
+}
+
+// applyOrderResult records the venue's answer, and any fills fetched for
+// it, against the tracked order.
+func (m *Manager) applyOrderResult(cloid string, resp *exchange.OrderResponse, err error, fills []exchange.Fill) {
+	if cloid == "" {
+		return
+	}
+	m.orders.ApplyResponse(cloid, resp, err)
+	for _, fill := range fills {
+		m.orders.ApplyFill(fill)
+	}
+}
+
+// reconcileOrders polls the trader's open orders when it still has orders
+// the tracker considers unfinished.
+func (m *Manager) reconcileOrders(ctx context.Context, t *VirtualTrader) {
+	account := t.accountKey()
+	if !m.orders.HasActive(account) {
+		return
+	}
+	open, err := t.ExchangeProvider.GetOpenOrders(ctx)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: fetch open orders trader=%s err=%v", t.ID, err)
+		return
+	}
+	for _, order := range m.orders.Reconcile(account, open) {
+		logx.Infof("manager: trader %s order cloid=%s oid=%d state=%s filled=%.6f", order.Owner, order.Cloid, order.Oid, order.State, order.FilledSz)
+	}
+}
+
+// Order returns the order the manager submitted under cloid, including
+// market orders and closes whose cloid never reached the venue.
+func (m *Manager) Order(cloid string) (exchange.TrackedOrder, bool) {
+	return m.orders.Get(cloid)
+}
+
+// TraderOrders returns the orders the manager submitted for a trader,
+// oldest first, as far back as the tracker remembers.
+func (m *Manager) TraderOrders(traderID string) []exchange.TrackedOrder {
+	return m.orders.Orders(traderID)
+}
+
+func (m *Manager) recordOrderUpdate(order exchange.TrackedOrder) {
+	if m == nil || m.persistence == nil || strings.TrimSpace(order.Owner) == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	record := OrderRecord{TraderID: order.Owner, Order: order}
+	if order.TwapID != 0 {
+		m.twapMu.Lock()
+		if exec, ok := m.twapOrders[order.Cloid]; ok {
+			record.Leverage = exec.decision.Leverage
+			record.StopLoss, record.TakeProfit = exec.decision.StopLoss, exec.decision.TakeProfit
+		}
+		m.twapMu.Unlock()
+	}
+	err := m.persistence.RecordOrderUpdate(ctx, record)
+	logPersistenceError(err, "order persistence failed", map[string]any{
+		"trader_id": order.Owner,
+		"cloid":     order.Cloid,
+		"state":     order.State,
+	})
+}
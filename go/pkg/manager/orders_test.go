@@ -0,0 +1,159 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// failingCloser rejects every close.
+type failingCloser struct{ exchange.Provider }
+
+func (failingCloser) ClosePosition(ctx context.Context, coin string) (*exchange.OrderResponse, error) {
+	return nil, assert.AnError
+}
+
+func TestExecuteDecisionTracksOrders(t *testing.T) {
+	persist := &capturePersistence{}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: sim.New(),
+		MarketProvider:   fixedPriceMarket{price: 110},
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		OrderStyle:       OrderStyleMarketIOC,
+		Cooldown:         map[string]time.Time{},
+	}
+	m := newEventTestManager(persist, trader)
+
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 100, PositionSizeUSD: 200}))
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "close_long"}))
+
+	orders := m.TraderOrders("t1")
+	require.Len(t, orders, 2)
+	open, closing := orders[0], orders[1]
+	assert.Equal(t, exchange.OrderStateFilled, open.State)
+	assert.True(t, open.IsBuy)
+	assert.NotZero(t, open.Oid)
+	assert.InDelta(t, 2, open.FilledSz, 1e-9)
+	assert.Equal(t, "sim", open.Account)
+	assert.Equal(t, exchange.OrderStateFilled, closing.State)
+	assert.False(t, closing.IsBuy)
+	assert.InDelta(t, 110, closing.AvgPx, 1e-9)
+
+	got, ok := m.Order(open.Cloid)
+	require.True(t, ok)
+	assert.Equal(t, open, got)
+
+	persist.mu.Lock()
+	records := append([]OrderRecord(nil), persist.orders...)
+	persist.mu.Unlock()
+	require.NotEmpty(t, records)
+	assert.Equal(t, "t1", records[0].TraderID)
+	assert.Equal(t, exchange.OrderStatePending, records[0].Order.State)
+	last := records[len(records)-1]
+	assert.Equal(t, closing.Cloid, last.Order.Cloid)
+	assert.Equal(t, exchange.OrderStateFilled, last.Order.State)
+
+	trader.ExchangeProvider = failingCloser{trader.ExchangeProvider}
+	require.Error(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "ETH", Action: "close_short"}))
+	orders = m.TraderOrders("t1")
+	require.Len(t, orders, 3)
+	assert.Equal(t, exchange.OrderStateRejected, orders[2].State)
+	assert.Equal(t, assert.AnError.Error(), orders[2].Error)
+}
+
+func TestExecuteDecisionBandsEntryPriceAgainstMarket(t *testing.T) {
+	persist := &capturePersistence{}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: exchange.NewRiskGateway(sim.New(), exchange.RiskLimits{PriceBandPct: 5}),
+		MarketProvider:   fixedPriceMarket{price: 100},
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		OrderStyle:       OrderStyleLimitIOC,
+		Cooldown:         map[string]time.Time{},
+	}
+	m := newEventTestManager(persist, trader)
+
+	err := m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 150, PositionSizeUSD: 300})
+	require.ErrorIs(t, err, exchange.ErrRiskRejected, "an entry price far from the market is banded against the market mark")
+	assert.Empty(t, persist.snapshot())
+
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 102, PositionSizeUSD: 204}))
+}
+
+// lotSim is a simulator with a coarser lot step that records placed orders.
+type lotSim struct {
+	*sim.Provider
+	step   exchange.Decimal
+	placed []exchange.Order
+}
+
+func (s *lotSim) SizeStep(ctx context.Context, coin string) (exchange.Decimal, error) {
+	return s.step, nil
+}
+
+func (s *lotSim) PlaceOrder(ctx context.Context, order exchange.Order) (*exchange.OrderResponse, error) {
+	s.placed = append(s.placed, order)
+	return s.Provider.PlaceOrder(ctx, order)
+}
+
+func (s *lotSim) Capabilities() exchange.Capabilities { return exchange.DetectCapabilities(s) }
+
+func TestExecuteDecisionSizesOnLotStep(t *testing.T) {
+	venue := &lotSim{Provider: sim.New(), step: exchange.MustParseDecimal("0.001")}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: venue,
+		MarketProvider:   fixedPriceMarket{price: 3},
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		OrderStyle:       OrderStyleLimitIOC,
+		Cooldown:         map[string]time.Time{},
+	}
+	m := newEventTestManager(&capturePersistence{}, trader)
+
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 3, PositionSizeUSD: 200}))
+	require.Len(t, venue.placed, 1)
+	assert.Equal(t, "66.666", venue.placed[0].Sz, "sizes round down to the lot step")
+	orders := m.TraderOrders("t1")
+	require.Len(t, orders, 1)
+	assert.Equal(t, exchange.MustParseDecimal(venue.placed[0].Sz).Float64(), orders[0].Size, "the tracked size is the wire size")
+
+	// 0.3 / 0.1 is 2.9999999999999996 in float64.
+	venue.step = exchange.DecimalFromInt(1)
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "ETH", Action: "open_long", EntryPrice: 0.1, PositionSizeUSD: 0.3}))
+	require.Len(t, venue.placed, 2)
+	assert.Equal(t, "3", venue.placed[1].Sz)
+
+	err := m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "SOL", Action: "open_long", EntryPrice: 3, PositionSizeUSD: 2})
+	require.Error(t, err, "a notional below one lot is not submitted")
+	assert.Len(t, venue.placed, 2)
+}
+
+func TestHandleExchangeEventAdvancesTrackedOrders(t *testing.T) {
+	m := newEventTestManager(nil)
+	trader := &VirtualTrader{ID: "t1", Exchange: "hl"}
+	cloid := m.trackOrder(trader, "BTC", "open_long", true, exchange.DecimalFromInt(2), "100", "")
+	require.NotEmpty(t, cloid)
+	assert.Empty(t, m.trackOrder(trader, "BTC", "open_long", true, exchange.DecimalFromInt(2), "100", cloid), "duplicate cloids are not tracked")
+
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventOrderUpdate, Order: &exchange.OrderStatus{
+		Status: "open", Order: exchange.OrderInfo{Cloid: cloid, Oid: 41, Sz: "2", OrigSz: "2"},
+	}})
+	order, _ := m.Order(cloid)
+	assert.Equal(t, exchange.OrderStateResting, order.State)
+
+	m.handleExchangeEvent("hl", exchange.Event{Type: exchange.EventFill, Fill: &exchange.Fill{Coin: "BTC", Oid: 41, Tid: 1, Dir: "Open Long", Px: "100", Sz: "2"}})
+	order, _ = m.Order(cloid)
+	assert.Equal(t, exchange.OrderStateFilled, order.State)
+	assert.InDelta(t, 100, order.AvgPx, 1e-9)
+}
@@ -32,6 +32,11 @@ type PositionEvent struct {
 	OccurredAt       time.Time
 	FillPrice        float64
 	FillSize         float64
+	// Fills carries the venue executions behind the event: the fills of the
+	// manager's own order when the provider supports exchange.FillHistory, or
+	// the streamed fill of an exchange-side close (e.g. a triggered stop-loss).
+	// Persistence prefers them over FillPrice/FillSize for prices, fees and PnL.
+	Fills []exchange.Fill
 }
 
 // DecisionCycleRecord is emitted after each decision loop for DB/cache mirroring.
@@ -47,7 +52,45 @@ type AccountSyncSnapshot struct {
 	MarginUsedUSD       float64
 	AvailableBalanceUSD float64
 	UnrealizedPnLUSD    float64
-	SyncedAt            time.Time
+	// FundingUSD is the cumulative funding attributed to the trader since it
+	// was registered (positive when received).
+	FundingUSD float64
+	SyncedAt   time.Time
+}
+
+// FundingRecord carries perpetual funding payments attributed to a trader.
+type FundingRecord struct {
+	TraderID string
+	Exchange string
+	Payments []exchange.FundingPayment
+}
+
+// FundingCursorStore is implemented by persistence services that can tell
+// where a trader's funding sync should resume after a restart.
+type FundingCursorStore interface {
+	// FundingCursor returns the time just after the latest funding payment
+	// recorded for traderID, or the zero time when none was.
+	FundingCursor(ctx context.Context, traderID string) (time.Time, error)
+}
+
+// OrderRecord carries the latest state of an order the manager submitted.
+type OrderRecord struct {
+	TraderID string
+	Order    exchange.TrackedOrder
+	// Leverage, StopLoss and TakeProfit are set for TWAP orders
+	// (Order.TwapID != 0), whose protection is placed once the TWAP stops,
+	// so a TWAP still running after a restart can be re-attached.
+	Leverage   int
+	StopLoss   float64
+	TakeProfit float64
+}
+
+// TWAPStore is implemented by persistence services that can list the TWAP
+// orders a trader left running, so the manager re-attaches them after a
+// restart.
+type TWAPStore interface {
+	// ActiveTWAPOrders returns traderID's unfinished orders with a TwapID.
+	ActiveTWAPOrders(ctx context.Context, traderID string) ([]OrderRecord, error)
 }
 
 // AnalyticsSnapshot captures performance metrics for persistence/leaderboard.
@@ -59,7 +102,9 @@ type AnalyticsSnapshot struct {
 	WinRate        float64
 	TotalTrades    int
 	MaxDrawdownPct float64
-	UpdatedAt      time.Time
+	// TotalFundingUSD mirrors AccountSyncSnapshot.FundingUSD.
+	TotalFundingUSD float64
+	UpdatedAt       time.Time
 }
 
 // PersistenceService describes the hooks manager emits to capture state changes.
@@ -68,6 +113,8 @@ type PersistenceService interface {
 	RecordDecisionCycle(ctx context.Context, record DecisionCycleRecord) error
 	RecordAccountSnapshot(ctx context.Context, snapshot AccountSyncSnapshot) error
 	RecordAnalytics(ctx context.Context, snapshot AnalyticsSnapshot) error
+	RecordFundingPayments(ctx context.Context, record FundingRecord) error
+	RecordOrderUpdate(ctx context.Context, record OrderRecord) error
 	HydrateCaches(ctx context.Context, traderIDs []string) error
 }
 
@@ -89,6 +136,14 @@ func (noopPersistenceService) RecordAnalytics(ctx context.Context, snapshot Anal
 	return nil
 }
 
+func (noopPersistenceService) RecordFundingPayments(ctx context.Context, record FundingRecord) error {
+	return nil
+}
+
+func (noopPersistenceService) RecordOrderUpdate(ctx context.Context, record OrderRecord) error {
+	return nil
+}
+
 func (noopPersistenceService) HydrateCaches(ctx context.Context, traderIDs []string) error {
 	return nil
 }
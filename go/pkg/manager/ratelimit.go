@@ -0,0 +1,39 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// rateLimitSyncInterval spaces out request allowance lookups per exchange
+// account; the allowance only changes as the account trades.
+const rateLimitSyncInterval = 5 * time.Minute
+
+// syncRateLimit aligns the request budget of t's exchange account with the
+// allowance the venue reports, so the client neither idles below a grown cap
+// nor overruns one already spent by another process. SyncTraderPositions
+// calls it; each account is synced at most once per rateLimitSyncInterval.
+func (m *Manager) syncRateLimit(ctx context.Context, t *VirtualTrader) {
+	syncer, ok := t.ExchangeProvider.(exchange.RateLimitSyncer)
+	if !ok {
+		return
+	}
+	key := t.accountKey()
+	now := time.Now()
+	m.rateLimitMu.Lock()
+	due := now.Sub(m.rateLimitSynced[key]) >= rateLimitSyncInterval
+	if due {
+		m.rateLimitSynced[key] = now
+	}
+	m.rateLimitMu.Unlock()
+	if !due {
+		return
+	}
+	if err := syncer.SyncRateLimit(ctx); err != nil {
+		logx.WithContext(ctx).Errorf("manager: rate limit sync failed trader=%s exchange=%s err=%v", t.ID, key, err)
+	}
+}
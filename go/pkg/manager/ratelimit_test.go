@@ -0,0 +1,60 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+)
+
+// budgetSim counts request budget syncs on top of the simulator.
+type budgetSim struct {
+	*sim.Provider
+
+	mu    sync.Mutex
+	syncs int
+	err   error
+}
+
+func (s *budgetSim) Capabilities() exchange.Capabilities { return exchange.DetectCapabilities(s) }
+
+func (s *budgetSim) SyncRateLimit(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncs++
+	return s.err
+}
+
+func TestSyncRateLimitThrottlesPerAccount(t *testing.T) {
+	venue := &budgetSim{Provider: sim.New()}
+	risk := exchange.NewRiskGateway(venue, exchange.RiskLimits{})
+	m := NewManager(&Config{}, nil, map[string]exchange.Provider{"hl": risk}, nil, nil)
+	a := &VirtualTrader{ID: "a", Exchange: "hl", ExchangeProvider: risk}
+	b := &VirtualTrader{ID: "b", Exchange: "hl", ExchangeProvider: risk}
+	sub := &VirtualTrader{ID: "c", Exchange: "hl", SubAccount: "c", ExchangeProvider: risk}
+	ctx := context.Background()
+
+	m.syncRateLimit(ctx, a)
+	assert.Equal(t, 1, venue.syncs, "decorators forward the sync")
+	m.syncRateLimit(ctx, b)
+	assert.Equal(t, 1, venue.syncs, "traders on one account share the sync")
+	m.syncRateLimit(ctx, sub)
+	assert.Equal(t, 2, venue.syncs, "sub-accounts have their own allowance")
+
+	m.rateLimitSynced["hl"] = time.Now().Add(-rateLimitSyncInterval)
+	venue.err = errors.New("info endpoint down")
+	m.syncRateLimit(ctx, a)
+	assert.Equal(t, 3, venue.syncs, "failures are logged, not retried until due")
+	m.syncRateLimit(ctx, a)
+	assert.Equal(t, 3, venue.syncs)
+
+	paper := &VirtualTrader{ID: "p", Exchange: "paper", ExchangeProvider: sim.New()}
+	m.syncRateLimit(ctx, paper)
+	assert.NotContains(t, m.rateLimitSynced, "paper", "providers without an allowance are skipped")
+}
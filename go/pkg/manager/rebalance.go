@@ -0,0 +1,117 @@
+package manager
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+)
+
+// Differences below the larger of these bounds are left alone so PnL noise
+// does not churn transfers.
+const (
+	minRebalanceTransferUSD = 1.0
+	rebalanceTolerancePct   = 1.0
+)
+
+// AllocationTransfer is one transfer attempted by FundSubAccounts.
+type AllocationTransfer struct {
+	TraderID  string
+	AmountUSD float64 // positive funds the trader's sub-account, negative returns funds
+	Err       error
+}
+
+// rebalanceAllocations runs FundSubAccounts on the first heartbeat and then
+// once per manager.rebalance_interval.
+func (m *Manager) rebalanceAllocations(ctx context.Context) {
+	if m == nil || m.config == nil || m.config.Manager.RebalanceInterval <= 0 {
+		return
+	}
+	now := time.Now()
+	m.rebalanceMu.Lock()
+	due := m.lastRebalance.IsZero() || now.Sub(m.lastRebalance) >= m.config.Manager.RebalanceInterval
+	if due {
+		m.lastRebalance = now
+	}
+	m.rebalanceMu.Unlock()
+	if due {
+		m.FundSubAccounts(ctx)
+	}
+}
+
+// FundSubAccounts moves USD between each trader's sub-account and the master
+// account of its exchange provider so the sub-account's equity matches the
+// trader's AllocatedEquityUSD. Withdrawals are capped at the sub-account's
+// margin-free equity and run before deposits so they can fund them. Traders
+// without a sub-account, or whose provider cannot transfer funds, are skipped.
+func (m *Manager) FundSubAccounts(ctx context.Context) []AllocationTransfer {
+	type plan struct {
+		trader     *VirtualTrader
+		transferer exchange.FundTransferer
+		amount     float64
+	}
+	m.mu.RLock()
+	traders := make([]*VirtualTrader, 0, len(m.traders))
+	for _, t := range m.traders {
+		traders = append(traders, t)
+	}
+	providers := m.exchangeProviders
+	m.mu.RUnlock()
+	sort.Slice(traders, func(i, j int) bool { return traders[i].ID < traders[j].ID })
+
+	var plans []plan
+	for _, t := range traders {
+		if t.SubAccount == "" {
+			continue
+		}
+		master := providers[t.Exchange]
+		transferer, ok := master.(exchange.FundTransferer)
+		if !ok || !exchange.CapabilitiesOf(master).FundTransfers {
+			continue
+		}
+		t.mu.RLock()
+		target := t.ResourceAlloc.AllocatedEquityUSD
+		t.mu.RUnlock()
+		if target <= 0 {
+			continue
+		}
+		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		acct, err := t.ExchangeProvider.GetAccountState(reqCtx)
+		cancel()
+		if err != nil || acct == nil {
+			logx.WithContext(ctx).Errorf("manager: rebalance trader=%s account state err=%v", t.ID, err)
+			continue
+		}
+		equity := parseFloat(acct.MarginSummary.AccountValue)
+		free := math.Max(0, equity-parseFloat(acct.MarginSummary.TotalMarginUsed))
+		delta := target - equity
+		if delta < 0 {
+			delta = -math.Min(-delta, free)
+		}
+		// Whole cents, rounded towards zero so withdrawals never exceed free.
+		delta = math.Trunc(delta*100) / 100
+		if math.Abs(delta) < math.Max(minRebalanceTransferUSD, target*rebalanceTolerancePct/100) {
+			continue
+		}
+		plans = append(plans, plan{trader: t, transferer: transferer, amount: delta})
+	}
+	sort.SliceStable(plans, func(i, j int) bool { return plans[i].amount < 0 && plans[j].amount >= 0 })
+
+	results := make([]AllocationTransfer, 0, len(plans))
+	for _, p := range plans {
+		reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := p.transferer.TransferToSubAccount(reqCtx, p.trader.SubAccount, p.amount)
+		cancel()
+		results = append(results, AllocationTransfer{TraderID: p.trader.ID, AmountUSD: p.amount, Err: err})
+		if err != nil {
+			logx.WithContext(ctx).Errorf("manager: rebalance trader=%s sub_account=%s amount=%.2f usd err=%v", p.trader.ID, p.trader.SubAccount, p.amount, err)
+			continue
+		}
+		logx.WithContext(ctx).Infof("manager: rebalance trader=%s sub_account=%s transferred=%.2f usd", p.trader.ID, p.trader.SubAccount, p.amount)
+	}
+	return results
+}
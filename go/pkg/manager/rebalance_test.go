@@ -0,0 +1,86 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+)
+
+func TestFundSubAccountsMatchesAllocations(t *testing.T) {
+	ctx := context.Background()
+	root := sim.New(sim.WithInitialEquity(10000))
+	cfg := &Config{Manager: ManagerConfig{RebalanceInterval: time.Hour}}
+	m := NewManager(cfg, nil, map[string]exchange.Provider{"paper": root}, nil, &capturePersistence{})
+
+	newTrader := func(id string, funded, allocated float64) *VirtualTrader {
+		account, err := root.SubAccount(ctx, id, funded)
+		require.NoError(t, err)
+		trader := &VirtualTrader{
+			ID:               id,
+			Exchange:         "paper",
+			SubAccount:       id,
+			ExchangeProvider: account,
+			ResourceAlloc:    ResourceAllocation{AllocatedEquityUSD: allocated},
+		}
+		m.traders[id] = trader
+		return trader
+	}
+	over := newTrader("over", 800, 500)
+	under := newTrader("under", 100, 400)
+	near := newTrader("near", 298, 300) // within tolerance
+	// Traders without a sub-account are never funded.
+	m.traders["shared"] = &VirtualTrader{ID: "shared", Exchange: "paper", ExchangeProvider: root}
+
+	m.rebalanceAllocations(ctx)
+	equity := func(p exchange.Provider) float64 {
+		value, err := p.GetAccountValue(ctx)
+		require.NoError(t, err)
+		return value
+	}
+	assert.InDelta(t, 500, equity(over.ExchangeProvider), 1e-9)
+	assert.InDelta(t, 400, equity(under.ExchangeProvider), 1e-9)
+	assert.InDelta(t, 298, equity(near.ExchangeProvider), 1e-9)
+	assert.InDelta(t, 10000, equity(root), 1e-9, "funds moved through the master account")
+
+	// The next pass waits for rebalance_interval.
+	require.NoError(t, root.TransferToSubAccount(ctx, "under", -50))
+	m.rebalanceAllocations(ctx)
+	assert.InDelta(t, 350, equity(under.ExchangeProvider), 1e-9)
+
+	transfers := m.FundSubAccounts(ctx)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, AllocationTransfer{TraderID: "under", AmountUSD: 50}, transfers[0])
+}
+
+func TestFundSubAccountsWithdrawsOnlyFreeMargin(t *testing.T) {
+	ctx := context.Background()
+	root := sim.New(sim.WithInitialEquity(10000))
+	require.NoError(t, root.SetMarkPrice(ctx, "BTC", 100))
+	m := NewManager(nil, nil, map[string]exchange.Provider{"paper": root}, nil, &capturePersistence{})
+	account, err := root.SubAccount(ctx, "t1", 1000)
+	require.NoError(t, err)
+	m.traders["t1"] = &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "paper",
+		SubAccount:       "t1",
+		ExchangeProvider: account,
+		ResourceAlloc:    ResourceAllocation{AllocatedEquityUSD: 100},
+	}
+	idx, err := account.GetAssetIndex(ctx, "BTC")
+	require.NoError(t, err)
+	require.NoError(t, account.UpdateLeverage(ctx, idx, true, 1))
+	_, err = account.(exchange.MarketOrderer).IOCMarket(ctx, "BTC", true, 6, 0, false)
+	require.NoError(t, err)
+
+	transfers := m.FundSubAccounts(ctx)
+	require.Len(t, transfers, 1)
+	require.NoError(t, transfers[0].Err)
+	assert.Less(t, transfers[0].AmountUSD, -390.0)
+	assert.Greater(t, transfers[0].AmountUSD, -400.0, "margin posted for the open position stays in the sub-account")
+}
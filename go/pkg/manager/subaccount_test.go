@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+	"nof0-api/pkg/market"
+)
+
+type nopExecutorFactory struct{}
+
+func (nopExecutorFactory) NewExecutor(TraderConfig) (executorpkg.Executor, error) { return nil, nil }
+
+func TestRegisterTraderOpensSimSubAccounts(t *testing.T) {
+	dir := t.TempDir()
+	prompt := filepath.Join(dir, "prompt.tmpl")
+	require.NoError(t, os.WriteFile(prompt, []byte("prompt"), 0o644))
+	cfg := &Config{
+		Manager:    ManagerConfig{TotalEquityUSD: 1000, StateStorageBackend: "file", StateStoragePath: dir},
+		Monitoring: MonitoringConfig{MetricsExporter: "prometheus"},
+	}
+	paper := sim.New()
+	m := NewManager(cfg, nopExecutorFactory{},
+		map[string]exchange.Provider{"paper": paper},
+		map[string]market.Provider{"mkt": fixedPriceMarket{price: 100}}, nil)
+
+	traderCfg := func(id string, pct float64) TraderConfig {
+		return TraderConfig{
+			ID: id, Name: id, ExchangeProvider: "paper", MarketProvider: "mkt",
+			PromptTemplate: prompt, ExecutorTemplate: prompt, OrderStyle: OrderStyleLimitIOC,
+			AllocationPct: pct,
+			RiskParams: RiskParameters{MaxPositions: 1, MaxPositionSizeUSD: 100, MajorCoinLeverage: 1,
+				AltcoinLeverage: 1, MinRiskRewardRatio: 1},
+		}
+	}
+	t1, err := m.RegisterTrader(traderCfg("t1", 60))
+	require.NoError(t, err)
+	t2, err := m.RegisterTrader(traderCfg("t2", 40))
+	require.NoError(t, err)
+
+	assert.Equal(t, "t1", t1.SubAccount)
+	assert.Equal(t, "paper/t1", t1.accountKey())
+	assert.NotSame(t, t1.ExchangeProvider, t2.ExchangeProvider, "each trader gets its own ledger")
+	assert.InDelta(t, 600, t1.ResourceAlloc.AllocatedEquityUSD, 1e-9)
+
+	_, err = t1.ExchangeProvider.(exchange.MarketOrderer).IOCMarket(context.Background(), "BTC", true, 1, 0.01, false)
+	require.NoError(t, err)
+	require.NoError(t, m.SyncTraderPositions("t1"))
+	require.NoError(t, m.SyncTraderPositions("t2"))
+	assert.InDelta(t, 600, t1.ResourceAlloc.CurrentEquityUSD, 5, "t1 reports its own equity")
+	assert.Greater(t, t1.ResourceAlloc.MarginUsedUSD, 0.0)
+	assert.Equal(t, 400.0, t2.ResourceAlloc.CurrentEquityUSD, "t2 is unaffected by t1's trade")
+	assert.Zero(t, t2.ResourceAlloc.MarginUsedUSD)
+}
@@ -30,6 +30,7 @@ type ResourceAllocation struct {
 	AvailableBalanceUSD float64 // Available balance (updated via sync)
 	MarginUsedUSD       float64 // Margin currently used
 	UnrealizedPnLUSD    float64 // Unrealized PnL
+	FundingPnLUSD       float64 // Cumulative funding received (negative when paid)
 }
 
 // IsOverAllocated reports whether live usage exceeds the assigned slice.
@@ -77,12 +78,16 @@ type VirtualTrader struct {
 	ID                   string
 	Name                 string
 	Exchange             string
+	SubAccount           string // isolated account on Exchange; empty when the account is shared
 	ExchangeProvider     exchange.Provider
 	MarketProvider       market.Provider
 	Executor             executorpkg.Executor
 	PromptTemplate       string
 	OrderStyle           OrderStyle
 	MarketIOCSlippageBps float64
+	TWAPDuration         time.Duration
+	TWAPRandomize        bool
+	MarginMode           MarginMode
 	RiskParams           RiskParameters
 	ExecGuards           ExecGuards
 	ResourceAlloc        ResourceAllocation
@@ -100,6 +105,23 @@ type VirtualTrader struct {
 	JournalEnabled bool
 	// Pause window for Sharpe gating
 	PauseUntil time.Time
+	// twaps tracks running TWAP executions per symbol
+	twaps map[string]*twapExecution
+	// twapsRestored is set once TWAPs left running by a previous process
+	// were re-attached (see restoreTWAPs)
+	twapsRestored bool
+	// assets caches MarketProvider.ListAssets for margin mode lookups
+	assets        []market.Asset
+	assetsFetched time.Time
+}
+
+// accountKey identifies the exchange account the trader trades: the provider
+// name, qualified by the sub-account when the trader has its own.
+func (t *VirtualTrader) accountKey() string {
+	if t.SubAccount == "" {
+		return t.Exchange
+	}
+	return t.Exchange + "/" + t.SubAccount
 }
 
 // Start transitions the trader into running state.
@@ -0,0 +1,289 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/exchange"
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// twapExecution is a running TWAP and how much of it the manager has
+// recorded as opened.
+type twapExecution struct {
+	status   exchange.TWAPStatus
+	decision executorpkg.Decision
+	cloid    string // OrderTracker entry
+	isBuy    bool
+
+	recordedSz  float64
+	recordedNtl float64
+}
+
+// placeTWAP starts a venue-side TWAP for an opening decision and tracks it on
+// the trader, and under cloid in the order tracker, until it stops placing
+// slices.
+func (m *Manager) placeTWAP(ctx context.Context, trader *VirtualTrader, decision *executorpkg.Decision, isBuy bool, sz, cloid string) (*exchange.TWAPStatus, error) {
+	twaper, ok := trader.ExchangeProvider.(exchange.TWAPOrderer)
+	if !ok || !exchange.CapabilitiesOf(trader.ExchangeProvider).TWAPOrders {
+		return nil, fmt.Errorf("manager: trader %s order_style=twap unsupported by exchange provider", trader.ID)
+	}
+	status, err := twaper.PlaceTWAP(ctx, exchange.TWAPOrder{
+		Coin:      decision.Symbol,
+		IsBuy:     isBuy,
+		Sz:        sz,
+		Duration:  trader.TWAPDuration,
+		Randomize: trader.TWAPRandomize,
+	})
+	if err != nil {
+		m.applyOrderResult(cloid, nil, err, nil)
+		return nil, err
+	}
+	m.attachTWAP(trader, &twapExecution{status: *status, decision: *decision, cloid: cloid, isBuy: isBuy})
+	return status, nil
+}
+
+// attachTWAP tracks exec on the trader and records its TWAP id on the
+// tracked order, so the order record carries what restoreTWAPs needs.
+func (m *Manager) attachTWAP(trader *VirtualTrader, exec *twapExecution) {
+	trader.mu.Lock()
+	if trader.twaps == nil {
+		trader.twaps = make(map[string]*twapExecution)
+	}
+	trader.twaps[exec.decision.Symbol] = exec
+	trader.mu.Unlock()
+	if exec.cloid == "" {
+		return
+	}
+	m.twapMu.Lock()
+	m.twapOrders[exec.cloid] = exec
+	m.twapMu.Unlock()
+	m.orders.SetTWAP(exec.cloid, exec.status.TwapID)
+}
+
+// finishTWAP moves exec's tracked order to state once its TWAP stopped.
+func (m *Manager) finishTWAP(exec *twapExecution, state string) {
+	m.orders.ApplyUpdate(exchange.OrderStatus{Status: state, Order: exchange.OrderInfo{Coin: exec.decision.Symbol, Cloid: exec.cloid}})
+	m.twapMu.Lock()
+	delete(m.twapOrders, exec.cloid)
+	m.twapMu.Unlock()
+}
+
+// restoreTWAPs re-attaches the TWAPs a previous process left running, from
+// the order records of persistence implementing TWAPStore, so their opens
+// and protection are recorded. It runs once per trader; a failed lookup is
+// retried on the next sync.
+func (m *Manager) restoreTWAPs(ctx context.Context, trader *VirtualTrader) {
+	trader.mu.RLock()
+	restored := trader.twapsRestored
+	trader.mu.RUnlock()
+	if restored {
+		return
+	}
+	store, ok := m.persistence.(TWAPStore)
+	if !ok {
+		trader.mu.Lock()
+		trader.twapsRestored = true
+		trader.mu.Unlock()
+		return
+	}
+	records, err := store.ActiveTWAPOrders(ctx, trader.ID)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("manager: restore twaps trader=%s err=%v", trader.ID, err)
+		return
+	}
+	trader.mu.Lock()
+	trader.twapsRestored = true
+	trader.mu.Unlock()
+	for _, record := range records {
+		order := record.Order
+		if order.TwapID == 0 || order.Cloid == "" {
+			continue
+		}
+		if _, tracked := m.orders.Get(order.Cloid); tracked {
+			continue
+		}
+		trader.mu.RLock()
+		_, running := trader.twaps[order.Coin]
+		trader.mu.RUnlock()
+		if running {
+			continue
+		}
+		action := "open_short"
+		if order.IsBuy {
+			action = "open_long"
+		}
+		exec := &twapExecution{
+			status: exchange.TWAPStatus{
+				TwapID: order.TwapID,
+				Coin:   order.Coin,
+				Sz:     strconv.FormatFloat(order.Size, 'f', -1, 64),
+				Status: exchange.TWAPStatusActive,
+			},
+			decision: executorpkg.Decision{
+				Symbol:     order.Coin,
+				Action:     action,
+				Leverage:   record.Leverage,
+				StopLoss:   record.StopLoss,
+				TakeProfit: record.TakeProfit,
+			},
+			cloid:       order.Cloid,
+			isBuy:       order.IsBuy,
+			recordedSz:  order.FilledSz,
+			recordedNtl: order.FilledSz * order.AvgPx,
+		}
+		filledSz, avgPx := order.FilledSz, order.AvgPx
+		order.Account, order.Owner = trader.accountKey(), trader.ID
+		if err := m.orders.Track(order); err != nil {
+			logx.WithContext(ctx).Errorf("manager: restore twap trader=%s symbol=%s twap_id=%d err=%v", trader.ID, order.Coin, order.TwapID, err)
+			continue
+		}
+		if filledSz > 0 {
+			m.orders.ApplyFill(exchange.Fill{Coin: order.Coin, Cloid: order.Cloid, Px: strconv.FormatFloat(avgPx, 'f', -1, 64), Sz: strconv.FormatFloat(filledSz, 'f', -1, 64)})
+		}
+		m.attachTWAP(trader, exec)
+		m.setSymbolOwner(trader.accountKey(), order.Coin, trader.ID)
+		logx.WithContext(ctx).Infof("manager: trader %s re-attached twap symbol=%s twap_id=%d recorded_sz=%s", trader.ID, order.Coin, order.TwapID, strconv.FormatFloat(filledSz, 'f', -1, 64))
+	}
+}
+
+// cancelTWAP stops a running TWAP on symbol so a close is not undone by
+// slices still being placed. What it executed so far is recorded first.
+func (m *Manager) cancelTWAP(ctx context.Context, trader *VirtualTrader, symbol string) {
+	trader.mu.Lock()
+	exec, ok := trader.twaps[symbol]
+	delete(trader.twaps, symbol)
+	trader.mu.Unlock()
+	if !ok {
+		return
+	}
+	twaper, ok := trader.ExchangeProvider.(exchange.TWAPOrderer)
+	if !ok {
+		return
+	}
+	if err := twaper.CancelTWAP(ctx, symbol, exec.status.TwapID); err != nil {
+		logx.WithContext(ctx).Errorf("manager: cancel twap trader=%s symbol=%s twap_id=%d err=%v", trader.ID, symbol, exec.status.TwapID, err)
+		return
+	}
+	logx.WithContext(ctx).Infof("manager: trader %s cancelled twap symbol=%s twap_id=%d", trader.ID, symbol, exec.status.TwapID)
+	if status, err := twaper.GetTWAPStatus(ctx, exec.status.TwapID); err == nil {
+		m.recordTWAPProgress(trader, exec, status)
+	}
+	m.finishTWAP(exec, "canceled")
+}
+
+// syncTWAPs polls tracked TWAPs, records what they executed since the last
+// poll as opens and forgets those that finished, were terminated or failed.
+// Stop-loss and take-profit orders are placed once a TWAP stops, sized to
+// what it executed. TWAPs left running by a previous process are re-attached
+// first (see restoreTWAPs).
+func (m *Manager) syncTWAPs(ctx context.Context, trader *VirtualTrader) {
+	twaper, ok := trader.ExchangeProvider.(exchange.TWAPOrderer)
+	if !ok {
+		return
+	}
+	m.restoreTWAPs(ctx, trader)
+	trader.mu.RLock()
+	symbols := make([]string, 0, len(trader.twaps))
+	for symbol := range trader.twaps {
+		symbols = append(symbols, symbol)
+	}
+	trader.mu.RUnlock()
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		trader.mu.RLock()
+		exec, ok := trader.twaps[symbol]
+		trader.mu.RUnlock()
+		if !ok {
+			continue
+		}
+		status, err := twaper.GetTWAPStatus(ctx, exec.status.TwapID)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("manager: poll twap trader=%s symbol=%s twap_id=%d err=%v", trader.ID, symbol, exec.status.TwapID, err)
+			continue
+		}
+		trader.mu.Lock()
+		current := trader.twaps[symbol] == exec
+		if current {
+			if status.Active() {
+				exec.status = *status
+			} else {
+				delete(trader.twaps, symbol)
+			}
+		}
+		trader.mu.Unlock()
+		if !current {
+			continue // cancelled by a close meanwhile
+		}
+		m.recordTWAPProgress(trader, exec, status)
+		if status.Active() {
+			continue
+		}
+		logx.WithContext(ctx).Infof("manager: trader %s twap done symbol=%s twap_id=%d status=%s executed_sz=%s/%s executed_ntl=%s %s",
+			trader.ID, symbol, status.TwapID, status.Status, status.ExecutedSz, status.Sz, status.ExecutedNtl, status.Description)
+		state := "filled"
+		switch {
+		case status.Status == exchange.TWAPStatusError:
+			state = "rejected"
+		case exec.recordedSz < parseFloat(status.Sz)-1e-9:
+			state = "canceled"
+		}
+		m.finishTWAP(exec, state)
+		if exec.recordedSz > 0 {
+			m.placeProtectiveOrders(ctx, trader, &exec.decision, exec.isBuy, exec.recordedSz)
+		}
+	}
+}
+
+// recordTWAPProgress records the size a TWAP executed beyond what was
+// already recorded. The open event carries the cumulative executed size and
+// average price, as persistence keeps one open row per symbol.
+func (m *Manager) recordTWAPProgress(trader *VirtualTrader, exec *twapExecution, status *exchange.TWAPStatus) {
+	executedSz := parseFloat(status.ExecutedSz)
+	executedNtl := parseFloat(status.ExecutedNtl)
+	trader.mu.Lock()
+	deltaSz := executedSz - exec.recordedSz
+	deltaNtl := executedNtl - exec.recordedNtl
+	if deltaSz <= 1e-12 {
+		trader.mu.Unlock()
+		return
+	}
+	exec.recordedSz, exec.recordedNtl = executedSz, executedNtl
+	trader.mu.Unlock()
+
+	var avgPx, slicePx float64
+	if executedSz > 0 {
+		avgPx = executedNtl / executedSz
+	}
+	if deltaNtl > 0 {
+		slicePx = deltaNtl / deltaSz
+	}
+	// Slice fills carry no cloid; feed the progress to the tracked TWAP.
+	m.orders.ApplyFill(exchange.Fill{Coin: exec.decision.Symbol, Cloid: exec.cloid, Px: strconv.FormatFloat(slicePx, 'f', -1, 64), Sz: strconv.FormatFloat(deltaSz, 'f', -1, 64)})
+	m.recordPositionEvent(PositionEvent{
+		TraderID:   trader.ID,
+		Trader:     trader,
+		Decision:   exec.decision,
+		Event:      PositionEventOpen,
+		FillPrice:  avgPx,
+		FillSize:   executedSz,
+		OccurredAt: time.Now(),
+	})
+}
+
+// ActiveTWAPs returns the trader's running TWAP executions ordered by symbol.
+func (t *VirtualTrader) ActiveTWAPs() []exchange.TWAPStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	out := make([]exchange.TWAPStatus, 0, len(t.twaps))
+	for _, exec := range t.twaps {
+		out = append(out, exec.status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Coin < out[j].Coin })
+	return out
+}
@@ -0,0 +1,213 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/exchange"
+	"nof0-api/pkg/exchange/sim"
+	executorpkg "nof0-api/pkg/executor"
+)
+
+// twapSim adds a scripted TWAPOrderer to the simulator.
+type twapSim struct {
+	*sim.Provider
+
+	mu       sync.Mutex
+	placed   []exchange.TWAPOrder
+	cancels  []int64
+	statuses map[int64]*exchange.TWAPStatus
+	stops    []float64
+}
+
+func (s *twapSim) SetStopLoss(ctx context.Context, coin string, positionSide string, qty float64, stopPrice float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stops = append(s.stops, qty)
+	return nil
+}
+
+func (s *twapSim) PlaceTWAP(ctx context.Context, order exchange.TWAPOrder) (*exchange.TWAPStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.placed = append(s.placed, order)
+	status := &exchange.TWAPStatus{TwapID: int64(len(s.placed)), Coin: order.Coin, Sz: order.Sz, Status: exchange.TWAPStatusActive}
+	s.statuses[status.TwapID] = status
+	return status, nil
+}
+
+func (s *twapSim) CancelTWAP(ctx context.Context, coin string, twapID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancels = append(s.cancels, twapID)
+	s.statuses[twapID].Status = exchange.TWAPStatusTerminated
+	return nil
+}
+
+func (s *twapSim) GetTWAPStatus(ctx context.Context, twapID int64) (*exchange.TWAPStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := *s.statuses[twapID]
+	return &status, nil
+}
+
+// Capabilities reports the extensions added on top of the simulator.
+func (s *twapSim) Capabilities() exchange.Capabilities { return exchange.DetectCapabilities(s) }
+
+func TestExecuteDecisionTWAP(t *testing.T) {
+	venue := &twapSim{Provider: sim.New(), statuses: map[int64]*exchange.TWAPStatus{}}
+	trader := &VirtualTrader{
+		ID:               "t1",
+		Exchange:         "sim",
+		ExchangeProvider: venue,
+		MarketProvider:   fixedPriceMarket{price: 100},
+		RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+		OrderStyle:       OrderStyleTWAP,
+		TWAPDuration:     time.Hour,
+		TWAPRandomize:    true,
+		Cooldown:         map[string]time.Time{},
+	}
+	persist := &capturePersistence{}
+	m := newEventTestManager(persist, trader)
+
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "ETH", Action: "open_short", EntryPrice: 100, PositionSizeUSD: 250}))
+	require.Len(t, venue.placed, 1)
+	assert.Equal(t, exchange.TWAPOrder{Coin: "ETH", IsBuy: false, Sz: venue.placed[0].Sz, Duration: time.Hour, Randomize: true}, venue.placed[0])
+	assert.InDelta(t, 2.5, parseFloat(venue.placed[0].Sz), 1e-9)
+	require.Len(t, trader.ActiveTWAPs(), 1)
+	assert.Empty(t, persist.snapshot(), "nothing is recorded as opened at submit")
+	orders := m.TraderOrders("t1")
+	require.Len(t, orders, 1)
+	assert.Equal(t, exchange.OrderStateResting, orders[0].State)
+
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", EntryPrice: 100, PositionSizeUSD: 100, StopLoss: 90}))
+	venue.statuses[2].ExecutedSz, venue.statuses[2].ExecutedNtl = "0.4", "40"
+	require.NoError(t, m.SyncTraderPositions("t1"))
+	events := persist.snapshot()
+	require.Len(t, events, 1)
+	assert.Equal(t, PositionEventOpen, events[0].Event)
+	assert.Equal(t, "BTC", events[0].Decision.Symbol)
+	assert.InDelta(t, 0.4, events[0].FillSize, 1e-9)
+	assert.InDelta(t, 100, events[0].FillPrice, 1e-9)
+	assert.Empty(t, venue.stops, "protection waits for the TWAP to stop")
+
+	venue.statuses[2].Status = exchange.TWAPStatusFinished
+	venue.statuses[2].ExecutedSz, venue.statuses[2].ExecutedNtl = "0.9", "94"
+	require.NoError(t, m.SyncTraderPositions("t1"))
+	active := trader.ActiveTWAPs()
+	require.Len(t, active, 1, "finished TWAPs are no longer tracked")
+	assert.Equal(t, "ETH", active[0].Coin)
+	events = persist.snapshot()
+	require.Len(t, events, 2)
+	assert.InDelta(t, 0.9, events[1].FillSize, 1e-9, "opens carry the cumulative executed size")
+	assert.InDelta(t, 94.0/0.9, events[1].FillPrice, 1e-9)
+	assert.Equal(t, []float64{0.9}, venue.stops, "protection is sized to the executed size")
+	for _, order := range m.TraderOrders("t1") {
+		if order.Coin == "BTC" {
+			assert.Equal(t, exchange.OrderStateCancelled, order.State, "a TWAP that stopped short is not filled")
+			assert.InDelta(t, 0.9, order.FilledSz, 1e-9)
+		}
+	}
+
+	require.NoError(t, m.ExecuteDecision(trader, &executorpkg.Decision{Symbol: "ETH", Action: "close_short"}))
+	assert.Equal(t, []int64{1}, venue.cancels, "closing cancels the running TWAP")
+	assert.Empty(t, trader.ActiveTWAPs())
+}
+
+// twapStore replays the order records of a previous manager the way the
+// orders table keeps them: the latest state per cloid, keeping the TWAP id
+// and protection once recorded.
+type twapStore struct {
+	*capturePersistence
+}
+
+func (s twapStore) ActiveTWAPOrders(ctx context.Context, traderID string) ([]OrderRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latest := map[string]OrderRecord{}
+	var cloids []string
+	for _, record := range s.orders {
+		prev, seen := latest[record.Order.Cloid]
+		if !seen {
+			cloids = append(cloids, record.Order.Cloid)
+		}
+		if record.Order.TwapID == 0 {
+			record.Order.TwapID = prev.Order.TwapID
+		}
+		if record.StopLoss == 0 {
+			record.Leverage, record.StopLoss, record.TakeProfit = prev.Leverage, prev.StopLoss, prev.TakeProfit
+		}
+		latest[record.Order.Cloid] = record
+	}
+	var out []OrderRecord
+	for _, cloid := range cloids {
+		record := latest[cloid]
+		if record.TraderID == traderID && record.Order.TwapID != 0 && !record.Order.State.Terminal() {
+			out = append(out, record)
+		}
+	}
+	return out, nil
+}
+
+func TestSyncTWAPsReattachesAfterRestart(t *testing.T) {
+	venue := &twapSim{Provider: sim.New(), statuses: map[int64]*exchange.TWAPStatus{}}
+	newTrader := func() *VirtualTrader {
+		return &VirtualTrader{
+			ID:               "t1",
+			Exchange:         "sim",
+			ExchangeProvider: venue,
+			MarketProvider:   fixedPriceMarket{price: 100},
+			RiskParams:       RiskParameters{MajorCoinLeverage: 5, AltcoinLeverage: 3},
+			OrderStyle:       OrderStyleTWAP,
+			TWAPDuration:     time.Hour,
+			Cooldown:         map[string]time.Time{},
+		}
+	}
+	store := twapStore{&capturePersistence{}}
+	before := newTrader()
+	m := newEventTestManager(store, before)
+	require.NoError(t, m.ExecuteDecision(before, &executorpkg.Decision{Symbol: "BTC", Action: "open_long", Leverage: 3, EntryPrice: 100, PositionSizeUSD: 100, StopLoss: 90}))
+	venue.statuses[1].ExecutedSz, venue.statuses[1].ExecutedNtl = "0.4", "40"
+	require.NoError(t, m.SyncTraderPositions("t1"))
+	require.Len(t, store.snapshot(), 1)
+
+	// Restart: the TWAP keeps running while no process tracks it.
+	after := newTrader()
+	restarted := newEventTestManager(store, after)
+	venue.statuses[1].Status = exchange.TWAPStatusFinished
+	venue.statuses[1].ExecutedSz, venue.statuses[1].ExecutedNtl = "1", "101"
+	require.NoError(t, restarted.SyncTraderPositions("t1"))
+
+	events := store.snapshot()
+	require.Len(t, events, 2, "the re-attached TWAP records what it executed since the restart")
+	assert.Equal(t, PositionEventOpen, events[1].Event)
+	assert.Equal(t, "open_long", events[1].Decision.Action)
+	assert.Equal(t, 3, events[1].Decision.Leverage)
+	assert.InDelta(t, 1, events[1].FillSize, 1e-9)
+	assert.InDelta(t, 101, events[1].FillPrice, 1e-9)
+	assert.Equal(t, []float64{1}, venue.stops, "protection is sized to the executed size")
+	assert.Empty(t, after.ActiveTWAPs())
+	orders := restarted.TraderOrders("t1")
+	require.Len(t, orders, 1)
+	assert.Equal(t, exchange.OrderStateFilled, orders[0].State)
+	assert.Equal(t, int64(1), orders[0].TwapID)
+	assert.InDelta(t, 1, orders[0].FilledSz, 1e-9)
+
+	again := newEventTestManager(store, newTrader())
+	require.NoError(t, again.SyncTraderPositions("t1"))
+	assert.Empty(t, again.TraderOrders("t1"), "finished TWAPs are not re-attached")
+}
+
+func TestValidateOrderStyleTWAPDuration(t *testing.T) {
+	cfg := TraderConfig{OrderStyle: OrderStyleTWAP, TWAPDuration: 30 * time.Minute}
+	assert.NoError(t, cfg.validateOrderStyle(0))
+	cfg.TWAPDuration = time.Minute
+	assert.ErrorContains(t, cfg.validateOrderStyle(0), "twap_duration")
+	cfg.TWAPDuration = 48 * time.Hour
+	assert.ErrorContains(t, cfg.validateOrderStyle(0), "twap_duration")
+}
@@ -24,6 +24,8 @@ type ProviderConfig struct {
 	Type    string `yaml:"type"`
 	Testnet bool   `yaml:"testnet"`
 	Mode    string `yaml:"mode"`
+	// Spot also lists spot pairs (Asset.Type == InstrumentSpot).
+	Spot bool `yaml:"spot"`
 
 	TimeoutRaw     string        `yaml:"timeout"`
 	Timeout        time.Duration `yaml:"-"`
@@ -3,11 +3,12 @@ package market_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	market "nof0-api/pkg/market"
-	_ "nof0-api/pkg/market/exchanges/hyperliquid"
+	"nof0-api/pkg/market/exchanges/hyperliquid"
 )
 
 func TestLoadMarketConfig(t *testing.T) {
@@ -116,3 +117,18 @@ providers:
 		})
 	}
 }
+
+func TestLoadMarketConfigStreamProvider(t *testing.T) {
+	cfg, err := market.LoadConfigFromReader(strings.NewReader(`
+providers:
+  hyperliquid_stream:
+    type: hyperliquid_stream
+    testnet: true
+`))
+	assert.NoError(t, err)
+	providers, err := cfg.BuildProviders()
+	assert.NoError(t, err)
+	stream, ok := providers["hyperliquid_stream"].(*hyperliquid.StreamProvider)
+	assert.True(t, ok, "hyperliquid_stream should build a StreamProvider")
+	assert.NoError(t, stream.Close(), "a stream that never started closes cleanly")
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"nof0-api/pkg/ratelimit"
 )
 
 const (
@@ -32,10 +34,15 @@ type Client struct {
 	maxRetries int
 	logger     *log.Logger
 
+	rateLimiter  *ratelimit.Limiter
+	rateLimitOff bool
+	includeSpot  bool
+
 	symbolsMu        sync.RWMutex
 	symbolIndex      map[string]string
 	assetCtxBySymbol map[string]AssetCtx
 	universeMeta     map[string]UniverseEntry
+	spotPairs        map[string]spotListing // keyed by wire name, see spot.go
 }
 
 // Option configures a new Client.
@@ -77,6 +84,43 @@ func WithLogger(l *log.Logger) Option {
 	}
 }
 
+// WithRateLimiter replaces the shared per-host weight bucket.
+func WithRateLimiter(l *ratelimit.Limiter) Option {
+	return func(c *Client) {
+		if l != nil {
+			c.rateLimiter = l
+		}
+	}
+}
+
+// WithoutRateLimit disables client-side request budgeting.
+func WithoutRateLimit() Option {
+	return func(c *Client) {
+		c.rateLimitOff = true
+	}
+}
+
+// WithSpotMarkets adds spot pairs to the symbol directory so they can be
+// listed and snapshotted by "BASE/QUOTE" or wire name.
+func WithSpotMarkets() Option {
+	return func(c *Client) {
+		c.includeSpot = true
+	}
+}
+
+// limiter returns the weight bucket shared with every Hyperliquid client
+// talking to the same host, so market polling and trading draw from one
+// per-IP budget. Market data uses the lowest lane.
+func (c *Client) limiter() *ratelimit.Limiter {
+	if c.rateLimitOff {
+		return nil
+	}
+	if c.rateLimiter != nil {
+		return c.rateLimiter
+	}
+	return ratelimit.HyperliquidIP(c.baseURL)
+}
+
 // NewClient constructs a Hyperliquid API client.
 func NewClient(opts ...Option) *Client {
 	httpClient := &http.Client{Timeout: defaultHTTPTimeout}
@@ -107,6 +151,9 @@ func (c *Client) doRequest(ctx context.Context, req InfoRequest, result interfac
 	var lastErr error
 	backoff := defaultRetryBackoffBase
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if err := c.limiter().Wait(ctx, ratelimit.LaneMarket, ratelimit.HyperliquidInfoWeight(req.Type)); err != nil {
+			return err
+		}
 		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL, bytes.NewReader(payload))
 		if err != nil {
 			return fmt.Errorf("hyperliquid: build request: %w", err)
@@ -125,6 +172,9 @@ func (c *Client) doRequest(ctx context.Context, req InfoRequest, result interfac
 			if readErr != nil {
 				lastErr = fmt.Errorf("hyperliquid: read response: %w", readErr)
 			} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				if resp.StatusCode == http.StatusTooManyRequests {
+					c.limiter().Drain()
+				}
 				lastErr = fmt.Errorf("hyperliquid: http status %d: %s", resp.StatusCode, string(body))
 			} else {
 				if result != nil {
@@ -211,10 +261,19 @@ func (c *Client) refreshSymbolDirectory(ctx context.Context) error {
 		universe[canonical] = entry
 	}
 
+	var spotPairs map[string]spotListing
+	if c.includeSpot {
+		var err error
+		if spotPairs, err = c.loadSpotDirectory(ctx, index, assetCtx); err != nil {
+			return err
+		}
+	}
+
 	c.symbolsMu.Lock()
 	c.symbolIndex = index
 	c.assetCtxBySymbol = assetCtx
 	c.universeMeta = universe
+	c.spotPairs = spotPairs
 	c.symbolsMu.Unlock()
 	return nil
 }
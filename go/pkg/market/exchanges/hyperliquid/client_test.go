@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"nof0-api/pkg/market"
+	"nof0-api/pkg/ratelimit"
 )
 
 func TestProviderSnapshot(t *testing.T) {
@@ -87,6 +88,21 @@ func TestClientGetMarketInfo(t *testing.T) {
 	require.InDelta(t, 150.0, info.OpenInterest, 1e-9)
 }
 
+func TestClientSpendsMarketLaneBudget(t *testing.T) {
+	server, _ := newMockHyperliquidServer(t)
+	defer server.Close()
+
+	limiter := ratelimit.NewLimiter("market-test", 1200, time.Minute)
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithRateLimiter(limiter))
+	_, err := client.GetKlines(context.Background(), "BTC", "3m", 20)
+	require.NoError(t, err)
+
+	stats := limiter.Stats()
+	require.Greater(t, stats.Lanes["market"].Requests, int64(0))
+	require.Zero(t, stats.Lanes["trade"].Requests)
+	require.InDelta(t, stats.Capacity-stats.Lanes["market"].Weight, stats.Tokens, 1)
+}
+
 // TestClientGetMarketInfoErrors tests error handling in GetMarketInfo.
 func TestClientGetMarketInfoErrors(t *testing.T) {
 	tests := []struct {
@@ -216,6 +232,52 @@ func TestListAssetsReflectsDelistedStatus(t *testing.T) {
 	require.False(t, pepe.IsActive)
 }
 
+func TestProviderSpotMarkets(t *testing.T) {
+	server, _ := newMockHyperliquidServer(t)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithMaxRetries(0), WithSpotMarkets())
+	provider := &Provider{client: client, timeout: defaultProviderTimeout}
+	assets, err := provider.ListAssets(ctx)
+	require.NoError(t, err)
+	require.Len(t, assets, 4)
+	bySymbol := make(map[string]market.Asset, len(assets))
+	for _, asset := range assets {
+		bySymbol[asset.Symbol] = asset
+	}
+	require.Equal(t, market.InstrumentPerp, bySymbol["BTC"].Type)
+	hype, ok := bySymbol["HYPE/USDC"]
+	require.True(t, ok)
+	require.Equal(t, market.InstrumentSpot, hype.Type)
+	require.Equal(t, "HYPE", hype.Base)
+	require.Equal(t, "USDC", hype.Quote)
+	require.Equal(t, 2, hype.Precision)
+	require.Equal(t, "@107", hype.RawMetadata["coin"])
+	require.Equal(t, 10107, hype.RawMetadata["assetIndex"])
+	require.Equal(t, market.InstrumentSpot, bySymbol["PURR/USDC"].Type)
+
+	snapshot, err := provider.Snapshot(ctx, "hype/usdc")
+	require.NoError(t, err)
+	require.Equal(t, "@107", snapshot.Symbol)
+	require.InDelta(t, 25.0, snapshot.Price.Last, 1e-9)
+	require.Nil(t, snapshot.Funding)
+	require.Nil(t, snapshot.OpenInterest)
+}
+
+func TestProviderListAssetsSkipsSpotByDefault(t *testing.T) {
+	server, provider := newMockProvider(t)
+	defer server.Close()
+
+	assets, err := provider.ListAssets(context.Background())
+	require.NoError(t, err)
+	for _, asset := range assets {
+		require.Equal(t, market.InstrumentPerp, asset.Type)
+	}
+	_, err = provider.client.GetMarketInfo(context.Background(), "HYPE/USDC")
+	require.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
 // --- helpers ---
 
 func newMockProvider(t *testing.T) (*httptest.Server, *Provider) {
@@ -233,10 +295,12 @@ func newMockHyperliquidServer(t *testing.T) (*httptest.Server, *Client) {
 	intradaySeries := map[string][]float64{
 		"BTC":   makeSequence(111.0, 150.0, 1.0),
 		"kPEPE": makeSequence(0.00080, 0.00119, 0.00001),
+		"@107":  makeSequence(21.1, 25.0, 0.1),
 	}
 	longerSeries := map[string][]float64{
 		"BTC":   makeSequence(91.0, 150.0, 1.0),
 		"kPEPE": makeSequence(0.00060, 0.00119, 0.00001),
+		"@107":  makeSequence(19.1, 25.0, 0.1),
 	}
 
 	candlePayload := make(map[string]map[string][]map[string]interface{})
@@ -282,9 +346,29 @@ func newMockHyperliquidServer(t *testing.T) (*httptest.Server, *Client) {
 		},
 	}
 
+	spotPayload := []interface{}{
+		map[string]interface{}{
+			"tokens": []map[string]interface{}{
+				{"name": "USDC", "szDecimals": 8, "index": 0},
+				{"name": "PURR", "szDecimals": 0, "index": 1},
+				{"name": "HYPE", "szDecimals": 2, "index": 150},
+			},
+			"universe": []map[string]interface{}{
+				{"name": "PURR/USDC", "tokens": []int{1, 0}, "index": 0, "isCanonical": true},
+				{"name": "@107", "tokens": []int{150, 0}, "index": 107, "isCanonical": false},
+			},
+		},
+		[]map[string]interface{}{
+			{"coin": "PURR/USDC", "markPx": "0.1234", "midPx": "0.12345", "prevDayPx": "0.12", "dayNtlVlm": "1000", "dayBaseVlm": "8100"},
+			{"coin": "@107", "markPx": "25.01", "midPx": "25.0", "prevDayPx": "24.9", "dayNtlVlm": "5000000", "dayBaseVlm": "200000"},
+		},
+	}
+
 	allMids := map[string]string{
-		"BTC":   "150",
-		"kPEPE": "0.00095",
+		"BTC":       "150",
+		"kPEPE":     "0.00095",
+		"@107":      "25.0",
+		"PURR/USDC": "0.12345",
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -320,6 +404,8 @@ func newMockHyperliquidServer(t *testing.T) (*httptest.Server, *Client) {
 			writeJSON(w, data)
 		case "metaAndAssetCtxs":
 			writeJSON(w, metaPayload)
+		case "spotMetaAndAssetCtxs":
+			writeJSON(w, spotPayload)
 		case "allMids":
 			writeJSON(w, allMids)
 		default:
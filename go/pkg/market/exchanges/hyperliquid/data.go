@@ -22,34 +22,74 @@ const (
 )
 
 func (c *Client) buildSnapshot(ctx context.Context, symbol string) (*market.Snapshot, []market.PriceTick, error) {
+	in, err := c.fetchSnapshotInputs(ctx, symbol)
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot := assembleSnapshot(in.info, in.lastPrice, newIntradaySeries(in.intraday), newLongerSeries(in.longer))
+	ticks := append(buildPriceTicks(intradayInterval, in.intraday), buildPriceTicks(longerInterval, in.longer)...)
+	return snapshot, ticks, nil
+}
+
+// snapshotInputs is the raw market data a snapshot is derived from.
+type snapshotInputs struct {
+	info      *MarketInfo
+	lastPrice float64
+	intraday  []Kline
+	longer    []Kline
+}
+
+func (c *Client) fetchSnapshotInputs(ctx context.Context, symbol string) (*snapshotInputs, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
 	info, err := c.GetMarketInfo(ctx, symbol)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	intradayKlines, err := c.GetKlines(ctx, info.Symbol, intradayInterval, intradayLookback)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	longerKlines, err := c.GetKlines(ctx, info.Symbol, longerInterval, longerLookback)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	lastPrice, err := c.getCurrentPriceForCanonical(ctx, info.Symbol)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	return &snapshotInputs{info: info, lastPrice: lastPrice, intraday: intradayKlines, longer: longerKlines}, nil
+}
+
+// klineSeries is a kline window together with the series and indicators
+// derived from it.
+type klineSeries struct {
+	klines  []Kline
+	series  *market.SeriesBundle
+	signals *indicatorSnapshot
+}
+
+func newIntradaySeries(klines []Kline) klineSeries {
+	series, signals := buildIntradaySeries(klines)
+	return klineSeries{klines: klines, series: series, signals: signals}
+}
+
+func newLongerSeries(klines []Kline) klineSeries {
+	series, signals := buildLongerSeries(klines)
+	return klineSeries{klines: klines, series: series, signals: signals}
+}
 
-	intradaySeries, intradaySignals := buildIntradaySeries(intradayKlines)
-	longerSeries, longerSignals := buildLongerSeries(longerKlines)
+// assembleSnapshot combines market context, the latest price and both kline
+// windows into a snapshot.
+func assembleSnapshot(info *MarketInfo, lastPrice float64, intraday, longer klineSeries) *market.Snapshot {
+	intradaySignals, longerSignals := intraday.signals, longer.signals
 
-	change1h := calculatePriceChange(lastPrice, priceAt(intradayKlines, intradayChangeLookback))
-	change4h := calculatePriceChange(lastPrice, priceAt(longerKlines, priceChange4hLookback))
+	change1h := calculatePriceChange(lastPrice, priceAt(intraday.klines, intradayChangeLookback))
+	change4h := calculatePriceChange(lastPrice, priceAt(longer.klines, priceChange4hLookback))
 
 	indicatorEMA := make(map[string]float64)
 	indicatorRSI := make(map[string]float64)
@@ -111,12 +151,10 @@ func (c *Client) buildSnapshot(ctx context.Context, symbol string) (*market.Snap
 		Indicators:   indicator,
 		OpenInterest: openInterest,
 		Funding:      funding,
-		Intraday:     intradaySeries,
-		LongTerm:     longerSeries,
+		Intraday:     intraday.series,
+		LongTerm:     longer.series,
 	}
-
-	ticks := append(buildPriceTicks(intradayInterval, intradayKlines), buildPriceTicks(longerInterval, longerKlines)...)
-	return snapshot, ticks, nil
+	return snapshot
 }
 
 func (c *Client) getCurrentPriceForCanonical(ctx context.Context, symbol string) (float64, error) {
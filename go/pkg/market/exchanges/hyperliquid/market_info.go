@@ -54,6 +54,11 @@ func (c *Client) GetMarketInfo(ctx context.Context, symbol string) (*MarketInfo,
 	if !ok {
 		return nil, ErrSymbolNotFound
 	}
+	return marketInfoFromCtx(canonical, ctxData)
+}
+
+// marketInfoFromCtx parses the asset context of canonical.
+func marketInfoFromCtx(canonical string, ctxData AssetCtx) (*MarketInfo, error) {
 	mark, err := parseFloat(ctxData.MarkPx)
 	if err != nil {
 		return nil, fmt.Errorf("hyperliquid: parse mark price: %w", err)
@@ -29,6 +29,12 @@ type Provider struct {
 type providerConfig struct {
 	timeout      time.Duration
 	clientConfig []Option
+
+	// Streaming settings, used by NewStreamProvider only.
+	wsURL        string
+	pingInterval time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
 }
 
 // ProviderOption customises the Hyperliquid provider.
@@ -52,13 +58,24 @@ func WithClientOptions(options ...Option) ProviderOption {
 
 // NewProvider constructs a Hyperliquid market provider.
 func NewProvider(opts ...ProviderOption) *Provider {
+	return newProvider(newProviderConfig(opts))
+}
+
+func newProviderConfig(opts []ProviderOption) *providerConfig {
 	cfg := &providerConfig{
-		timeout: defaultProviderTimeout,
+		timeout:      defaultProviderTimeout,
+		wsURL:        defaultWSURL,
+		pingInterval: defaultPingInterval,
+		minBackoff:   defaultReconnectMin,
+		maxBackoff:   defaultReconnectMax,
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	return cfg
+}
 
+func newProvider(cfg *providerConfig) *Provider {
 	client := NewClient(cfg.clientConfig...)
 	return &Provider{
 		client:    client,
@@ -69,29 +86,38 @@ func NewProvider(opts ...ProviderOption) *Provider {
 
 func init() {
 	market.RegisterProvider("hyperliquid", func(name string, cfg *market.ProviderConfig) (market.Provider, error) {
-		opts := []ProviderOption{}
-		clientOptions := []Option{}
-		if cfg.Timeout > 0 {
-			opts = append(opts, WithTimeout(cfg.Timeout))
-		}
-		if cfg.HTTPTimeout > 0 {
-			clientOptions = append(clientOptions, WithHTTPClient(&http.Client{Timeout: cfg.HTTPTimeout}))
-		}
-		if cfg.Testnet {
-			clientOptions = append(clientOptions, WithBaseURL(testnetBaseURL))
-		}
-		if cfg.MaxRetries > 0 {
-			clientOptions = append(clientOptions, WithMaxRetries(cfg.MaxRetries))
-		}
-		if len(clientOptions) > 0 {
-			opts = append(opts, WithClientOptions(clientOptions...))
-		}
-		provider := NewProvider(opts...)
+		provider := NewProvider(providerOptions(cfg)...)
 		provider.providerID = name
 		return provider, nil
 	})
 }
 
+// providerOptions translates market configuration into provider options.
+func providerOptions(cfg *market.ProviderConfig) []ProviderOption {
+	opts := []ProviderOption{}
+	clientOptions := []Option{}
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithTimeout(cfg.Timeout))
+	}
+	if cfg.HTTPTimeout > 0 {
+		clientOptions = append(clientOptions, WithHTTPClient(&http.Client{Timeout: cfg.HTTPTimeout}))
+	}
+	if cfg.Testnet {
+		clientOptions = append(clientOptions, WithBaseURL(testnetBaseURL))
+		opts = append(opts, WithWebSocketURL(testnetWSURL))
+	}
+	if cfg.MaxRetries > 0 {
+		clientOptions = append(clientOptions, WithMaxRetries(cfg.MaxRetries))
+	}
+	if cfg.Spot {
+		clientOptions = append(clientOptions, WithSpotMarkets())
+	}
+	if len(clientOptions) > 0 {
+		opts = append(opts, WithClientOptions(clientOptions...))
+	}
+	return opts
+}
+
 // Snapshot implements market.Provider by returning an aggregated market snapshot.
 func (p *Provider) Snapshot(ctx context.Context, symbol string) (*market.Snapshot, error) {
 	ctx, cancel := p.withTimeout(ctx)
@@ -138,14 +164,14 @@ func (p *Provider) collectAssets() []market.Asset {
 	p.client.symbolsMu.RLock()
 	defer p.client.symbolsMu.RUnlock()
 
-	assets := make([]market.Asset, 0, len(p.client.symbolIndex))
-	for _, canonical := range p.client.symbolIndex {
-		meta := p.client.universeMeta[canonical]
+	assets := make([]market.Asset, 0, len(p.client.universeMeta)+len(p.client.spotPairs))
+	for canonical, meta := range p.client.universeMeta {
 		ctxData := p.client.assetCtxBySymbol[canonical]
 		asset := market.Asset{
 			Symbol:    canonical,
 			Base:      "", // Hyperliquid uses single-coin naming; leave base/quote empty for now.
 			Quote:     "",
+			Type:      market.InstrumentPerp,
 			Precision: meta.SzDecimals,
 			IsActive:  !meta.IsDelisted,
 			RawMetadata: map[string]any{
@@ -161,6 +187,26 @@ func (p *Provider) collectAssets() []market.Asset {
 		}
 		assets = append(assets, asset)
 	}
+	for canonical, pair := range p.client.spotPairs {
+		ctxData := p.client.assetCtxBySymbol[canonical]
+		assets = append(assets, market.Asset{
+			Symbol:    pair.Symbol,
+			Base:      pair.Base,
+			Quote:     pair.Quote,
+			Type:      market.InstrumentSpot,
+			Precision: pair.SzDecimals,
+			IsActive:  true,
+			RawMetadata: map[string]any{
+				"coin":        canonical,
+				"assetIndex":  pair.AssetIndex,
+				"isCanonical": pair.IsCanonical,
+				"markPx":      ctxData.MarkPx,
+				"dayBaseVlm":  ctxData.DayBaseVlm,
+				"dayNtlVlm":   ctxData.DayNtlVlm,
+				"prevDayPx":   ctxData.PrevDayPx,
+			},
+		})
+	}
 
 	sort.Slice(assets, func(i, j int) bool {
 		return assets[i].Symbol < assets[j].Symbol
@@ -0,0 +1,82 @@
+package hyperliquid
+
+import (
+	"context"
+	"strings"
+)
+
+// spotAssetOffset is added to a spot pair's index to form its exchange
+// asset id.
+const spotAssetOffset = 10000
+
+// spotListing describes a spot pair in the symbol directory.
+type spotListing struct {
+	Symbol      string // "BASE/QUOTE", or the wire name when another pair claims it
+	Base        string
+	Quote       string
+	SzDecimals  int
+	AssetIndex  int
+	IsCanonical bool
+}
+
+// loadSpotDirectory fetches spotMetaAndAssetCtxs and adds every pair to
+// index and assetCtx under its wire name, plus its "BASE/QUOTE" name.
+func (c *Client) loadSpotDirectory(ctx context.Context, index map[string]string, assetCtx map[string]AssetCtx) (map[string]spotListing, error) {
+	var payload SpotMetaAndAssetCtxsResponse
+	if err := c.doRequest(ctx, InfoRequest{Type: "spotMetaAndAssetCtxs"}, &payload); err != nil {
+		return nil, err
+	}
+	tokens := make(map[int]SpotToken, len(payload.Tokens))
+	for _, token := range payload.Tokens {
+		tokens[token.Index] = token
+	}
+	ctxByCoin := make(map[string]AssetCtx, len(payload.AssetCtxs))
+	for _, spotCtx := range payload.AssetCtxs {
+		ctxByCoin[spotCtx.Coin] = spotCtx.AssetCtx
+	}
+
+	pairs := make(map[string]spotListing, len(payload.Universe))
+	claimed := make(map[string]SpotPair, len(payload.Universe))
+	for i, pair := range payload.Universe {
+		canonical := strings.TrimSpace(pair.Name)
+		if canonical == "" || len(pair.Tokens) != 2 {
+			continue
+		}
+		base, okBase := tokens[pair.Tokens[0]]
+		quote, okQuote := tokens[pair.Tokens[1]]
+		if !okBase || !okQuote {
+			continue
+		}
+		index[normalizeKey(canonical)] = canonical
+		if pairCtx, ok := ctxByCoin[canonical]; ok {
+			assetCtx[canonical] = pairCtx
+		} else if i < len(payload.AssetCtxs) {
+			assetCtx[canonical] = payload.AssetCtxs[i].AssetCtx
+		}
+		pairs[canonical] = spotListing{
+			Symbol:      canonical,
+			Base:        base.Name,
+			Quote:       quote.Name,
+			SzDecimals:  base.SzDecimals,
+			AssetIndex:  spotAssetOffset + pair.Index,
+			IsCanonical: pair.IsCanonical,
+		}
+		// Token names are not unique, so the canonical pair claims its
+		// "BASE/QUOTE" name first.
+		display := base.Name + "/" + quote.Name
+		if prev, taken := claimed[display]; !taken || (pair.IsCanonical && !prev.IsCanonical) {
+			claimed[display] = pair
+		}
+	}
+	for display, pair := range claimed {
+		key := normalizeKey(display)
+		if _, taken := index[key]; taken && index[key] != pair.Name {
+			continue
+		}
+		index[key] = pair.Name
+		listing := pairs[pair.Name]
+		listing.Symbol = display
+		pairs[pair.Name] = listing
+	}
+	return pairs, nil
+}
@@ -0,0 +1,548 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"nof0-api/pkg/market"
+)
+
+const (
+	defaultWSURL        = "wss://api.hyperliquid.xyz/ws"
+	testnetWSURL        = "wss://api.hyperliquid-testnet.xyz/ws"
+	defaultPingInterval = 30 * time.Second
+	defaultReconnectMin = 500 * time.Millisecond
+	defaultReconnectMax = 30 * time.Second
+	wsWriteTimeout      = 10 * time.Second
+)
+
+// WithWebSocketURL overrides the websocket endpoint used by NewStreamProvider.
+func WithWebSocketURL(url string) ProviderOption {
+	return func(cfg *providerConfig) {
+		if url != "" {
+			cfg.wsURL = url
+		}
+	}
+}
+
+// WithPingInterval sets how often the stream sends keepalive pings. The
+// connection is considered dead when nothing is received for two intervals.
+func WithPingInterval(interval time.Duration) ProviderOption {
+	return func(cfg *providerConfig) {
+		if interval > 0 {
+			cfg.pingInterval = interval
+		}
+	}
+}
+
+// WithReconnectBackoff bounds the exponential delay between stream reconnects.
+func WithReconnectBackoff(min, max time.Duration) ProviderOption {
+	return func(cfg *providerConfig) {
+		if min > 0 {
+			cfg.minBackoff = min
+		}
+		if max >= cfg.minBackoff {
+			cfg.maxBackoff = max
+		}
+	}
+}
+
+type streamRequest struct {
+	Method       string              `json:"method"`
+	Subscription *streamSubscription `json:"subscription,omitempty"`
+}
+
+type streamSubscription struct {
+	Type     string `json:"type"`
+	Coin     string `json:"coin,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+type streamMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type streamAssetCtx struct {
+	Coin string   `json:"coin"`
+	Ctx  AssetCtx `json:"ctx"`
+}
+
+// StreamProvider serves snapshots from the allMids, candle and
+// activeAssetCtx websocket channels instead of polling the info endpoint.
+//
+// The first Snapshot of a symbol is built over REST, like Provider does, and
+// seeds rolling kline windows that the candle channel then keeps current;
+// indicators are recomputed as candles arrive, so later snapshots need no
+// network round-trip. After a reconnect the windows are backfilled over REST
+// before they are served again. While the feed is down, or a symbol's
+// backfill failed, Snapshot falls back to the REST provider.
+//
+// The feed starts with the first Snapshot and runs until Close.
+type StreamProvider struct {
+	rest   *Provider
+	client *Client
+	url    string
+	dialer *websocket.Dialer
+
+	pingInterval time.Duration
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+
+	startOnce sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+	wake      chan struct{}
+
+	mu      sync.RWMutex
+	live    bool
+	mids    map[string]float64
+	symbols map[string]*streamSymbol
+}
+
+// streamSymbol is the in-memory market state of one tracked symbol.
+type streamSymbol struct {
+	info     *MarketInfo
+	intraday klineSeries
+	longer   klineSeries
+	// stale is set when the feed drops and cleared by the backfill after
+	// the next connect.
+	stale     bool
+	persisted time.Time
+}
+
+// NewStreamProvider constructs a websocket-backed Hyperliquid market provider.
+func NewStreamProvider(opts ...ProviderOption) *StreamProvider {
+	cfg := newProviderConfig(opts)
+	return newStreamProvider(newProvider(cfg), cfg)
+}
+
+func newStreamProvider(rest *Provider, cfg *providerConfig) *StreamProvider {
+	return &StreamProvider{
+		rest:         rest,
+		client:       rest.client,
+		url:          cfg.wsURL,
+		dialer:       websocket.DefaultDialer,
+		pingInterval: cfg.pingInterval,
+		minBackoff:   cfg.minBackoff,
+		maxBackoff:   cfg.maxBackoff,
+		done:         make(chan struct{}),
+		wake:         make(chan struct{}, 1),
+		mids:         make(map[string]float64),
+		symbols:      make(map[string]*streamSymbol),
+	}
+}
+
+func init() {
+	market.RegisterProvider("hyperliquid_stream", func(name string, cfg *market.ProviderConfig) (market.Provider, error) {
+		provider := NewStreamProvider(providerOptions(cfg)...)
+		provider.rest.providerID = name
+		return provider, nil
+	})
+}
+
+// Snapshot implements market.Provider, serving tracked symbols from memory.
+func (s *StreamProvider) Snapshot(ctx context.Context, symbol string) (*market.Snapshot, error) {
+	s.start()
+	ctx, cancel := s.rest.withTimeout(ctx)
+	defer cancel()
+	canonical, err := s.client.canonicalSymbolFor(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	snap, tracked, persist := s.streamSnapshot(canonical)
+	if snap != nil {
+		if persist {
+			s.rest.persistSnapshot(ctx, canonical, snap)
+		}
+		return snap, nil
+	}
+	if tracked {
+		return s.rest.Snapshot(ctx, symbol)
+	}
+	return s.track(ctx, canonical)
+}
+
+// ListAssets implements market.Provider; the asset directory is served over REST.
+func (s *StreamProvider) ListAssets(ctx context.Context) ([]market.Asset, error) {
+	return s.rest.ListAssets(ctx)
+}
+
+// SetPersistence wires a persistence layer for market data.
+func (s *StreamProvider) SetPersistence(persist market.Persistence) {
+	s.rest.SetPersistence(persist)
+}
+
+// Close stops the feed. Snapshots keep working over REST.
+func (s *StreamProvider) Close() error {
+	s.startOnce.Do(func() { close(s.done) })
+	if s.cancel != nil {
+		s.cancel()
+	}
+	<-s.done
+	return nil
+}
+
+func (s *StreamProvider) start() {
+	s.startOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancel = cancel
+		go s.run(ctx)
+	})
+}
+
+// streamSnapshot assembles a snapshot from memory. It returns nil when the
+// symbol is untracked or its state cannot be served, and reports whether
+// the snapshot is due to be persisted.
+func (s *StreamProvider) streamSnapshot(canonical string) (snap *market.Snapshot, tracked, persist bool) {
+	s.mu.Lock()
+	sym, tracked := s.symbols[canonical]
+	mid := s.mids[canonical]
+	if !tracked || !s.live || sym.stale || sym.info == nil || len(sym.intraday.klines) == 0 || !(mid > 0) {
+		s.mu.Unlock()
+		return nil, tracked, false
+	}
+	info, intraday, longer := *sym.info, sym.intraday, sym.longer
+	now := time.Now()
+	if now.Sub(sym.persisted) >= snapshotCacheTTL {
+		sym.persisted, persist = now, true
+	}
+	s.mu.Unlock()
+	return assembleSnapshot(&info, mid, intraday, longer), true, persist
+}
+
+// track builds the first snapshot of canonical over REST, seeds its state
+// and asks the feed to subscribe to it.
+func (s *StreamProvider) track(ctx context.Context, canonical string) (*market.Snapshot, error) {
+	in, err := s.client.fetchSnapshotInputs(ctx, canonical)
+	if err != nil {
+		return nil, err
+	}
+	sym := &streamSymbol{
+		info:      in.info,
+		intraday:  newIntradaySeries(in.intraday),
+		longer:    newLongerSeries(in.longer),
+		persisted: time.Now(),
+	}
+	s.mu.Lock()
+	if _, ok := s.symbols[canonical]; !ok {
+		s.symbols[canonical] = sym
+		if _, ok := s.mids[canonical]; !ok {
+			s.mids[canonical] = in.lastPrice
+		}
+	}
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	snap := assembleSnapshot(in.info, in.lastPrice, sym.intraday, sym.longer)
+	s.rest.persistSnapshot(ctx, canonical, snap)
+	s.recordTicks(ctx, canonical, intradayInterval, in.intraday)
+	s.recordTicks(ctx, canonical, longerInterval, in.longer)
+	return snap, nil
+}
+
+func (s *StreamProvider) run(ctx context.Context) {
+	defer close(s.done)
+	backoff := s.minBackoff
+	for {
+		connected, err := s.session(ctx)
+		s.markStale()
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = s.minBackoff
+		}
+		logx.WithContext(ctx).Errorf("hyperliquid: market stream disconnected: %v (reconnect in %s)", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// session runs a single connection until it fails or ctx is cancelled. It
+// reports whether the connection was established so the caller can reset
+// its backoff.
+func (s *StreamProvider) session(ctx context.Context) (bool, error) {
+	conn, _, err := s.dialer.DialContext(ctx, s.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("hyperliquid: dial %s: %w", s.url, err)
+	}
+	defer conn.Close()
+
+	if err := s.write(conn, streamRequest{Method: "subscribe", Subscription: &streamSubscription{Type: "allMids"}}); err != nil {
+		return true, fmt.Errorf("hyperliquid: subscribe allMids: %w", err)
+	}
+	subscribed := make(map[string]bool)
+	coins, err := s.subscribeTracked(conn, subscribed)
+	if err != nil {
+		return true, err
+	}
+	// Pushes queue up unread until the windows are backfilled, so they
+	// apply on top of the REST data.
+	s.backfill(ctx, coins)
+
+	msgs := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(2 * s.pingInterval))
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case msgs <- data:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+			return true, ctx.Err()
+		case err := <-readErr:
+			return true, err
+		case <-ticker.C:
+			if err := s.write(conn, streamRequest{Method: "ping"}); err != nil {
+				return true, fmt.Errorf("hyperliquid: ping: %w", err)
+			}
+		case <-s.wake:
+			if _, err := s.subscribeTracked(conn, subscribed); err != nil {
+				return true, err
+			}
+		case data := <-msgs:
+			if err := s.handleMessage(ctx, data); err != nil {
+				logx.WithContext(ctx).Errorf("hyperliquid: market stream: %v", err)
+			}
+		}
+	}
+}
+
+// subscribeTracked subscribes to every tracked symbol not yet in subscribed
+// and returns the symbols it added.
+func (s *StreamProvider) subscribeTracked(conn *websocket.Conn, subscribed map[string]bool) ([]string, error) {
+	s.mu.RLock()
+	var coins []string
+	for coin := range s.symbols {
+		if !subscribed[coin] {
+			coins = append(coins, coin)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Strings(coins)
+	for _, coin := range coins {
+		subs := []streamSubscription{
+			{Type: "activeAssetCtx", Coin: coin},
+			{Type: "candle", Coin: coin, Interval: intradayInterval},
+			{Type: "candle", Coin: coin, Interval: longerInterval},
+		}
+		for i := range subs {
+			if err := s.write(conn, streamRequest{Method: "subscribe", Subscription: &subs[i]}); err != nil {
+				return nil, fmt.Errorf("hyperliquid: subscribe %s %s: %w", subs[i].Type, coin, err)
+			}
+		}
+		subscribed[coin] = true
+	}
+	return coins, nil
+}
+
+// backfill reloads the kline windows of coins over REST and marks the feed
+// live. A symbol whose backfill fails stays stale, and is served over REST,
+// until the next connect.
+func (s *StreamProvider) backfill(ctx context.Context, coins []string) {
+	for _, coin := range coins {
+		intraday, err := s.client.GetKlines(ctx, coin, intradayInterval, intradayLookback)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("hyperliquid: market stream backfill symbol=%s err=%v", coin, err)
+			continue
+		}
+		longer, err := s.client.GetKlines(ctx, coin, longerInterval, longerLookback)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("hyperliquid: market stream backfill symbol=%s err=%v", coin, err)
+			continue
+		}
+		s.mu.Lock()
+		if sym, ok := s.symbols[coin]; ok {
+			sym.intraday = newIntradaySeries(intraday)
+			sym.longer = newLongerSeries(longer)
+			sym.stale = false
+		}
+		s.mu.Unlock()
+		s.recordTicks(ctx, coin, intradayInterval, intraday)
+		s.recordTicks(ctx, coin, longerInterval, longer)
+	}
+	s.mu.Lock()
+	s.live = true
+	s.mu.Unlock()
+}
+
+func (s *StreamProvider) markStale() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.live = false
+	for _, sym := range s.symbols {
+		sym.stale = true
+	}
+}
+
+func (s *StreamProvider) write(conn *websocket.Conn, msg streamRequest) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(msg)
+}
+
+func (s *StreamProvider) handleMessage(ctx context.Context, data []byte) error {
+	var msg streamMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("decode message: %w", err)
+	}
+	switch msg.Channel {
+	case "allMids":
+		var payload struct {
+			Mids map[string]string `json:"mids"`
+		}
+		if err := json.Unmarshal(msg.Data, &payload); err != nil {
+			return fmt.Errorf("decode allMids: %w", err)
+		}
+		s.mu.Lock()
+		for coin, raw := range payload.Mids {
+			if mid, err := parseFloat(raw); err == nil && mid > 0 {
+				s.mids[coin] = mid
+			}
+		}
+		s.mu.Unlock()
+	case "candle":
+		// Candles are pushed one at a time; accept a batch as well.
+		raw := bytes.TrimSpace(msg.Data)
+		if len(raw) > 0 && raw[0] == '{' {
+			raw = append(append([]byte{'['}, raw...), ']')
+		}
+		var candles CandleResponse
+		if err := json.Unmarshal(raw, &candles); err != nil {
+			return fmt.Errorf("decode candle: %w", err)
+		}
+		for _, c := range candles {
+			s.applyCandle(ctx, c.S, c.I, Kline{
+				OpenTime:  c.T,
+				Open:      c.O,
+				High:      c.H,
+				Low:       c.L,
+				Close:     c.C,
+				Volume:    c.V,
+				CloseTime: c.TClose,
+			})
+		}
+	case "activeAssetCtx", "activeSpotAssetCtx":
+		var payload streamAssetCtx
+		if err := json.Unmarshal(msg.Data, &payload); err != nil {
+			return fmt.Errorf("decode %s: %w", msg.Channel, err)
+		}
+		info, err := marketInfoFromCtx(payload.Coin, payload.Ctx)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		if sym, ok := s.symbols[payload.Coin]; ok {
+			sym.info = info
+		}
+		s.mu.Unlock()
+	case "error":
+		return fmt.Errorf("server error: %s", string(msg.Data))
+	}
+	return nil
+}
+
+// applyCandle merges k into the coin's window for interval and recomputes
+// that window's indicators. A candle that closes is persisted.
+func (s *StreamProvider) applyCandle(ctx context.Context, coin, interval string, k Kline) {
+	s.mu.Lock()
+	sym, ok := s.symbols[coin]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	var closed *Kline
+	switch interval {
+	case intradayInterval:
+		var klines []Kline
+		klines, closed = mergeKline(sym.intraday.klines, k, intradayLookback)
+		sym.intraday = newIntradaySeries(klines)
+	case longerInterval:
+		var klines []Kline
+		klines, closed = mergeKline(sym.longer.klines, k, longerLookback)
+		sym.longer = newLongerSeries(klines)
+	}
+	s.mu.Unlock()
+	if closed != nil {
+		s.recordTicks(ctx, coin, interval, []Kline{*closed})
+	}
+}
+
+// mergeKline returns window with k applied: an update to a candle in the
+// window replaces it, a newer candle is appended and the window trimmed to
+// limit. It also returns the candle k closed, if any. window is not modified,
+// since snapshots assembled from it may still be reading it.
+func mergeKline(window []Kline, k Kline, limit int) ([]Kline, *Kline) {
+	for i := len(window) - 1; i >= 0; i-- {
+		if window[i].OpenTime == k.OpenTime {
+			out := append([]Kline(nil), window...)
+			out[i] = k
+			return out, nil
+		}
+		if window[i].OpenTime < k.OpenTime {
+			break
+		}
+	}
+	if len(window) > 0 && k.OpenTime < window[len(window)-1].OpenTime {
+		return window, nil
+	}
+	var closed *Kline
+	if len(window) > 0 {
+		last := window[len(window)-1]
+		closed = &last
+	}
+	out := append(append(make([]Kline, 0, len(window)+1), window...), k)
+	if len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out, closed
+}
+
+func (s *StreamProvider) recordTicks(ctx context.Context, coin, interval string, klines []Kline) {
+	persist := s.rest.persistence
+	if persist == nil {
+		return
+	}
+	ticks := buildPriceTicks(interval, klines)
+	if len(ticks) == 0 {
+		return
+	}
+	if err := persist.RecordPriceSeries(ctx, s.rest.providerName(), coin, ticks); err != nil {
+		logx.WithContext(ctx).Errorf("hyperliquid: persist price series symbol=%s err=%v", coin, err)
+	}
+}
@@ -0,0 +1,247 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nof0-api/pkg/market"
+)
+
+// fakeMarketFeed is a websocket server that records subscriptions and hands
+// each connection to the test.
+type fakeMarketFeed struct {
+	server *httptest.Server
+	conns  chan *websocket.Conn
+
+	mu   sync.Mutex
+	subs []streamSubscription
+}
+
+func newFakeMarketFeed(t *testing.T) *fakeMarketFeed {
+	t.Helper()
+	f := &fakeMarketFeed{conns: make(chan *websocket.Conn, 4)}
+	upgrader := websocket.Upgrader{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		f.conns <- conn
+		for {
+			var req streamRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			if req.Subscription != nil {
+				f.mu.Lock()
+				f.subs = append(f.subs, *req.Subscription)
+				f.mu.Unlock()
+			}
+		}
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeMarketFeed) url() string {
+	return "ws" + strings.TrimPrefix(f.server.URL, "http")
+}
+
+func (f *fakeMarketFeed) subscribed(sub streamSubscription) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, s := range f.subs {
+		if s == sub {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeMarketFeed) accept(t *testing.T) *websocket.Conn {
+	t.Helper()
+	select {
+	case conn := <-f.conns:
+		return conn
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not connect")
+		return nil
+	}
+}
+
+func push(t *testing.T, conn *websocket.Conn, channel string, data interface{}) {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteJSON(streamMessage{Channel: channel, Data: raw}))
+}
+
+// infoCounter counts info requests by type.
+type infoCounter struct {
+	next http.RoundTripper
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+func (c *infoCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	var info InfoRequest
+	_ = json.Unmarshal(body, &info)
+	c.mu.Lock()
+	c.seen[info.Type]++
+	c.mu.Unlock()
+	return c.next.RoundTrip(req)
+}
+
+func (c *infoCounter) count(typ string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if typ == "" {
+		total := 0
+		for _, n := range c.seen {
+			total += n
+		}
+		return total
+	}
+	return c.seen[typ]
+}
+
+func newTestStreamProvider(t *testing.T) (*StreamProvider, *fakeMarketFeed, *infoCounter) {
+	t.Helper()
+	_, client := newMockHyperliquidServer(t)
+	counter := &infoCounter{next: client.httpClient.Transport, seen: make(map[string]int)}
+	if counter.next == nil {
+		counter.next = http.DefaultTransport
+	}
+	client.httpClient = &http.Client{Transport: counter}
+	feed := newFakeMarketFeed(t)
+	cfg := newProviderConfig([]ProviderOption{
+		WithWebSocketURL(feed.url()),
+		WithReconnectBackoff(10*time.Millisecond, 50*time.Millisecond),
+	})
+	stream := newStreamProvider(&Provider{client: client, timeout: defaultProviderTimeout}, cfg)
+	t.Cleanup(func() { _ = stream.Close() })
+	return stream, feed, counter
+}
+
+func TestStreamProviderServesSnapshotsFromFeed(t *testing.T) {
+	stream, feed, counter := newTestStreamProvider(t)
+	ctx := context.Background()
+
+	first, err := stream.Snapshot(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	rest, err := (&Provider{client: stream.client, timeout: defaultProviderTimeout}).Snapshot(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, rest, first, "the first snapshot is built over REST")
+
+	conn := feed.accept(t)
+	require.Eventually(t, func() bool {
+		return feed.subscribed(streamSubscription{Type: "allMids"}) &&
+			feed.subscribed(streamSubscription{Type: "activeAssetCtx", Coin: "BTC"}) &&
+			feed.subscribed(streamSubscription{Type: "candle", Coin: "BTC", Interval: "3m"}) &&
+			feed.subscribed(streamSubscription{Type: "candle", Coin: "BTC", Interval: "4h"})
+	}, 5*time.Second, 10*time.Millisecond)
+
+	openTime := int64(1_700_000_000_000) + 40*180_000
+	push(t, conn, "candle", map[string]interface{}{
+		"t": openTime, "T": openTime + 179_999, "s": "BTC", "i": "3m",
+		"o": "150", "c": "160", "h": "161", "l": "149", "v": "12",
+	})
+	push(t, conn, "activeAssetCtx", map[string]interface{}{
+		"coin": "BTC",
+		"ctx":  map[string]interface{}{"funding": "0.0002", "openInterest": "200", "markPx": "160.1", "midPx": "160"},
+	})
+	push(t, conn, "allMids", map[string]interface{}{"mids": map[string]string{"BTC": "160"}})
+
+	requests := counter.count("")
+	var snap *market.Snapshot
+	require.Eventually(t, func() bool {
+		snap, err = stream.Snapshot(ctx, "BTC")
+		return err == nil && snap.Price.Last == 160
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, requests, counter.count(""), "streamed snapshots make no info requests")
+
+	require.NotNil(t, snap.OpenInterest)
+	assert.InDelta(t, 200, snap.OpenInterest.Latest, 1e-9)
+	require.NotNil(t, snap.Funding)
+	assert.InDelta(t, 0.0002, snap.Funding.Rate, 1e-12)
+	prices := snap.Intraday.Prices
+	assert.Equal(t, 160.0, prices[len(prices)-1], "the pushed candle extends the intraday window")
+	assert.NotEqual(t, first.Indicators.EMA["EMA20"], snap.Indicators.EMA["EMA20"], "indicators follow the window")
+	assert.InDelta(t, (160.0-131.0)/131.0, snap.Change.OneHour, 1e-9)
+}
+
+func TestStreamProviderBackfillsOnReconnect(t *testing.T) {
+	stream, feed, counter := newTestStreamProvider(t)
+	ctx := context.Background()
+
+	_, err := stream.Snapshot(ctx, "BTC")
+	require.NoError(t, err)
+	conn := feed.accept(t)
+	require.Eventually(t, func() bool {
+		return feed.subscribed(streamSubscription{Type: "candle", Coin: "BTC", Interval: "4h"})
+	}, 5*time.Second, 10*time.Millisecond)
+	push(t, conn, "candle", map[string]interface{}{
+		"t": int64(1_700_000_000_000) + 40*180_000, "s": "BTC", "i": "3m",
+		"o": "150", "c": "170", "h": "171", "l": "149", "v": "12",
+	})
+	require.Eventually(t, func() bool {
+		snap, err := stream.Snapshot(ctx, "BTC")
+		return err == nil && snap.Intraday.Prices[len(snap.Intraday.Prices)-1] == 170
+	}, 5*time.Second, 10*time.Millisecond)
+
+	candles := counter.count("candleSnapshot")
+	require.NoError(t, conn.Close())
+	feed.accept(t)
+	require.Eventually(t, func() bool {
+		snap, err := stream.Snapshot(ctx, "BTC")
+		return err == nil && snap.Intraday.Prices[len(snap.Intraday.Prices)-1] == 150
+	}, 5*time.Second, 10*time.Millisecond, "the window is reloaded from REST after reconnecting")
+	assert.GreaterOrEqual(t, counter.count("candleSnapshot"), candles+2)
+
+	require.NoError(t, stream.Close())
+	streamed, tracked, _ := stream.streamSnapshot("BTC")
+	assert.True(t, tracked)
+	assert.Nil(t, streamed, "a closed stream is not served from memory")
+	snap, err := stream.Snapshot(ctx, "BTC")
+	require.NoError(t, err)
+	assert.Equal(t, "BTC", snap.Symbol, "snapshots fall back to REST")
+}
+
+func TestMergeKline(t *testing.T) {
+	window := []Kline{{OpenTime: 1, Close: 10}, {OpenTime: 2, Close: 20}, {OpenTime: 3, Close: 30}}
+
+	updated, closed := mergeKline(window, Kline{OpenTime: 3, Close: 31}, 3)
+	assert.Nil(t, closed)
+	assert.Equal(t, 31.0, updated[2].Close)
+	assert.Equal(t, 30.0, window[2].Close, "the input window is not modified")
+
+	appended, closed := mergeKline(window, Kline{OpenTime: 4, Close: 40}, 3)
+	require.NotNil(t, closed)
+	assert.Equal(t, int64(3), closed.OpenTime)
+	assert.Equal(t, []Kline{{OpenTime: 2, Close: 20}, {OpenTime: 3, Close: 30}, {OpenTime: 4, Close: 40}}, appended)
+
+	older, closed := mergeKline(window, Kline{OpenTime: 0, Close: 1}, 3)
+	assert.Nil(t, closed)
+	assert.Equal(t, window, older)
+
+	first, closed := mergeKline(nil, Kline{OpenTime: 1, Close: 10}, 3)
+	assert.Nil(t, closed)
+	assert.Len(t, first, 1)
+}
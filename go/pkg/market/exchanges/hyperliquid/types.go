@@ -109,5 +109,61 @@ func (m *MetaAndAssetCtxsResponse) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// SpotMetaAndAssetCtxsResponse contains spot tokens, pairs and per-pair contexts.
+type SpotMetaAndAssetCtxsResponse struct {
+	Tokens    []SpotToken
+	Universe  []SpotPair
+	AssetCtxs []SpotAssetCtx
+}
+
+// SpotToken describes a token tradable on the spot book.
+type SpotToken struct {
+	Name       string `json:"name"`
+	SzDecimals int    `json:"szDecimals"`
+	Index      int    `json:"index"`
+}
+
+// SpotPair describes a spot market. Tokens holds the base and quote token
+// indices; Name is the coin used on the wire, e.g. "PURR/USDC" or "@107".
+type SpotPair struct {
+	Name        string `json:"name"`
+	Tokens      []int  `json:"tokens"`
+	Index       int    `json:"index"`
+	IsCanonical bool   `json:"isCanonical"`
+}
+
+// SpotAssetCtx holds per-pair market context. Perp-only fields such as
+// funding stay empty.
+type SpotAssetCtx struct {
+	AssetCtx
+	Coin string `json:"coin"`
+}
+
+// UnmarshalJSON decodes the [meta, assetCtxs] array payload.
+func (m *SpotMetaAndAssetCtxsResponse) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) == 0 {
+		return fmt.Errorf("unexpected spotMetaAndAssetCtxs payload: empty array")
+	}
+	var meta struct {
+		Tokens   []SpotToken `json:"tokens"`
+		Universe []SpotPair  `json:"universe"`
+	}
+	if err := json.Unmarshal(raw[0], &meta); err != nil {
+		return err
+	}
+	m.Tokens = meta.Tokens
+	m.Universe = meta.Universe
+	if len(raw) > 1 {
+		if err := json.Unmarshal(raw[1], &m.AssetCtxs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AllMidsResponse maps symbols to their current mid prices.
 type AllMidsResponse map[string]string
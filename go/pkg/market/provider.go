@@ -27,11 +27,22 @@ type Snapshot struct {
 	LongTerm     *SeriesBundle     // Longer-term time series context
 }
 
+// InstrumentType classifies a tradeable instrument.
+type InstrumentType string
+
+const (
+	// InstrumentPerp is a perpetual future.
+	InstrumentPerp InstrumentType = "perp"
+	// InstrumentSpot is a spot pair.
+	InstrumentSpot InstrumentType = "spot"
+)
+
 // Asset describes a tradeable instrument.
 type Asset struct {
-	Symbol      string         // Exchange-native symbol, e.g. "BTC", "kPEPE"
+	Symbol      string         // Exchange-native symbol, e.g. "BTC", "kPEPE", "HYPE/USDC"
 	Base        string         // Optional base asset
 	Quote       string         // Optional quote asset
+	Type        InstrumentType // Instrument type; empty means perpetual
 	Precision   int            // Price precision when available
 	IsActive    bool           // Whether the asset is currently tradeable
 	RawMetadata map[string]any // Exchange-specific fields for callers that need more detail
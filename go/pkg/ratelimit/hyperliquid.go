@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hyperliquid budgets REST traffic per IP by weight (1200 per minute across
+// info and exchange requests) and signed actions per address (an initial
+// buffer of 10000 requests, growing with traded volume; one request per 10s
+// once exhausted).
+const (
+	HyperliquidIPWeightPerMinute = 1200
+	HyperliquidAddressBuffer     = 10000
+	HyperliquidAddressRefill     = 10 * time.Second
+)
+
+// HyperliquidInfoWeight returns the weight of an info request of the given
+// type. Endpoints that also charge per returned item are counted at their
+// base weight.
+func HyperliquidInfoWeight(reqType string) float64 {
+	switch reqType {
+	case "l2Book", "allMids", "clearinghouseState", "orderStatus", "spotClearinghouseState", "exchangeStatus":
+		return 2
+	case "userRole":
+		return 60
+	default:
+		return 20
+	}
+}
+
+// HyperliquidActionWeight returns the IP weight of a signed action carrying
+// batchLen orders or cancels (1 + floor(batchLen/40)).
+func HyperliquidActionWeight(batchLen int) float64 {
+	if batchLen < 0 {
+		batchLen = 0
+	}
+	return float64(1 + batchLen/40)
+}
+
+var (
+	hlMu        sync.Mutex
+	hlIP        = make(map[string]*Limiter)
+	hlAddresses = make(map[string]*Limiter)
+)
+
+// HyperliquidIP returns the process-wide IP bucket for the API host of
+// endpoint (a URL or bare host), shared by every client talking to it.
+func HyperliquidIP(endpoint string) *Limiter {
+	host := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.ToLower(host)
+	hlMu.Lock()
+	defer hlMu.Unlock()
+	if l, ok := hlIP[host]; ok {
+		return l
+	}
+	l := NewLimiter("hyperliquid_ip:"+host, HyperliquidIPWeightPerMinute, time.Minute)
+	hlIP[host] = l
+	return l
+}
+
+// HyperliquidAddress returns the process-wide bucket for signed actions of
+// address. It starts with the venue's initial buffer and refills at the
+// exhausted-budget rate; SetTokens can align it with the venue-reported
+// allowance.
+func HyperliquidAddress(address string) *Limiter {
+	key := strings.ToLower(strings.TrimSpace(address))
+	hlMu.Lock()
+	defer hlMu.Unlock()
+	if l, ok := hlAddresses[key]; ok {
+		return l
+	}
+	l := NewLimiter("hyperliquid_address:"+key, HyperliquidAddressBuffer, HyperliquidAddressBuffer*HyperliquidAddressRefill, WithReserve(0))
+	hlAddresses[key] = l
+	return l
+}
@@ -0,0 +1,31 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperliquidWeights(t *testing.T) {
+	assert.Equal(t, 2.0, HyperliquidInfoWeight("clearinghouseState"))
+	assert.Equal(t, 2.0, HyperliquidInfoWeight("l2Book"))
+	assert.Equal(t, 60.0, HyperliquidInfoWeight("userRole"))
+	assert.Equal(t, 20.0, HyperliquidInfoWeight("metaAndAssetCtxs"))
+	assert.Equal(t, 20.0, HyperliquidInfoWeight("candleSnapshot"))
+
+	assert.Equal(t, 1.0, HyperliquidActionWeight(1))
+	assert.Equal(t, 1.0, HyperliquidActionWeight(39))
+	assert.Equal(t, 2.0, HyperliquidActionWeight(40))
+	assert.Equal(t, 3.0, HyperliquidActionWeight(85))
+}
+
+func TestHyperliquidSharedBuckets(t *testing.T) {
+	info := HyperliquidIP("https://api.hyperliquid.xyz/info")
+	assert.Same(t, info, HyperliquidIP("https://API.hyperliquid.xyz/exchange"), "info and exchange share the per-IP budget")
+	assert.NotSame(t, info, HyperliquidIP("https://api.hyperliquid-testnet.xyz/info"))
+	assert.Equal(t, float64(HyperliquidIPWeightPerMinute), info.Stats().Capacity)
+
+	addr := HyperliquidAddress("0xABC")
+	assert.Same(t, addr, HyperliquidAddress("0xabc"))
+	assert.Equal(t, float64(HyperliquidAddressBuffer), addr.Stats().Capacity)
+}
@@ -0,0 +1,278 @@
+// Package ratelimit provides weight-aware token buckets with priority lanes
+// for venues that budget requests by weight rather than count.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Lane ranks callers competing for one bucket. Higher lanes are served first
+// and may dip into headroom that lower lanes must leave untouched, so order
+// placement is never starved by polling.
+type Lane int
+
+const (
+	// LaneMarket is market-data polling (snapshots, candles, directories).
+	LaneMarket Lane = iota
+	// LaneAccount is account, position, fill and order-status queries.
+	LaneAccount
+	// LaneTrade is signed actions: orders, cancels, leverage and transfers.
+	LaneTrade
+
+	numLanes = int(LaneTrade) + 1
+)
+
+// String returns the lane name used in logs and metrics.
+func (l Lane) String() string {
+	switch l {
+	case LaneMarket:
+		return "market"
+	case LaneAccount:
+		return "account"
+	case LaneTrade:
+		return "trade"
+	default:
+		return fmt.Sprintf("lane(%d)", int(l))
+	}
+}
+
+func (l Lane) valid() bool {
+	return l >= LaneMarket && l <= LaneTrade
+}
+
+// maxPoll bounds how long a waiter sleeps before re-checking the bucket, so
+// it notices refunds and released higher-lane waiters promptly.
+const maxPoll = 250 * time.Millisecond
+
+// Limiter is a token bucket holding up to capacity weight units, refilled
+// continuously at capacity per window. Lane L may only take tokens while at
+// least reserve × (LaneTrade − L) remain afterwards, and never while a
+// higher lane is waiting. It is safe for concurrent use.
+type Limiter struct {
+	name     string
+	capacity float64
+	rate     float64 // tokens per second
+	reserve  float64
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	waiting [numLanes]int
+	lanes   [numLanes]LaneStats
+}
+
+// Option customises a Limiter.
+type Option func(*Limiter)
+
+// WithReserve sets the headroom, in weight units, kept free for each lane
+// above the caller's (default 5% of capacity).
+func WithReserve(reserve float64) Option {
+	return func(l *Limiter) {
+		if reserve >= 0 {
+			l.reserve = reserve
+		}
+	}
+}
+
+// WithClock overrides the time source (primarily for tests).
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		if now != nil {
+			l.now = now
+		}
+	}
+}
+
+// NewLimiter returns a full bucket named name that allows capacity weight per
+// window.
+func NewLimiter(name string, capacity float64, window time.Duration, opts ...Option) *Limiter {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	l := &Limiter{
+		name:     name,
+		capacity: capacity,
+		rate:     capacity / window.Seconds(),
+		reserve:  capacity * 0.05,
+		now:      time.Now,
+		sleep:    sleepCtx,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.tokens = capacity
+	l.last = l.now()
+	return l
+}
+
+// Name returns the bucket name.
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+// Wait blocks until weight tokens are available to lane and takes them. It
+// returns ctx's error if ctx ends first, and an error when weight can never
+// fit in the bucket.
+func (l *Limiter) Wait(ctx context.Context, lane Lane, weight float64) error {
+	if l == nil || weight <= 0 {
+		return nil
+	}
+	if !lane.valid() {
+		return fmt.Errorf("ratelimit: %s: invalid lane %d", l.name, int(lane))
+	}
+	if weight > l.capacity-l.floor(lane) {
+		return fmt.Errorf("ratelimit: %s: weight %.0f exceeds %s lane budget %.0f", l.name, weight, lane, l.capacity-l.floor(lane))
+	}
+	start := l.now()
+	queued := false
+	defer func() {
+		if queued {
+			l.mu.Lock()
+			l.waiting[lane]--
+			l.mu.Unlock()
+		}
+	}()
+	for {
+		l.mu.Lock()
+		l.refillLocked()
+		if !l.higherWaitingLocked(lane) && l.tokens-weight >= l.floor(lane) {
+			l.tokens -= weight
+			waited := l.now().Sub(start)
+			st := &l.lanes[lane]
+			st.Requests++
+			st.Weight += weight
+			if queued {
+				st.Throttled++
+				st.Waited += waited
+			}
+			tokens := l.tokens
+			l.mu.Unlock()
+			observe(l.name, lane, weight, waited, queued, tokens)
+			return nil
+		}
+		if !queued {
+			queued = true
+			l.waiting[lane]++
+		}
+		deficit := weight + l.floor(lane) - l.tokens
+		l.mu.Unlock()
+
+		delay := maxPoll
+		if deficit > 0 {
+			if d := time.Duration(deficit / l.rate * float64(time.Second)); d < delay {
+				delay = d
+			}
+		}
+		if delay < time.Millisecond {
+			delay = time.Millisecond
+		}
+		if err := l.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Drain empties the bucket, e.g. after the venue answered 429, so callers
+// back off until it refills.
+func (l *Limiter) Drain() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.refillLocked()
+	l.tokens = 0
+	l.mu.Unlock()
+	setTokens(l.name, 0)
+}
+
+// SetTokens overrides the available budget, e.g. with a venue-reported
+// allowance. It is clamped to [0, capacity].
+func (l *Limiter) SetTokens(tokens float64) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.refillLocked()
+	l.tokens = clamp(tokens, 0, l.capacity)
+	tokens = l.tokens
+	l.mu.Unlock()
+	setTokens(l.name, tokens)
+}
+
+// Stats summarises a limiter's budget consumption.
+type Stats struct {
+	Name     string               `json:"name"`
+	Tokens   float64              `json:"tokens"`
+	Capacity float64              `json:"capacity"`
+	Lanes    map[string]LaneStats `json:"lanes"`
+}
+
+// LaneStats counts what one lane consumed and how long it waited.
+type LaneStats struct {
+	Requests  int64         `json:"requests"`
+	Weight    float64       `json:"weight"`
+	Throttled int64         `json:"throttled"` // requests that had to wait
+	Waited    time.Duration `json:"waited"`
+}
+
+// Stats returns the current budget and per-lane consumption.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refillLocked()
+	out := Stats{Name: l.name, Tokens: l.tokens, Capacity: l.capacity, Lanes: make(map[string]LaneStats, numLanes)}
+	for lane := LaneMarket; lane <= LaneTrade; lane++ {
+		out.Lanes[lane.String()] = l.lanes[lane]
+	}
+	return out
+}
+
+func (l *Limiter) floor(lane Lane) float64 {
+	return l.reserve * float64(LaneTrade-lane)
+}
+
+func (l *Limiter) higherWaitingLocked(lane Lane) bool {
+	for higher := lane + 1; higher <= LaneTrade; higher++ {
+		if l.waiting[higher] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Limiter) refillLocked() {
+	now := l.now()
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens = clamp(l.tokens+elapsed*l.rate, 0, l.capacity)
+	}
+	l.last = now
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advances only when a waiter sleeps.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+	return nil
+}
+
+func newFakeLimiter(capacity float64, window time.Duration, opts ...Option) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	l := NewLimiter("test", capacity, window, append(opts, WithClock(clock.Now))...)
+	l.sleep = clock.Sleep
+	return l, clock
+}
+
+func TestLimiterSpendsWeightAndRefills(t *testing.T) {
+	l, clock := newFakeLimiter(1200, time.Minute, WithReserve(0))
+	ctx := context.Background()
+	start := clock.Now()
+
+	require.NoError(t, l.Wait(ctx, LaneAccount, 1000))
+	require.NoError(t, l.Wait(ctx, LaneAccount, 200))
+	assert.Equal(t, start, clock.Now(), "a full bucket admits without waiting")
+	assert.InDelta(t, 0, l.Stats().Tokens, 1e-9)
+
+	require.NoError(t, l.Wait(ctx, LaneAccount, 20))
+	waited := clock.Now().Sub(start)
+	assert.InDelta(t, time.Second.Seconds(), waited.Seconds(), 0.01, "20 weight refills in one second at 1200/min")
+
+	stats := l.Stats().Lanes["account"]
+	assert.Equal(t, int64(3), stats.Requests)
+	assert.Equal(t, 1220.0, stats.Weight)
+	assert.Equal(t, int64(1), stats.Throttled)
+	assert.Greater(t, stats.Waited, time.Duration(0))
+}
+
+func TestLimiterKeepsHeadroomForHigherLanes(t *testing.T) {
+	l, clock := newFakeLimiter(100, time.Minute, WithReserve(10))
+	ctx := context.Background()
+	start := clock.Now()
+
+	require.NoError(t, l.Wait(ctx, LaneMarket, 80))
+	assert.Equal(t, start, clock.Now())
+	// Market must leave 2 × 10 for account and trade; 20 tokens remain.
+	require.NoError(t, l.Wait(ctx, LaneTrade, 15), "trade may use the reserve")
+	assert.Equal(t, start, clock.Now())
+
+	require.NoError(t, l.Wait(ctx, LaneMarket, 1))
+	assert.True(t, clock.Now().After(start), "market waited until the reserve refilled")
+	assert.GreaterOrEqual(t, l.Stats().Tokens, 19.0-1e-9)
+
+	err := l.Wait(ctx, LaneMarket, 90)
+	assert.ErrorContains(t, err, "exceeds market lane budget")
+}
+
+func TestLimiterServesTradeBeforeWaitingMarket(t *testing.T) {
+	// Real clock: 100 weight per 250ms.
+	l := NewLimiter("priority", 100, 250*time.Millisecond, WithReserve(0))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, l.Wait(ctx, LaneMarket, 100))
+
+	var mu sync.Mutex
+	var order []Lane
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, l.Wait(ctx, LaneMarket, 60))
+			mu.Lock()
+			order = append(order, LaneMarket)
+			mu.Unlock()
+		}()
+	}
+	time.Sleep(20 * time.Millisecond) // market waiters are queued first
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, l.Wait(ctx, LaneTrade, 60))
+		mu.Lock()
+		order = append(order, LaneTrade)
+		mu.Unlock()
+	}()
+	wg.Wait()
+
+	require.Len(t, order, 5)
+	assert.Equal(t, LaneTrade, order[0], "trade actions are never starved by polling")
+}
+
+func TestLimiterContextAndDrain(t *testing.T) {
+	l := NewLimiter("ctx", 10, time.Hour, WithReserve(0))
+	require.NoError(t, l.Wait(context.Background(), LaneTrade, 10))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Wait(ctx, LaneTrade, 1), context.DeadlineExceeded)
+
+	l.SetTokens(50)
+	assert.InDelta(t, 10, l.Stats().Tokens, 1e-6, "SetTokens clamps to capacity")
+	l.Drain()
+	assert.InDelta(t, 0, l.Stats().Tokens, 1e-6)
+
+	var nilLimiter *Limiter
+	assert.NoError(t, nilLimiter.Wait(context.Background(), LaneTrade, 1), "a nil limiter admits everything")
+}
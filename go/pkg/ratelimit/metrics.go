@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/zeromicro/go-zero/core/metric"
+)
+
+// Prometheus series are recorded only when go-zero's prometheus exporter is
+// enabled; Limiter.Stats is always available.
+var (
+	metricWeight = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "nof0",
+		Subsystem: "ratelimit",
+		Name:      "weight_total",
+		Help:      "Weight units consumed from a rate-limit bucket.",
+		Labels:    []string{"bucket", "lane"},
+	})
+	metricRequests = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "nof0",
+		Subsystem: "ratelimit",
+		Name:      "requests_total",
+		Help:      "Requests admitted by a rate-limit bucket.",
+		Labels:    []string{"bucket", "lane", "throttled"},
+	})
+	metricWait = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: "nof0",
+		Subsystem: "ratelimit",
+		Name:      "wait_seconds",
+		Help:      "Time requests waited for rate-limit budget.",
+		Labels:    []string{"bucket", "lane"},
+		Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	})
+	metricTokens = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "nof0",
+		Subsystem: "ratelimit",
+		Name:      "tokens",
+		Help:      "Weight units left in a rate-limit bucket after the last request.",
+		Labels:    []string{"bucket"},
+	})
+)
+
+func observe(bucket string, lane Lane, weight float64, waited time.Duration, throttled bool, tokens float64) {
+	laneName := lane.String()
+	metricWeight.Add(weight, bucket, laneName)
+	flag := "false"
+	if throttled {
+		flag = "true"
+		metricWait.ObserveFloat(waited.Seconds(), bucket, laneName)
+	}
+	metricRequests.Inc(bucket, laneName, flag)
+	metricTokens.Set(tokens, bucket)
+}
+
+func setTokens(bucket string, tokens float64) {
+	metricTokens.Set(tokens, bucket)
+}